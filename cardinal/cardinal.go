@@ -2,7 +2,9 @@ package cardinal
 
 import (
 	"errors"
+	"path/filepath"
 	"reflect"
+	"runtime"
 	"strconv"
 
 	"github.com/rotisserie/eris"
@@ -47,6 +49,27 @@ func RegisterSystems(w *World, sys ...System) error {
 	return w.SystemManager.registerSystems(false, sys...)
 }
 
+// RegisterSystem registers a single system with options. Use WithSystemReads, WithSystemWrites and
+// WithSystemStructuralChanges to declare the components the system accesses, which allows it to be run concurrently
+// with other systems it does not conflict with. Use WithSystemStage, WithSystemBefore, WithSystemAfter and
+// WithRunCondition to decide when the system runs.
+func RegisterSystem(w *World, sys System, opts ...SystemOption) error {
+	if w.worldStage.Current() != worldstage.Init {
+		return eris.Errorf(
+			"world state is %s, expected %s to register systems",
+			w.worldStage.Current(),
+			worldstage.Init,
+		)
+	}
+	return w.SystemManager.registerSystem(false, SystemName(sys), sys, opts...)
+}
+
+// SystemName returns the name a system is registered with when it is registered with RegisterSystem or
+// RegisterSystems. It can be used to refer to the system in WithSystemBefore and WithSystemAfter.
+func SystemName(sys System) string {
+	return filepath.Base(runtime.FuncForPC(reflect.ValueOf(sys).Pointer()).Name())
+}
+
 func RegisterInitSystems(w *World, sys ...System) error {
 	if w.worldStage.Current() != worldstage.Init {
 		return eris.Errorf(
@@ -58,7 +81,7 @@ func RegisterInitSystems(w *World, sys ...System) error {
 	return w.SystemManager.registerSystems(true, sys...)
 }
 
-func RegisterComponent[T types.Component](w *World) error {
+func RegisterComponent[T types.Component](w *World, opts ...component.Option[T]) error {
 	if w.worldStage.Current() != worldstage.Init {
 		return eris.Errorf(
 			"world state is %s, expected %s to register component",
@@ -67,7 +90,7 @@ func RegisterComponent[T types.Component](w *World) error {
 		)
 	}
 
-	compMetadata, err := component.NewComponentMetadata[T]()
+	compMetadata, err := component.NewComponentMetadata[T](opts...)
 	if err != nil {
 		return err
 	}
@@ -80,13 +103,87 @@ func RegisterComponent[T types.Component](w *World) error {
 	return nil
 }
 
-func MustRegisterComponent[T types.Component](w *World) {
-	err := RegisterComponent[T](w)
+func MustRegisterComponent[T types.Component](w *World, opts ...component.Option[T]) {
+	err := RegisterComponent[T](w, opts...)
 	if err != nil {
 		panic(err)
 	}
 }
 
+// RegisterComponentMigration registers a migration from the Old to the New version of a component. Both types must
+// return the same Name(). When the schema stored for the component matches Old, every stored instance is converted
+// with migrate when the game starts, and the stored schema is updated. Migrations of a component are applied in order
+// of their version, so a component can be migrated through several versions at once.
+//
+// Migrations must be registered before the component. Adding fields to a component does not need a migration: the
+// new fields are set to the value from component.WithDefault, or to their zero value.
+//
+// Usage:
+//
+//	cardinal.RegisterComponentMigration(world, 1, func(old HealthV1) (Health, error) {
+//		return Health{Current: old.HP, Max: old.HP}, nil
+//	})
+//	cardinal.RegisterComponent[Health](world)
+func RegisterComponentMigration[Old, New types.Component](
+	w *World, version int, migrate func(Old) (New, error),
+) error {
+	if w.worldStage.Current() != worldstage.Init {
+		return eris.Errorf(
+			"world state is %s, expected %s to register component migration",
+			w.worldStage.Current(),
+			worldstage.Init,
+		)
+	}
+
+	migration, err := component.NewMigration[Old, New](version, migrate)
+	if err != nil {
+		return err
+	}
+	return w.RegisterMigration(migration)
+}
+
+// RegisterSpatialIndex maintains an index of the positions of the entities that have the component T, which is used
+// by the WithinRadius, WithinBox and KNearest searches. position returns the position of an entity from the value of
+// its component. The component must be registered first.
+//
+// Usage:
+//
+//	cardinal.RegisterSpatialIndex(world, func(loc Location) (float64, float64) {
+//		return float64(loc.X), float64(loc.Y)
+//	}, cardinal.WithSpatialCellSize(64))
+func RegisterSpatialIndex[T types.Component](
+	w *World, position func(T) (x, y float64), opts ...SpatialIndexOption,
+) error {
+	if w.worldStage.Current() != worldstage.Init {
+		return eris.Errorf(
+			"world state is %s, expected %s to register spatial index",
+			w.worldStage.Current(),
+			worldstage.Init,
+		)
+	}
+
+	var t T
+	c, err := w.GetComponentByName(t.Name())
+	if err != nil {
+		return eris.Wrapf(err, "component %q must be registered before its spatial index", t.Name())
+	}
+	config := spatialIndexConfig{cellSize: gamestate.DefaultSpatialCellSize}
+	for _, opt := range opts {
+		opt(&config)
+	}
+	return w.entityStore.RegisterSpatialIndex(c, func(value any) (float64, float64, error) {
+		switch comp := value.(type) {
+		case T:
+			x, y := position(comp)
+			return x, y, nil
+		case *T:
+			x, y := position(*comp)
+			return x, y, nil
+		}
+		return 0, 0, eris.Errorf("component %q has unexpected type %T", t.Name(), value)
+	}, config.cellSize)
+}
+
 func EachMessage[In any, Out any](wCtx WorldContext, fn func(TxData[In]) (Out, error)) error {
 	var msg MessageType[In, Out]
 	msgType := reflect.TypeOf(msg)
@@ -122,6 +219,10 @@ func RegisterMessage[In any, Out any](world *World, name string, opts ...Message
 		return err
 	}
 
+	if msgType.maxTxsPerTick > 0 {
+		world.txPool.SetMessageLimit(msgType.ID(), msgType.maxTxsPerTick)
+	}
+
 	return nil
 }
 
@@ -148,6 +249,23 @@ func RegisterQuery[Request any, Reply any](
 	return res
 }
 
+// RegisterFeePayer registers the FeePayer that charges the fees of transactions. Fees are not supported in rollup
+// mode, because the base shard does not record them.
+func RegisterFeePayer(w *World, payer FeePayer) error {
+	if w.worldStage.Current() != worldstage.Init {
+		return eris.Errorf(
+			"world state is %s, expected %s to register a fee payer",
+			w.worldStage.Current(),
+			worldstage.Init,
+		)
+	}
+	if w.rollupEnabled {
+		return eris.New("transaction fees are not supported in rollup mode")
+	}
+	w.feePayer = payer
+	return nil
+}
+
 // Create creates a single entity in the world, and returns the id of the newly created entity.
 // At least 1 component must be provided.
 func Create(wCtx WorldContext, components ...types.Component) (_ types.EntityID, err error) {
@@ -183,6 +301,10 @@ func CreateMany(wCtx WorldContext, num int, components ...types.Component) (enti
 		acc = append(acc, c)
 	}
 
+	if err := wCtx.systemAccess().checkStructural(acc...); err != nil {
+		return nil, err
+	}
+
 	// Create the entities
 	entityIDs, err = wCtx.storeManager().CreateManyEntities(num, acc...)
 	if err != nil {
@@ -224,10 +346,14 @@ func SetComponent[T types.Component](wCtx WorldContext, id types.EntityID, compo
 		return err
 	}
 
+	if err := wCtx.systemAccess().checkWrite(c.Name()); err != nil {
+		return err
+	}
+
 	// Store the component
 	err = wCtx.storeManager().SetComponentForEntity(c, id, component)
 	if err != nil {
-		return err
+		return notAliveError(id, err)
 	}
 
 	// Log
@@ -251,10 +377,14 @@ func GetComponent[T types.Component](wCtx WorldContext, id types.EntityID) (comp
 		return nil, err
 	}
 
+	if err := wCtx.systemAccess().checkRead(c.Name()); err != nil {
+		return nil, err
+	}
+
 	// Get current component value
 	compValue, err := wCtx.storeReader().GetComponentForEntity(c, id)
 	if err != nil {
-		return nil, err
+		return nil, notAliveError(id, err)
 	}
 
 	// Type assert the component value to the component type
@@ -271,6 +401,30 @@ func GetComponent[T types.Component](wCtx WorldContext, id types.EntityID) (comp
 	return comp, nil
 }
 
+// IsAlive reports whether the entity exists. Entity IDs are generational handles, so the ID of a removed entity is never
+// alive again, even after its index is reused by a new entity.
+func IsAlive(wCtx WorldContext, id types.EntityID) (bool, error) {
+	_, err := wCtx.storeReader().GetComponentTypesForEntity(id)
+	if err == nil {
+		return true, nil
+	}
+	if eris.Is(err, ErrEntityDoesNotExist) {
+		return false, nil
+	}
+	return false, err
+}
+
+// notAliveError returns ErrEntityDoesNotExist, naming the generation of the entity, if err is because the entity is not
+// alive. Other errors are returned unchanged. The store already looks up the archetype of the entity, so whether it is
+// alive is not checked separately.
+func notAliveError(id types.EntityID, err error) error {
+	if eris.Is(err, ErrEntityDoesNotExist) {
+		return eris.Wrapf(ErrEntityDoesNotExist, "entity %d (index %d, generation %d) is not alive",
+			id, id.Index(), id.Generation())
+	}
+	return err
+}
+
 func UpdateComponent[T types.Component](wCtx WorldContext, id types.EntityID, fn func(*T) *T) (err error) {
 	defer func() { panicOnFatalError(wCtx, err) }()
 
@@ -312,6 +466,10 @@ func AddComponentTo[T types.Component](wCtx WorldContext, id types.EntityID) (er
 		return err
 	}
 
+	if err := checkStructuralChange(wCtx, id, c); err != nil {
+		return err
+	}
+
 	// Add the component to entity
 	err = wCtx.storeManager().AddComponentToEntity(c, id)
 	if err != nil {
@@ -337,6 +495,10 @@ func RemoveComponentFrom[T types.Component](wCtx WorldContext, id types.EntityID
 		return err
 	}
 
+	if err := checkStructuralChange(wCtx, id); err != nil {
+		return err
+	}
+
 	// Remove the component from entity
 	err = wCtx.storeManager().RemoveComponentFromEntity(c, id)
 	if err != nil {
@@ -355,6 +517,10 @@ func Remove(wCtx WorldContext, id types.EntityID) (err error) {
 		return ErrEntityMutationOnReadOnly
 	}
 
+	if err := checkStructuralChange(wCtx, id); err != nil {
+		return err
+	}
+
 	err = wCtx.storeManager().RemoveEntity(id)
 	if err != nil {
 		return err
@@ -362,3 +528,17 @@ func Remove(wCtx WorldContext, id types.EntityID) (err error) {
 
 	return nil
 }
+
+// checkStructuralChange verifies that the running system declared a structural change and a write on every component
+// of the entity, plus any components being added to it.
+func checkStructuralChange(wCtx WorldContext, id types.EntityID, added ...types.ComponentMetadata) error {
+	access := wCtx.systemAccess()
+	if access == nil {
+		return nil
+	}
+	comps, err := wCtx.storeReader().GetComponentTypesForEntity(id)
+	if err != nil {
+		return err
+	}
+	return access.checkStructural(append(comps, added...)...)
+}
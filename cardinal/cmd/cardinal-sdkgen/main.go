@@ -0,0 +1,86 @@
+// Command cardinal-sdkgen generates typed TypeScript and C# clients for a world from its OpenAPI document.
+//
+// Usage:
+//
+//	cardinal-sdkgen [-openapi http://localhost:4040/openapi.json] [-lang typescript,csharp] [-out .]
+//
+// The document is read from the /openapi.json endpoint of a running Cardinal, or from a file.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/rotisserie/eris"
+
+	"pkg.world.dev/world-engine/cardinal/sdkgen"
+	"pkg.world.dev/world-engine/cardinal/types"
+)
+
+const fetchTimeout = 30 * time.Second
+
+func main() {
+	source := flag.String("openapi", "http://localhost:4040/openapi.json",
+		"URL or path of the OpenAPI document of the world")
+	langs := flag.String("lang", "typescript,csharp", "comma separated languages to generate clients in")
+	out := flag.String("out", ".", "directory the clients are written to")
+	csharpNamespace := flag.String("csharp-namespace", "Cardinal", "namespace of the generated C# code")
+	flag.Parse()
+
+	if err := run(*source, *langs, *out, sdkgen.Options{CSharpNamespace: *csharpNamespace}); err != nil {
+		fmt.Fprintln(os.Stderr, "cardinal-sdkgen:", eris.ToString(err, false))
+		os.Exit(1)
+	}
+}
+
+func run(source, langs, out string, opts sdkgen.Options) error {
+	bz, err := readDocument(source)
+	if err != nil {
+		return err
+	}
+	var doc types.OpenAPI
+	if err := json.Unmarshal(bz, &doc); err != nil {
+		return eris.Wrap(err, "failed to decode the OpenAPI document")
+	}
+	if err := os.MkdirAll(out, 0o755); err != nil { //nolint:mnd // the usual permissions of a directory
+		return eris.Wrap(err, "failed to create the output directory")
+	}
+	for _, lang := range strings.Split(langs, ",") {
+		file, err := sdkgen.Generate(doc, sdkgen.Language(strings.TrimSpace(lang)), opts)
+		if err != nil {
+			return err
+		}
+		path := filepath.Join(out, file.Name)
+		if err := os.WriteFile(path, file.Content, 0o644); err != nil { //nolint:mnd,gosec // the client is not secret
+			return eris.Wrapf(err, "failed to write %s", path)
+		}
+		fmt.Println("wrote", path)
+	}
+	return nil
+}
+
+// readDocument reads the OpenAPI document from a URL, or from a file.
+func readDocument(source string) ([]byte, error) {
+	if !strings.HasPrefix(source, "http://") && !strings.HasPrefix(source, "https://") {
+		bz, err := os.ReadFile(source)
+		return bz, eris.Wrapf(err, "failed to read %s", source)
+	}
+	client := http.Client{Timeout: fetchTimeout}
+	res, err := client.Get(source) //nolint:noctx // the client has a timeout
+	if err != nil {
+		return nil, eris.Wrapf(err, "failed to fetch %s", source)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, eris.Errorf("failed to fetch %s: %s", source, res.Status)
+	}
+	bz, err := io.ReadAll(res.Body)
+	return bz, eris.Wrapf(err, "failed to read %s", source)
+}
@@ -3,6 +3,7 @@ package component
 import (
 	"fmt"
 	"reflect"
+	"strings"
 
 	"github.com/invopop/jsonschema"
 	"github.com/rotisserie/eris"
@@ -28,6 +29,7 @@ type componentMetadata[T types.Component] struct {
 	name       string
 	schema     []byte
 	defaultVal types.Component
+	indexes    []types.ComponentIndex
 }
 
 // NewComponentMetadata creates a new component type.
@@ -51,6 +53,9 @@ func NewComponentMetadata[T types.Component](opts ...Option[T]) (
 	for _, opt := range opts {
 		opt(compMetadata)
 	}
+	if err := compMetadata.validateIndexes(); err != nil {
+		return nil, err
+	}
 
 	return compMetadata, nil
 }
@@ -118,6 +123,40 @@ func (c *componentMetadata[T]) ValidateAgainstSchema(targetSchema []byte) error
 	return nil
 }
 
+// Indexes returns the indexes declared with WithHashIndex and WithOrderedIndex.
+func (c *componentMetadata[T]) Indexes() []types.ComponentIndex {
+	return c.indexes
+}
+
+// validateIndexes checks that every indexed field is declared in the schema of the component, and is only indexed
+// once.
+func (c *componentMetadata[T]) validateIndexes() error {
+	if len(c.indexes) == 0 {
+		return nil
+	}
+	schema, err := normalizeSchema(c.schema)
+	if err != nil {
+		return err
+	}
+	seen := make(map[string]bool, len(c.indexes))
+	for _, index := range c.indexes {
+		if seen[index.Field] {
+			return eris.Errorf("field %q of component %q is indexed more than once", index.Field, c.name)
+		}
+		seen[index.Field] = true
+		node := schema
+		for _, name := range strings.Split(index.Field, ".") {
+			properties, _ := node["properties"].(map[string]any)
+			node, _ = properties[name].(map[string]any)
+			if node == nil {
+				return eris.Errorf("cannot index field %q of component %q, the field does not exist",
+					index.Field, c.name)
+			}
+		}
+	}
+	return nil
+}
+
 func (c *componentMetadata[T]) validateDefaultVal() {
 	if !reflect.TypeOf(c.defaultVal).AssignableTo(c.compType) {
 		panic(fmt.Sprintf("default value is not assignable to component type: %s", c.name))
@@ -131,3 +170,19 @@ func WithDefault[T types.Component](defaultVal T) Option[T] {
 		c.validateDefaultVal()
 	}
 }
+
+// WithHashIndex declares an index on a field of the component that supports lookups by value, e.g. with
+// cardinal.FieldEquals. The field is the dot separated path to the field in the JSON encoding of the component.
+func WithHashIndex[T types.Component](field string) Option[T] {
+	return func(c *componentMetadata[T]) {
+		c.indexes = append(c.indexes, types.ComponentIndex{Field: field, Ordered: false})
+	}
+}
+
+// WithOrderedIndex declares an index on a field of the component that supports range lookups, e.g. with
+// cardinal.FieldInRange, in addition to lookups by value.
+func WithOrderedIndex[T types.Component](field string) Option[T] {
+	return func(c *componentMetadata[T]) {
+		c.indexes = append(c.indexes, types.ComponentIndex{Field: field, Ordered: true})
+	}
+}
@@ -5,6 +5,7 @@ import (
 
 	"pkg.world.dev/world-engine/assert"
 	"pkg.world.dev/world-engine/cardinal"
+	"pkg.world.dev/world-engine/cardinal/component"
 	"pkg.world.dev/world-engine/cardinal/filter"
 	"pkg.world.dev/world-engine/cardinal/gamestate"
 	"pkg.world.dev/world-engine/cardinal/types"
@@ -329,7 +330,7 @@ func (OldComponent) Name() string {
 }
 
 type NewComponent struct {
-	Val                     int
+	Val                     string
 	NewFieldToScrewUpSchema int
 }
 
@@ -350,6 +351,93 @@ func TestRegisterComponent_ErrorOnSchemaMismatch(t *testing.T) {
 		"component schema does not match target schema")
 }
 
+type AdditiveComponent struct {
+	Val   int
+	Added int
+}
+
+func (AdditiveComponent) Name() string {
+	return "OldComponent"
+}
+
+func TestRegisterComponent_AddedFieldsAreSetToDefault(t *testing.T) {
+	tf1 := cardinal.NewTestFixture(t, nil)
+	assert.NilError(t, cardinal.RegisterComponent[OldComponent](tf1.World))
+	tf1.StartWorld()
+	id, err := cardinal.Create(cardinal.NewWorldContext(tf1.World), OldComponent{Val: 5})
+	assert.NilError(t, err)
+	tf1.DoTick()
+
+	tf2 := cardinal.NewTestFixture(t, tf1.Redis)
+	assert.NilError(t, cardinal.RegisterComponent[AdditiveComponent](tf2.World,
+		component.WithDefault(AdditiveComponent{Val: 0, Added: 9})))
+	tf2.StartWorld()
+
+	got, err := cardinal.GetComponent[AdditiveComponent](cardinal.NewWorldContext(tf2.World), id)
+	assert.NilError(t, err)
+	assert.Equal(t, *got, AdditiveComponent{Val: 5, Added: 9})
+}
+
+type HealthV1 struct {
+	HP int
+}
+
+func (HealthV1) Name() string {
+	return "health"
+}
+
+type HealthV2 struct {
+	Current int
+	Max     int
+}
+
+func (HealthV2) Name() string {
+	return "health"
+}
+
+type Health struct {
+	Current int
+	Max     int
+	Regen   bool
+}
+
+func (Health) Name() string {
+	return "health"
+}
+
+func TestRegisterComponentMigration_MigratesStoredComponents(t *testing.T) {
+	tf1 := cardinal.NewTestFixture(t, nil)
+	assert.NilError(t, cardinal.RegisterComponent[HealthV1](tf1.World))
+	tf1.StartWorld()
+	id, err := cardinal.Create(cardinal.NewWorldContext(tf1.World), HealthV1{HP: 50})
+	assert.NilError(t, err)
+	tf1.DoTick()
+
+	// The first migration changes the fields of the component, and adding Regen is handled automatically.
+	tf2 := cardinal.NewTestFixture(t, tf1.Redis)
+	assert.NilError(t, cardinal.RegisterComponentMigration(tf2.World, 1, func(old HealthV1) (HealthV2, error) {
+		return HealthV2{Current: old.HP, Max: old.HP * 2}, nil
+	}))
+	assert.NilError(t, cardinal.RegisterComponent[Health](tf2.World))
+	tf2.StartWorld()
+
+	got, err := cardinal.GetComponent[Health](cardinal.NewWorldContext(tf2.World), id)
+	assert.NilError(t, err)
+	assert.Equal(t, *got, Health{Current: 50, Max: 100, Regen: false})
+
+	// The stored schema was updated, so the migration does not need to be registered anymore.
+	tf3 := cardinal.NewTestFixture(t, tf1.Redis)
+	assert.NilError(t, cardinal.RegisterComponent[Health](tf3.World))
+}
+
+func TestRegisterComponentMigration_MustBeRegisteredBeforeComponent(t *testing.T) {
+	tf := cardinal.NewTestFixture(t, nil)
+	assert.NilError(t, cardinal.RegisterComponent[HealthV2](tf.World))
+	assert.ErrorContains(t, cardinal.RegisterComponentMigration(tf.World, 1, func(old HealthV1) (HealthV2, error) {
+		return HealthV2{Current: old.HP, Max: old.HP}, nil
+	}), "must be registered before the component")
+}
+
 func TestGetRegisteredComponents(t *testing.T) {
 	tf1 := cardinal.NewTestFixture(t, nil)
 	world := tf1.World
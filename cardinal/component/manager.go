@@ -2,10 +2,12 @@ package component
 
 import (
 	"fmt"
+	"slices"
+	"strings"
 
 	"github.com/rotisserie/eris"
 
-	"pkg.world.dev/world-engine/cardinal/storage/redis"
+	"pkg.world.dev/world-engine/cardinal/storage"
 	"pkg.world.dev/world-engine/cardinal/types"
 )
 
@@ -15,13 +17,20 @@ type manager struct {
 	registeredComponents map[string]types.ComponentMetadata
 	nextComponentID      types.ComponentID
 	schemaStorage        SchemaStorage
+	migrations           map[string][]Migration
+	pendingMigrations    map[string]SchemaMigration
 }
 
 //nolint:revive // reason: we want this name for World which will take on the name of the manager as a prop
 type ComponentManager interface {
 	RegisterComponent(compMetadata types.ComponentMetadata) error
+	RegisterMigration(migration Migration) error
 	GetComponents() []types.ComponentMetadata
 	GetComponentByName(name string) (types.ComponentMetadata, error)
+	PendingSchemaMigrations() []SchemaMigration
+	CompleteSchemaMigration(name string) error
+	CheckRestoredSchema(name string, schema []byte) error
+	RestoreSchema(name string, schema []byte) error
 }
 
 // NewManager creates a new component manager.
@@ -30,9 +39,33 @@ func NewManager(schemaStorage SchemaStorage) ComponentManager {
 		registeredComponents: make(map[string]types.ComponentMetadata),
 		nextComponentID:      1,
 		schemaStorage:        schemaStorage,
+		migrations:           make(map[string][]Migration),
+		pendingMigrations:    make(map[string]SchemaMigration),
 	}
 }
 
+// RegisterMigration registers a migration of a component. Migrations must be registered before the component they
+// migrate, so that a stored schema that does not match the component can be migrated instead of rejected.
+func (m *manager) RegisterMigration(migration Migration) error {
+	if _, ok := m.registeredComponents[migration.Name()]; ok {
+		return eris.Errorf("migration %d of component %q must be registered before the component",
+			migration.Version(), migration.Name())
+	}
+	migrations := m.migrations[migration.Name()]
+	for _, other := range migrations {
+		if other.Version() == migration.Version() {
+			return eris.Errorf("migration %d of component %q is already registered",
+				migration.Version(), migration.Name())
+		}
+	}
+	migrations = append(migrations, migration)
+	slices.SortFunc(migrations, func(a, b Migration) int {
+		return a.Version() - b.Version()
+	})
+	m.migrations[migration.Name()] = migrations
+	return nil
+}
+
 // RegisterComponent registers component with the component manager.
 // There can only be one component with a given name, which is declared by the user by implementing the Name() method.
 // If there is a duplicate component name, an error will be returned and the component will not be registered.
@@ -46,22 +79,27 @@ func (m *manager) RegisterComponent(compMetadata types.ComponentMetadata) error
 	// If the error is simply the schema not existing yet in storage, we can safely proceed.
 	// However, if it is a different error, we need to terminate and return the error.
 	storedSchema, err := m.schemaStorage.GetSchema(compMetadata.Name())
-	if err != nil && !eris.Is(err, redis.ErrNoSchemaFound) {
+	if err != nil && !eris.Is(err, storage.ErrNoSchemaFound) {
 		return err
 	}
 
 	//nolint:nestif // Comments for nested if statements provided for clarity
 	if storedSchema != nil {
 		// If there is a schema stored in storage, check if it matches the current schema of the component.
-		// If it does not match or schema validation failed, return an error.
+		// If it does not match, the stored instances of the component must be migrated to the current schema. If there
+		// is no way to migrate them, return an error.
 		// If it does match, our job here is done.
 		if err := compMetadata.ValidateAgainstSchema(storedSchema); err != nil {
-			if eris.Is(err, types.ErrComponentSchemaMismatch) {
+			if !eris.Is(err, types.ErrComponentSchemaMismatch) {
+				return eris.Wrap(err, "error when validating component schema against stored schema in storage")
+			}
+			migration, err := planSchemaMigration(compMetadata, storedSchema, m.migrations[compMetadata.Name()])
+			if err != nil {
 				return eris.Wrap(err,
 					fmt.Sprintf("component %q does not match the schema stored in storage", compMetadata.Name()),
 				)
 			}
-			return eris.Wrap(err, "error when validating component schema against stored schema in storage")
+			m.pendingMigrations[compMetadata.Name()] = migration
 		}
 	} else {
 		// If there is no schema stored in storage, store the schema of the component in storage.
@@ -82,6 +120,78 @@ func (m *manager) RegisterComponent(compMetadata types.ComponentMetadata) error
 	return nil
 }
 
+// PendingSchemaMigrations returns the migrations needed to bring the stored instances of the registered components up to
+// date, sorted by component name.
+func (m *manager) PendingSchemaMigrations() []SchemaMigration {
+	migrations := make([]SchemaMigration, 0, len(m.pendingMigrations))
+	for _, migration := range m.pendingMigrations {
+		migrations = append(migrations, migration)
+	}
+	slices.SortFunc(migrations, func(a, b SchemaMigration) int {
+		return strings.Compare(a.Component.Name(), b.Component.Name())
+	})
+	return migrations
+}
+
+// CompleteSchemaMigration stores the schema of the component once all of its stored instances have been migrated.
+func (m *manager) CompleteSchemaMigration(name string) error {
+	migration, ok := m.pendingMigrations[name]
+	if !ok {
+		return eris.Errorf("component %q has no pending schema migration", name)
+	}
+	if err := m.schemaStorage.SetSchema(name, migration.Component.GetSchema()); err != nil {
+		return err
+	}
+	delete(m.pendingMigrations, name)
+	return nil
+}
+
+// CheckRestoredSchema returns an error if the stored instances of the registered component can't be brought from the
+// given schema to the schema of the component.
+func (m *manager) CheckRestoredSchema(name string, schema []byte) error {
+	_, _, err := m.planRestoredSchema(name, schema)
+	return err
+}
+
+// RestoreSchema stores the schema that the stored instances of the registered component have, for instance once the
+// game state was restored from a snapshot, and replaces the pending migration of the component with the one from
+// that schema.
+func (m *manager) RestoreSchema(name string, schema []byte) error {
+	migration, pending, err := m.planRestoredSchema(name, schema)
+	if err != nil {
+		return err
+	}
+	if err := m.schemaStorage.SetSchema(name, schema); err != nil {
+		return err
+	}
+	delete(m.pendingMigrations, name)
+	if pending {
+		m.pendingMigrations[name] = migration
+	}
+	return nil
+}
+
+// planRestoredSchema plans the migration of the registered component from the given schema. It reports whether a
+// migration is needed, which is the case if the schema doesn't match the component.
+func (m *manager) planRestoredSchema(name string, schema []byte) (SchemaMigration, bool, error) {
+	compMetadata, err := m.GetComponentByName(name)
+	if err != nil {
+		return SchemaMigration{}, false, err
+	}
+	err = compMetadata.ValidateAgainstSchema(schema)
+	if err == nil {
+		return SchemaMigration{}, false, nil
+	}
+	if !eris.Is(err, types.ErrComponentSchemaMismatch) {
+		return SchemaMigration{}, false, eris.Wrap(err, "error when validating component schema against restored schema")
+	}
+	migration, err := planSchemaMigration(compMetadata, schema, m.migrations[name])
+	if err != nil {
+		return SchemaMigration{}, false, eris.Wrapf(err, "component %q does not match the restored schema", name)
+	}
+	return migration, true, nil
+}
+
 // GetComponents returns a list of all registered components.
 // Note: The order of the components in the list is not deterministic.
 func (m *manager) GetComponents() []types.ComponentMetadata {
@@ -0,0 +1,304 @@
+package component
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/invopop/jsonschema"
+	"github.com/rotisserie/eris"
+
+	"pkg.world.dev/world-engine/cardinal/codec"
+	"pkg.world.dev/world-engine/cardinal/types"
+)
+
+// Migration converts stored instances of a component from an old version of the component struct to a new one.
+type Migration struct {
+	name       string
+	version    int
+	fromSchema []byte
+	toSchema   []byte
+	migrate    func(bz []byte) ([]byte, error)
+}
+
+// NewMigration creates a migration from the Old to the New version of a component. Both types must return the same
+// Name(). Migrations of the same component are applied in order of their version.
+func NewMigration[Old, New types.Component](version int, migrate func(Old) (New, error)) (Migration, error) {
+	var oldComp Old
+	var newComp New
+	if oldComp.Name() != newComp.Name() {
+		return Migration{}, eris.Errorf("cannot migrate component %q to component %q, the names must match",
+			oldComp.Name(), newComp.Name())
+	}
+	fromSchema, err := jsonschema.ReflectFromType(reflect.TypeOf(oldComp)).MarshalJSON()
+	if err != nil {
+		return Migration{}, eris.Wrap(err, "component must be json serializable")
+	}
+	toSchema, err := jsonschema.ReflectFromType(reflect.TypeOf(newComp)).MarshalJSON()
+	if err != nil {
+		return Migration{}, eris.Wrap(err, "component must be json serializable")
+	}
+	return Migration{
+		name:       oldComp.Name(),
+		version:    version,
+		fromSchema: fromSchema,
+		toSchema:   toSchema,
+		migrate: func(bz []byte) ([]byte, error) {
+			oldVal, err := codec.Decode[Old](bz)
+			if err != nil {
+				return nil, err
+			}
+			newVal, err := migrate(oldVal)
+			if err != nil {
+				return nil, eris.Wrapf(err, "migration %d of component %q failed", version, oldComp.Name())
+			}
+			return codec.Encode(newVal)
+		},
+	}, nil
+}
+
+// Name returns the name of the migrated component.
+func (m Migration) Name() string {
+	return m.name
+}
+
+// Version returns the version of the migration.
+func (m Migration) Version() int {
+	return m.version
+}
+
+// SchemaMigration is the plan to bring every stored instance of a component from the schema in storage to the
+// schema of the registered component.
+type SchemaMigration struct {
+	Component types.ComponentMetadata
+	// FromSchema is the schema the stored instances currently have.
+	FromSchema []byte
+	// Migrate converts a single stored instance of the component to the registered schema. It is nil if the stored
+	// instances do not need to change, and only the stored schema has to be updated.
+	Migrate func(bz []byte) ([]byte, error)
+}
+
+// planSchemaMigration finds the migrations that bring instances of a component with the stored schema up to the
+// schema of the given component. Registered migrations are applied in version order, starting with the one whose old
+// schema matches the stored schema. If the remaining difference only adds fields, the new fields are filled in from
+// the component's default value. An error wrapping types.ErrComponentSchemaMismatch is returned if there is no way
+// to migrate the stored schema.
+func planSchemaMigration(
+	comp types.ComponentMetadata, storedSchema []byte, migrations []Migration,
+) (SchemaMigration, error) {
+	var steps []func(bz []byte) ([]byte, error)
+	currSchema := storedSchema
+	for _, migration := range migrations {
+		if isSameSchema(currSchema, migration.fromSchema) {
+			steps = append(steps, migration.migrate)
+			currSchema = migration.toSchema
+		}
+	}
+
+	if !isSameSchema(currSchema, comp.GetSchema()) {
+		additive, err := isAdditiveSchemaChange(currSchema, comp.GetSchema())
+		if err != nil {
+			return SchemaMigration{}, err
+		}
+		if !additive {
+			return SchemaMigration{}, comp.ValidateAgainstSchema(currSchema)
+		}
+		defaultBz, err := comp.New()
+		if err != nil {
+			return SchemaMigration{}, err
+		}
+		steps = append(steps, func(bz []byte) ([]byte, error) {
+			return mergeDefaults(bz, defaultBz)
+		})
+	}
+
+	if len(steps) == 0 {
+		// Only the names of the types changed. The stored instances are already valid.
+		return SchemaMigration{Component: comp, FromSchema: storedSchema, Migrate: nil}, nil
+	}
+	return SchemaMigration{
+		Component:  comp,
+		FromSchema: storedSchema,
+		Migrate: func(bz []byte) ([]byte, error) {
+			var err error
+			for _, step := range steps {
+				if bz, err = step(bz); err != nil {
+					return nil, err
+				}
+			}
+			// Round trip through the component so the value is encoded exactly like the component would encode it.
+			value, err := comp.Decode(bz)
+			if err != nil {
+				return nil, err
+			}
+			return comp.Encode(value)
+		},
+	}, nil
+}
+
+func isSameSchema(a, b []byte) bool {
+	aVal, err := normalizeSchema(a)
+	if err != nil {
+		return false
+	}
+	bVal, err := normalizeSchema(b)
+	if err != nil {
+		return false
+	}
+	return reflect.DeepEqual(aVal, bVal)
+}
+
+// isAdditiveSchemaChange reports whether newSchema only adds properties to oldSchema. In other words, removing the
+// added properties from newSchema results in oldSchema.
+func isAdditiveSchemaChange(oldSchema, newSchema []byte) (bool, error) {
+	oldVal, err := normalizeSchema(oldSchema)
+	if err != nil {
+		return false, eris.Wrap(err, "failed to normalize stored schema")
+	}
+	newVal, err := normalizeSchema(newSchema)
+	if err != nil {
+		return false, eris.Wrap(err, "failed to normalize component schema")
+	}
+	return reflect.DeepEqual(oldVal, restrictSchema(newVal, oldVal)), nil
+}
+
+// normalizeSchema parses the schema and inlines every definition it references. The schema of a Go type contains
+// the name and package of the type, so two versions of a component with the same fields only have the same
+// normalized schema.
+func normalizeSchema(schema []byte) (map[string]any, error) {
+	var root map[string]any
+	if err := json.Unmarshal(schema, &root); err != nil {
+		return nil, eris.Wrap(err, "")
+	}
+	defs, _ := root["$defs"].(map[string]any)
+	delete(root, "$schema")
+	delete(root, "$id")
+	delete(root, "$defs")
+	resolved, _ := resolveSchemaRefs(root, defs, map[string]bool{}).(map[string]any)
+	return resolved, nil
+}
+
+func resolveSchemaRefs(node any, defs map[string]any, resolving map[string]bool) any {
+	switch v := node.(type) {
+	case map[string]any:
+		if ref, ok := v["$ref"].(string); ok {
+			name := strings.TrimPrefix(ref, "#/$defs/")
+			if def, ok := defs[name]; ok && !resolving[name] {
+				resolving[name] = true
+				defer delete(resolving, name)
+				return resolveSchemaRefs(def, defs, resolving)
+			}
+		}
+		resolved := make(map[string]any, len(v))
+		for key, value := range v {
+			resolved[key] = resolveSchemaRefs(value, defs, resolving)
+		}
+		return resolved
+	case []any:
+		resolved := make([]any, len(v))
+		for i, value := range v {
+			resolved[i] = resolveSchemaRefs(value, defs, resolving)
+		}
+		return resolved
+	default:
+		return v
+	}
+}
+
+// restrictSchema returns a copy of the schema without the properties that are not in the reference schema.
+func restrictSchema(schema, reference map[string]any) map[string]any {
+	restricted := make(map[string]any, len(schema))
+	for key, value := range schema {
+		refValue, ok := reference[key]
+		if !ok {
+			if key != "properties" && key != "required" {
+				restricted[key] = value
+			}
+			continue
+		}
+		valueMap, isMap := value.(map[string]any)
+		refMap, isRefMap := refValue.(map[string]any)
+		switch {
+		case key == "properties" && isMap && isRefMap:
+			// Drop the properties that were added, and restrict the rest.
+			properties := make(map[string]any, len(refMap))
+			for name, property := range valueMap {
+				refProperty, ok := refMap[name]
+				if !ok {
+					continue
+				}
+				propertyMap, isPropertyMap := property.(map[string]any)
+				refPropertyMap, isRefPropertyMap := refProperty.(map[string]any)
+				if isPropertyMap && isRefPropertyMap {
+					properties[name] = restrictSchema(propertyMap, refPropertyMap)
+				} else {
+					properties[name] = property
+				}
+			}
+			restricted[key] = properties
+		case key == "required":
+			restricted[key] = restrictRequired(value, refValue)
+		case isMap && isRefMap:
+			restricted[key] = restrictSchema(valueMap, refMap)
+		default:
+			restricted[key] = value
+		}
+	}
+	return restricted
+}
+
+// restrictRequired drops the required fields that are not required in the reference schema.
+func restrictRequired(required, reference any) any {
+	values, ok := required.([]any)
+	if !ok {
+		return required
+	}
+	refValues, ok := reference.([]any)
+	if !ok {
+		return required
+	}
+	restricted := make([]any, 0, len(refValues))
+	for _, value := range values {
+		if slices.Contains(refValues, value) {
+			restricted = append(restricted, value)
+		}
+	}
+	return restricted
+}
+
+// mergeDefaults adds every field of defaultBz that is missing from bz. Nested objects are merged recursively.
+func mergeDefaults(bz, defaultBz []byte) ([]byte, error) {
+	var value, defaultValue map[string]json.RawMessage
+	if err := json.Unmarshal(bz, &value); err != nil {
+		return nil, eris.Wrap(err, "failed to unmarshal stored component")
+	}
+	if err := json.Unmarshal(defaultBz, &defaultValue); err != nil {
+		return nil, eris.Wrap(err, "failed to unmarshal default component")
+	}
+	if value == nil {
+		return bz, nil
+	}
+	for key, defaultField := range defaultValue {
+		field, ok := value[key]
+		if !ok {
+			value[key] = defaultField
+			continue
+		}
+		if isJSONObject(field) && isJSONObject(defaultField) {
+			merged, err := mergeDefaults(field, defaultField)
+			if err != nil {
+				return nil, err
+			}
+			value[key] = merged
+		}
+	}
+	bz, err := json.Marshal(value)
+	return bz, eris.Wrap(err, "")
+}
+
+func isJSONObject(bz json.RawMessage) bool {
+	trimmed := bytes.TrimSpace(bz)
+	return len(trimmed) > 0 && trimmed[0] == '{'
+}
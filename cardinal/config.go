@@ -17,11 +17,30 @@ import (
 	"pkg.world.dev/world-engine/rift/credentials"
 )
 
+const (
+	// StorageBackendRedis stores the game state in the redis server at REDIS_ADDRESS.
+	StorageBackendRedis = "redis"
+	// StorageBackendBadger stores the game state in an embedded badger database at CARDINAL_STORAGE_PATH.
+	StorageBackendBadger = "badger"
+)
+
+const (
+	// TickOverrunSkip skips the ticks that should have started while a tick overran its budget, so that the next tick
+	// starts on time.
+	TickOverrunSkip = "skip"
+	// TickOverrunCatchUp runs the ticks that should have started while a tick overran its budget right after it, until
+	// the world has caught up.
+	TickOverrunCatchUp = "catch_up"
+)
+
 const (
 	DefaultCardinalNamespace         = "world-1"
 	DefaultCardinalLogLevel          = "info"
 	DefaultRedisAddress              = "localhost:6379"
+	DefaultStorageBackend            = StorageBackendRedis
+	DefaultSnapshotDir               = "./.cardinal/snapshots"
 	DefaultBaseShardSequencerAddress = "localhost:9601"
+	DefaultTickOverrunPolicy         = TickOverrunSkip
 
 	// Toml config file related.
 	configFilePathEnvVariable = "CARDINAL_CONFIG"
@@ -37,17 +56,36 @@ var (
 		zerolog.Disabled.String(),
 	}
 
+	validStorageBackends = []string{
+		StorageBackendRedis,
+		StorageBackendBadger,
+	}
+
+	validTickOverrunPolicies = []string{
+		TickOverrunSkip,
+		TickOverrunCatchUp,
+	}
+
 	defaultConfig = WorldConfig{
-		CardinalNamespace:         DefaultCardinalNamespace,
-		CardinalRollupEnabled:     false,
-		CardinalLogPretty:         false,
-		CardinalLogLevel:          DefaultCardinalLogLevel,
-		RedisAddress:              DefaultRedisAddress,
-		RedisPassword:             "",
-		BaseShardSequencerAddress: DefaultBaseShardSequencerAddress,
-		BaseShardRouterKey:        "",
-		TelemetryTraceEnabled:     false,
-		CardinalTickRate:          0,
+		CardinalNamespace:           DefaultCardinalNamespace,
+		CardinalRollupEnabled:       false,
+		CardinalLogPretty:           false,
+		CardinalLogLevel:            DefaultCardinalLogLevel,
+		RedisAddress:                DefaultRedisAddress,
+		RedisPassword:               "",
+		CardinalStorageBackend:      DefaultStorageBackend,
+		CardinalStoragePath:         "",
+		CardinalSnapshotInterval:    0,
+		CardinalSnapshotDir:         DefaultSnapshotDir,
+		CardinalReceiptLog:          false,
+		CardinalReceiptLogRetention: 0,
+		CardinalTxPoolSize:          0,
+		CardinalTxPersonaLimit:      0,
+		BaseShardSequencerAddress:   DefaultBaseShardSequencerAddress,
+		BaseShardRouterKey:          "",
+		TelemetryTraceEnabled:       false,
+		CardinalTickRate:            0,
+		CardinalTickOverrunPolicy:   DefaultTickOverrunPolicy,
 	}
 )
 
@@ -70,6 +108,38 @@ type WorldConfig struct {
 	// RedisPassword The password for the redis server. Make sure to use a password in production.
 	RedisPassword string `mapstructure:"REDIS_PASSWORD"`
 
+	// CardinalStorageBackend The storage backend for the game state, nonces and component schemas.
+	// Either "redis" (default) or "badger".
+	CardinalStorageBackend string `mapstructure:"CARDINAL_STORAGE_BACKEND"`
+
+	// CardinalStoragePath The directory of the embedded database when the storage backend is "badger".
+	// If empty, the database is kept in memory and the game state is lost on shutdown.
+	CardinalStoragePath string `mapstructure:"CARDINAL_STORAGE_PATH"`
+
+	// CardinalSnapshotInterval The number of ticks between snapshots of the game state. 0 disables snapshots.
+	// On startup, the latest snapshot is restored if it is ahead of the game state storage, and only the ticks after
+	// it are recovered from the base shard.
+	CardinalSnapshotInterval uint64 `mapstructure:"CARDINAL_SNAPSHOT_INTERVAL"`
+
+	// CardinalSnapshotDir The directory snapshots are written to and restored from.
+	CardinalSnapshotDir string `mapstructure:"CARDINAL_SNAPSHOT_DIR"`
+
+	// CardinalReceiptLog When true, the receipts and events of every tick are kept in the storage backend, and can be
+	// looked up by transaction hash, persona tag and tick range long after they leave the receipt history.
+	CardinalReceiptLog bool `mapstructure:"CARDINAL_RECEIPT_LOG"`
+
+	// CardinalReceiptLogRetention The number of ticks kept in the receipt log. The receipts and events of older ticks
+	// are deleted. 0 keeps every tick.
+	CardinalReceiptLogRetention uint64 `mapstructure:"CARDINAL_RECEIPT_LOG_RETENTION"`
+
+	// CardinalTxPoolSize The maximum number of transactions waiting for the next tick. Transactions submitted when the
+	// pool is full are rejected with 429 Too Many Requests. 0 disables the limit.
+	CardinalTxPoolSize uint32 `mapstructure:"CARDINAL_TX_POOL_SIZE"`
+
+	// CardinalTxPersonaLimit The maximum number of transactions a single persona can submit for the next tick.
+	// 0 disables the limit.
+	CardinalTxPersonaLimit uint32 `mapstructure:"CARDINAL_TX_PERSONA_LIMIT"`
+
 	// BaseShardSequencerAddress This is the address that Cardinal will use to sequence and recover to/from base shard.
 	BaseShardSequencerAddress string `mapstructure:"BASE_SHARD_SEQUENCER_ADDRESS"`
 
@@ -81,6 +151,10 @@ type WorldConfig struct {
 
 	// CardinalTickRate The number of ticks per second
 	CardinalTickRate uint64 `mapstructure:"CARDINAL_TICK_RATE"`
+
+	// CardinalTickOverrunPolicy What happens to the ticks that should have started while a tick took longer than its
+	// budget of 1 / CARDINAL_TICK_RATE seconds. Either "skip" (default) or "catch_up".
+	CardinalTickOverrunPolicy string `mapstructure:"CARDINAL_TICK_OVERRUN_POLICY"`
 }
 
 func loadWorldConfig() (*WorldConfig, error) {
@@ -129,6 +203,14 @@ func (w *WorldConfig) Validate() error {
 	if w.CardinalLogLevel == "" || !slices.Contains(validLogLevels, w.CardinalLogLevel) {
 		return eris.New("CARDINAL_LOG_LEVEL must be one of the following: " + strings.Join(validLogLevels, ", "))
 	}
+	if !slices.Contains(validStorageBackends, w.CardinalStorageBackend) {
+		return eris.New("CARDINAL_STORAGE_BACKEND must be one of the following: " +
+			strings.Join(validStorageBackends, ", "))
+	}
+	if !slices.Contains(validTickOverrunPolicies, w.CardinalTickOverrunPolicy) {
+		return eris.New("CARDINAL_TICK_OVERRUN_POLICY must be one of the following: " +
+			strings.Join(validTickOverrunPolicies, ", "))
+	}
 
 	// Validate base shard configs (only required when rollup mode is enabled)
 	if w.CardinalRollupEnabled {
@@ -28,15 +28,24 @@ func TestWorldConfig_LoadFromEnv(t *testing.T) {
 	// This target config intentionally does not use the default config values
 	// to make sure that all custom config is properly loaded from env vars.
 	wantCfg := WorldConfig{
-		CardinalNamespace:         "baz",
-		CardinalRollupEnabled:     false,
-		CardinalLogLevel:          "error",
-		CardinalLogPretty:         true,
-		RedisAddress:              "localhost:7070",
-		RedisPassword:             "bar",
-		BaseShardSequencerAddress: "localhost:8080",
-		BaseShardRouterKey:        "abcdefghijklmnopqrstuvwxyz0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ01",
-		CardinalTickRate:          10,
+		CardinalNamespace:           "baz",
+		CardinalRollupEnabled:       false,
+		CardinalLogLevel:            "error",
+		CardinalLogPretty:           true,
+		RedisAddress:                "localhost:7070",
+		RedisPassword:               "bar",
+		CardinalStorageBackend:      StorageBackendBadger,
+		CardinalStoragePath:         "/tmp/cardinal",
+		CardinalSnapshotInterval:    100,
+		CardinalSnapshotDir:         "/tmp/snapshots",
+		CardinalReceiptLog:          true,
+		CardinalReceiptLogRetention: 10000,
+		CardinalTxPoolSize:          5000,
+		CardinalTxPersonaLimit:      20,
+		BaseShardSequencerAddress:   "localhost:8080",
+		BaseShardRouterKey:          "abcdefghijklmnopqrstuvwxyz0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ01",
+		CardinalTickRate:            10,
+		CardinalTickOverrunPolicy:   TickOverrunCatchUp,
 	}
 
 	// Set env vars to target config values
@@ -46,9 +55,18 @@ func TestWorldConfig_LoadFromEnv(t *testing.T) {
 	t.Setenv("CARDINAL_LOG_PRETTY", strconv.FormatBool(wantCfg.CardinalLogPretty))
 	t.Setenv("REDIS_ADDRESS", wantCfg.RedisAddress)
 	t.Setenv("REDIS_PASSWORD", wantCfg.RedisPassword)
+	t.Setenv("CARDINAL_STORAGE_BACKEND", wantCfg.CardinalStorageBackend)
+	t.Setenv("CARDINAL_STORAGE_PATH", wantCfg.CardinalStoragePath)
+	t.Setenv("CARDINAL_SNAPSHOT_INTERVAL", strconv.FormatUint(wantCfg.CardinalSnapshotInterval, 10))
+	t.Setenv("CARDINAL_SNAPSHOT_DIR", wantCfg.CardinalSnapshotDir)
+	t.Setenv("CARDINAL_RECEIPT_LOG", strconv.FormatBool(wantCfg.CardinalReceiptLog))
+	t.Setenv("CARDINAL_RECEIPT_LOG_RETENTION", strconv.FormatUint(wantCfg.CardinalReceiptLogRetention, 10))
+	t.Setenv("CARDINAL_TX_POOL_SIZE", strconv.FormatUint(uint64(wantCfg.CardinalTxPoolSize), 10))
+	t.Setenv("CARDINAL_TX_PERSONA_LIMIT", strconv.FormatUint(uint64(wantCfg.CardinalTxPersonaLimit), 10))
 	t.Setenv("BASE_SHARD_SEQUENCER_ADDRESS", wantCfg.BaseShardSequencerAddress)
 	t.Setenv("BASE_SHARD_ROUTER_KEY", wantCfg.BaseShardRouterKey)
 	t.Setenv("CARDINAL_TICK_RATE", strconv.FormatUint(wantCfg.CardinalTickRate, 10))
+	t.Setenv("CARDINAL_TICK_OVERRUN_POLICY", wantCfg.CardinalTickOverrunPolicy)
 	gotCfg, err := loadWorldConfig()
 	assert.NilError(t, err)
 
@@ -106,6 +124,34 @@ func TestWorldConfig_Validate_LogLevel(t *testing.T) {
 	})
 }
 
+func TestWorldConfig_Validate_StorageBackend(t *testing.T) {
+	for _, backend := range validStorageBackends {
+		t.Run("If storage backend is set to "+backend+", no errors", func(t *testing.T) {
+			cfg := defaultConfigWithOverrides(WorldConfig{CardinalStorageBackend: backend})
+			assert.NilError(t, cfg.Validate())
+		})
+	}
+
+	t.Run("If storage backend is invalid, error", func(t *testing.T) {
+		cfg := defaultConfigWithOverrides(WorldConfig{CardinalStorageBackend: "foo"})
+		assert.IsError(t, cfg.Validate())
+	})
+}
+
+func TestWorldConfig_Validate_TickOverrunPolicy(t *testing.T) {
+	for _, policy := range validTickOverrunPolicies {
+		t.Run("If tick overrun policy is set to "+policy+", no errors", func(t *testing.T) {
+			cfg := defaultConfigWithOverrides(WorldConfig{CardinalTickOverrunPolicy: policy})
+			assert.NilError(t, cfg.Validate())
+		})
+	}
+
+	t.Run("If tick overrun policy is invalid, error", func(t *testing.T) {
+		cfg := defaultConfigWithOverrides(WorldConfig{CardinalTickOverrunPolicy: "foo"})
+		assert.IsError(t, cfg.Validate())
+	})
+}
+
 func TestWorldConfig_Validate_RollupMode(t *testing.T) {
 	testCases := []struct {
 		name    string
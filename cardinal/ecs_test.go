@@ -4,6 +4,8 @@ import (
 	"errors"
 	"testing"
 
+	"github.com/rotisserie/eris"
+
 	"pkg.world.dev/world-engine/assert"
 	"pkg.world.dev/world-engine/cardinal"
 	"pkg.world.dev/world-engine/cardinal/filter"
@@ -290,6 +292,41 @@ func TestCanRemoveEntity(t *testing.T) {
 	assert.Check(t, err != nil)
 }
 
+func TestStaleEntityIDIsNotAlive(t *testing.T) {
+	tf := cardinal.NewTestFixture(t, nil)
+	world := tf.World
+	assert.NilError(t, cardinal.RegisterComponent[Tuple](world))
+	tf.StartWorld()
+
+	wCtx := cardinal.NewWorldContext(world)
+	stale, err := cardinal.Create(wCtx, Tuple{A: 1})
+	assert.NilError(t, err)
+	alive, err := cardinal.IsAlive(wCtx, stale)
+	assert.NilError(t, err)
+	assert.Check(t, alive)
+
+	assert.NilError(t, cardinal.Remove(wCtx, stale))
+	tf.DoTick()
+
+	// The new entity reuses the index of the removed entity with a new generation.
+	id, err := cardinal.Create(wCtx, Tuple{A: 2})
+	assert.NilError(t, err)
+	assert.Equal(t, id.Index(), stale.Index())
+	assert.Equal(t, id.Generation(), stale.Generation()+1)
+
+	alive, err = cardinal.IsAlive(wCtx, stale)
+	assert.NilError(t, err)
+	assert.Check(t, !alive)
+	_, err = cardinal.GetComponent[Tuple](wCtx, stale)
+	assert.Check(t, eris.Is(err, cardinal.ErrEntityDoesNotExist))
+	err = cardinal.SetComponent[Tuple](wCtx, stale, &Tuple{A: 3})
+	assert.Check(t, eris.Is(err, cardinal.ErrEntityDoesNotExist))
+
+	got, err := cardinal.GetComponent[Tuple](wCtx, id)
+	assert.NilError(t, err)
+	assert.Equal(t, got.A, 2)
+}
+
 type CountComponent struct {
 	Val int
 }
@@ -393,8 +393,10 @@ func TestTransactionsSentToRouterAfterTick(t *testing.T) {
 					},
 				},
 			},
+			txpool.ArrivalOrder.Name,
 			world.CurrentTick(),
 			gomock.Any(),
+			gomock.Any(),
 		).
 		Return(nil).
 		Times(1)
@@ -409,8 +411,10 @@ func TestTransactionsSentToRouterAfterTick(t *testing.T) {
 		SubmitTxBlob(
 			gomock.Any(),
 			txpool.TxMap{},
+			txpool.ArrivalOrder.Name,
 			world.CurrentTick(),
 			gomock.Any(),
+			gomock.Any(),
 		).
 		Return(nil).
 		Times(1)
@@ -418,6 +422,36 @@ func TestTransactionsSentToRouterAfterTick(t *testing.T) {
 	tf.DoTick()
 }
 
+func TestStateRootSentToRouterAfterTick(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	rtr := mocks.NewMockRouter(ctrl)
+	tf := cardinal.NewTestFixture(t, nil, cardinal.WithCustomRouter(rtr))
+	world := tf.World
+	assert.NilError(t, cardinal.RegisterComponent[EnergyComponent](world))
+
+	var stateRoots [][]byte
+	rtr.EXPECT().
+		SubmitTxBlob(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Do(func(_, _, _, _, _ any, stateRoot []byte) {
+			stateRoots = append(stateRoots, stateRoot)
+		}).
+		Return(nil).
+		Times(2)
+	rtr.EXPECT().Start().Times(1)
+	rtr.EXPECT().RegisterGameShard(gomock.Any()).Times(1)
+	tf.StartWorld()
+	tf.DoTick()
+
+	wCtx := cardinal.NewWorldContext(world)
+	_, err := cardinal.Create(wCtx, EnergyComponent{Amt: 10})
+	assert.NilError(t, err)
+	tf.DoTick()
+
+	assert.Equal(t, len(stateRoots), 2)
+	assert.Equal(t, len(stateRoots[1]), 32)
+	assert.Check(t, string(stateRoots[0]) != string(stateRoots[1]))
+}
+
 // setEnvToCardinalRollupMode sets a bunch of environment variables that are required
 // for Cardinal to be able to run in rollup node.
 func setEnvToCardinalRollupMode(t *testing.T) {
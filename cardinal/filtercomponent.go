@@ -3,6 +3,7 @@ package cardinal
 import (
 	"github.com/rotisserie/eris"
 
+	"pkg.world.dev/world-engine/cardinal/gamestate"
 	"pkg.world.dev/world-engine/cardinal/types"
 )
 
@@ -20,6 +21,9 @@ func ComponentFilter[T types.Component](f func(comp T) bool) FilterFn {
 		if err != nil {
 			return false, err
 		}
+		if err := wCtx.systemAccess().checkRead(c.Name()); err != nil {
+			return false, err
+		}
 		// Get current component value
 		compValue, err := wCtx.storeReader().GetComponentForEntity(c, id)
 		if err != nil {
@@ -80,3 +84,70 @@ func OrFilter(fns ...FilterFn) FilterFn {
 		return result, nil
 	}
 }
+
+// FieldEquals returns a filter for entities whose component field is equal to one of the values. The field is the dot
+// separated path to the field in the JSON encoding of the component. When the filter is passed to WhereField and the
+// field is indexed with component.WithHashIndex or component.WithOrderedIndex, a search only visits the entities found
+// in the index.
+func FieldEquals[T types.Component](field string, values ...any) *FieldFilter {
+	var t T
+	return &FieldFilter{
+		component: t.Name(),
+		field:     field,
+		query:     gamestate.IndexQuery{Values: values},
+	}
+}
+
+// FieldInRange returns a filter for entities whose component field is between min and max, inclusive. A nil bound is
+// unbounded. Numbers are compared with numbers, and strings with strings. When the filter is passed to WhereField and
+// the field is indexed with component.WithOrderedIndex, a search only visits the entities found in the index.
+func FieldInRange[T types.Component](field string, minValue, maxValue any) *FieldFilter {
+	var t T
+	return &FieldFilter{
+		component: t.Name(),
+		field:     field,
+		query:     gamestate.IndexQuery{IsRange: true, Min: minValue, Max: maxValue},
+	}
+}
+
+// FieldFilter is a filter on the value of a component field that can be answered by an index. It is created with
+// FieldEquals or FieldInRange, and passed to the WhereField clause of a search. Its Evaluate method is a FilterFn, so
+// it can also be combined with other filters, without an index.
+type FieldFilter struct {
+	component string
+	field     string
+	query     gamestate.IndexQuery
+}
+
+// Evaluate checks the field of a single entity without an index.
+func (f *FieldFilter) Evaluate(wCtx WorldContext, id types.EntityID) (bool, error) {
+	c, err := wCtx.getComponentByName(f.component)
+	if err != nil {
+		return false, err
+	}
+	if err := wCtx.systemAccess().checkRead(c.Name()); err != nil {
+		return false, err
+	}
+	bz, err := wCtx.storeReader().GetComponentForEntityInRawJSON(c, id)
+	if err != nil {
+		return false, err
+	}
+	return f.query.Matches(bz, f.field)
+}
+
+// lookup returns the entities that match the filter from the index on the field. ok is false if there is no index
+// that can answer the filter, in which case the filter has to be evaluated for every entity.
+func (f *FieldFilter) lookup(wCtx WorldContext) (ids []types.EntityID, ok bool) {
+	c, err := wCtx.getComponentByName(f.component)
+	if err != nil {
+		return nil, false
+	}
+	if err := wCtx.systemAccess().checkRead(c.Name()); err != nil {
+		return nil, false
+	}
+	ids, ok, err = wCtx.storeReader().LookupIndex(c, f.field, f.query)
+	if err != nil || !ok {
+		return nil, false
+	}
+	return ids, true
+}
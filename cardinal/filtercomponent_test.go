@@ -0,0 +1,42 @@
+package cardinal
+
+import (
+	"testing"
+
+	"pkg.world.dev/world-engine/assert"
+	"pkg.world.dev/world-engine/cardinal/component"
+	"pkg.world.dev/world-engine/cardinal/filter"
+)
+
+type Gem struct {
+	Color string
+	Carat int
+}
+
+func (Gem) Name() string { return "gem" }
+
+func TestWhereFieldIsAnsweredByTheIndexOfTheField(t *testing.T) {
+	tf := NewTestFixture(t, nil)
+	world := tf.World
+	assert.NilError(t, RegisterComponent[Gem](world, component.WithHashIndex[Gem]("Color")))
+	tf.StartWorld()
+
+	wCtx := NewWorldContext(world)
+	for i := 0; i < 10; i++ {
+		_, err := Create(wCtx, Gem{Color: []string{"red", "blue"}[i%2], Carat: i})
+		assert.NilError(t, err)
+	}
+	tf.DoTick()
+
+	search, ok := NewSearch().Entity(filter.Exact(filter.Component[Gem]())).
+		WhereField(FieldEquals[Gem]("Color", "red")).
+		WhereField(FieldInRange[Gem]("Carat", 4, nil)).(*Search)
+	assert.True(t, ok)
+	candidates, unindexed := search.lookupIndexes(wCtx)
+	// The color is answered by its index, and the unindexed carat is checked for each of the red gems.
+	assert.Equal(t, len(candidates), 5)
+	assert.Equal(t, len(unindexed), 1)
+	count, err := search.Count(wCtx)
+	assert.NilError(t, err)
+	assert.Equal(t, count, 3)
+}
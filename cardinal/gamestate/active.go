@@ -1,36 +1,51 @@
 package gamestate
 
 import (
+	"slices"
+
 	"github.com/rotisserie/eris"
 
 	"pkg.world.dev/world-engine/cardinal/types"
 )
 
-// activeEntities represents a group of entities.
+// activeEntities represents a group of entities. The ids are kept in ascending order, so that a page of entities can
+// be found with a binary search rather than by reading every entity of the archetype.
 type activeEntities struct {
 	ids      []types.EntityID
 	modified bool
 }
 
-// swapRemove removes the given entity EntityID from this list of active entities. This is used when moving
-// an entity from one archetype to another, and then deleting an entity altogether.
-func (a *activeEntities) swapRemove(idToRemove types.EntityID) error {
-	// TODO: The finding and removing of these entity ids can be sped up. We're going with a simple implementation
-	// here to get to an MVP
-	indexOfID := -1
-	for i, id := range a.ids {
-		if idToRemove == id {
-			indexOfID = i
-			break
-		}
+// newActiveEntities returns the given entities in ascending order. Entities saved before they were kept in order are
+// sorted when they are loaded.
+func newActiveEntities(ids []types.EntityID) activeEntities {
+	if !slices.IsSorted(ids) {
+		ids = slices.Clone(ids)
+		slices.Sort(ids)
 	}
-	if indexOfID == -1 {
-		return eris.Errorf("cannot find entity id %d", idToRemove)
+	return activeEntities{ids: ids}
+}
+
+// insert adds the given entity ids to this list of active entities, keeping the list in order.
+func (a *activeEntities) insert(idsToAdd ...types.EntityID) {
+	// The IDs are copied rather than changed in place, as the previous list may be kept to roll back to a savepoint.
+	ids := make([]types.EntityID, 0, len(a.ids)+len(idsToAdd))
+	ids = append(ids, a.ids...)
+	for _, id := range idsToAdd {
+		i, _ := slices.BinarySearch(ids, id)
+		ids = slices.Insert(ids, i, id)
 	}
-	lastIndex := len(a.ids) - 1
-	if indexOfID < lastIndex {
-		a.ids[indexOfID] = a.ids[lastIndex]
+	a.ids = ids
+}
+
+// remove removes the given entity EntityID from this list of active entities. This is used when moving an entity from
+// one archetype to another, and then deleting an entity altogether.
+func (a *activeEntities) remove(idToRemove types.EntityID) error {
+	i, found := slices.BinarySearch(a.ids, idToRemove)
+	if !found {
+		return eris.Errorf("cannot find entity id %d", idToRemove)
 	}
-	a.ids = a.ids[:len(a.ids)-1]
+	ids := make([]types.EntityID, 0, len(a.ids)-1)
+	ids = append(ids, a.ids[:i]...)
+	a.ids = append(ids, a.ids[i+1:]...)
 	return nil
 }
@@ -0,0 +1,425 @@
+package gamestate
+
+import (
+	"bytes"
+	"context"
+	"encoding"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/dgraph-io/badger/v4"
+	"github.com/rotisserie/eris"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var (
+	_ PrimitiveStorage[string] = &BadgerStorage{}
+	_ PrefixScanner            = &BadgerStorage{}
+	_ StatePinner              = &BadgerStorage{}
+)
+
+// BadgerStorage is a PrimitiveStorage backed by an embedded Badger database. Values are stored the same way Redis
+// stores them (numbers and booleans are encoded as strings), so the EntityCommandBuffer behaves identically on
+// either backend.
+//
+// When writes is set, the storage is a transaction: writes are buffered, and nothing is persisted until
+// EndTransaction is called. Reads see the buffered writes. This mirrors the way RedisStorage uses a TxPipeline for
+// transactions. See commitWrites for how a transaction too large for a single Badger transaction is committed.
+type BadgerStorage struct {
+	db     *badger.DB
+	writes map[string]badgerWrite
+	// commitMu is shared by the storage and its transactions. Commits hold it for writing, so that reads never see
+	// part of a transaction that is written in several batches.
+	commitMu *sync.RWMutex
+	tracer   trace.Tracer
+}
+
+// NewBadgerPrimitiveStorage returns a storage backed by the database. A transaction that was interrupted while it was
+// written is completed if it was committed, and discarded otherwise.
+func NewBadgerPrimitiveStorage(db *badger.DB) (BadgerStorage, error) {
+	b := BadgerStorage{
+		db:       db,
+		writes:   nil,
+		commitMu: &sync.RWMutex{},
+		tracer:   otel.Tracer("badger"),
+	}
+	if err := b.recoverCommit(); err != nil {
+		return BadgerStorage{}, err
+	}
+	return b, nil
+}
+
+func (b *BadgerStorage) GetFloat64(ctx context.Context, key string) (float64, error) {
+	s, err := b.getString(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+	res, err := strconv.ParseFloat(s, 64)
+	return res, eris.Wrap(err, "")
+}
+
+func (b *BadgerStorage) GetFloat32(ctx context.Context, key string) (float32, error) {
+	s, err := b.getString(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+	res, err := strconv.ParseFloat(s, 32)
+	return float32(res), eris.Wrap(err, "")
+}
+
+func (b *BadgerStorage) GetUInt64(ctx context.Context, key string) (uint64, error) {
+	s, err := b.getString(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+	res, err := strconv.ParseUint(s, 10, 64)
+	return res, eris.Wrap(err, "")
+}
+
+func (b *BadgerStorage) GetInt64(ctx context.Context, key string) (int64, error) {
+	s, err := b.getString(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+	res, err := strconv.ParseInt(s, 10, 64)
+	return res, eris.Wrap(err, "")
+}
+
+func (b *BadgerStorage) GetInt(ctx context.Context, key string) (int, error) {
+	s, err := b.getString(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+	res, err := strconv.Atoi(s)
+	return res, eris.Wrap(err, "")
+}
+
+func (b *BadgerStorage) GetBool(ctx context.Context, key string) (bool, error) {
+	s, err := b.getString(ctx, key)
+	if err != nil {
+		return false, err
+	}
+	res, err := strconv.ParseBool(s)
+	return res, eris.Wrap(err, "")
+}
+
+func (b *BadgerStorage) GetBytes(ctx context.Context, key string) ([]byte, error) {
+	if w, ok := b.writes[key]; ok {
+		if w.deleted {
+			return nil, eris.Wrap(ErrKeyNotFound, key)
+		}
+		return bytes.Clone(w.value), nil
+	}
+	var bz []byte
+	err := b.view(ctx, func(txn *badger.Txn) error {
+		item, err := txn.Get([]byte(key))
+		if err != nil {
+			return err
+		}
+		bz, err = item.ValueCopy(nil)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return bz, nil
+}
+
+// Get returns the value stored at the given key as a string, matching the behavior of RedisStorage.
+func (b *BadgerStorage) Get(ctx context.Context, key string) (any, error) {
+	return b.getString(ctx, key)
+}
+
+func (b *BadgerStorage) Set(ctx context.Context, key string, value any) error {
+	bz, err := encodeBadgerValue(value)
+	if err != nil {
+		return err
+	}
+	return b.write(ctx, key, badgerWrite{value: bz})
+}
+
+func (b *BadgerStorage) Incr(ctx context.Context, key string) error {
+	return b.add(ctx, key, 1)
+}
+
+func (b *BadgerStorage) Decr(ctx context.Context, key string) error {
+	return b.add(ctx, key, -1)
+}
+
+func (b *BadgerStorage) Delete(ctx context.Context, key string) error {
+	return b.write(ctx, key, badgerWrite{deleted: true})
+}
+
+func (b *BadgerStorage) Close(_ context.Context) error {
+	if b.db.IsClosed() {
+		return eris.Wrap(ErrStorageClosed, "")
+	}
+	return eris.Wrap(b.db.Close(), "")
+}
+
+// Keys returns every key, except the keys used to commit large transactions.
+func (b *BadgerStorage) Keys(ctx context.Context) ([]string, error) {
+	var keys []string
+	err := b.view(ctx, func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.PrefetchValues = false
+		it := txn.NewIterator(opts)
+		defer it.Close()
+		for it.Rewind(); it.Valid(); it.Next() {
+			key := string(it.Item().KeyCopy(nil))
+			if _, ok := b.writes[key]; ok || isBadgerCommitKey(key) {
+				continue
+			}
+			keys = append(keys, key)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for key, w := range b.writes {
+		if !w.deleted {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+// ScanPrefix iterates over the keys with the prefix in a single read transaction, so the values are consistent.
+func (b *BadgerStorage) ScanPrefix(ctx context.Context, prefix string, fn func(key string, value []byte) error) error {
+	err := b.view(ctx, func(txn *badger.Txn) error {
+		return scanBadgerTxn(txn, prefix, func(key string, value []byte) error {
+			if _, ok := b.writes[key]; ok {
+				return nil
+			}
+			return fn(key, value)
+		})
+	})
+	if err != nil {
+		return err
+	}
+	for key, w := range b.writes {
+		if w.deleted || !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		if err := fn(key, bytes.Clone(w.value)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PinState opens a read-only transaction, which keeps reading the state as it was when it was opened while new state
+// is committed. The transaction is discarded by release.
+func (b *BadgerStorage) PinState(_ context.Context) (PrefixScanner, func(), error) {
+	if b.writes != nil {
+		return nil, nil, eris.New("cannot pin the state of badger storage while in a transaction")
+	}
+	if b.db.IsClosed() {
+		return nil, nil, eris.Wrap(ErrStorageClosed, "")
+	}
+	// A commit written in several batches holds commitMu, so the transaction never sees part of it.
+	b.commitMu.RLock()
+	txn := b.db.NewTransaction(false)
+	b.commitMu.RUnlock()
+	return badgerPinnedState{txn: txn}, txn.Discard, nil
+}
+
+// badgerPinnedState scans the state seen by a read-only transaction.
+type badgerPinnedState struct {
+	txn *badger.Txn
+}
+
+func (s badgerPinnedState) ScanPrefix(_ context.Context, prefix string, fn func(key string, value []byte) error) error {
+	return wrapBadgerError(scanBadgerTxn(s.txn, prefix, fn))
+}
+
+// scanBadgerTxn calls fn with every key with the prefix that the transaction sees, and its value. The keys used to
+// commit a transaction in several batches are skipped.
+func scanBadgerTxn(txn *badger.Txn, prefix string, fn func(key string, value []byte) error) error {
+	opts := badger.DefaultIteratorOptions
+	opts.Prefix = []byte(prefix)
+	it := txn.NewIterator(opts)
+	defer it.Close()
+	for it.Rewind(); it.Valid(); it.Next() {
+		item := it.Item()
+		key := string(item.KeyCopy(nil))
+		if isBadgerCommitKey(key) {
+			continue
+		}
+		bz, err := item.ValueCopy(nil)
+		if err != nil {
+			return err
+		}
+		if err := fn(key, bz); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *BadgerStorage) Clear(_ context.Context) error {
+	if b.writes != nil {
+		return eris.New("cannot clear badger storage while in a transaction")
+	}
+	return wrapBadgerError(b.db.DropAll())
+}
+
+func (b *BadgerStorage) StartTransaction(_ context.Context) (Transaction[string], error) {
+	if b.db.IsClosed() {
+		return nil, eris.Wrap(ErrStorageClosed, "")
+	}
+	return &BadgerStorage{
+		db:       b.db,
+		writes:   make(map[string]badgerWrite),
+		commitMu: b.commitMu,
+		tracer:   b.tracer,
+	}, nil
+}
+
+func (b *BadgerStorage) EndTransaction(ctx context.Context) error {
+	_, span := b.tracer.Start(ctx, "badger.transaction.end")
+	defer span.End()
+
+	if b.writes == nil {
+		err := eris.New("current badger dbStorage is not a transaction")
+		span.SetStatus(codes.Error, eris.ToString(err, true))
+		span.RecordError(err)
+		return err
+	}
+
+	err := b.commitWrites(b.writes)
+	b.writes = nil
+	if err != nil {
+		span.SetStatus(codes.Error, eris.ToString(err, true))
+		span.RecordError(err)
+		return err
+	}
+
+	return nil
+}
+
+// view runs fn in a new read-only transaction. It does not see the writes buffered by a transaction.
+func (b *BadgerStorage) view(_ context.Context, fn func(txn *badger.Txn) error) error {
+	b.commitMu.RLock()
+	defer b.commitMu.RUnlock()
+	return wrapBadgerError(b.db.View(fn))
+}
+
+// write buffers the write if the storage is a transaction, otherwise it is committed immediately.
+func (b *BadgerStorage) write(_ context.Context, key string, w badgerWrite) error {
+	if b.writes != nil {
+		b.writes[key] = w
+		return nil
+	}
+	b.commitMu.RLock()
+	defer b.commitMu.RUnlock()
+	return wrapBadgerError(b.db.Update(func(txn *badger.Txn) error {
+		return w.apply(txn, []byte(key))
+	}))
+}
+
+func (b *BadgerStorage) getString(ctx context.Context, key string) (string, error) {
+	bz, err := b.GetBytes(ctx, key)
+	if err != nil {
+		return "", err
+	}
+	return string(bz), nil
+}
+
+// add adds delta to the integer stored at key. A missing key is treated as 0, like the INCR and DECR commands in Redis.
+func (b *BadgerStorage) add(ctx context.Context, key string, delta int64) error {
+	if b.writes != nil {
+		curr, err := b.GetInt64(ctx, key)
+		if errors.Is(err, ErrKeyNotFound) {
+			curr = 0
+		} else if err != nil {
+			return eris.Wrapf(err, "value at key %q is not an integer", key)
+		}
+		return b.write(ctx, key, badgerWrite{value: []byte(strconv.FormatInt(curr+delta, 10))})
+	}
+	b.commitMu.RLock()
+	defer b.commitMu.RUnlock()
+	return wrapBadgerError(b.db.Update(func(txn *badger.Txn) error {
+		var curr int64
+		item, err := txn.Get([]byte(key))
+		switch {
+		case errors.Is(err, badger.ErrKeyNotFound):
+			curr = 0
+		case err != nil:
+			return err
+		default:
+			bz, err := item.ValueCopy(nil)
+			if err != nil {
+				return err
+			}
+			curr, err = strconv.ParseInt(string(bz), 10, 64)
+			if err != nil {
+				return eris.Wrapf(err, "value at key %q is not an integer", key)
+			}
+		}
+		return txn.Set([]byte(key), []byte(strconv.FormatInt(curr+delta, 10)))
+	}))
+}
+
+// encodeBadgerValue converts the value to bytes using the same rules the Redis client uses for command arguments.
+func encodeBadgerValue(value any) ([]byte, error) {
+	switch v := value.(type) {
+	case nil:
+		return []byte{}, nil
+	case string:
+		return []byte(v), nil
+	case []byte:
+		return v, nil
+	case int:
+		return []byte(strconv.FormatInt(int64(v), 10)), nil
+	case int8:
+		return []byte(strconv.FormatInt(int64(v), 10)), nil
+	case int16:
+		return []byte(strconv.FormatInt(int64(v), 10)), nil
+	case int32:
+		return []byte(strconv.FormatInt(int64(v), 10)), nil
+	case int64:
+		return []byte(strconv.FormatInt(v, 10)), nil
+	case uint:
+		return []byte(strconv.FormatUint(uint64(v), 10)), nil
+	case uint8:
+		return []byte(strconv.FormatUint(uint64(v), 10)), nil
+	case uint16:
+		return []byte(strconv.FormatUint(uint64(v), 10)), nil
+	case uint32:
+		return []byte(strconv.FormatUint(uint64(v), 10)), nil
+	case uint64:
+		return []byte(strconv.FormatUint(v, 10)), nil
+	case float32:
+		return []byte(strconv.FormatFloat(float64(v), 'f', -1, 32)), nil
+	case float64:
+		return []byte(strconv.FormatFloat(v, 'f', -1, 64)), nil
+	case bool:
+		if v {
+			return []byte("1"), nil
+		}
+		return []byte("0"), nil
+	case encoding.BinaryMarshaler:
+		bz, err := v.MarshalBinary()
+		return bz, eris.Wrap(err, "")
+	default:
+		return nil, eris.Errorf("can't marshal %T to store in badger", value)
+	}
+}
+
+// wrapBadgerError converts badger specific errors into the general storage errors.
+func wrapBadgerError(err error) error {
+	switch {
+	case errors.Is(err, badger.ErrKeyNotFound):
+		return eris.Wrap(ErrKeyNotFound, err.Error())
+	case errors.Is(err, badger.ErrDBClosed):
+		return eris.Wrap(ErrStorageClosed, err.Error())
+	default:
+		return eris.Wrap(err, "")
+	}
+}
@@ -0,0 +1,194 @@
+package gamestate
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/dgraph-io/badger/v4"
+	"github.com/rotisserie/eris"
+)
+
+// A transaction that is too large for a single Badger transaction is committed in several write batches. The writes
+// are first staged under badgerStagedKeyPrefix, then the commit marker is set, which is the point where the transaction
+// is committed, and only then are the staged writes applied to their keys. If the process stops before the marker is
+// set, the staged writes are discarded when the storage is opened again. If it stops after, they are applied again.
+// The commit marker and the staged keys are never returned by Keys or ScanPrefix.
+const (
+	badgerCommitKeyPrefix = "\x00badger:"
+	badgerStagedKeyPrefix = badgerCommitKeyPrefix + "staged:"
+	badgerCommitMarkerKey = badgerCommitKeyPrefix + "commit"
+)
+
+const (
+	badgerOpSet    byte = 's'
+	badgerOpDelete byte = 'd'
+)
+
+// badgerWrite is a write buffered by a transaction.
+type badgerWrite struct {
+	value   []byte
+	deleted bool
+}
+
+func (w badgerWrite) apply(txn *badger.Txn, key []byte) error {
+	if w.deleted {
+		return txn.Delete(key)
+	}
+	return txn.Set(key, w.value)
+}
+
+func isBadgerCommitKey(key string) bool {
+	return strings.HasPrefix(key, badgerCommitKeyPrefix)
+}
+
+// commitWrites writes the buffered writes of a transaction. Small transactions are written in a single Badger
+// transaction, and the others are staged first, so that they are written completely or not at all.
+func (b *BadgerStorage) commitWrites(writes map[string]badgerWrite) error {
+	b.commitMu.Lock()
+	defer b.commitMu.Unlock()
+
+	txn := b.db.NewTransaction(true)
+	defer txn.Discard()
+	for key, w := range writes {
+		err := w.apply(txn, []byte(key))
+		if errors.Is(err, badger.ErrTxnTooBig) {
+			txn.Discard()
+			return b.commitStaged(writes)
+		} else if err != nil {
+			return wrapBadgerError(err)
+		}
+	}
+	err := txn.Commit()
+	if errors.Is(err, badger.ErrTxnTooBig) {
+		return b.commitStaged(writes)
+	}
+	return wrapBadgerError(err)
+}
+
+// commitStaged stages the writes, sets the commit marker and then applies the staged writes.
+func (b *BadgerStorage) commitStaged(writes map[string]badgerWrite) error {
+	wb := b.db.NewWriteBatch()
+	defer wb.Cancel()
+	for key, w := range writes {
+		staged := append([]byte{badgerOpSet}, w.value...)
+		if w.deleted {
+			staged = []byte{badgerOpDelete}
+		}
+		if err := wb.Set([]byte(badgerStagedKeyPrefix+key), staged); err != nil {
+			return wrapBadgerError(err)
+		}
+	}
+	if err := wb.Flush(); err != nil {
+		// The staged writes must not be applied with the next transaction.
+		return errors.Join(eris.Wrap(err, "failed to stage the transaction"), b.discardStaged())
+	}
+
+	err := b.db.Update(func(txn *badger.Txn) error {
+		return txn.Set([]byte(badgerCommitMarkerKey), nil)
+	})
+	if err != nil {
+		return eris.Wrap(err, "failed to set the commit marker")
+	}
+	return b.applyStaged()
+}
+
+// applyStaged applies the staged writes to their keys, then deletes the commit marker and the staged writes.
+func (b *BadgerStorage) applyStaged() error {
+	apply := b.db.NewWriteBatch()
+	defer apply.Cancel()
+	var stagedKeys [][]byte
+	err := b.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.Prefix = []byte(badgerStagedKeyPrefix)
+		it := txn.NewIterator(opts)
+		defer it.Close()
+		for it.Rewind(); it.Valid(); it.Next() {
+			item := it.Item()
+			stagedKey := item.KeyCopy(nil)
+			key := stagedKey[len(badgerStagedKeyPrefix):]
+			staged, err := item.ValueCopy(nil)
+			if err != nil {
+				return err
+			}
+			switch {
+			case len(staged) > 0 && staged[0] == badgerOpSet:
+				err = apply.Set(key, staged[1:])
+			case len(staged) > 0 && staged[0] == badgerOpDelete:
+				err = apply.Delete(key)
+			default:
+				err = eris.Errorf("invalid staged write for key %q", key)
+			}
+			if err != nil {
+				return err
+			}
+			stagedKeys = append(stagedKeys, stagedKey)
+		}
+		return nil
+	})
+	if err != nil {
+		return wrapBadgerError(err)
+	}
+	if err := apply.Flush(); err != nil {
+		return eris.Wrap(err, "failed to apply the staged transaction")
+	}
+
+	// Once the marker is deleted, the staged writes are never applied again, so they can be deleted in any order.
+	err = b.db.Update(func(txn *badger.Txn) error {
+		return txn.Delete([]byte(badgerCommitMarkerKey))
+	})
+	if err != nil {
+		return eris.Wrap(err, "failed to delete the commit marker")
+	}
+	return b.deleteStaged(stagedKeys)
+}
+
+func (b *BadgerStorage) deleteStaged(stagedKeys [][]byte) error {
+	wb := b.db.NewWriteBatch()
+	defer wb.Cancel()
+	for _, key := range stagedKeys {
+		if err := wb.Delete(key); err != nil {
+			return wrapBadgerError(err)
+		}
+	}
+	return eris.Wrap(wb.Flush(), "failed to delete the staged transaction")
+}
+
+// recoverCommit completes a transaction that was committed, but not completely applied, and discards the staged
+// writes of a transaction that was not committed.
+func (b *BadgerStorage) recoverCommit() error {
+	b.commitMu.Lock()
+	defer b.commitMu.Unlock()
+
+	err := b.db.View(func(txn *badger.Txn) error {
+		_, err := txn.Get([]byte(badgerCommitMarkerKey))
+		return err
+	})
+	switch {
+	case err == nil:
+		return b.applyStaged()
+	case errors.Is(err, badger.ErrKeyNotFound):
+		return b.discardStaged()
+	default:
+		return wrapBadgerError(err)
+	}
+}
+
+// discardStaged deletes the staged writes of a transaction that was not committed.
+func (b *BadgerStorage) discardStaged() error {
+	var stagedKeys [][]byte
+	err := b.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.PrefetchValues = false
+		opts.Prefix = []byte(badgerStagedKeyPrefix)
+		it := txn.NewIterator(opts)
+		defer it.Close()
+		for it.Rewind(); it.Valid(); it.Next() {
+			stagedKeys = append(stagedKeys, it.Item().KeyCopy(nil))
+		}
+		return nil
+	})
+	if err != nil {
+		return wrapBadgerError(err)
+	}
+	return b.deleteStaged(stagedKeys)
+}
@@ -0,0 +1,110 @@
+package gamestate
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/dgraph-io/badger/v4"
+
+	"pkg.world.dev/world-engine/assert"
+)
+
+func newBadgerStorageForCommitTest(t *testing.T) (*badger.DB, BadgerStorage) {
+	db, err := badger.Open(badger.DefaultOptions("").WithInMemory(true).WithLogger(nil))
+	assert.NilError(t, err)
+	t.Cleanup(func() {
+		assert.NilError(t, db.Close())
+	})
+	storage, err := NewBadgerPrimitiveStorage(db)
+	assert.NilError(t, err)
+	return db, storage
+}
+
+// stageWrites stages the writes the way commitStaged does, and sets the commit marker if committed is true, as if the
+// process stopped before the staged writes were applied.
+func stageWrites(t *testing.T, db *badger.DB, writes map[string]badgerWrite, committed bool) {
+	err := db.Update(func(txn *badger.Txn) error {
+		for key, w := range writes {
+			staged := append([]byte{badgerOpSet}, w.value...)
+			if w.deleted {
+				staged = []byte{badgerOpDelete}
+			}
+			if err := txn.Set([]byte(badgerStagedKeyPrefix+key), staged); err != nil {
+				return err
+			}
+		}
+		if committed {
+			return txn.Set([]byte(badgerCommitMarkerKey), nil)
+		}
+		return nil
+	})
+	assert.NilError(t, err)
+}
+
+func TestBadgerStorageCompletesACommittedTransactionWhenOpened(t *testing.T) {
+	ctx := t.Context()
+	db, storage := newBadgerStorageForCommitTest(t)
+	assert.NilError(t, storage.Set(ctx, "a", 1))
+	assert.NilError(t, storage.Set(ctx, "b", 2))
+
+	stageWrites(t, db, map[string]badgerWrite{
+		"a": {value: []byte("10")},
+		"b": {deleted: true},
+		"c": {value: []byte("30")},
+	}, true)
+
+	// The staged writes are not visible until they are applied.
+	keys, err := storage.Keys(ctx)
+	assert.NilError(t, err)
+	slices.Sort(keys)
+	assert.DeepEqual(t, []string{"a", "b"}, keys)
+
+	reopened, err := NewBadgerPrimitiveStorage(db)
+	assert.NilError(t, err)
+	keys, err = reopened.Keys(ctx)
+	assert.NilError(t, err)
+	slices.Sort(keys)
+	assert.DeepEqual(t, []string{"a", "c"}, keys)
+	a, err := reopened.GetInt(ctx, "a")
+	assert.NilError(t, err)
+	assert.Equal(t, 10, a)
+	c, err := reopened.GetInt(ctx, "c")
+	assert.NilError(t, err)
+	assert.Equal(t, 30, c)
+	assertNoCommitKeys(t, db)
+}
+
+func TestBadgerStorageDiscardsAnUncommittedTransactionWhenOpened(t *testing.T) {
+	ctx := t.Context()
+	db, storage := newBadgerStorageForCommitTest(t)
+	assert.NilError(t, storage.Set(ctx, "a", 1))
+
+	stageWrites(t, db, map[string]badgerWrite{
+		"a": {value: []byte("10")},
+		"c": {value: []byte("30")},
+	}, false)
+
+	reopened, err := NewBadgerPrimitiveStorage(db)
+	assert.NilError(t, err)
+	keys, err := reopened.Keys(ctx)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, []string{"a"}, keys)
+	a, err := reopened.GetInt(ctx, "a")
+	assert.NilError(t, err)
+	assert.Equal(t, 1, a)
+	assertNoCommitKeys(t, db)
+}
+
+func assertNoCommitKeys(t *testing.T, db *badger.DB) {
+	err := db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.Prefix = []byte(badgerCommitKeyPrefix)
+		it := txn.NewIterator(opts)
+		defer it.Close()
+		for it.Rewind(); it.Valid(); it.Next() {
+			t.Errorf("unexpected key %q", it.Item().Key())
+		}
+		return nil
+	})
+	assert.NilError(t, err)
+}
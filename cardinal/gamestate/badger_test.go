@@ -0,0 +1,139 @@
+package gamestate_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dgraph-io/badger/v4"
+	"github.com/rotisserie/eris"
+
+	"pkg.world.dev/world-engine/assert"
+	"pkg.world.dev/world-engine/cardinal/gamestate"
+)
+
+func newBadgerDBForTest(t *testing.T) *badger.DB {
+	db, err := badger.Open(badger.DefaultOptions("").WithInMemory(true).WithLogger(nil))
+	assert.NilError(t, err)
+	t.Cleanup(func() {
+		assert.NilError(t, db.Close())
+	})
+	return db
+}
+
+func newCmdBufferForBadgerDB(t *testing.T, db *badger.DB) *gamestate.EntityCommandBuffer {
+	storage, err := gamestate.NewBadgerPrimitiveStorage(db)
+	assert.NilError(t, err)
+	manager, err := gamestate.NewEntityCommandBuffer(&storage)
+	assert.NilError(t, err)
+	assert.NilError(t, manager.RegisterComponents(allComponents))
+	return manager
+}
+
+func TestBadgerStorageReturnsKeyNotFound(t *testing.T) {
+	ctx := t.Context()
+	storage, err := gamestate.NewBadgerPrimitiveStorage(newBadgerDBForTest(t))
+	assert.NilError(t, err)
+
+	_, err = storage.GetUInt64(ctx, "missing")
+	assert.ErrorIs(t, err, gamestate.ErrKeyNotFound)
+
+	// Incr treats a missing key as 0.
+	assert.NilError(t, storage.Incr(ctx, "counter"))
+	assert.NilError(t, storage.Incr(ctx, "counter"))
+	assert.NilError(t, storage.Decr(ctx, "counter"))
+	got, err := storage.GetInt(ctx, "counter")
+	assert.NilError(t, err)
+	assert.Equal(t, 1, got)
+
+	assert.NilError(t, storage.Delete(ctx, "counter"))
+	_, err = storage.GetInt(ctx, "counter")
+	assert.ErrorIs(t, err, gamestate.ErrKeyNotFound)
+}
+
+func TestBadgerStorageTransactionIsAtomic(t *testing.T) {
+	ctx := t.Context()
+	storage, err := gamestate.NewBadgerPrimitiveStorage(newBadgerDBForTest(t))
+	assert.NilError(t, err)
+
+	txn, err := storage.StartTransaction(ctx)
+	assert.NilError(t, err)
+	assert.NilError(t, txn.Set(ctx, "a", 1))
+	assert.NilError(t, txn.Incr(ctx, "a"))
+
+	// The transaction can read its own writes, but nothing is visible outside of it until it ends.
+	got, err := txn.GetInt(ctx, "a")
+	assert.NilError(t, err)
+	assert.Equal(t, 2, got)
+	_, err = storage.GetInt(ctx, "a")
+	assert.ErrorIs(t, err, gamestate.ErrKeyNotFound)
+
+	assert.NilError(t, txn.EndTransaction(ctx))
+	got, err = storage.GetInt(ctx, "a")
+	assert.NilError(t, err)
+	assert.Equal(t, 2, got)
+}
+
+func TestBadgerStorageCanReloadFinalizedState(t *testing.T) {
+	ctx := t.Context()
+	db := newBadgerDBForTest(t)
+	manager := newCmdBufferForBadgerDB(t, db)
+
+	id, err := manager.CreateEntity(fooComp)
+	assert.NilError(t, err)
+	assert.NilError(t, manager.SetComponentForEntity(fooComp, id, Foo{Value: 42}))
+	assert.NilError(t, manager.FinalizeTick(ctx))
+
+	// This entity is never finalized, so it should not be visible to a new manager.
+	_, err = manager.CreateEntity(barComp)
+	assert.NilError(t, err)
+
+	reloaded := newCmdBufferForBadgerDB(t, db)
+	tick, err := reloaded.GetLastFinalizedTick()
+	assert.NilError(t, err)
+	assert.Equal(t, uint64(1), tick)
+
+	val, err := reloaded.GetComponentForEntity(fooComp, id)
+	assert.NilError(t, err)
+	assert.Equal(t, 42, val.(Foo).Value)
+
+	_, err = reloaded.GetComponentForEntity(barComp, id+1)
+	assert.Check(t, eris.Is(err, gamestate.ErrEntityDoesNotExist))
+}
+
+func TestBadgerStorageCommitsTicksTooLargeForASingleTransaction(t *testing.T) {
+	ctx := t.Context()
+	// With a small memtable, a single badger transaction only holds a few thousand writes.
+	db, err := badger.Open(badger.DefaultOptions("").WithInMemory(true).WithLogger(nil).WithMemTableSize(1 << 20).WithValueThreshold(1 << 17))
+	assert.NilError(t, err)
+	t.Cleanup(func() {
+		assert.NilError(t, db.Close())
+	})
+	manager := newCmdBufferForBadgerDB(t, db)
+
+	const count = 20000
+	ids, err := manager.CreateManyEntities(count, fooComp)
+	assert.NilError(t, err)
+	for i, id := range ids {
+		assert.NilError(t, manager.SetComponentForEntity(fooComp, id, Foo{Value: i}))
+	}
+	assert.NilError(t, manager.FinalizeTick(ctx))
+
+	reloaded := newCmdBufferForBadgerDB(t, db)
+	tick, err := reloaded.GetLastFinalizedTick()
+	assert.NilError(t, err)
+	assert.Equal(t, uint64(1), tick)
+	for i, id := range ids {
+		val, err := reloaded.GetComponentForEntity(fooComp, id)
+		assert.NilError(t, err)
+		assert.Equal(t, i, val.(Foo).Value)
+	}
+
+	// Only the game state is left in storage once the tick is committed.
+	storage, err := gamestate.NewBadgerPrimitiveStorage(db)
+	assert.NilError(t, err)
+	keys, err := storage.Keys(ctx)
+	assert.NilError(t, err)
+	for _, key := range keys {
+		assert.Check(t, !strings.HasPrefix(key, "\x00"), "unexpected key %q", key)
+	}
+}
@@ -0,0 +1,293 @@
+package gamestate
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+
+	"github.com/rotisserie/eris"
+
+	"pkg.world.dev/world-engine/cardinal/types"
+)
+
+const stateRootHashSize = sha256.Size
+
+// TickDiff is the set of state changes committed by a single call to FinalizeTick, along with the state root after
+// the changes were applied.
+type TickDiff struct {
+	CreatedEntities []types.EntityID `json:"createdEntities"`
+	RemovedEntities []types.EntityID `json:"removedEntities"`
+	// MovedEntities are the entities that existed before and after the tick, but whose set of components changed. A
+	// component added with its default value is not stored, so it only shows up here and not in Components.
+	MovedEntities []types.EntityID  `json:"movedEntities"`
+	Components    []ComponentChange `json:"components"`
+	// StateRoot is a commitment to every component value in storage. Two replicas with the same state always have the
+	// same state root.
+	StateRoot []byte `json:"stateRoot"`
+}
+
+// ComponentChange describes a single component value that was set or removed. OldValue is empty if the component
+// was not stored before, and NewValue is empty if the component was removed.
+type ComponentChange struct {
+	EntityID  types.EntityID  `json:"entityId"`
+	Component string          `json:"component"`
+	OldValue  json.RawMessage `json:"oldValue,omitempty"`
+	NewValue  json.RawMessage `json:"newValue,omitempty"`
+}
+
+// committedValue is the encoded value of a component as it was when it was loaded from dbStorage. stored is false
+// if there was nothing in dbStorage and bz holds the default value of the component.
+type committedValue struct {
+	bz     []byte
+	stored bool
+}
+
+// stateRootTree is a sparse Merkle tree over all component values in storage. Each leaf is keyed by the hash of the
+// component's storage key, which names the component and the entity, and the path from the root to a leaf follows the
+// bits of that hash. A subtree that holds a single leaf is replaced by the leaf, so the tree is only as deep as needed
+// to tell its leaves apart. The shape of the tree, and so its root, only depends on the leaves it holds.
+//
+// Comparing the subtrees of two diverging replicas narrows down where they differ, one level at a time. Nodes are
+// never changed once they are created: add and remove replace the nodes on the path to the leaf, and share the rest of
+// the tree, so a tree can be cloned in constant time.
+type stateRootTree struct {
+	top *stateRootNode
+}
+
+// stateRootNode is either a leaf, or an internal node with at least two leaves below it. One of the children of an
+// internal node can be nil.
+type stateRootNode struct {
+	hash [stateRootHashSize]byte
+	// path is the hash of the storage key of a leaf. It is not set for internal nodes.
+	path        [stateRootHashSize]byte
+	leaf        bool
+	left, right *stateRootNode
+}
+
+const (
+	stateRootLeafPrefix     = 0
+	stateRootInternalPrefix = 1
+)
+
+func newStateRootTree() *stateRootTree {
+	return &stateRootTree{}
+}
+
+func (t *stateRootTree) clone() *stateRootTree {
+	return &stateRootTree{top: t.top}
+}
+
+// root returns the hash of the root node. The root of an empty tree is all zeros.
+func (t *stateRootTree) root() []byte {
+	hash := stateRootHash(t.top)
+	return hash[:]
+}
+
+// add sets the value of the component stored at key.
+func (t *stateRootTree) add(key string, value []byte) {
+	leaf := newStateRootLeaf(key, value)
+	t.top = insertStateRootLeaf(t.top, leaf, 0)
+}
+
+// remove removes the component stored at key.
+func (t *stateRootTree) remove(key string) {
+	t.top = removeStateRootLeaf(t.top, sha256.Sum256([]byte(key)), 0)
+}
+
+func newStateRootLeaf(key string, value []byte) *stateRootNode {
+	leaf := &stateRootNode{path: sha256.Sum256([]byte(key)), leaf: true}
+	valueHash := sha256.Sum256(value)
+	h := sha256.New()
+	h.Write([]byte{stateRootLeafPrefix})
+	h.Write(leaf.path[:])
+	h.Write(valueHash[:])
+	h.Sum(leaf.hash[:0])
+	return leaf
+}
+
+func newStateRootInternal(left, right *stateRootNode) *stateRootNode {
+	node := &stateRootNode{left: left, right: right}
+	leftHash, rightHash := stateRootHash(left), stateRootHash(right)
+	h := sha256.New()
+	h.Write([]byte{stateRootInternalPrefix})
+	h.Write(leftHash[:])
+	h.Write(rightHash[:])
+	h.Sum(node.hash[:0])
+	return node
+}
+
+func stateRootHash(node *stateRootNode) [stateRootHashSize]byte {
+	if node == nil {
+		return [stateRootHashSize]byte{}
+	}
+	return node.hash
+}
+
+// stateRootBit returns the bit of the path at the given depth, which selects the child to follow at that depth.
+func stateRootBit(path [stateRootHashSize]byte, depth int) bool {
+	return path[depth/8]&(0x80>>(depth%8)) != 0 //nolint:mnd // bits in a byte
+}
+
+// insertStateRootLeaf returns the subtree at the given depth with the leaf added to it, or replacing the leaf with the
+// same path.
+func insertStateRootLeaf(node, leaf *stateRootNode, depth int) *stateRootNode {
+	switch {
+	case node == nil:
+		return leaf
+	case node.leaf && node.path == leaf.path:
+		return leaf
+	case node.leaf:
+		// Both leaves are pushed down until their paths differ.
+		if stateRootBit(node.path, depth) == stateRootBit(leaf.path, depth) {
+			child := insertStateRootLeaf(node, leaf, depth+1)
+			if stateRootBit(leaf.path, depth) {
+				return newStateRootInternal(nil, child)
+			}
+			return newStateRootInternal(child, nil)
+		}
+		if stateRootBit(leaf.path, depth) {
+			return newStateRootInternal(node, leaf)
+		}
+		return newStateRootInternal(leaf, node)
+	case stateRootBit(leaf.path, depth):
+		return newStateRootInternal(node.left, insertStateRootLeaf(node.right, leaf, depth+1))
+	default:
+		return newStateRootInternal(insertStateRootLeaf(node.left, leaf, depth+1), node.right)
+	}
+}
+
+// removeStateRootLeaf returns the subtree at the given depth without the leaf with the given path. An internal node
+// that is left with a single leaf below it is replaced by the leaf.
+func removeStateRootLeaf(node *stateRootNode, path [stateRootHashSize]byte, depth int) *stateRootNode {
+	switch {
+	case node == nil:
+		return nil
+	case node.leaf:
+		if node.path == path {
+			return nil
+		}
+		return node
+	}
+	left, right := node.left, node.right
+	if stateRootBit(path, depth) {
+		right = removeStateRootLeaf(right, path, depth+1)
+	} else {
+		left = removeStateRootLeaf(left, path, depth+1)
+	}
+	switch {
+	case left == node.left && right == node.right:
+		return node
+	case left == nil && (right == nil || right.leaf):
+		return right
+	case right == nil && left.leaf:
+		return left
+	default:
+		return newStateRootInternal(left, right)
+	}
+}
+
+// loadStateRootTree returns the state root tree, building it from every stored component value the first time. The
+// tree is only kept in memory.
+func (m *EntityCommandBuffer) loadStateRootTree(ctx context.Context) (*stateRootTree, error) {
+	if m.stateRoot != nil {
+		return m.stateRoot, nil
+	}
+	tree := newStateRootTree()
+	add := func(key string, value []byte) error {
+		tree.add(key, value)
+		return nil
+	}
+	if scanner, ok := m.dbStorage.(PrefixScanner); ok {
+		if err := scanner.ScanPrefix(ctx, storageComponentKeyPrefix, add); err != nil {
+			return nil, eris.Wrap(err, "failed to scan component values")
+		}
+	} else {
+		keys, err := m.dbStorage.Keys(ctx)
+		if err != nil {
+			return nil, eris.Wrap(err, "failed to list keys")
+		}
+		for _, key := range keys {
+			if !strings.HasPrefix(key, storageComponentKeyPrefix) {
+				continue
+			}
+			value, err := m.dbStorage.GetBytes(ctx, key)
+			if err != nil {
+				return nil, err
+			}
+			tree.add(key, value)
+		}
+	}
+	// State written by older versions has the bucket digests of the previous state root, which are deleted on the next
+	// FinalizeTick.
+	m.stateRootRebuilt = true
+	m.stateRoot = tree
+	return tree, nil
+}
+
+// getCommittedValue returns the encoded value of the component as it is in dbStorage. ok is false if the component
+// is not stored.
+func (m *EntityCommandBuffer) getCommittedValue(ctx context.Context, key compKey) (bz []byte, ok bool, err error) {
+	if committed, err := m.compOriginals.Get(key); err == nil {
+		if !committed.stored {
+			return nil, false, nil
+		}
+		return committed.bz, true, nil
+	}
+	// Entities created during this tick have nothing in dbStorage.
+	if originArchID, err := m.entityIDToOriginArchID.Get(key.entityID); err == nil &&
+		originArchID == doesNotExistArchetypeID {
+		return nil, false, nil
+	}
+	bz, err = m.dbStorage.GetBytes(ctx, storageComponentKey(key.typeID, key.entityID))
+	if errors.Is(err, ErrKeyNotFound) {
+		return nil, false, nil
+	} else if err != nil {
+		return nil, false, err
+	}
+	return bz, true, nil
+}
+
+// addEntityChangesToDiff records which entities were created, removed and moved to another archetype in the pending
+// tick.
+func (m *EntityCommandBuffer) addEntityChangesToDiff(diff *TickDiff) error {
+	ids, err := m.entityIDToOriginArchID.Keys()
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		originArchID, err := m.entityIDToOriginArchID.Get(id)
+		if err != nil {
+			return err
+		}
+		archID, err := m.entityIDToArchID.Get(id)
+		exists := err == nil
+		switch {
+		case originArchID == doesNotExistArchetypeID && exists:
+			diff.CreatedEntities = append(diff.CreatedEntities, id)
+		case originArchID != doesNotExistArchetypeID && !exists:
+			diff.RemovedEntities = append(diff.RemovedEntities, id)
+		case exists && originArchID != archID:
+			diff.MovedEntities = append(diff.MovedEntities, id)
+		}
+	}
+	slices.Sort(diff.CreatedEntities)
+	slices.Sort(diff.RemovedEntities)
+	slices.Sort(diff.MovedEntities)
+	return nil
+}
+
+// sortComponentChanges sorts the changes by entity and then by component name so the diff is deterministic.
+func sortComponentChanges(changes []ComponentChange) {
+	slices.SortFunc(changes, func(a, b ComponentChange) int {
+		if a.EntityID != b.EntityID {
+			if a.EntityID < b.EntityID {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a.Component, b.Component)
+	})
+}
@@ -0,0 +1,146 @@
+package gamestate_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+
+	"pkg.world.dev/world-engine/assert"
+	"pkg.world.dev/world-engine/cardinal/gamestate"
+	"pkg.world.dev/world-engine/cardinal/types"
+)
+
+func TestTickDiffContainsEntityAndComponentChanges(t *testing.T) {
+	manager := newCmdBufferForTest(t)
+	assert.Check(t, manager.LastTickDiff() == nil)
+
+	ids, err := manager.CreateManyEntities(2, fooComp, barComp)
+	assert.NilError(t, err)
+	assert.NilError(t, manager.SetComponentForEntity(fooComp, ids[0], Foo{Value: 1}))
+	assert.NilError(t, manager.FinalizeTick(context.Background()))
+
+	diff := manager.LastTickDiff()
+	assert.DeepEqual(t, diff.CreatedEntities, ids)
+	assert.Equal(t, len(diff.RemovedEntities), 0)
+	assert.DeepEqual(t, diff.Components, []gamestate.ComponentChange{
+		{EntityID: ids[0], Component: "foo", NewValue: json.RawMessage(`{"Value":1}`)},
+	})
+
+	assert.NilError(t, manager.SetComponentForEntity(fooComp, ids[0], Foo{Value: 2}))
+	assert.NilError(t, manager.RemoveEntity(ids[1]))
+	assert.NilError(t, manager.FinalizeTick(context.Background()))
+
+	diff = manager.LastTickDiff()
+	assert.Equal(t, len(diff.CreatedEntities), 0)
+	assert.DeepEqual(t, diff.RemovedEntities, []types.EntityID{ids[1]})
+	assert.DeepEqual(t, diff.Components, []gamestate.ComponentChange{
+		{
+			EntityID:  ids[0],
+			Component: "foo",
+			OldValue:  json.RawMessage(`{"Value":1}`),
+			NewValue:  json.RawMessage(`{"Value":2}`),
+		},
+	})
+}
+
+func TestUnchangedComponentsAreNotWrittenOrInTickDiff(t *testing.T) {
+	manager := newCmdBufferForTest(t)
+	id, err := manager.CreateEntity(fooComp)
+	assert.NilError(t, err)
+	assert.NilError(t, manager.SetComponentForEntity(fooComp, id, Foo{Value: 1}))
+	assert.NilError(t, manager.FinalizeTick(context.Background()))
+	root := manager.LastTickDiff().StateRoot
+
+	_, err = manager.GetComponentForEntity(fooComp, id)
+	assert.NilError(t, err)
+	assert.NilError(t, manager.SetComponentForEntity(fooComp, id, Foo{Value: 1}))
+	assert.NilError(t, manager.FinalizeTick(context.Background()))
+
+	diff := manager.LastTickDiff()
+	assert.Equal(t, len(diff.Components), 0)
+	assert.DeepEqual(t, diff.StateRoot, root)
+}
+
+func TestRemovedComponentIsInTickDiff(t *testing.T) {
+	manager := newCmdBufferForTest(t)
+	id, err := manager.CreateEntity(fooComp, barComp)
+	assert.NilError(t, err)
+	assert.NilError(t, manager.SetComponentForEntity(barComp, id, Bar{Value: 7}))
+	assert.NilError(t, manager.FinalizeTick(context.Background()))
+
+	assert.NilError(t, manager.RemoveComponentFromEntity(barComp, id))
+	assert.NilError(t, manager.FinalizeTick(context.Background()))
+
+	assert.DeepEqual(t, manager.LastTickDiff().Components, []gamestate.ComponentChange{
+		{EntityID: id, Component: "bar", OldValue: json.RawMessage(`{"Value":7}`)},
+	})
+}
+
+func TestEntitiesThatChangeArchetypeAreInTickDiff(t *testing.T) {
+	manager := newCmdBufferForTest(t)
+	ids, err := manager.CreateManyEntities(3, fooComp)
+	assert.NilError(t, err)
+	assert.NilError(t, manager.FinalizeTick(context.Background()))
+	assert.Equal(t, len(manager.LastTickDiff().MovedEntities), 0)
+
+	assert.NilError(t, manager.AddComponentToEntity(barComp, ids[0]))
+	// Moving back to the original archetype within the tick is not a change.
+	assert.NilError(t, manager.AddComponentToEntity(barComp, ids[1]))
+	assert.NilError(t, manager.RemoveComponentFromEntity(barComp, ids[1]))
+	assert.NilError(t, manager.RemoveEntity(ids[2]))
+	assert.NilError(t, manager.FinalizeTick(context.Background()))
+
+	diff := manager.LastTickDiff()
+	assert.DeepEqual(t, diff.MovedEntities, []types.EntityID{ids[0]})
+	assert.DeepEqual(t, diff.RemovedEntities, []types.EntityID{ids[2]})
+	// The added component has its default value, which is not stored.
+	assert.Equal(t, len(diff.Components), 0)
+}
+
+func TestStateRootOnlyDependsOnState(t *testing.T) {
+	first := newCmdBufferForTest(t)
+	second := newCmdBufferForTest(t)
+	ctx := context.Background()
+
+	// Reach the same state in a different number of ticks and with a different order of writes.
+	id, err := first.CreateEntity(fooComp, barComp)
+	assert.NilError(t, err)
+	assert.NilError(t, first.SetComponentForEntity(fooComp, id, Foo{Value: 1}))
+	assert.NilError(t, first.SetComponentForEntity(barComp, id, Bar{Value: 2}))
+	assert.NilError(t, first.FinalizeTick(ctx))
+
+	id, err = second.CreateEntity(fooComp, barComp)
+	assert.NilError(t, err)
+	assert.NilError(t, second.SetComponentForEntity(barComp, id, Bar{Value: 5}))
+	assert.NilError(t, second.FinalizeTick(ctx))
+	assert.Check(t, string(first.LastTickDiff().StateRoot) != string(second.LastTickDiff().StateRoot))
+	assert.NilError(t, second.SetComponentForEntity(barComp, id, Bar{Value: 2}))
+	assert.NilError(t, second.SetComponentForEntity(fooComp, id, Foo{Value: 1}))
+	assert.NilError(t, second.FinalizeTick(ctx))
+
+	assert.DeepEqual(t, first.LastTickDiff().StateRoot, second.LastTickDiff().StateRoot)
+}
+
+func TestStateRootIsRebuiltFromStorage(t *testing.T) {
+	ctx := context.Background()
+	s := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: s.Addr()})
+	manager, _ := newCmdBufferAndRedisClientForTest(t, client)
+
+	id, err := manager.CreateEntity(fooComp, barComp)
+	assert.NilError(t, err)
+	assert.NilError(t, manager.SetComponentForEntity(fooComp, id, Foo{Value: 1}))
+	assert.NilError(t, manager.SetComponentForEntity(barComp, id, Bar{Value: 2}))
+	assert.NilError(t, manager.FinalizeTick(ctx))
+	root := manager.LastTickDiff().StateRoot
+
+	// Simulate state that was written by a version that stored the state root.
+	assert.NilError(t, client.Set(ctx, "ECB:STATE-ROOT-BUCKETS", "digests", 0).Err())
+	manager, _ = newCmdBufferAndRedisClientForTest(t, client)
+	assert.NilError(t, manager.FinalizeTick(ctx))
+	assert.DeepEqual(t, manager.LastTickDiff().StateRoot, root)
+	assert.ErrorIs(t, client.Get(ctx, "ECB:STATE-ROOT-BUCKETS").Err(), redis.Nil)
+}
@@ -24,19 +24,51 @@ operations are accumulated.
 Pending changes can be discarded with EntityCommandBuffer.DiscardPending. A subsequent read will return identical
 data to the data stored in Redis.
 
+Savepoints mark a point within the pending changes. EntityCommandBuffer.RollbackToSavepoint undoes the pending
+changes made since the newest savepoint, while EntityCommandBuffer.ReleaseSavepoint keeps them. Savepoints can be
+nested. Each manager returned by NewSynchronizedManagers has its own savepoints, so a writer only rolls back its own
+changes.
+
 Pending changes can be committed to redis with EntityCommandBuffer.FinalizeTick. All pending changes will
 be packaged into a single redis [multi/exec pipeline](https://redis.io/docs/interact/transactions/) and applied
 atomically. Reads to redis during this time will never return any pending state. For example, if a series of 100
 commands increments some value from 0 to 100, and then FinalizeTick is called, reading this value from the DB will
 only ever return 0 or 100 (depending on the exact timing of the call).
 
+Only components whose encoded value actually changed are written by FinalizeTick. The changes (created and removed
+entities, and component sets and removals with their old and new values) are available from
+EntityCommandBuffer.LastTickDiff once FinalizeTick returns, along with the new state root. The state root is the root
+of a sparse Merkle tree over every stored component value. The tree is only kept in memory: it is built from the
+ECB:COMPONENT-VALUE keys the first time it is needed, and then updated with the changes of each tick.
+
+# Badger
+
+BadgerStorage is an embedded alternative to RedisStorage. The transaction returned by BadgerStorage.StartTransaction
+buffers all writes in memory, and writes them in a single Badger transaction when it ends. A tick too large for a single
+Badger transaction is staged in write batches under an internal key prefix, committed by setting a commit marker, and
+then applied; a tick interrupted after the marker is set is applied again when the storage is opened. Either way,
+FinalizeTick has the same all-or-nothing guarantee as it has with Redis. Badger stores the same keys and values as
+Redis, so the model below applies to both backends.
+
 # Redis PrimitiveStorage Model
 
 The Redis keys that store data in redis are defined in keys.go. All keys are prefixed with "ECB".
 
 key:	"ECB:NEXT-ENTITY-ID"
 value: 	An integer that represents the next available entity ID that can be assigned to some entity. It can be assumed
-that entity IDs smaller than this value have already been assigned.
+that entity indices smaller than this value have already been assigned.
+
+key:	fmt.Sprintf("ECB:FREE-ENTITY-ID:POSITION-%d", position)
+value:	An integer that represents an entity ID. Together, these keys are a queue of the handles that replace removed
+entities. When an entity is removed, the handle with its index and the next generation is added at the end of the
+queue. New entities take their handle from the front of the queue before a new index is assigned.
+
+key:	"ECB:FREE-ENTITY-IDS:HEAD"
+value:	An integer that represents the position of the handle at the front of the queue.
+
+key:	"ECB:FREE-ENTITY-IDS:TAIL"
+value:	An integer that represents the position after the handle at the end of the queue. The queue is empty when the
+head and the tail are equal.
 
 key:	fmt.Sprintf("ECB:COMPONENT-VALUE:TYPE-ID-%d:ENTITY-ID-%d", componentTypeID, entityID)
 value: 	JSON serialized bytes that can be deserialized to the component with the matching componentTypeID. This
@@ -54,6 +86,10 @@ value:	JSON serialized bytes that can be deserialized to a map of archetype.ID t
 what archetype IDs have already been assigned and what groups of components each archetype ID corresponds to. This field
 must be loaded into memory before any entity creation or component addition/removals take place.
 
+key:	fmt.Sprintf("ECB:COMPONENT-SCHEMA:%s", componentName)
+value:	The JSON schema that the stored values of the component were last migrated to. It is written in the same
+transaction as the migrated values, so a migration is never applied twice.
+
 key: 	"ECB:START-TICK"
 value:  An integer that represents the last tick that was started.
 
@@ -71,8 +107,5 @@ Components are stored as generic interfaces and not as serialized JSON.
 In redis, the ECB:ACTIVE-ENTITY-IDS and ECB:ARCHETYPE-ID:ENTITY-ID keys contains the same data, but are just reversed
 mapping of one another. The amount of data in redis, and the data written can likely be reduced if we abandon one of
 these keys and rebuild the other mapping in memory.
-
-In memory, compValues are written to redis during a FinalizeTick cycle. Components that were not actually changed (e.g.
-only read operations were performed) are still written to the DB.
 */
 package gamestate
@@ -4,8 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"math"
 
-	"github.com/redis/go-redis/v9"
 	"github.com/rotisserie/eris"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
@@ -30,7 +30,10 @@ type EntityCommandBuffer struct {
 
 	compValues         VolatileStorage[compKey, any]
 	compValuesToDelete VolatileStorage[compKey, bool]
-	typeToComponent    VolatileStorage[types.ComponentID, types.ComponentMetadata]
+	// compOriginals holds the encoded value of every component that was loaded from dbStorage in the current tick.
+	// It is used to skip writing components that were not changed, and as the old value in the tick's diff.
+	compOriginals   VolatileStorage[compKey, committedValue]
+	typeToComponent VolatileStorage[types.ComponentID, types.ComponentMetadata]
 
 	activeEntities VolatileStorage[types.ArchetypeID, activeEntities]
 
@@ -38,6 +41,14 @@ type EntityCommandBuffer struct {
 	nextEntityIDSaved uint64
 	pendingEntityIDs  uint64
 	isEntityIDLoaded  bool
+	// The handles that replace removed entities are stored in dbStorage at the positions from freeEntityIDsHead to
+	// freeEntityIDsTail, in the order they are reused. freeEntityIDsUsed counts the ones that were reused in the pending
+	// tick. pendingFreeEntityIDs replace the entities removed in the pending tick, and can only be reused after the tick
+	// is finalized.
+	freeEntityIDsHead    uint64
+	freeEntityIDsTail    uint64
+	freeEntityIDsUsed    uint64
+	pendingFreeEntityIDs []types.EntityID
 
 	// Archetype EntityID management.
 	entityIDToArchID       VolatileStorage[types.EntityID, types.ArchetypeID]
@@ -46,33 +57,63 @@ type EntityCommandBuffer struct {
 	archIDToComps  VolatileStorage[types.ArchetypeID, []types.ComponentMetadata]
 	pendingArchIDs []types.ArchetypeID
 
+	// stateRoot is the state root tree of the committed state. It is lazily built from the component values in
+	// dbStorage. stateRootRebuilt is true until the first FinalizeTick after it was built.
+	stateRoot        *stateRootTree
+	stateRootRebuilt bool
+	// pendingStateRoot and pendingDiff are computed while the FinalizeTick transaction is built, and only replace
+	// stateRoot and lastDiff once the transaction is committed.
+	pendingStateRoot *stateRootTree
+	pendingDiff      *TickDiff
+	lastDiff         *TickDiff
+
+	// indexes are the indexes on the fields of each component. indexedFields holds the names of the indexes whose
+	// entries are stored in dbStorage, or nil if it is not loaded yet. pendingIndexedFields replaces it once the
+	// pending index changes are committed.
+	indexes              map[types.ComponentID][]*fieldIndex
+	indexedFields        []string
+	pendingIndexedFields []string
+	// spatialIndexes are the spatial indexes registered with RegisterSpatialIndex, by component.
+	spatialIndexes map[types.ComponentID]*spatialIndex
+
+	// journal records the pending changes made since the open savepoints, so they can be rolled back.
+	journal *journal
+
 	// OpenTelemetry tracer
 	tracer trace.Tracer
 }
 
 // NewEntityCommandBuffer creates a new command buffer manager that is able to queue up a series of states changes and
-// atomically commit them to the underlying redis dbStorage layer.
+// atomically commit them to the underlying dbStorage layer.
 func NewEntityCommandBuffer(storage PrimitiveStorage[string]) (*EntityCommandBuffer, error) {
 	m := &EntityCommandBuffer{
-		dbStorage:          storage,
-		compValues:         NewMapStorage[compKey, any](),
-		compValuesToDelete: NewMapStorage[compKey, bool](),
-
-		activeEntities: NewMapStorage[types.ArchetypeID, activeEntities](),
-		archIDToComps:  NewMapStorage[types.ArchetypeID, []types.ComponentMetadata](),
-
-		entityIDToArchID:       NewMapStorage[types.EntityID, types.ArchetypeID](),
-		entityIDToOriginArchID: NewMapStorage[types.EntityID, types.ArchetypeID](),
+		dbStorage:     storage,
+		compOriginals: NewMapStorage[compKey, committedValue](),
+		archIDToComps: NewMapStorage[types.ArchetypeID, []types.ComponentMetadata](),
 
 		// This field cannot be set until RegisterComponents is called
 		typeToComponent: nil,
 
+		spatialIndexes: make(map[types.ComponentID]*spatialIndex),
+
+		journal: &journal{},
+
 		tracer: otel.Tracer("ecb"),
 	}
+	m.newJournaledStorages()
 
 	return m, nil
 }
 
+// newJournaledStorages creates the storages whose pending changes can be rolled back to a savepoint.
+func (m *EntityCommandBuffer) newJournaledStorages() {
+	m.compValues = newJournaledStorage[compKey, any](&m.journal)
+	m.compValuesToDelete = newJournaledStorage[compKey, bool](&m.journal)
+	m.activeEntities = newJournaledStorage[types.ArchetypeID, activeEntities](&m.journal)
+	m.entityIDToArchID = newJournaledStorage[types.EntityID, types.ArchetypeID](&m.journal)
+	m.entityIDToOriginArchID = newJournaledStorage[types.EntityID, types.ArchetypeID](&m.journal)
+}
+
 func (m *EntityCommandBuffer) RegisterComponents(comps []types.ComponentMetadata) error {
 	m.typeToComponent = NewMapStorage[types.ComponentID, types.ComponentMetadata]()
 	for _, comp := range comps {
@@ -81,16 +122,27 @@ func (m *EntityCommandBuffer) RegisterComponents(comps []types.ComponentMetadata
 			return err
 		}
 	}
+	if err := m.initIndexes(); err != nil {
+		return err
+	}
 
 	return m.loadArchIDs()
 }
 
 // DiscardPending discards any pending state changes.
 func (m *EntityCommandBuffer) DiscardPending() error {
+	m.journal.reset()
 	err := m.compValues.Clear()
 	if err != nil {
 		return err
 	}
+	err = m.compOriginals.Clear()
+	if err != nil {
+		return err
+	}
+	m.pendingStateRoot = nil
+	m.pendingDiff = nil
+	m.discardPendingIndexes()
 
 	// Any entity archetypes movements need to be undone
 	err = m.activeEntities.Clear()
@@ -114,6 +166,10 @@ func (m *EntityCommandBuffer) DiscardPending() error {
 
 	m.isEntityIDLoaded = false
 	m.pendingEntityIDs = 0
+	m.freeEntityIDsHead = 0
+	m.freeEntityIDsTail = 0
+	m.freeEntityIDsUsed = 0
+	m.pendingFreeEntityIDs = nil
 
 	for _, archID := range m.pendingArchIDs {
 		err = m.archIDToComps.Delete(archID)
@@ -136,7 +192,7 @@ func (m *EntityCommandBuffer) RemoveEntity(idToRemove types.EntityID) error {
 		return err
 	}
 
-	if err = active.swapRemove(idToRemove); err != nil {
+	if err = active.remove(idToRemove); err != nil {
 		return err
 	}
 
@@ -155,6 +211,18 @@ func (m *EntityCommandBuffer) RemoveEntity(idToRemove types.EntityID) error {
 		return err
 	}
 
+	// The index of the entity is reused with the next generation. An index whose generation can't be incremented
+	// anymore is retired.
+	if idToRemove.Generation() < math.MaxUint32 {
+		pendingFree := len(m.pendingFreeEntityIDs)
+		m.journal.record(func() error {
+			m.pendingFreeEntityIDs = m.pendingFreeEntityIDs[:pendingFree]
+			return nil
+		})
+		m.pendingFreeEntityIDs = append(m.pendingFreeEntityIDs,
+			types.NewEntityID(idToRemove.Index(), idToRemove.Generation()+1))
+	}
+
 	comps, err := m.GetComponentTypesForArchID(archID)
 	if err != nil {
 		return err
@@ -169,6 +237,7 @@ func (m *EntityCommandBuffer) RemoveEntity(idToRemove types.EntityID) error {
 		if err != nil {
 			return err
 		}
+		m.removeIndexedValue(comp, idToRemove)
 	}
 
 	return nil
@@ -209,14 +278,19 @@ func (m *EntityCommandBuffer) CreateManyEntities(num int, comps ...types.Compone
 		if err != nil {
 			return nil, err
 		}
-		active.ids = append(active.ids, currID)
-		active.modified = true
 		ecslog.Entity(&log.Logger, zerolog.DebugLevel, currID, archID, comps)
 	}
+	active.insert(ids...)
+	active.modified = true
 	err = m.setActiveEntities(archID, active)
 	if err != nil {
 		return nil, err
 	}
+	for _, comp := range comps {
+		if err := m.setIndexedDefault(comp, ids...); err != nil {
+			return nil, err
+		}
+	}
 	return ids, nil
 }
 
@@ -234,6 +308,9 @@ func (m *EntityCommandBuffer) SetComponentForEntity(
 	}
 
 	key := compKey{cType.ID(), id}
+	if err := m.setIndexedValue(cType, id, value); err != nil {
+		return err
+	}
 	return m.compValues.Set(key, value)
 }
 
@@ -257,14 +334,14 @@ func (m *EntityCommandBuffer) GetComponentForEntity(cType types.ComponentMetadat
 	// Fetch the value from storage
 	redisKey := storageComponentKey(cType.ID(), id)
 
+	stored := true
 	bz, err := m.dbStorage.GetBytes(ctx, redisKey)
 	if err != nil {
-		// todo: this is redis specific, should be changed to a general error on storage
-		// todo: RedisStorage needs to be modified to return this general error when a redis.Nil is detected.
-		if !errors.Is(err, redis.Nil) {
+		if !errors.Is(err, ErrKeyNotFound) {
 			return nil, err
 		}
 		// This value has never been set. Make a default value.
+		stored = false
 		bz, err = cType.New()
 		if err != nil {
 			return nil, err
@@ -274,6 +351,9 @@ func (m *EntityCommandBuffer) GetComponentForEntity(cType types.ComponentMetadat
 	if err != nil {
 		return nil, err
 	}
+	if err := m.compOriginals.Set(key, committedValue{bz: bz, stored: stored}); err != nil {
+		return nil, err
+	}
 	return value, m.compValues.Set(key, value)
 }
 
@@ -311,7 +391,10 @@ func (m *EntityCommandBuffer) AddComponentToEntity(cType types.ComponentMetadata
 	if err != nil {
 		return err
 	}
-	return m.moveEntityByArchetype(fromArchID, toArchID, id)
+	if err := m.moveEntityByArchetype(fromArchID, toArchID, id); err != nil {
+		return err
+	}
+	return m.setIndexedDefault(cType, id)
 }
 
 // RemoveComponentFromEntity removes the given component from the given entity. An error is returned if the entity
@@ -345,6 +428,7 @@ func (m *EntityCommandBuffer) RemoveComponentFromEntity(cType types.ComponentMet
 	if err != nil {
 		return err
 	}
+	m.removeIndexedValue(cType, id)
 	fromArchID, err := m.getOrMakeArchIDForComponents(comps)
 	if err != nil {
 		return err
@@ -397,7 +481,8 @@ func (m *EntityCommandBuffer) GetArchIDForComponents(components []types.Componen
 	return 0, eris.Wrap(ErrArchetypeNotFound, "")
 }
 
-// GetEntitiesForArchID returns all the entities that currently belong to the given archetype EntityID.
+// GetEntitiesForArchID returns all the entities that currently belong to the given archetype EntityID, in ascending
+// order.
 func (m *EntityCommandBuffer) GetEntitiesForArchID(archID types.ArchetypeID) ([]types.EntityID, error) {
 	active, err := m.getActiveEntities(archID)
 	if err != nil {
@@ -432,11 +517,10 @@ func (m *EntityCommandBuffer) ArchetypeCount() int {
 func (m *EntityCommandBuffer) Close() error {
 	ctx := context.Background()
 	err := eris.Wrap(m.dbStorage.Close(ctx), "")
-	// todo: make error general to storage and not redis specific
-	// todo: adjust redis client to be return a general storage error when redis.ErrClosed is detected
-	if eris.Is(eris.Cause(err), redis.ErrClosed) {
-		// if redis is already closed that means another shutdown pathway got to it first.
-		// There are multiple modules that will try to shutdown redis, if it is already shutdown it is not an error.
+	if errors.Is(err, ErrStorageClosed) {
+		// if the storage is already closed that means another shutdown pathway got to it first.
+		// There are multiple modules that will try to shutdown the storage, if it is already shutdown it is not an
+		// error.
 		return nil
 	}
 	return err
@@ -451,9 +535,8 @@ func (m *EntityCommandBuffer) getArchetypeForEntity(id types.EntityID) (types.Ar
 	key := storageArchetypeIDForEntityID(id)
 	num, err := m.dbStorage.GetInt(context.Background(), key)
 	if err != nil {
-		// todo: Make redis.Nil a general error on storage
-		if errors.Is(err, redis.Nil) {
-			return 0, eris.Wrap(redis.Nil, ErrEntityDoesNotExist.Error())
+		if errors.Is(err, ErrKeyNotFound) {
+			return 0, eris.Wrap(ErrKeyNotFound, ErrEntityDoesNotExist.Error())
 		}
 		return 0, eris.Wrap(err, "")
 	}
@@ -465,29 +548,69 @@ func (m *EntityCommandBuffer) getArchetypeForEntity(id types.EntityID) (types.Ar
 	return archID, nil
 }
 
-// nextEntityID returns the next available entity EntityID.
+// nextEntityID returns the next available entity EntityID. The handles of removed entities are reused first, in the
+// order the entities were removed. Otherwise, a new index is assigned.
 func (m *EntityCommandBuffer) nextEntityID() (types.EntityID, error) {
-	if !m.isEntityIDLoaded {
-		// The next valid entity EntityID needs to be loaded from dbStorage.
-		ctx := context.Background()
-		nextID, err := m.dbStorage.GetUInt64(ctx, storageNextEntityIDKey())
-		err = eris.Wrap(err, "")
+	if err := m.loadEntityIDs(); err != nil {
+		return 0, err
+	}
+	freeUsed, pending := m.freeEntityIDsUsed, m.pendingEntityIDs
+	m.journal.record(func() error {
+		m.freeEntityIDsUsed, m.pendingEntityIDs = freeUsed, pending
+		return nil
+	})
+
+	if position := m.freeEntityIDsHead + m.freeEntityIDsUsed; position < m.freeEntityIDsTail {
+		id, err := m.dbStorage.GetUInt64(context.Background(), storageFreeEntityIDKey(position))
 		if err != nil {
-			// todo: make redis.Nil a general error on storage.
-			if !eris.Is(eris.Cause(err), redis.Nil) {
-				return 0, err
-			}
-			// redis.Nil means there's no value at this key. Start with an EntityID of 0
-			nextID = 0
+			return 0, eris.Wrapf(err, "failed to get the free entity ID at position %d", position)
 		}
-		m.nextEntityIDSaved = nextID
-		m.pendingEntityIDs = 0
-		m.isEntityIDLoaded = true
+		m.freeEntityIDsUsed++
+		return types.EntityID(id), nil
 	}
 
-	id := m.nextEntityIDSaved + m.pendingEntityIDs
+	index := m.nextEntityIDSaved + m.pendingEntityIDs
+	if index > math.MaxUint32 {
+		return 0, eris.New("all entity indices are in use")
+	}
 	m.pendingEntityIDs++
-	return types.EntityID(id), nil
+	return types.NewEntityID(uint32(index), 0), nil
+}
+
+// loadEntityIDs loads the next valid entity index and the positions of the free entity handles from dbStorage, if they
+// were not loaded yet. The free handles themselves are only read when they are reused.
+func (m *EntityCommandBuffer) loadEntityIDs() error {
+	if m.isEntityIDLoaded {
+		return nil
+	}
+	ctx := context.Background()
+	nextID, err := m.dbStorage.GetUInt64(ctx, storageNextEntityIDKey())
+	err = eris.Wrap(err, "")
+	if err != nil {
+		if !errors.Is(err, ErrKeyNotFound) {
+			return err
+		}
+		// There's no value at this key. Start with an EntityID of 0
+		nextID = 0
+	}
+
+	// The queue of free handles is empty until an entity is removed.
+	head, err := m.dbStorage.GetUInt64(ctx, storageFreeEntityIDsHeadKey())
+	if err != nil && !errors.Is(err, ErrKeyNotFound) {
+		return eris.Wrap(err, "")
+	}
+	tail, err := m.dbStorage.GetUInt64(ctx, storageFreeEntityIDsTailKey())
+	if err != nil && !errors.Is(err, ErrKeyNotFound) {
+		return eris.Wrap(err, "")
+	}
+
+	m.nextEntityIDSaved = nextID
+	m.pendingEntityIDs = 0
+	m.freeEntityIDsHead = head
+	m.freeEntityIDsTail = tail
+	m.freeEntityIDsUsed = 0
+	m.isEntityIDLoaded = true
+	return nil
 }
 
 // getOrMakeArchIDForComponents converts the given set of components into an archetype EntityID.
@@ -527,9 +650,7 @@ func (m *EntityCommandBuffer) getActiveEntities(archID types.ArchetypeID) (activ
 	err = eris.Wrap(err, "")
 	var ids []types.EntityID
 	if err != nil {
-		// todo: this is redis specific, should be changed to a general error on storage
-		// todo: RedisStorage needs to be modified to return this general error when a redis.Nil is detected.
-		if !eris.Is(eris.Cause(err), redis.Nil) {
+		if !errors.Is(err, ErrKeyNotFound) {
 			return active, err
 		}
 	} else {
@@ -538,10 +659,7 @@ func (m *EntityCommandBuffer) getActiveEntities(archID types.ArchetypeID) (activ
 			return active, err
 		}
 	}
-	result := activeEntities{
-		ids:      ids,
-		modified: false,
-	}
+	result := newActiveEntities(ids)
 	err = m.activeEntities.Set(archID, result)
 	if err != nil {
 		return activeEntities{}, err
@@ -573,7 +691,7 @@ func (m *EntityCommandBuffer) moveEntityByArchetype(fromArchID, toArchID types.A
 	if err != nil {
 		return err
 	}
-	if err = active.swapRemove(id); err != nil {
+	if err = active.remove(id); err != nil {
 		return err
 	}
 	err = m.setActiveEntities(fromArchID, active)
@@ -585,7 +703,7 @@ func (m *EntityCommandBuffer) moveEntityByArchetype(fromArchID, toArchID types.A
 	if err != nil {
 		return err
 	}
-	active.ids = append(active.ids, id)
+	active.insert(id)
 	err = m.setActiveEntities(toArchID, active)
 	if err != nil {
 		return err
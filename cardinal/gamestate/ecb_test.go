@@ -1,12 +1,15 @@
 package gamestate_test
 
 import (
+	"context"
 	"runtime"
+	"slices"
 	"testing"
 	"time"
 
 	"github.com/alicebob/miniredis/v2"
 	"github.com/redis/go-redis/v9"
+	"github.com/rotisserie/eris"
 
 	"pkg.world.dev/world-engine/assert"
 	"pkg.world.dev/world-engine/cardinal"
@@ -438,6 +441,98 @@ func TestEntityCanBeRemoved(t *testing.T) {
 	}
 }
 
+func TestRemovedEntityIndicesAreReusedWithNextGeneration(t *testing.T) {
+	ctx := context.Background()
+	manager := newCmdBufferForTest(t)
+
+	ids, err := manager.CreateManyEntities(3, fooComp)
+	assert.NilError(t, err)
+	assert.NilError(t, manager.FinalizeTick(ctx))
+
+	assert.NilError(t, manager.RemoveEntity(ids[2]))
+	assert.NilError(t, manager.RemoveEntity(ids[0]))
+	// Indices are not reused in the tick the entity was removed in.
+	id, err := manager.CreateEntity(fooComp)
+	assert.NilError(t, err)
+	assert.Equal(t, id, types.NewEntityID(3, 0))
+	assert.NilError(t, manager.FinalizeTick(ctx))
+
+	// Indices are reused in the order the entities were removed in.
+	reused, err := manager.CreateManyEntities(3, fooComp)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, reused, []types.EntityID{
+		types.NewEntityID(2, 1),
+		types.NewEntityID(0, 1),
+		types.NewEntityID(4, 0),
+	})
+
+	// The old handles do not refer to the new entities.
+	_, err = manager.GetComponentTypesForEntity(ids[0])
+	assert.Check(t, eris.Is(err, gamestate.ErrEntityDoesNotExist))
+	_, err = manager.GetComponentTypesForEntity(reused[1])
+	assert.NilError(t, err)
+}
+
+func TestFreeEntityIDsAreDiscardedWithPendingChanges(t *testing.T) {
+	ctx := context.Background()
+	manager := newCmdBufferForTest(t)
+
+	ids, err := manager.CreateManyEntities(2, fooComp)
+	assert.NilError(t, err)
+	assert.NilError(t, manager.FinalizeTick(ctx))
+	assert.NilError(t, manager.RemoveEntity(ids[0]))
+	assert.NilError(t, manager.FinalizeTick(ctx))
+
+	id, err := manager.CreateEntity(fooComp)
+	assert.NilError(t, err)
+	assert.Equal(t, id, types.NewEntityID(0, 1))
+	assert.NilError(t, manager.DiscardPending())
+
+	// The discarded entity did not use up the free handle.
+	id, err = manager.CreateEntity(fooComp)
+	assert.NilError(t, err)
+	assert.Equal(t, id, types.NewEntityID(0, 1))
+}
+
+func TestFreeEntityIDsAreStoredIndividually(t *testing.T) {
+	ctx := context.Background()
+	manager, client := newCmdBufferAndRedisClientForTest(t, nil)
+
+	ids, err := manager.CreateManyEntities(3, fooComp)
+	assert.NilError(t, err)
+	assert.NilError(t, manager.FinalizeTick(ctx))
+	for _, id := range ids {
+		assert.NilError(t, manager.RemoveEntity(id))
+	}
+	assert.NilError(t, manager.FinalizeTick(ctx))
+
+	id, err := manager.CreateEntity(fooComp)
+	assert.NilError(t, err)
+	assert.Equal(t, id, types.NewEntityID(0, 1))
+	assert.NilError(t, manager.FinalizeTick(ctx))
+
+	// Reusing a handle only removes it from the front of the queue, the other handles are not written again.
+	keys, err := client.Keys(ctx, "ECB:FREE-ENTITY-ID*").Result()
+	assert.NilError(t, err)
+	slices.Sort(keys)
+	assert.DeepEqual(t, keys, []string{
+		"ECB:FREE-ENTITY-ID:POSITION-1",
+		"ECB:FREE-ENTITY-ID:POSITION-2",
+		"ECB:FREE-ENTITY-IDS:HEAD",
+		"ECB:FREE-ENTITY-IDS:TAIL",
+	})
+
+	// A new manager continues from the front of the stored queue.
+	reloaded, _ := newCmdBufferAndRedisClientForTest(t, client)
+	reused, err := reloaded.CreateManyEntities(3, fooComp)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, reused, []types.EntityID{
+		types.NewEntityID(1, 1),
+		types.NewEntityID(2, 1),
+		types.NewEntityID(3, 0),
+	})
+}
+
 func TestMovedEntitiesCanBeFoundInNewArchetype(t *testing.T) {
 	manager := newCmdBufferForTest(t)
 
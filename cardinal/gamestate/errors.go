@@ -10,8 +10,16 @@ var (
 	ErrComponentNotOnEntity              = errors.New("component not on entity")
 	ErrEntityMustHaveAtLeastOneComponent = errors.New("entities must have at least 1 component")
 	ErrMustRegisterComponent             = errors.New("must register component")
+	ErrNoSavepoint                       = errors.New("there is no open savepoint")
 
 	// ErrComponentMismatchWithSavedState is an error that is returned when a ComponentID from
 	// the saved state is not found in the passed in list of components.
 	ErrComponentMismatchWithSavedState = errors.New("registered components do not match with the saved state")
+
+	// ErrKeyNotFound is returned by a PrimitiveStorage when there is no value stored at the given key.
+	ErrKeyNotFound = errors.New("key not found")
+
+	// ErrStorageClosed is returned by a PrimitiveStorage when the underlying connection or database has already been
+	// closed.
+	ErrStorageClosed = errors.New("storage is closed")
 )
@@ -0,0 +1,796 @@
+package gamestate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/rotisserie/eris"
+
+	"pkg.world.dev/world-engine/cardinal/codec"
+	"pkg.world.dev/world-engine/cardinal/types"
+)
+
+// IndexQuery selects entities by the value of a field of one of their components.
+type IndexQuery struct {
+	// Values selects the entities whose field is equal to one of the values. It is ignored if IsRange is true.
+	Values []any
+	// IsRange selects the entities whose field is between Min and Max, inclusive, instead. A nil bound is unbounded.
+	// Numbers are compared with numbers, and strings with strings.
+	IsRange bool
+	Min     any
+	Max     any
+}
+
+// Matches reports whether the field of the JSON encoded component matches the query. It is used to evaluate the
+// query without an index, and gives the same result as looking the entity up in an index.
+func (q IndexQuery) Matches(componentJSON []byte, field string) (bool, error) {
+	value, ok, err := fieldValue(componentJSON, strings.Split(field, "."))
+	if err != nil || !ok {
+		return false, err
+	}
+	return q.matches(value)
+}
+
+func (q IndexQuery) matches(value indexValue) (bool, error) {
+	if !q.IsRange {
+		for _, v := range q.Values {
+			other, err := newIndexValue(v)
+			if err != nil {
+				return false, err
+			}
+			if other.key == value.key {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+	if q.Min != nil {
+		lowest, err := newIndexValue(q.Min)
+		if err != nil {
+			return false, err
+		}
+		if !value.comparableTo(lowest) || compareIndexValues(value, lowest) < 0 {
+			return false, nil
+		}
+	}
+	if q.Max != nil {
+		highest, err := newIndexValue(q.Max)
+		if err != nil {
+			return false, err
+		}
+		if !value.comparableTo(highest) || compareIndexValues(value, highest) > 0 {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// indexValue is the value of an indexed field. key is the canonical JSON encoding of the value, so that values that
+// are equal in JSON, like the int 1 and the float64 1, have the same key.
+type indexValue struct {
+	key  string
+	rank int
+	// order is a bool, *big.Rat or string, depending on rank. It is nil for values that can't be ordered.
+	order any
+}
+
+const (
+	rankBool = iota
+	rankNumber
+	rankString
+	rankOther
+)
+
+func newIndexValue(value any) (indexValue, error) {
+	bz, err := json.Marshal(value)
+	if err != nil {
+		return indexValue{}, eris.Wrap(err, "indexed values must be json serializable")
+	}
+	return parseIndexValue(bz)
+}
+
+func parseIndexValue(bz []byte) (indexValue, error) {
+	decoder := json.NewDecoder(bytes.NewReader(bz))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return indexValue{}, eris.Wrap(err, "failed to decode indexed value")
+	}
+	key, err := json.Marshal(value)
+	if err != nil {
+		return indexValue{}, eris.Wrap(err, "")
+	}
+	result := indexValue{key: string(key), rank: rankOther}
+	switch v := value.(type) {
+	case bool:
+		result.rank, result.order = rankBool, v
+	case json.Number:
+		number, ok := new(big.Rat).SetString(v.String())
+		if !ok {
+			return indexValue{}, eris.Errorf("invalid number %q", v)
+		}
+		// Numbers that are equal have the same key, e.g. 1 and 1.0. Integers are exact, other numbers are float64.
+		if number.IsInt() {
+			result.key = number.Num().String()
+		} else {
+			f, _ := number.Float64()
+			result.key = strconv.FormatFloat(f, 'g', -1, 64)
+			number = new(big.Rat).SetFloat64(f)
+		}
+		result.rank, result.order = rankNumber, number
+	case string:
+		result.rank, result.order = rankString, v
+	}
+	return result, nil
+}
+
+func (v indexValue) comparableTo(other indexValue) bool {
+	return v.rank != rankOther && v.rank == other.rank
+}
+
+// compareIndexValues orders values by type first (booleans, numbers, strings, other values), and then by value.
+func compareIndexValues(a, b indexValue) int {
+	if a.rank != b.rank {
+		return a.rank - b.rank
+	}
+	switch a.rank {
+	case rankBool:
+		aBool, _ := a.order.(bool)
+		bBool, _ := b.order.(bool)
+		switch {
+		case aBool == bBool:
+			return 0
+		case bBool:
+			return -1
+		}
+		return 1
+	case rankNumber:
+		aNum, _ := a.order.(*big.Rat)
+		bNum, _ := b.order.(*big.Rat)
+		return aNum.Cmp(bNum)
+	}
+	return strings.Compare(a.key, b.key)
+}
+
+// fieldValue returns the value at the path in the JSON encoded component. ok is false if the field does not exist.
+func fieldValue(componentJSON []byte, path []string) (value indexValue, ok bool, err error) {
+	bz := json.RawMessage(componentJSON)
+	for _, name := range path {
+		var object map[string]json.RawMessage
+		if err := json.Unmarshal(bz, &object); err != nil {
+			// The parent of the field is not an object.
+			return indexValue{}, false, nil //nolint:nilerr // a missing field is not an error
+		}
+		if bz, ok = object[name]; !ok {
+			return indexValue{}, false, nil
+		}
+	}
+	value, err = parseIndexValue(bz)
+	return value, err == nil, err
+}
+
+// indexBucket holds the entities whose indexed field has the same value.
+type indexBucket struct {
+	value indexValue
+	ids   map[types.EntityID]struct{}
+}
+
+// fieldIndex maps the values of a field of a component to the entities that have the value. The committed entries
+// mirror the entries in dbStorage, and are loaded lazily. Changes made in the pending tick are kept separately until
+// the tick is finalized.
+type fieldIndex struct {
+	cType   types.ComponentMetadata
+	field   string
+	path    []string
+	ordered bool
+
+	loaded  bool
+	buckets map[string]*indexBucket
+	// sorted holds the buckets in value order. It is only maintained for ordered indexes.
+	sorted     []*indexBucket
+	entityKeys map[types.EntityID]string
+	// rebuilt is true if the committed entries were rebuilt from the component values, so every entry needs to be
+	// written, and staleKeys need to be deleted, when the tick is finalized.
+	rebuilt   bool
+	staleKeys []string
+
+	// pending holds the value of every entity whose component changed in the pending tick, or nil if the entity
+	// does not have the component anymore.
+	pending map[types.EntityID]*indexValue
+}
+
+func newFieldIndex(cType types.ComponentMetadata, index types.ComponentIndex) *fieldIndex {
+	return &fieldIndex{
+		cType:   cType,
+		field:   index.Field,
+		path:    strings.Split(index.Field, "."),
+		ordered: index.Ordered,
+		pending: make(map[types.EntityID]*indexValue),
+	}
+}
+
+// name identifies the index in the list of indexes stored at storageIndexedFieldsKey.
+func (f *fieldIndex) name() string {
+	return fmt.Sprintf("%d:%s", f.cType.ID(), f.field)
+}
+
+func (f *fieldIndex) reset() {
+	f.loaded = false
+	f.buckets = make(map[string]*indexBucket)
+	f.sorted = nil
+	f.entityKeys = make(map[types.EntityID]string)
+	f.rebuilt = false
+	f.staleKeys = nil
+}
+
+// addCommitted adds the entity to the committed entries. The entity must not be in the index.
+func (f *fieldIndex) addCommitted(id types.EntityID, value indexValue) {
+	bucket, ok := f.buckets[value.key]
+	if !ok {
+		bucket = &indexBucket{value: value, ids: make(map[types.EntityID]struct{})}
+		f.buckets[value.key] = bucket
+		if f.ordered {
+			i, _ := slices.BinarySearchFunc(f.sorted, value, compareBucketToValue)
+			f.sorted = slices.Insert(f.sorted, i, bucket)
+		}
+	}
+	bucket.ids[id] = struct{}{}
+	f.entityKeys[id] = value.key
+}
+
+// removeCommitted removes the entity from the committed entries, if it is in the index.
+func (f *fieldIndex) removeCommitted(id types.EntityID) {
+	key, ok := f.entityKeys[id]
+	if !ok {
+		return
+	}
+	delete(f.entityKeys, id)
+	bucket := f.buckets[key]
+	delete(bucket.ids, id)
+	if len(bucket.ids) > 0 {
+		return
+	}
+	delete(f.buckets, key)
+	if f.ordered {
+		if i, found := slices.BinarySearchFunc(f.sorted, bucket.value, compareBucketToValue); found {
+			f.sorted = slices.Delete(f.sorted, i, i+1)
+		}
+	}
+}
+
+func compareBucketToValue(bucket *indexBucket, value indexValue) int {
+	return compareIndexValues(bucket.value, value)
+}
+
+// lookup returns the IDs of the entities that match the query, including the changes of the pending tick, in
+// ascending order.
+func (f *fieldIndex) lookup(query IndexQuery) ([]types.EntityID, error) {
+	var matches []*indexBucket
+	if query.IsRange {
+		start := 0
+		if query.Min != nil {
+			lowest, err := newIndexValue(query.Min)
+			if err != nil {
+				return nil, err
+			}
+			start, _ = slices.BinarySearchFunc(f.sorted, lowest, compareBucketToValue)
+		}
+		var highest *indexValue
+		if query.Max != nil {
+			value, err := newIndexValue(query.Max)
+			if err != nil {
+				return nil, err
+			}
+			highest = &value
+		}
+		for _, bucket := range f.sorted[start:] {
+			if highest != nil && compareIndexValues(bucket.value, *highest) > 0 {
+				break
+			}
+			if ok, err := query.matches(bucket.value); err != nil {
+				return nil, err
+			} else if ok {
+				matches = append(matches, bucket)
+			}
+		}
+	} else {
+		for _, v := range query.Values {
+			value, err := newIndexValue(v)
+			if err != nil {
+				return nil, err
+			}
+			if bucket, ok := f.buckets[value.key]; ok {
+				matches = append(matches, bucket)
+			}
+		}
+	}
+
+	seen := make(map[types.EntityID]struct{})
+	ids := make([]types.EntityID, 0)
+	for _, bucket := range matches {
+		for id := range bucket.ids {
+			if _, changed := f.pending[id]; changed {
+				continue
+			}
+			if _, ok := seen[id]; !ok {
+				seen[id] = struct{}{}
+				ids = append(ids, id)
+			}
+		}
+	}
+	for id, value := range f.pending {
+		if value == nil {
+			continue
+		}
+		if ok, err := query.matches(*value); err != nil {
+			return nil, err
+		} else if ok {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+	return ids, nil
+}
+
+// initIndexes creates an empty index for every field that is indexed by a registered component.
+func (m *EntityCommandBuffer) initIndexes() error {
+	m.indexes = make(map[types.ComponentID][]*fieldIndex)
+	m.indexedFields = nil
+	for _, s := range m.spatialIndexes {
+		s.reset()
+	}
+	if m.typeToComponent == nil {
+		return nil
+	}
+	typeIDs, err := m.typeToComponent.Keys()
+	if err != nil {
+		return err
+	}
+	for _, typeID := range typeIDs {
+		cType, err := m.typeToComponent.Get(typeID)
+		if err != nil {
+			return err
+		}
+		for _, index := range cType.Indexes() {
+			f := newFieldIndex(cType, index)
+			f.reset()
+			m.indexes[typeID] = append(m.indexes[typeID], f)
+		}
+	}
+	return nil
+}
+
+func (m *EntityCommandBuffer) allIndexes() []*fieldIndex {
+	var all []*fieldIndex
+	for _, indexes := range m.indexes {
+		all = append(all, indexes...)
+	}
+	slices.SortFunc(all, func(a, b *fieldIndex) int {
+		return strings.Compare(a.name(), b.name())
+	})
+	return all
+}
+
+// LookupIndex returns the IDs of the entities whose component field matches the query, in ascending order. ok is
+// false if the field is not indexed, or the index can't answer the query, e.g. a range query on a hash index.
+func (m *EntityCommandBuffer) LookupIndex(cType types.ComponentMetadata, field string, query IndexQuery) (
+	ids []types.EntityID, ok bool, err error,
+) {
+	for _, f := range m.indexes[cType.ID()] {
+		if f.field != field {
+			continue
+		}
+		if query.IsRange && !f.ordered {
+			return nil, false, nil
+		}
+		if err := m.loadIndexes(context.Background()); err != nil {
+			return nil, false, err
+		}
+		ids, err := f.lookup(query)
+		return ids, err == nil, err
+	}
+	return nil, false, nil
+}
+
+// setIndexedValue records the new value of a component in the indexes of the component.
+func (m *EntityCommandBuffer) setIndexedValue(cType types.ComponentMetadata, id types.EntityID, value any) error {
+	if err := m.setSpatialValue(cType, value, id); err != nil {
+		return err
+	}
+	if len(m.indexes[cType.ID()]) == 0 {
+		return nil
+	}
+	bz, err := cType.Encode(value)
+	if err != nil {
+		return err
+	}
+	return m.setIndexedBytes(cType, []types.EntityID{id}, bz)
+}
+
+// setIndexedDefault records that the components of the entities have the default value in the indexes of the
+// component.
+func (m *EntityCommandBuffer) setIndexedDefault(cType types.ComponentMetadata, ids ...types.EntityID) error {
+	if err := m.setSpatialDefault(cType, ids...); err != nil {
+		return err
+	}
+	if len(m.indexes[cType.ID()]) == 0 {
+		return nil
+	}
+	bz, err := encodedDefault(cType)
+	if err != nil {
+		return err
+	}
+	return m.setIndexedBytes(cType, ids, bz)
+}
+
+// encodedDefault returns the default value of the component, encoded like a stored value of the component. Unlike
+// the bytes returned by New, it contains every field of the component.
+func encodedDefault(cType types.ComponentMetadata) ([]byte, error) {
+	bz, err := cType.New()
+	if err != nil {
+		return nil, err
+	}
+	value, err := cType.Decode(bz)
+	if err != nil {
+		return nil, err
+	}
+	return cType.Encode(value)
+}
+
+func (m *EntityCommandBuffer) setIndexedBytes(cType types.ComponentMetadata, ids []types.EntityID, bz []byte) error {
+	for _, f := range m.indexes[cType.ID()] {
+		value, ok, err := fieldValue(bz, f.path)
+		if err != nil {
+			return err
+		}
+		for _, id := range ids {
+			recordMapEntry(m.journal, f.pending, id)
+			if ok {
+				f.pending[id] = &value
+			} else {
+				f.pending[id] = nil
+			}
+		}
+	}
+	return nil
+}
+
+// removeIndexedValue records that the entity does not have the component anymore in the indexes of the component.
+func (m *EntityCommandBuffer) removeIndexedValue(cType types.ComponentMetadata, id types.EntityID) {
+	if s, ok := m.spatialIndexes[cType.ID()]; ok {
+		recordMapEntry(m.journal, s.pending, id)
+		s.pending[id] = nil
+	}
+	for _, f := range m.indexes[cType.ID()] {
+		recordMapEntry(m.journal, f.pending, id)
+		f.pending[id] = nil
+	}
+}
+
+func (m *EntityCommandBuffer) discardPendingIndexes() {
+	m.pendingIndexedFields = nil
+	for _, indexes := range m.indexes {
+		for _, f := range indexes {
+			clear(f.pending)
+		}
+	}
+	for _, s := range m.spatialIndexes {
+		clear(s.pending)
+	}
+}
+
+// loadIndexedFields loads the names of the indexes that are stored in dbStorage.
+func (m *EntityCommandBuffer) loadIndexedFields(ctx context.Context) error {
+	if m.indexedFields != nil {
+		return nil
+	}
+	m.indexedFields = []string{}
+	bz, err := m.dbStorage.GetBytes(ctx, storageIndexedFieldsKey())
+	if errors.Is(err, ErrKeyNotFound) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	m.indexedFields, err = codec.Decode[[]string](bz)
+	return err
+}
+
+// loadIndexes loads the committed entries of every index from dbStorage. Indexes that are not stored yet, e.g.
+// because the index was just declared, are rebuilt from the committed component values.
+func (m *EntityCommandBuffer) loadIndexes(ctx context.Context) error {
+	var toLoad []*fieldIndex
+	for _, f := range m.allIndexes() {
+		if !f.loaded {
+			toLoad = append(toLoad, f)
+		}
+	}
+	if len(toLoad) == 0 {
+		return nil
+	}
+	if err := m.loadIndexedFields(ctx); err != nil {
+		return eris.Wrap(err, "failed to load indexed fields")
+	}
+	keys, err := m.dbStorage.Keys(ctx)
+	if err != nil {
+		return eris.Wrap(err, "failed to list keys")
+	}
+	for _, f := range toLoad {
+		f.reset()
+		prefix := storageIndexKeyPrefix(f.cType.ID(), f.field)
+		stored := slices.Contains(m.indexedFields, f.name())
+		for _, key := range keys {
+			if !strings.HasPrefix(key, prefix) {
+				continue
+			}
+			if !stored {
+				f.staleKeys = append(f.staleKeys, key)
+				continue
+			}
+			if err := m.loadIndexBucket(ctx, f, key, strings.TrimPrefix(key, prefix)); err != nil {
+				return err
+			}
+		}
+		if !stored {
+			if err := m.rebuildIndex(ctx, f); err != nil {
+				return eris.Wrapf(err, "failed to build index on field %q of component %q", f.field, f.cType.Name())
+			}
+			f.rebuilt = true
+		}
+		f.loaded = true
+	}
+	return nil
+}
+
+func (m *EntityCommandBuffer) loadIndexBucket(ctx context.Context, f *fieldIndex, key, valueKey string) error {
+	value, err := parseIndexValue([]byte(valueKey))
+	if err != nil {
+		return err
+	}
+	bz, err := m.dbStorage.GetBytes(ctx, key)
+	if err != nil {
+		return err
+	}
+	ids, err := codec.Decode[[]types.EntityID](bz)
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		f.addCommitted(id, value)
+	}
+	return nil
+}
+
+// rebuildIndex adds the committed value of every entity that has the component of the index.
+func (m *EntityCommandBuffer) rebuildIndex(ctx context.Context, f *fieldIndex) error {
+	return eachStoredComponent(ctx, m.dbStorage, m.archIDToComps, f.cType, func(id types.EntityID, bz []byte) error {
+		value, ok, err := fieldValue(bz, f.path)
+		if err != nil {
+			return err
+		}
+		if ok {
+			f.addCommitted(id, value)
+		}
+		return nil
+	})
+}
+
+// eachStoredComponent calls fn with the encoded value of the component of every entity in storage that has the
+// component. Components that are not stored have their default value.
+func eachStoredComponent(
+	ctx context.Context,
+	storage PrimitiveStorage[string],
+	archIDToComps VolatileStorage[types.ArchetypeID, []types.ComponentMetadata],
+	cType types.ComponentMetadata,
+	fn func(id types.EntityID, bz []byte) error,
+) error {
+	archIDs, err := archIDToComps.Keys()
+	if err != nil {
+		return err
+	}
+	defaultBz, err := encodedDefault(cType)
+	if err != nil {
+		return err
+	}
+	for _, archID := range archIDs {
+		comps, err := archIDToComps.Get(archID)
+		if err != nil {
+			return err
+		}
+		if !slices.ContainsFunc(comps, func(c types.ComponentMetadata) bool { return c.ID() == cType.ID() }) {
+			continue
+		}
+		bz, err := storage.GetBytes(ctx, storageActiveEntityIDKey(archID))
+		if errors.Is(err, ErrKeyNotFound) {
+			continue
+		} else if err != nil {
+			return err
+		}
+		ids, err := codec.Decode[[]types.EntityID](bz)
+		if err != nil {
+			return err
+		}
+		for _, id := range ids {
+			compBz, err := storage.GetBytes(ctx, storageComponentKey(cType.ID(), id))
+			if errors.Is(err, ErrKeyNotFound) {
+				compBz = defaultBz
+			} else if err != nil {
+				return err
+			}
+			if err := fn(id, compBz); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// addIndexChangesToPipe writes the index entries that were changed by the pending tick to the pipe, as well as
+// every entry of the indexes that were rebuilt.
+func (m *EntityCommandBuffer) addIndexChangesToPipe(ctx context.Context, pipe PrimitiveStorage[string]) error {
+	all := m.allIndexes()
+	changed := false
+	for _, f := range all {
+		if len(f.pending) > 0 || f.rebuilt {
+			changed = true
+			break
+		}
+	}
+	if !changed {
+		return nil
+	}
+	if err := m.loadIndexes(ctx); err != nil {
+		return err
+	}
+
+	indexedFields := make([]string, 0, len(all))
+	for _, f := range all {
+		indexedFields = append(indexedFields, f.name())
+		if err := addFieldIndexChangesToPipe(ctx, f, pipe); err != nil {
+			return err
+		}
+	}
+	if !slices.Equal(indexedFields, m.indexedFields) {
+		bz, err := codec.Encode(indexedFields)
+		if err != nil {
+			return err
+		}
+		if err := pipe.Set(ctx, storageIndexedFieldsKey(), bz); err != nil {
+			return eris.Wrap(err, "")
+		}
+	}
+	m.pendingIndexedFields = indexedFields
+	return nil
+}
+
+func addFieldIndexChangesToPipe(ctx context.Context, f *fieldIndex, pipe PrimitiveStorage[string]) error {
+	prefix := storageIndexKeyPrefix(f.cType.ID(), f.field)
+	for _, key := range f.staleKeys {
+		if err := pipe.Delete(ctx, key); err != nil {
+			return eris.Wrap(err, "")
+		}
+	}
+
+	// Find the buckets that change, and the values of the new buckets.
+	changedKeys := make(map[string]indexValue)
+	if f.rebuilt {
+		for key, bucket := range f.buckets {
+			changedKeys[key] = bucket.value
+		}
+	}
+	for id, value := range f.pending {
+		oldKey, hadValue := f.entityKeys[id]
+		if hadValue && value != nil && oldKey == value.key {
+			continue
+		}
+		if hadValue {
+			changedKeys[oldKey] = f.buckets[oldKey].value
+		}
+		if value != nil {
+			changedKeys[value.key] = *value
+		}
+	}
+
+	for key := range changedKeys {
+		var ids []types.EntityID
+		if bucket, ok := f.buckets[key]; ok {
+			for id := range bucket.ids {
+				if _, changed := f.pending[id]; !changed {
+					ids = append(ids, id)
+				}
+			}
+		}
+		for id, value := range f.pending {
+			if value != nil && value.key == key {
+				ids = append(ids, id)
+			}
+		}
+		storageKey := prefix + key
+		if len(ids) == 0 {
+			if err := pipe.Delete(ctx, storageKey); err != nil {
+				return eris.Wrap(err, "")
+			}
+			continue
+		}
+		slices.Sort(ids)
+		bz, err := codec.Encode(ids)
+		if err != nil {
+			return err
+		}
+		if err := pipe.Set(ctx, storageKey, bz); err != nil {
+			return eris.Wrap(err, "")
+		}
+	}
+	return nil
+}
+
+// commitIndexes applies the pending changes to the committed index entries once the tick has been finalized.
+func (m *EntityCommandBuffer) commitIndexes() {
+	for _, s := range m.spatialIndexes {
+		s.commit()
+	}
+	if m.pendingIndexedFields == nil {
+		return
+	}
+	for _, f := range m.allIndexes() {
+		for id, value := range f.pending {
+			f.removeCommitted(id)
+			if value != nil {
+				f.addCommitted(id, *value)
+			}
+		}
+		f.rebuilt = false
+		f.staleKeys = nil
+	}
+	m.indexedFields = m.pendingIndexedFields
+	m.pendingIndexedFields = nil
+}
+
+// dropIndexesInPipe removes the stored entries of the indexes of the component, so they are rebuilt the next time
+// they are used. keys must contain every key in dbStorage. The in-memory indexes must be reset with resetIndexes once
+// the pipe is committed.
+func (m *EntityCommandBuffer) dropIndexesInPipe(
+	ctx context.Context, cType types.ComponentMetadata, keys []string, pipe PrimitiveStorage[string],
+) error {
+	if err := m.loadIndexedFields(ctx); err != nil {
+		return err
+	}
+	prefix := fmt.Sprintf("%d:", cType.ID())
+	indexedFields := slices.DeleteFunc(slices.Clone(m.indexedFields), func(name string) bool {
+		return strings.HasPrefix(name, prefix)
+	})
+	if len(indexedFields) == len(m.indexedFields) {
+		return nil
+	}
+	keyPrefix := storageIndexTypeKeyPrefix(cType.ID())
+	for _, key := range keys {
+		if strings.HasPrefix(key, keyPrefix) {
+			if err := pipe.Delete(ctx, key); err != nil {
+				return eris.Wrap(err, "")
+			}
+		}
+	}
+	bz, err := codec.Encode(indexedFields)
+	if err != nil {
+		return err
+	}
+	return eris.Wrap(pipe.Set(ctx, storageIndexedFieldsKey(), bz), "")
+}
+
+// resetIndexes drops the in-memory entries of the indexes of the component, after their stored entries were dropped.
+func (m *EntityCommandBuffer) resetIndexes(cType types.ComponentMetadata) {
+	m.indexedFields = nil
+	for _, f := range m.indexes[cType.ID()] {
+		f.reset()
+		clear(f.pending)
+	}
+	if s, ok := m.spatialIndexes[cType.ID()]; ok {
+		s.reset()
+	}
+}
@@ -0,0 +1,174 @@
+package gamestate_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+
+	"pkg.world.dev/world-engine/assert"
+	"pkg.world.dev/world-engine/cardinal/component"
+	"pkg.world.dev/world-engine/cardinal/gamestate"
+	"pkg.world.dev/world-engine/cardinal/types"
+)
+
+type Cell struct {
+	X, Y int
+}
+
+type Player struct {
+	Nick  string
+	Level int
+	Cell  Cell
+}
+
+func (Player) Name() string { return "player" }
+
+func newIndexedCmdBuffer(t *testing.T, client *redis.Client, opts ...component.Option[Player]) (
+	*gamestate.EntityCommandBuffer, types.ComponentMetadata,
+) {
+	playerComp, err := component.NewComponentMetadata[Player](opts...)
+	assert.NilError(t, err)
+	assert.NilError(t, playerComp.SetID(1))
+	storage := gamestate.NewRedisPrimitiveStorage(client)
+	manager, err := gamestate.NewEntityCommandBuffer(&storage)
+	assert.NilError(t, err)
+	assert.NilError(t, manager.RegisterComponents([]types.ComponentMetadata{playerComp}))
+	return manager, playerComp
+}
+
+func newRedisClientForTest(t *testing.T) *redis.Client {
+	s := miniredis.RunT(t)
+	return redis.NewClient(&redis.Options{Addr: s.Addr()})
+}
+
+func lookup(t *testing.T, manager *gamestate.EntityCommandBuffer, cType types.ComponentMetadata, field string,
+	query gamestate.IndexQuery,
+) []types.EntityID {
+	ids, ok, err := manager.LookupIndex(cType, field, query)
+	assert.NilError(t, err)
+	assert.Check(t, ok)
+	return ids
+}
+
+func TestIndexLookupIncludesPendingChanges(t *testing.T) {
+	manager, playerComp := newIndexedCmdBuffer(t, newRedisClientForTest(t),
+		component.WithHashIndex[Player]("Nick"), component.WithOrderedIndex[Player]("Level"))
+	byName := func(name string) []types.EntityID {
+		return lookup(t, manager, playerComp, "Nick", gamestate.IndexQuery{Values: []any{name}})
+	}
+
+	ids, err := manager.CreateManyEntities(3, playerComp)
+	assert.NilError(t, err)
+	// New entities are indexed with the default value.
+	assert.DeepEqual(t, byName(""), ids)
+
+	assert.NilError(t, manager.SetComponentForEntity(playerComp, ids[0], Player{Nick: "alice", Level: 3}))
+	assert.NilError(t, manager.SetComponentForEntity(playerComp, ids[1], Player{Nick: "bob", Level: 7}))
+	assert.DeepEqual(t, byName("alice"), []types.EntityID{ids[0]})
+	assert.NilError(t, manager.FinalizeTick(context.Background()))
+
+	assert.DeepEqual(t, byName("alice"), []types.EntityID{ids[0]})
+	assert.DeepEqual(t, byName(""), []types.EntityID{ids[2]})
+	assert.DeepEqual(t, lookup(t, manager, playerComp, "Level", gamestate.IndexQuery{IsRange: true, Min: 1, Max: 10}),
+		[]types.EntityID{ids[0], ids[1]})
+
+	assert.NilError(t, manager.SetComponentForEntity(playerComp, ids[0], Player{Nick: "carol", Level: 3}))
+	assert.NilError(t, manager.RemoveEntity(ids[1]))
+	assert.Equal(t, len(byName("alice")), 0)
+	assert.Equal(t, len(byName("bob")), 0)
+	assert.DeepEqual(t, byName("carol"), []types.EntityID{ids[0]})
+
+	// Discarded changes are removed from the index.
+	assert.NilError(t, manager.DiscardPending())
+	assert.DeepEqual(t, byName("alice"), []types.EntityID{ids[0]})
+	assert.DeepEqual(t, byName("bob"), []types.EntityID{ids[1]})
+}
+
+func TestOrderedIndexRangeLookup(t *testing.T) {
+	manager, playerComp := newIndexedCmdBuffer(t, newRedisClientForTest(t),
+		component.WithHashIndex[Player]("Nick"), component.WithOrderedIndex[Player]("Cell.X"))
+	ids, err := manager.CreateManyEntities(5, playerComp)
+	assert.NilError(t, err)
+	for i, id := range ids {
+		assert.NilError(t, manager.SetComponentForEntity(playerComp, id, Player{Cell: Cell{X: i * 10}}))
+	}
+	assert.NilError(t, manager.FinalizeTick(context.Background()))
+
+	byX := func(query gamestate.IndexQuery) []types.EntityID {
+		query.IsRange = true
+		return lookup(t, manager, playerComp, "Cell.X", query)
+	}
+	assert.DeepEqual(t, byX(gamestate.IndexQuery{Min: 10, Max: 30}), ids[1:4])
+	assert.DeepEqual(t, byX(gamestate.IndexQuery{Min: 15.5}), ids[2:])
+	assert.DeepEqual(t, byX(gamestate.IndexQuery{Max: 0}), ids[:1])
+	assert.Equal(t, len(byX(gamestate.IndexQuery{Min: "a"})), 0)
+	assert.DeepEqual(t, lookup(t, manager, playerComp, "Cell.X", gamestate.IndexQuery{Values: []any{20, 40.0}}),
+		[]types.EntityID{ids[2], ids[4]})
+
+	// Range lookups can't be answered by a hash index, and fields without an index can't be looked up.
+	_, ok, err := manager.LookupIndex(playerComp, "Nick", gamestate.IndexQuery{IsRange: true, Min: "a"})
+	assert.NilError(t, err)
+	assert.Check(t, !ok)
+	_, ok, err = manager.LookupIndex(playerComp, "Level", gamestate.IndexQuery{Values: []any{1}})
+	assert.NilError(t, err)
+	assert.Check(t, !ok)
+}
+
+func TestIndexIsPersistedWithTick(t *testing.T) {
+	ctx := context.Background()
+	client := newRedisClientForTest(t)
+	manager, playerComp := newIndexedCmdBuffer(t, client, component.WithHashIndex[Player]("Nick"))
+	ids, err := manager.CreateManyEntities(2, playerComp)
+	assert.NilError(t, err)
+	assert.NilError(t, manager.SetComponentForEntity(playerComp, ids[0], Player{Nick: "alice"}))
+	assert.NilError(t, manager.FinalizeTick(ctx))
+	assert.NilError(t, client.Exists(ctx, "ECB:INDEX:TYPE-ID-1:FIELD-Nick:\"alice\"").Err())
+
+	manager, playerComp = newIndexedCmdBuffer(t, client, component.WithHashIndex[Player]("Nick"))
+	assert.DeepEqual(t, lookup(t, manager, playerComp, "Nick", gamestate.IndexQuery{Values: []any{"alice"}}),
+		[]types.EntityID{ids[0]})
+
+	assert.NilError(t, manager.SetComponentForEntity(playerComp, ids[0], Player{Nick: "bob"}))
+	assert.NilError(t, manager.FinalizeTick(ctx))
+	n, err := client.Exists(ctx, "ECB:INDEX:TYPE-ID-1:FIELD-Nick:\"alice\"").Result()
+	assert.NilError(t, err)
+	assert.Equal(t, n, int64(0))
+}
+
+func TestIndexIsBuiltForExistingState(t *testing.T) {
+	ctx := context.Background()
+	client := newRedisClientForTest(t)
+	manager, playerComp := newIndexedCmdBuffer(t, client)
+	ids, err := manager.CreateManyEntities(3, playerComp)
+	assert.NilError(t, err)
+	assert.NilError(t, manager.SetComponentForEntity(playerComp, ids[1], Player{Level: 2}))
+	assert.NilError(t, manager.FinalizeTick(ctx))
+
+	// The index is declared after the components were stored.
+	manager, playerComp = newIndexedCmdBuffer(t, client, component.WithOrderedIndex[Player]("Level"))
+	levelZero := gamestate.IndexQuery{Values: []any{0}}
+	assert.DeepEqual(t, lookup(t, manager, playerComp, "Level", levelZero), []types.EntityID{ids[0], ids[2]})
+	assert.NilError(t, manager.SetComponentForEntity(playerComp, ids[0], Player{Level: 2}))
+	assert.NilError(t, manager.FinalizeTick(ctx))
+
+	// The index was stored, so it is not rebuilt from the component values.
+	manager, playerComp = newIndexedCmdBuffer(t, client, component.WithOrderedIndex[Player]("Level"))
+	assert.DeepEqual(t, lookup(t, manager, playerComp, "Level", levelZero), []types.EntityID{ids[2]})
+
+	// An index that stopped being maintained is rebuilt when it is declared again.
+	manager, playerComp = newIndexedCmdBuffer(t, client, component.WithHashIndex[Player]("Nick"))
+	assert.NilError(t, manager.SetComponentForEntity(playerComp, ids[2], Player{Level: 2}))
+	assert.NilError(t, manager.FinalizeTick(ctx))
+	manager, playerComp = newIndexedCmdBuffer(t, client, component.WithOrderedIndex[Player]("Level"))
+	assert.Equal(t, len(lookup(t, manager, playerComp, "Level", levelZero)), 0)
+}
+
+func TestIndexedFieldMustExist(t *testing.T) {
+	_, err := component.NewComponentMetadata[Player](component.WithHashIndex[Player]("Cell.Z"))
+	assert.ErrorContains(t, err, "does not exist")
+	_, err = component.NewComponentMetadata[Player](
+		component.WithHashIndex[Player]("Nick"), component.WithOrderedIndex[Player]("Nick"))
+	assert.ErrorContains(t, err, "more than once")
+}
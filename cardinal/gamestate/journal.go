@@ -0,0 +1,147 @@
+package gamestate
+
+import (
+	"github.com/rotisserie/eris"
+)
+
+var _ VolatileStorage[string, any] = &journaledStorage[string, any]{}
+
+// journal records how to undo the pending changes made since the savepoints that are open. Nothing is recorded while
+// no savepoint is open, so a journal costs nothing outside of savepoints.
+type journal struct {
+	undo []func() error
+	// savepoints holds the length of undo when each of the open savepoints was created, from the oldest to the newest.
+	savepoints []int
+}
+
+func (j *journal) active() bool {
+	return len(j.savepoints) > 0
+}
+
+// record adds a function that undoes a change, if a savepoint is open.
+func (j *journal) record(undo func() error) {
+	if j.active() {
+		j.undo = append(j.undo, undo)
+	}
+}
+
+func (j *journal) open() {
+	j.savepoints = append(j.savepoints, len(j.undo))
+}
+
+// release closes the newest savepoint and keeps its changes. They are undone if an outer savepoint is rolled back.
+func (j *journal) release() error {
+	if !j.active() {
+		return eris.Wrap(ErrNoSavepoint, "")
+	}
+	j.savepoints = j.savepoints[:len(j.savepoints)-1]
+	if !j.active() {
+		clear(j.undo)
+		j.undo = j.undo[:0]
+	}
+	return nil
+}
+
+// rollback undoes the changes made since the newest savepoint, in reverse order, and closes it.
+func (j *journal) rollback() error {
+	if !j.active() {
+		return eris.Wrap(ErrNoSavepoint, "")
+	}
+	start := j.savepoints[len(j.savepoints)-1]
+	j.savepoints = j.savepoints[:len(j.savepoints)-1]
+	for i := len(j.undo) - 1; i >= start; i-- {
+		if err := j.undo[i](); err != nil {
+			return eris.Wrap(err, "failed to roll back to the savepoint")
+		}
+	}
+	clear(j.undo[start:])
+	j.undo = j.undo[:start]
+	return nil
+}
+
+func (j *journal) reset() {
+	j.undo = nil
+	j.savepoints = nil
+}
+
+// journaledStorage is a VolatileStorage that records the previous value of every key it changes in the current
+// journal of an EntityCommandBuffer.
+type journaledStorage[K comparable, V any] struct {
+	VolatileStorage[K, V]
+	journal **journal
+}
+
+func newJournaledStorage[K comparable, V any](journal **journal) *journaledStorage[K, V] {
+	return &journaledStorage[K, V]{
+		VolatileStorage: NewMapStorage[K, V](),
+		journal:         journal,
+	}
+}
+
+func (s *journaledStorage[K, V]) Set(key K, value V) error {
+	s.recordKey(key)
+	return s.VolatileStorage.Set(key, value)
+}
+
+func (s *journaledStorage[K, V]) Delete(key K) error {
+	s.recordKey(key)
+	return s.VolatileStorage.Delete(key)
+}
+
+func (s *journaledStorage[K, V]) recordKey(key K) {
+	j := *s.journal
+	if !j.active() {
+		return
+	}
+	old, err := s.VolatileStorage.Get(key)
+	existed := err == nil
+	j.record(func() error {
+		if existed {
+			return s.VolatileStorage.Set(key, old)
+		}
+		return s.VolatileStorage.Delete(key)
+	})
+}
+
+// recordMapEntry records the previous value of the key of m in the journal.
+func recordMapEntry[K comparable, V any](j *journal, m map[K]V, key K) {
+	if !j.active() {
+		return
+	}
+	old, existed := m[key]
+	j.record(func() error {
+		if existed {
+			m[key] = old
+		} else {
+			delete(m, key)
+		}
+		return nil
+	})
+}
+
+// Savepoint opens a savepoint. The pending changes made after it can be undone with RollbackToSavepoint, or kept
+// with ReleaseSavepoint. Savepoints can be nested, in which case the newest one is rolled back or released.
+//
+// Archetypes created after the savepoint are kept when it is rolled back, as they have no effect on the state other
+// than their ID.
+func (m *EntityCommandBuffer) Savepoint() {
+	m.journal.open()
+}
+
+// ReleaseSavepoint closes the newest savepoint, and keeps the changes made since it was opened.
+func (m *EntityCommandBuffer) ReleaseSavepoint() error {
+	return m.journal.release()
+}
+
+// RollbackToSavepoint undoes the pending changes made since the newest savepoint was opened, and closes it.
+func (m *EntityCommandBuffer) RollbackToSavepoint() error {
+	return m.journal.rollback()
+}
+
+// useJournal makes the given journal record the pending changes, and returns the journal that recorded them before.
+// It lets every writer of a synchronizedManager have its own savepoints.
+func (m *EntityCommandBuffer) useJournal(j *journal) (previous *journal) {
+	previous = m.journal
+	m.journal = j
+	return previous
+}
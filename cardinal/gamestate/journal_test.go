@@ -0,0 +1,138 @@
+package gamestate_test
+
+import (
+	"testing"
+
+	"github.com/rotisserie/eris"
+
+	"pkg.world.dev/world-engine/assert"
+	"pkg.world.dev/world-engine/cardinal/component"
+	"pkg.world.dev/world-engine/cardinal/gamestate"
+	"pkg.world.dev/world-engine/cardinal/types"
+)
+
+func TestRollbackToSavepointUndoesTheChangesMadeSinceTheSavepoint(t *testing.T) {
+	ctx := t.Context()
+	manager, client := newCmdBufferAndRedisClientForTest(t, nil)
+
+	ids, err := manager.CreateManyEntities(2, fooComp)
+	assert.NilError(t, err)
+	assert.NilError(t, manager.SetComponentForEntity(fooComp, ids[0], Foo{1}))
+	assert.NilError(t, manager.FinalizeTick(ctx))
+	// Changes made before the savepoint are kept.
+	assert.NilError(t, manager.SetComponentForEntity(fooComp, ids[1], Foo{2}))
+
+	manager.Savepoint()
+	assert.NilError(t, manager.SetComponentForEntity(fooComp, ids[0], Foo{10}))
+	assert.NilError(t, manager.SetComponentForEntity(fooComp, ids[0], Foo{11}))
+	assert.NilError(t, manager.AddComponentToEntity(barComp, ids[1]))
+	assert.NilError(t, manager.RemoveEntity(ids[0]))
+	created, err := manager.CreateEntity(fooComp, barComp)
+	assert.NilError(t, err)
+	assert.NilError(t, manager.RollbackToSavepoint())
+
+	foo, err := manager.GetComponentForEntity(fooComp, ids[0])
+	assert.NilError(t, err)
+	assert.Equal(t, foo, Foo{1})
+	foo, err = manager.GetComponentForEntity(fooComp, ids[1])
+	assert.NilError(t, err)
+	assert.Equal(t, foo, Foo{2})
+	comps, err := manager.GetComponentTypesForEntity(ids[1])
+	assert.NilError(t, err)
+	assert.Equal(t, len(comps), 1)
+	_, err = manager.GetComponentTypesForEntity(created)
+	assert.Check(t, eris.Is(err, gamestate.ErrEntityDoesNotExist))
+
+	// The entity ID of the rolled back entity is assigned again.
+	id, err := manager.CreateEntity(fooComp)
+	assert.NilError(t, err)
+	assert.Equal(t, id, created)
+	assert.NilError(t, manager.FinalizeTick(ctx))
+
+	// The rolled back changes were never saved.
+	reloaded, _ := newCmdBufferAndRedisClientForTest(t, client)
+	foo, err = reloaded.GetComponentForEntity(fooComp, ids[0])
+	assert.NilError(t, err)
+	assert.Equal(t, foo, Foo{1})
+	archID, err := reloaded.GetArchIDForComponents([]types.ComponentMetadata{fooComp})
+	assert.NilError(t, err)
+	entities, err := reloaded.GetEntitiesForArchID(archID)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, entities, []types.EntityID{ids[0], ids[1], id})
+}
+
+func TestSavepointsCanBeNested(t *testing.T) {
+	manager := newCmdBufferForTest(t)
+	id, err := manager.CreateEntity(fooComp)
+	assert.NilError(t, err)
+	get := func() Foo {
+		foo, err := manager.GetComponentForEntity(fooComp, id)
+		assert.NilError(t, err)
+		return foo.(Foo)
+	}
+
+	manager.Savepoint()
+	assert.NilError(t, manager.SetComponentForEntity(fooComp, id, Foo{1}))
+	manager.Savepoint()
+	assert.NilError(t, manager.SetComponentForEntity(fooComp, id, Foo{2}))
+	manager.Savepoint()
+	assert.NilError(t, manager.SetComponentForEntity(fooComp, id, Foo{3}))
+	assert.NilError(t, manager.RollbackToSavepoint())
+	assert.Equal(t, get(), Foo{2})
+
+	// A released savepoint is undone with the savepoint it is nested in.
+	assert.NilError(t, manager.ReleaseSavepoint())
+	assert.Equal(t, get(), Foo{2})
+	assert.NilError(t, manager.RollbackToSavepoint())
+	assert.Equal(t, get(), Foo{})
+
+	err = manager.RollbackToSavepoint()
+	assert.Check(t, eris.Is(err, gamestate.ErrNoSavepoint))
+	err = manager.ReleaseSavepoint()
+	assert.Check(t, eris.Is(err, gamestate.ErrNoSavepoint))
+}
+
+func TestSynchronizedManagersHaveTheirOwnSavepoints(t *testing.T) {
+	manager := newCmdBufferForTest(t)
+	fooID, err := manager.CreateEntity(fooComp)
+	assert.NilError(t, err)
+	barID, err := manager.CreateEntity(barComp)
+	assert.NilError(t, err)
+	assert.NilError(t, manager.FinalizeTick(t.Context()))
+
+	stores := gamestate.NewSynchronizedManagers(manager, 2)
+	stores[0].Savepoint()
+	stores[1].Savepoint()
+	assert.NilError(t, stores[0].SetComponentForEntity(fooComp, fooID, Foo{1}))
+	assert.NilError(t, stores[1].SetComponentForEntity(barComp, barID, Bar{2}))
+
+	// Rolling back the first manager doesn't undo the changes of the second one.
+	assert.NilError(t, stores[0].RollbackToSavepoint())
+	assert.NilError(t, stores[1].ReleaseSavepoint())
+	foo, err := manager.GetComponentForEntity(fooComp, fooID)
+	assert.NilError(t, err)
+	assert.Equal(t, foo, Foo{})
+	bar, err := manager.GetComponentForEntity(barComp, barID)
+	assert.NilError(t, err)
+	assert.Equal(t, bar, Bar{2})
+}
+
+func TestRollbackToSavepointUndoesIndexChanges(t *testing.T) {
+	manager, playerComp := newIndexedCmdBuffer(t, newRedisClientForTest(t),
+		component.WithHashIndex[Player]("Nick"))
+	byName := func(name string) []types.EntityID {
+		return lookup(t, manager, playerComp, "Nick", gamestate.IndexQuery{Values: []any{name}})
+	}
+
+	id, err := manager.CreateEntity(playerComp)
+	assert.NilError(t, err)
+	assert.NilError(t, manager.SetComponentForEntity(playerComp, id, Player{Nick: "alice"}))
+
+	manager.Savepoint()
+	assert.NilError(t, manager.SetComponentForEntity(playerComp, id, Player{Nick: "bob"}))
+	assert.DeepEqual(t, byName("bob"), []types.EntityID{id})
+	assert.NilError(t, manager.RollbackToSavepoint())
+
+	assert.Equal(t, len(byName("bob")), 0)
+	assert.DeepEqual(t, byName("alice"), []types.EntityID{id})
+}
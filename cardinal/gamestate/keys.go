@@ -6,9 +6,12 @@ import (
 	"pkg.world.dev/world-engine/cardinal/types"
 )
 
+// storageComponentKeyPrefix is the prefix shared by every key returned by storageComponentKey.
+const storageComponentKeyPrefix = "ECB:COMPONENT-VALUE:"
+
 // storageComponentKey is the key that maps an entity ID and a specific component ID to the value of that component.
 func storageComponentKey(typeID types.ComponentID, id types.EntityID) string {
-	return fmt.Sprintf("ECB:COMPONENT-VALUE:TYPE-ID-%d:ENTITY-ID-%d", typeID, id)
+	return fmt.Sprintf(storageComponentKeyPrefix+"TYPE-ID-%d:ENTITY-ID-%d", typeID, id)
 }
 
 // storageNextEntityIDKey is the key that stores the next available entity ID that can be assigned to a newly created
@@ -17,6 +20,22 @@ func storageNextEntityIDKey() string {
 	return "ECB:NEXT-ENTITY-ID"
 }
 
+// storageFreeEntityIDKey is the key that stores the handle at the given position of the queue of handles that replace
+// removed entities. Handles are reused in the order of their positions.
+func storageFreeEntityIDKey(position uint64) string {
+	return fmt.Sprintf("ECB:FREE-ENTITY-ID:POSITION-%d", position)
+}
+
+// storageFreeEntityIDsHeadKey is the key that stores the position of the next handle to reuse.
+func storageFreeEntityIDsHeadKey() string {
+	return "ECB:FREE-ENTITY-IDS:HEAD"
+}
+
+// storageFreeEntityIDsTailKey is the key that stores the position after the last handle to reuse.
+func storageFreeEntityIDsTailKey() string {
+	return "ECB:FREE-ENTITY-IDS:TAIL"
+}
+
 // storageArchetypeIDForEntityID is the key that maps a specific entity ID to its archetype ID.
 // Note, this key and storageActiveEntityIDKey represent the same information.
 // This maps entity.ID -> archetype.ID.
@@ -42,3 +61,30 @@ func storageArchIDsToCompTypesKey() string {
 func storageLastFinalizedTickKey() string {
 	return "ECB:LAST-FINALIZED-TICK"
 }
+
+// storageLegacyStateRootKey is the key that stored the bucket digests of the state root in older versions. The state
+// root tree is now kept in memory, so the key is only deleted.
+func storageLegacyStateRootKey() string {
+	return "ECB:STATE-ROOT-BUCKETS"
+}
+
+// storageComponentSchemaKey is the key that stores the schema the values of a component were last migrated to.
+func storageComponentSchemaKey(name string) string {
+	return "ECB:COMPONENT-SCHEMA:" + name
+}
+
+// storageIndexedFieldsKey is the key that stores the names of the indexes whose entries are stored.
+func storageIndexedFieldsKey() string {
+	return "ECB:INDEXED-FIELDS"
+}
+
+// storageIndexTypeKeyPrefix is the prefix shared by the keys of the entries of every index of a component.
+func storageIndexTypeKeyPrefix(typeID types.ComponentID) string {
+	return fmt.Sprintf("ECB:INDEX:TYPE-ID-%d:", typeID)
+}
+
+// storageIndexKeyPrefix is the prefix of the keys of the entries of an index. The key of an entry is the prefix
+// followed by the JSON encoded value, and maps to the entities whose field has the value.
+func storageIndexKeyPrefix(typeID types.ComponentID, field string) string {
+	return storageIndexTypeKeyPrefix(typeID) + "FIELD-" + field + ":"
+}
@@ -20,12 +20,18 @@ type Reader interface {
 	GetComponentTypesForArchID(archID types.ArchetypeID) ([]types.ComponentMetadata, error)
 	GetArchIDForComponents(components []types.ComponentMetadata) (types.ArchetypeID, error)
 
-	// One Archetype Many Entities
+	// One Archetype Many Entities, in ascending order
 	GetEntitiesForArchID(archID types.ArchetypeID) ([]types.EntityID, error)
 
 	// Misc
 	SearchFrom(filter filter.ComponentFilter, start int) *ArchetypeIterator
 	ArchetypeCount() int
+	// LookupIndex returns the entities whose component field matches the query, using an index declared on the
+	// field. ok is false if there is no index that can answer the query.
+	LookupIndex(cType types.ComponentMetadata, field string, query IndexQuery) (ids []types.EntityID, ok bool, err error)
+	// LookupSpatial returns the IDs of the entities whose component has a position that matches the query. The
+	// component must have a spatial index.
+	LookupSpatial(cType types.ComponentMetadata, query SpatialQuery) ([]types.EntityID, error)
 }
 
 type Writer interface {
@@ -44,17 +50,31 @@ type Writer interface {
 	// Misc
 	Close() error
 	RegisterComponents([]types.ComponentMetadata) error
+	RegisterSpatialIndex(cType types.ComponentMetadata, position PositionFn, cellSize float64) error
+}
+
+// SavepointStorage can roll back the pending changes made since a savepoint. See EntityCommandBuffer.Savepoint.
+type SavepointStorage interface {
+	Savepoint()
+	ReleaseSavepoint() error
+	RollbackToSavepoint() error
 }
 
 type TickStorage interface {
 	GetLastFinalizedTick() (tick uint64, err error)
 	FinalizeTick(ctx context.Context) error
+	// DiscardPending discards the state changes of the tick that were not finalized.
+	DiscardPending() error
+	LastTickDiff() *TickDiff
 }
 
 // Manager represents all the methods required to track Component, Entity, and Archetype information
 // which powers the ECS dbStorage layer.
 type Manager interface {
 	TickStorage
+	SavepointStorage
+	SnapshotStorage
+	ComponentMigrator
 	Reader
 	Writer
 	ToReadOnly() Reader
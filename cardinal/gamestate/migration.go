@@ -0,0 +1,99 @@
+package gamestate
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/rotisserie/eris"
+	"go.opentelemetry.io/otel/codes"
+
+	"pkg.world.dev/world-engine/cardinal/types"
+)
+
+// ComponentMigrator is implemented by managers that can rewrite every stored instance of a component.
+type ComponentMigrator interface {
+	MigrateComponent(ctx context.Context, cType types.ComponentMetadata, migrate func([]byte) ([]byte, error)) error
+}
+
+var _ ComponentMigrator = &EntityCommandBuffer{}
+
+// MigrateComponent replaces every stored value of the given component with the result of migrate, in a single
+// transaction. The schema of the component is stored in the same transaction, so a migration that was committed is
+// never applied twice, even if the process stops before the schema in the schema storage is updated.
+// It must be called before any pending state changes are made.
+func (m *EntityCommandBuffer) MigrateComponent(
+	ctx context.Context, cType types.ComponentMetadata, migrate func([]byte) ([]byte, error),
+) error {
+	ctx, span := m.tracer.Start(ctx, "ecb.component.migrate")
+	defer span.End()
+
+	if err := m.migrateComponent(ctx, cType, migrate); err != nil {
+		span.SetStatus(codes.Error, eris.ToString(err, true))
+		span.RecordError(err)
+		return err
+	}
+	return nil
+}
+
+func (m *EntityCommandBuffer) migrateComponent(
+	ctx context.Context, cType types.ComponentMetadata, migrate func([]byte) ([]byte, error),
+) error {
+	schemaKey := storageComponentSchemaKey(cType.Name())
+	migratedSchema, err := m.dbStorage.GetBytes(ctx, schemaKey)
+	if err != nil && !errors.Is(err, ErrKeyNotFound) {
+		return err
+	}
+	if err == nil && bytes.Equal(migratedSchema, cType.GetSchema()) {
+		// The stored values were already migrated to this schema.
+		return nil
+	}
+
+	tree, err := m.loadStateRootTree(ctx)
+	if err != nil {
+		return eris.Wrap(err, "failed to load state root")
+	}
+	tree = tree.clone()
+
+	keys, err := m.dbStorage.Keys(ctx)
+	if err != nil {
+		return eris.Wrap(err, "failed to list keys")
+	}
+	pipe, err := m.dbStorage.StartTransaction(ctx)
+	if err != nil {
+		return err
+	}
+	prefix := fmt.Sprintf(storageComponentKeyPrefix+"TYPE-ID-%d:", cType.ID())
+	for _, key := range keys {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		oldValue, err := m.dbStorage.GetBytes(ctx, key)
+		if err != nil {
+			return err
+		}
+		newValue, err := migrate(oldValue)
+		if err != nil {
+			return eris.Wrapf(err, "failed to migrate %q", key)
+		}
+		if err := pipe.Set(ctx, key, newValue); err != nil {
+			return eris.Wrap(err, "")
+		}
+		tree.add(key, newValue)
+	}
+	if err := pipe.Set(ctx, schemaKey, cType.GetSchema()); err != nil {
+		return eris.Wrap(err, "")
+	}
+	// The indexes of the component are rebuilt from the migrated values.
+	if err := m.dropIndexesInPipe(ctx, cType, keys, pipe); err != nil {
+		return err
+	}
+	if err := pipe.EndTransaction(ctx); err != nil {
+		return eris.Wrap(err, "failed to end transaction")
+	}
+	m.stateRoot = tree
+	m.resetIndexes(cType)
+	return nil
+}
@@ -28,3 +28,18 @@ type PrimitiveStorage[K comparable] interface {
 type Transaction[K comparable] interface {
 	PrimitiveStorage[K]
 }
+
+// PrefixScanner is implemented by storages that can read every key with a prefix, and its value, in batches rather
+// than with one read per key.
+type PrefixScanner interface {
+	// ScanPrefix calls fn with every key that starts with prefix, and its value. A key is passed at most once.
+	ScanPrefix(ctx context.Context, prefix string, fn func(key string, value []byte) error) error
+}
+
+// StatePinner is implemented by storages that can pin the state they hold, so that it can be read while new state is
+// written.
+type StatePinner interface {
+	// PinState returns a scanner of the state as it is when PinState is called. release must be called once the state
+	// was read.
+	PinState(ctx context.Context) (scanner PrefixScanner, release func(), err error)
+}
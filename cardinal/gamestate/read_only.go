@@ -22,6 +22,7 @@ type readOnlyManager struct {
 	storage         PrimitiveStorage[string]
 	typeToComponent VolatileStorage[types.ComponentID, types.ComponentMetadata]
 	archIDToComps   VolatileStorage[types.ArchetypeID, []types.ComponentMetadata]
+	spatialIndexes  map[types.ComponentID]*spatialIndex
 }
 
 func (m *EntityCommandBuffer) ToReadOnly() Reader {
@@ -29,6 +30,7 @@ func (m *EntityCommandBuffer) ToReadOnly() Reader {
 		storage:         m.dbStorage,
 		typeToComponent: m.typeToComponent,
 		archIDToComps:   m.archIDToComps,
+		spatialIndexes:  m.spatialIndexes,
 	}
 }
 
@@ -62,6 +64,24 @@ func (r *readOnlyManager) GetComponentForEntityInRawJSON(
 	ctx := context.Background()
 	key := storageComponentKey(cType.ID(), id)
 	res, err := r.storage.GetBytes(ctx, key)
+	if !errors.Is(err, ErrKeyNotFound) {
+		return res, eris.Wrap(err, "")
+	}
+	// A component that was added to the entity without a value is not stored. Its value is the default value.
+	comps, compsErr := r.GetComponentTypesForEntity(id)
+	if compsErr != nil || !filter.MatchComponentMetadata(comps, cType) {
+		return nil, eris.Wrap(err, "")
+	}
+	bz, err := cType.New()
+	if err != nil {
+		return nil, eris.Wrap(err, "")
+	}
+	// Round trip the default value so it is encoded the same way as a stored value.
+	value, err := cType.Decode(bz)
+	if err != nil {
+		return nil, eris.Wrap(err, "")
+	}
+	res, err = cType.Encode(value)
 	return res, eris.Wrap(err, "")
 }
 
@@ -85,6 +105,9 @@ func (r *readOnlyManager) GetComponentTypesForEntity(id types.EntityID) ([]types
 	archIDKey := storageArchetypeIDForEntityID(id)
 	num, err := r.storage.GetInt(ctx, archIDKey)
 	if err != nil {
+		if errors.Is(err, ErrKeyNotFound) {
+			return nil, eris.Wrap(ErrKeyNotFound, ErrEntityDoesNotExist.Error())
+		}
 		return nil, eris.Wrap(err, "")
 	}
 	archID := types.ArchetypeID(num)
@@ -137,15 +160,18 @@ func (r *readOnlyManager) GetEntitiesForArchID(archID types.ArchetypeID) ([]type
 	ctx := context.Background()
 	key := storageActiveEntityIDKey(archID)
 	bz, err := r.storage.GetBytes(ctx, key)
+	if errors.Is(err, ErrKeyNotFound) {
+		// The archetype was created by changes that were rolled back, so no entities were saved for it.
+		return nil, nil
+	}
 	if err != nil {
-		// No entities were found for this archetype EntityID
 		return nil, eris.Wrap(err, "")
 	}
 	ids, err := codec.Decode[[]types.EntityID](bz)
 	if err != nil {
 		return nil, err
 	}
-	return ids, nil
+	return newActiveEntities(ids).ids, nil
 }
 
 func (r *readOnlyManager) SearchFrom(filter filter.ComponentFilter, start int) *ArchetypeIterator {
@@ -172,3 +198,25 @@ func (r *readOnlyManager) ArchetypeCount() int {
 	}
 	return r.archIDToComps.Len()
 }
+
+// LookupIndex never uses an index. The indexes are maintained in memory by the EntityCommandBuffer, and a read-only
+// view of the committed state can't share them safely while a tick is being processed.
+func (r *readOnlyManager) LookupIndex(types.ComponentMetadata, string, IndexQuery) ([]types.EntityID, bool, error) {
+	return nil, false, nil
+}
+
+// LookupSpatial reads the position of every entity that has the component from storage, for the same reason that
+// LookupIndex never uses an index.
+func (r *readOnlyManager) LookupSpatial(cType types.ComponentMetadata, query SpatialQuery) ([]types.EntityID, error) {
+	s, ok := r.spatialIndexes[cType.ID()]
+	if !ok {
+		return nil, eris.Wrapf(ErrNoSpatialIndex, "component %q", cType.Name())
+	}
+	if err := r.refreshArchIDToCompTypes(); errors.Is(err, ErrNoArchIDMappingFound) {
+		// Nothing was stored yet.
+		return []types.EntityID{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return lookupSpatialInStorage(context.Background(), r.storage, r.archIDToComps, s, query)
+}
@@ -152,6 +152,23 @@ func TestReadOnly_CanFindEntityIDAfterChangingArchetypes(t *testing.T) {
 	assert.Equal(t, gotIDs[0], id)
 }
 
+func TestReadOnly_ComponentAddedWithoutValueHasDefaultValue(t *testing.T) {
+	manager := newCmdBufferForTest(t)
+	ctx := t.Context()
+	id, err := manager.CreateEntity(fooComp)
+	assert.NilError(t, err)
+	assert.NilError(t, manager.AddComponentToEntity(barComp, id))
+	assert.NilError(t, manager.FinalizeTick(ctx))
+
+	roManager := manager.ToReadOnly()
+	bz, err := roManager.GetComponentForEntityInRawJSON(barComp, id)
+	assert.NilError(t, err)
+	assert.Equal(t, string(bz), `{"Value":0}`)
+
+	_, err = roManager.GetComponentForEntityInRawJSON(barComp, id+1)
+	assert.Check(t, err != nil)
+}
+
 func TestReadOnly_ArchetypeCount(t *testing.T) {
 	manager := newCmdBufferForTest(t)
 	ctx := t.Context()
@@ -1,7 +1,11 @@
 package gamestate
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"slices"
+	"time"
 
 	"github.com/redis/go-redis/v9"
 	"github.com/rotisserie/eris"
@@ -13,11 +17,19 @@ import (
 	"pkg.world.dev/world-engine/cardinal/types"
 )
 
-var _ PrimitiveStorage[string] = &RedisStorage{}
+var (
+	_ PrimitiveStorage[string] = &RedisStorage{}
+	_ PrefixScanner            = &RedisStorage{}
+)
+
+// scanBatchSize is the number of keys requested from each SCAN, whose values are then read in a single pipeline.
+const scanBatchSize = 1000
 
 type RedisStorage struct {
 	currentClient redis.Cmdable
 	tracer        trace.Tracer
+	// observePipeline is called with the time taken to execute each transaction pipeline.
+	observePipeline func(time.Duration)
 }
 
 func NewRedisPrimitiveStorage(client redis.Cmdable) RedisStorage {
@@ -27,24 +39,30 @@ func NewRedisPrimitiveStorage(client redis.Cmdable) RedisStorage {
 	}
 }
 
+// ObservePipelines sets a function that is called with the time taken to execute each transaction pipeline, which is
+// used to export the latency of redis commits as a metric.
+func (r *RedisStorage) ObservePipelines(observe func(time.Duration)) {
+	r.observePipeline = observe
+}
+
 func (r *RedisStorage) GetFloat64(ctx context.Context, key string) (float64, error) {
 	res, err := r.currentClient.Get(ctx, key).Float64()
 	if err != nil {
-		return 0, eris.Wrap(err, "")
+		return 0, wrapRedisError(err)
 	}
 	return res, nil
 }
 func (r *RedisStorage) GetFloat32(ctx context.Context, key string) (float32, error) {
 	res, err := r.currentClient.Get(ctx, key).Float32()
 	if err != nil {
-		return 0, eris.Wrap(err, "")
+		return 0, wrapRedisError(err)
 	}
 	return res, nil
 }
 func (r *RedisStorage) GetUInt64(ctx context.Context, key string) (uint64, error) {
 	res, err := r.currentClient.Get(ctx, key).Uint64()
 	if err != nil {
-		return 0, eris.Wrap(err, "")
+		return 0, wrapRedisError(err)
 	}
 	return res, nil
 }
@@ -52,7 +70,7 @@ func (r *RedisStorage) GetUInt64(ctx context.Context, key string) (uint64, error
 func (r *RedisStorage) GetInt64(ctx context.Context, key string) (int64, error) {
 	res, err := r.currentClient.Get(ctx, key).Int64()
 	if err != nil {
-		return 0, eris.Wrap(err, "")
+		return 0, wrapRedisError(err)
 	}
 	return res, nil
 }
@@ -60,7 +78,7 @@ func (r *RedisStorage) GetInt64(ctx context.Context, key string) (int64, error)
 func (r *RedisStorage) GetInt(ctx context.Context, key string) (int, error) {
 	res, err := r.currentClient.Get(ctx, key).Int()
 	if err != nil {
-		return 0, eris.Wrap(err, "")
+		return 0, wrapRedisError(err)
 	}
 	return res, nil
 }
@@ -68,7 +86,7 @@ func (r *RedisStorage) GetInt(ctx context.Context, key string) (int, error) {
 func (r *RedisStorage) GetBool(ctx context.Context, key string) (bool, error) {
 	res, err := r.currentClient.Get(ctx, key).Bool()
 	if err != nil {
-		return false, eris.Wrap(err, "")
+		return false, wrapRedisError(err)
 	}
 	return res, nil
 }
@@ -76,13 +94,13 @@ func (r *RedisStorage) GetBool(ctx context.Context, key string) (bool, error) {
 func (r *RedisStorage) GetBytes(ctx context.Context, key string) ([]byte, error) {
 	bz, err := r.currentClient.Get(ctx, key).Bytes()
 	if err != nil {
-		return nil, eris.Wrap(err, "")
+		return nil, wrapRedisError(err)
 	}
 	return bz, nil
 }
 
 func (r *RedisStorage) Set(ctx context.Context, key string, value any) error {
-	return eris.Wrap(r.currentClient.Set(ctx, key, value, 0).Err(), "")
+	return wrapRedisError(r.currentClient.Set(ctx, key, value, 0).Err())
 }
 
 // Underlying type is a string. Unfortunately this is the way redis works and this is the most generic return value.
@@ -90,36 +108,95 @@ func (r *RedisStorage) Get(ctx context.Context, key string) (any, error) {
 	var res any
 	var err error
 	res, err = r.currentClient.Get(ctx, key).Result()
-	return res, eris.Wrap(err, "")
+	return res, wrapRedisError(err)
 }
 
 func (r *RedisStorage) Incr(ctx context.Context, key string) error {
-	return eris.Wrap(r.currentClient.Incr(ctx, key).Err(), "")
+	return wrapRedisError(r.currentClient.Incr(ctx, key).Err())
 }
 
 func (r *RedisStorage) Decr(ctx context.Context, key string) error {
-	return eris.Wrap(r.currentClient.Decr(ctx, key).Err(), "")
+	return wrapRedisError(r.currentClient.Decr(ctx, key).Err())
 }
 
 func (r *RedisStorage) Delete(ctx context.Context, key string) error {
-	return eris.Wrap(r.currentClient.Del(ctx, key).Err(), "")
+	return wrapRedisError(r.currentClient.Del(ctx, key).Err())
 }
 
 func (r *RedisStorage) Close(ctx context.Context) error {
-	return eris.Wrap(r.currentClient.Shutdown(ctx).Err(), "")
+	return wrapRedisError(r.currentClient.Shutdown(ctx).Err())
 }
 
 func (r *RedisStorage) Keys(ctx context.Context) ([]string, error) {
 	return r.currentClient.Keys(ctx, "*").Result()
 }
 
+// ScanPrefix iterates over the keys with SCAN, and reads the values of each batch of keys in a single pipeline. SCAN
+// may return a key more than once, so the keys that were already passed to fn are skipped.
+func (r *RedisStorage) ScanPrefix(ctx context.Context, prefix string, fn func(key string, value []byte) error) error {
+	seen := make(map[string]struct{})
+	var cursor uint64
+	for {
+		keys, next, err := r.currentClient.Scan(ctx, cursor, prefix+"*", scanBatchSize).Result()
+		if err != nil {
+			return wrapRedisError(err)
+		}
+		keys = slices.DeleteFunc(keys, func(key string) bool {
+			_, ok := seen[key]
+			return ok
+		})
+		if len(keys) > 0 {
+			cmds, err := r.currentClient.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+				for _, key := range keys {
+					pipe.Get(ctx, key)
+				}
+				return nil
+			})
+			if err != nil && !errors.Is(err, redis.Nil) {
+				return wrapRedisError(err)
+			}
+			for i, cmd := range cmds {
+				bz, err := cmd.(*redis.StringCmd).Bytes() //nolint:errcheck // only GET commands are in the pipeline
+				if errors.Is(err, redis.Nil) {
+					// The key was deleted after it was scanned.
+					continue
+				} else if err != nil {
+					return wrapRedisError(err)
+				}
+				seen[keys[i]] = struct{}{}
+				if err := fn(keys[i], bz); err != nil {
+					return err
+				}
+			}
+		}
+		if next == 0 {
+			return nil
+		}
+		cursor = next
+	}
+}
+
 func (r *RedisStorage) Clear(ctx context.Context) error {
-	return eris.Wrap(r.currentClient.FlushAll(ctx).Err(), "")
+	return wrapRedisError(r.currentClient.FlushAll(ctx).Err())
+}
+
+// wrapRedisError converts redis specific errors into the general storage errors so that callers do not need to know
+// which PrimitiveStorage they are using.
+func wrapRedisError(err error) error {
+	switch {
+	case errors.Is(err, redis.Nil):
+		return eris.Wrap(ErrKeyNotFound, err.Error())
+	case errors.Is(err, redis.ErrClosed):
+		return eris.Wrap(ErrStorageClosed, err.Error())
+	default:
+		return eris.Wrap(err, "")
+	}
 }
 
 func (r *RedisStorage) StartTransaction(_ context.Context) (Transaction[string], error) {
 	pipeline := r.currentClient.TxPipeline()
 	redisTransaction := NewRedisPrimitiveStorage(pipeline)
+	redisTransaction.observePipeline = r.observePipeline
 	return &redisTransaction, nil
 }
 
@@ -135,7 +212,11 @@ func (r *RedisStorage) EndTransaction(ctx context.Context) error {
 		return err
 	}
 
+	start := time.Now()
 	_, err := pipeline.Exec(ctx)
+	if r.observePipeline != nil {
+		r.observePipeline(time.Since(start))
+	}
 	if err != nil {
 		span.SetStatus(codes.Error, eris.ToString(err, true))
 		span.RecordError(err)
@@ -170,6 +251,7 @@ func (m *EntityCommandBuffer) makePipeOfRedisCommands(ctx context.Context) (Prim
 		method func(ctx context.Context, pipe PrimitiveStorage[string]) error
 	}{
 		{"component_changes", m.addComponentChangesToPipe},
+		{"index_changes", m.addIndexChangesToPipe},
 		{"next_entity_id", m.addNextEntityIDToPipe},
 		{"pending_arch_ids", m.addPendingArchIDsToPipe},
 		{"entity_id_to_arch_id", m.addEntityIDToArchIDToPipe},
@@ -227,19 +309,65 @@ func (m *EntityCommandBuffer) addEntityIDToArchIDToPipe(ctx context.Context, pip
 	return nil
 }
 
-// addNextEntityIDToPipe adds any changes to the next available entity ArchetypeID to the given redis pipe.
+// addNextEntityIDToPipe adds any changes to the next available entity index and the free entity handles to the given
+// redis pipe.
 func (m *EntityCommandBuffer) addNextEntityIDToPipe(ctx context.Context, pipe PrimitiveStorage[string]) error {
-	// There are no pending entity id creations, so there's nothing to commit
-	if m.pendingEntityIDs == 0 {
+	if m.pendingEntityIDs > 0 {
+		key := storageNextEntityIDKey()
+		nextID := m.nextEntityIDSaved + m.pendingEntityIDs
+		if err := pipe.Set(ctx, key, nextID); err != nil {
+			return eris.Wrap(err, "")
+		}
+	}
+
+	// There are no reused or removed entities, so the free list is unchanged
+	if m.freeEntityIDsUsed == 0 && len(m.pendingFreeEntityIDs) == 0 {
 		return nil
 	}
-	key := storageNextEntityIDKey()
-	nextID := m.nextEntityIDSaved + m.pendingEntityIDs
-	return eris.Wrap(pipe.Set(ctx, key, nextID), "")
+	if err := m.loadEntityIDs(); err != nil {
+		return err
+	}
+
+	// Only the reused handles are removed from the front of the queue, and the new ones added at its end.
+	if m.freeEntityIDsUsed > 0 {
+		head := m.freeEntityIDsHead
+		for i := uint64(0); i < m.freeEntityIDsUsed; i++ {
+			if err := pipe.Delete(ctx, storageFreeEntityIDKey(head+i)); err != nil {
+				return eris.Wrap(err, "")
+			}
+		}
+		if err := pipe.Set(ctx, storageFreeEntityIDsHeadKey(), head+m.freeEntityIDsUsed); err != nil {
+			return eris.Wrap(err, "")
+		}
+	}
+	if len(m.pendingFreeEntityIDs) > 0 {
+		tail := m.freeEntityIDsTail
+		for i, id := range m.pendingFreeEntityIDs {
+			if err := pipe.Set(ctx, storageFreeEntityIDKey(tail+uint64(i)), uint64(id)); err != nil {
+				return eris.Wrap(err, "")
+			}
+		}
+		if err := pipe.Set(ctx, storageFreeEntityIDsTailKey(), tail+uint64(len(m.pendingFreeEntityIDs))); err != nil {
+			return eris.Wrap(err, "")
+		}
+	}
+	return nil
 }
 
-// addComponentChangesToPipe adds updated component values for entities to the redis pipe.
+// addComponentChangesToPipe adds updated component values for entities to the redis pipe. Components whose encoded
+// value did not change are not written. The changes are recorded in the pending TickDiff, and the pending state root
+// is updated to match.
 func (m *EntityCommandBuffer) addComponentChangesToPipe(ctx context.Context, pipe PrimitiveStorage[string]) error {
+	tree, err := m.loadStateRootTree(ctx)
+	if err != nil {
+		return eris.Wrap(err, "failed to load state root")
+	}
+	tree = tree.clone()
+	diff := &TickDiff{}
+	if err := m.addEntityChangesToDiff(diff); err != nil {
+		return err
+	}
+
 	keysToDelete, err := m.compValuesToDelete.Keys()
 	if err != nil {
 		return err
@@ -252,10 +380,32 @@ func (m *EntityCommandBuffer) addComponentChangesToPipe(ctx context.Context, pip
 		if !isMarkedForDeletion {
 			continue
 		}
+		// The component was removed and then added back to the entity. The new value is handled below.
+		if _, err := m.compValues.Get(key); err == nil {
+			continue
+		}
+		oldValue, stored, err := m.getCommittedValue(ctx, key)
+		if err != nil {
+			return err
+		}
+		if !stored {
+			continue
+		}
+		cType, err := m.typeToComponent.Get(key.typeID)
+		if err != nil {
+			return err
+		}
 		redisKey := storageComponentKey(key.typeID, key.entityID)
 		if err := pipe.Delete(ctx, redisKey); err != nil {
 			return eris.Wrap(err, "")
 		}
+		tree.remove(redisKey)
+		diff.Components = append(diff.Components, ComponentChange{
+			EntityID:  key.entityID,
+			Component: cType.Name(),
+			OldValue:  oldValue,
+			NewValue:  nil,
+		})
 	}
 	if err = m.compValuesToDelete.Clear(); err != nil {
 		return eris.Wrap(err, "failed to clear to-be-deleted component values store")
@@ -277,12 +427,36 @@ func (m *EntityCommandBuffer) addComponentChangesToPipe(ctx context.Context, pip
 		if err != nil {
 			return err
 		}
+		oldValue, stored, err := m.getCommittedValue(ctx, key)
+		if err != nil {
+			return err
+		}
+		if stored && bytes.Equal(oldValue, bz) {
+			continue
+		}
 
 		redisKey := storageComponentKey(key.typeID, key.entityID)
 		if err = pipe.Set(ctx, redisKey, bz); err != nil {
 			return eris.Wrap(err, "")
 		}
+		tree.add(redisKey, bz)
+		diff.Components = append(diff.Components, ComponentChange{
+			EntityID:  key.entityID,
+			Component: cType.Name(),
+			OldValue:  oldValue,
+			NewValue:  bz,
+		})
+	}
+	sortComponentChanges(diff.Components)
+
+	if m.stateRootRebuilt {
+		if err := pipe.Delete(ctx, storageLegacyStateRootKey()); err != nil {
+			return eris.Wrap(err, "")
+		}
 	}
+	diff.StateRoot = tree.root()
+	m.pendingStateRoot = tree
+	m.pendingDiff = diff
 	return nil
 }
 
@@ -372,8 +546,7 @@ func getArchIDToCompTypesFromRedis(
 	ctx := context.Background()
 	key := storageArchIDsToCompTypesKey()
 	bz, err := storage.GetBytes(ctx, key)
-	err = eris.Wrap(err, "")
-	if eris.Is(eris.Cause(err), redis.Nil) {
+	if errors.Is(err, ErrKeyNotFound) {
 		return nil, false, nil
 	} else if err != nil {
 		return nil, false, err
@@ -0,0 +1,194 @@
+package gamestate
+
+import (
+	"context"
+	"strings"
+
+	"github.com/rotisserie/eris"
+	"go.opentelemetry.io/otel/codes"
+
+	"pkg.world.dev/world-engine/cardinal/types"
+)
+
+// storageKeyPrefix is the prefix shared by every key the EntityCommandBuffer writes to dbStorage. See keys.go.
+const storageKeyPrefix = "ECB:"
+
+// SnapshotStorage is implemented by managers that can export and import all of their committed state. A snapshot only
+// contains finalized state, so it should be taken right after FinalizeTick.
+type SnapshotStorage interface {
+	Snapshot(ctx context.Context) (map[string][]byte, error)
+	PinSnapshot(ctx context.Context) (func(context.Context) (map[string][]byte, error), error)
+	Restore(ctx context.Context, state map[string][]byte) error
+}
+
+var _ SnapshotStorage = &EntityCommandBuffer{}
+
+// Snapshot returns every key and value the EntityCommandBuffer has committed to dbStorage. This includes entities,
+// archetypes, component values and the last finalized tick. Pending state changes are not included. If dbStorage is a
+// PrefixScanner, the values are read in batches rather than one at a time.
+func (m *EntityCommandBuffer) Snapshot(ctx context.Context) (map[string][]byte, error) {
+	ctx, span := m.tracer.Start(ctx, "ecb.snapshot")
+	defer span.End()
+
+	state, err := m.snapshot(ctx)
+	if err != nil {
+		span.SetStatus(codes.Error, eris.ToString(err, true))
+		span.RecordError(err)
+		return nil, err
+	}
+	return state, nil
+}
+
+// PinSnapshot pins the state the EntityCommandBuffer has committed to dbStorage, and returns a function that reads it,
+// so that the snapshot can be read while the following ticks are committed. If dbStorage is not a StatePinner, the
+// state is read before PinSnapshot returns. The returned function must be called exactly once.
+func (m *EntityCommandBuffer) PinSnapshot(
+	ctx context.Context,
+) (func(context.Context) (map[string][]byte, error), error) {
+	pinner, ok := m.dbStorage.(StatePinner)
+	if !ok {
+		state, err := m.Snapshot(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return func(context.Context) (map[string][]byte, error) {
+			return state, nil
+		}, nil
+	}
+
+	scanner, release, err := pinner.PinState(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return func(ctx context.Context) (map[string][]byte, error) {
+		defer release()
+		ctx, span := m.tracer.Start(ctx, "ecb.snapshot.pinned")
+		defer span.End()
+
+		state, err := scanSnapshot(ctx, scanner)
+		if err != nil {
+			span.SetStatus(codes.Error, eris.ToString(err, true))
+			span.RecordError(err)
+			return nil, err
+		}
+		return state, nil
+	}, nil
+}
+
+func (m *EntityCommandBuffer) snapshot(ctx context.Context) (map[string][]byte, error) {
+	if scanner, ok := m.dbStorage.(PrefixScanner); ok {
+		return scanSnapshot(ctx, scanner)
+	}
+
+	state := make(map[string][]byte)
+	keys, err := m.dbStorage.Keys(ctx)
+	if err != nil {
+		return nil, eris.Wrap(err, "failed to list keys")
+	}
+	for _, key := range keys {
+		if !strings.HasPrefix(key, storageKeyPrefix) {
+			continue
+		}
+		bz, err := m.dbStorage.GetBytes(ctx, key)
+		if err != nil {
+			return nil, eris.Wrapf(err, "failed to get value for key %q", key)
+		}
+		state[key] = bz
+	}
+	return state, nil
+}
+
+// scanSnapshot reads every key the EntityCommandBuffer writes, and its value, from the scanner.
+func scanSnapshot(ctx context.Context, scanner PrefixScanner) (map[string][]byte, error) {
+	state := make(map[string][]byte)
+	err := scanner.ScanPrefix(ctx, storageKeyPrefix, func(key string, value []byte) error {
+		state[key] = value
+		return nil
+	})
+	if err != nil {
+		return nil, eris.Wrap(err, "failed to scan keys")
+	}
+	return state, nil
+}
+
+// Restore replaces all state committed by the EntityCommandBuffer with the given snapshot in a single transaction,
+// and discards everything cached in memory. Keys in the snapshot that do not belong to the EntityCommandBuffer are
+// rejected.
+func (m *EntityCommandBuffer) Restore(ctx context.Context, state map[string][]byte) error {
+	ctx, span := m.tracer.Start(ctx, "ecb.restore")
+	defer span.End()
+
+	if err := m.restore(ctx, state); err != nil {
+		span.SetStatus(codes.Error, eris.ToString(err, true))
+		span.RecordError(err)
+		return err
+	}
+	return nil
+}
+
+func (m *EntityCommandBuffer) restore(ctx context.Context, state map[string][]byte) error {
+	for key := range state {
+		if !strings.HasPrefix(key, storageKeyPrefix) {
+			return eris.Errorf("snapshot contains unknown key %q", key)
+		}
+	}
+
+	keys, err := m.dbStorage.Keys(ctx)
+	if err != nil {
+		return eris.Wrap(err, "failed to list keys")
+	}
+
+	pipe, err := m.dbStorage.StartTransaction(ctx)
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if _, ok := state[key]; ok || !strings.HasPrefix(key, storageKeyPrefix) {
+			continue
+		}
+		if err := pipe.Delete(ctx, key); err != nil {
+			return eris.Wrap(err, "")
+		}
+	}
+	for key, value := range state {
+		if err := pipe.Set(ctx, key, value); err != nil {
+			return eris.Wrap(err, "")
+		}
+	}
+	if err := pipe.EndTransaction(ctx); err != nil {
+		return eris.Wrap(err, "failed to end transaction")
+	}
+
+	return m.resetCache()
+}
+
+// resetCache drops every value the EntityCommandBuffer has cached from dbStorage, as well as all pending state
+// changes, so that subsequent reads go to dbStorage.
+func (m *EntityCommandBuffer) resetCache() error {
+	m.journal.reset()
+	m.newJournaledStorages()
+	m.compOriginals = NewMapStorage[compKey, committedValue]()
+	m.archIDToComps = NewMapStorage[types.ArchetypeID, []types.ComponentMetadata]()
+	m.pendingArchIDs = nil
+	m.nextEntityIDSaved = 0
+	m.pendingEntityIDs = 0
+	m.isEntityIDLoaded = false
+	m.freeEntityIDsHead = 0
+	m.freeEntityIDsTail = 0
+	m.freeEntityIDsUsed = 0
+	m.pendingFreeEntityIDs = nil
+	m.stateRoot = nil
+	m.stateRootRebuilt = false
+	m.pendingStateRoot = nil
+	m.pendingDiff = nil
+	m.pendingIndexedFields = nil
+	if err := m.initIndexes(); err != nil {
+		return err
+	}
+
+	// The archetypes can only be loaded once the components are known.
+	if m.typeToComponent == nil {
+		return nil
+	}
+	return m.loadArchIDs()
+}
@@ -0,0 +1,152 @@
+package gamestate_test
+
+import (
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/rotisserie/eris"
+
+	"pkg.world.dev/world-engine/assert"
+	"pkg.world.dev/world-engine/cardinal/gamestate"
+)
+
+func TestSnapshotCanBeRestored(t *testing.T) {
+	ctx := t.Context()
+	manager := newCmdBufferForTest(t)
+
+	id, err := manager.CreateEntity(fooComp)
+	assert.NilError(t, err)
+	assert.NilError(t, manager.SetComponentForEntity(fooComp, id, Foo{Value: 1}))
+	assert.NilError(t, manager.FinalizeTick(ctx))
+
+	snapshot, err := manager.Snapshot(ctx)
+	assert.NilError(t, err)
+
+	// Make changes after the snapshot was taken, including a new entity.
+	assert.NilError(t, manager.SetComponentForEntity(fooComp, id, Foo{Value: 2}))
+	newID, err := manager.CreateEntity(barComp)
+	assert.NilError(t, err)
+	assert.NilError(t, manager.FinalizeTick(ctx))
+
+	assert.NilError(t, manager.Restore(ctx, snapshot))
+
+	tick, err := manager.GetLastFinalizedTick()
+	assert.NilError(t, err)
+	assert.Equal(t, uint64(1), tick)
+
+	val, err := manager.GetComponentForEntity(fooComp, id)
+	assert.NilError(t, err)
+	assert.Equal(t, 1, val.(Foo).Value)
+
+	_, err = manager.GetComponentForEntity(barComp, newID)
+	assert.Check(t, eris.Is(err, gamestate.ErrEntityDoesNotExist))
+
+	// Entity IDs are handed out again from where the snapshot left off.
+	nextID, err := manager.CreateEntity(barComp)
+	assert.NilError(t, err)
+	assert.Equal(t, newID, nextID)
+}
+
+func TestRollbackToSavepointAfterRestore(t *testing.T) {
+	ctx := t.Context()
+	manager := newCmdBufferForTest(t)
+
+	id, err := manager.CreateEntity(fooComp)
+	assert.NilError(t, err)
+	assert.NilError(t, manager.SetComponentForEntity(fooComp, id, Foo{Value: 1}))
+	assert.NilError(t, manager.FinalizeTick(ctx))
+	snapshot, err := manager.Snapshot(ctx)
+	assert.NilError(t, err)
+	assert.NilError(t, manager.Restore(ctx, snapshot))
+
+	// The changes made after the restore can still be rolled back.
+	manager.Savepoint()
+	assert.NilError(t, manager.SetComponentForEntity(fooComp, id, Foo{Value: 2}))
+	assert.NilError(t, manager.AddComponentToEntity(barComp, id))
+	created, err := manager.CreateEntity(barComp)
+	assert.NilError(t, err)
+	assert.NilError(t, manager.RollbackToSavepoint())
+
+	val, err := manager.GetComponentForEntity(fooComp, id)
+	assert.NilError(t, err)
+	assert.Equal(t, 1, val.(Foo).Value)
+	_, err = manager.GetComponentForEntity(barComp, id)
+	assert.IsError(t, err)
+	_, err = manager.GetComponentForEntity(barComp, created)
+	assert.Check(t, eris.Is(err, gamestate.ErrEntityDoesNotExist))
+}
+
+func TestRestoreRejectsUnknownKeys(t *testing.T) {
+	manager := newCmdBufferForTest(t)
+	err := manager.Restore(t.Context(), map[string][]byte{"USED_NONCES_foo": nil})
+	assert.IsError(t, err)
+}
+
+func TestScanPrefixReadsEveryKeyWithThePrefix(t *testing.T) {
+	ctx := t.Context()
+	redisStorage := gamestate.NewRedisPrimitiveStorage(
+		redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()}))
+	badgerStorage, err := gamestate.NewBadgerPrimitiveStorage(newBadgerDBForTest(t))
+	assert.NilError(t, err)
+	storages := map[string]interface {
+		gamestate.PrimitiveStorage[string]
+		gamestate.PrefixScanner
+	}{
+		"redis":  &redisStorage,
+		"badger": &badgerStorage,
+	}
+	for name, storage := range storages {
+		t.Run(name, func(t *testing.T) {
+			// More keys than a single SCAN returns.
+			want := make(map[string][]byte)
+			for i := range 2500 {
+				key := fmt.Sprintf("ECB:key-%d", i)
+				want[key] = []byte(strconv.Itoa(i))
+				assert.NilError(t, storage.Set(ctx, key, want[key]))
+			}
+			assert.NilError(t, storage.Set(ctx, "OTHER:key", []byte("other")))
+
+			got := make(map[string][]byte)
+			assert.NilError(t, storage.ScanPrefix(ctx, "ECB:", func(key string, value []byte) error {
+				_, seen := got[key]
+				assert.Check(t, !seen, "key %q was passed twice", key)
+				got[key] = value
+				return nil
+			}))
+			assert.DeepEqual(t, got, want)
+		})
+	}
+}
+
+func TestPinnedSnapshotIsNotChangedByLaterTicks(t *testing.T) {
+	ctx := t.Context()
+	manager := newCmdBufferForBadgerDB(t, newBadgerDBForTest(t))
+
+	id, err := manager.CreateEntity(fooComp)
+	assert.NilError(t, err)
+	assert.NilError(t, manager.SetComponentForEntity(fooComp, id, Foo{Value: 1}))
+	assert.NilError(t, manager.FinalizeTick(ctx))
+
+	readSnapshot, err := manager.PinSnapshot(ctx)
+	assert.NilError(t, err)
+	want, err := manager.Snapshot(ctx)
+	assert.NilError(t, err)
+
+	// The ticks committed after the state was pinned are not part of the snapshot.
+	assert.NilError(t, manager.SetComponentForEntity(fooComp, id, Foo{Value: 2}))
+	_, err = manager.CreateEntity(barComp)
+	assert.NilError(t, err)
+	assert.NilError(t, manager.FinalizeTick(ctx))
+
+	snapshot, err := readSnapshot(ctx)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, snapshot, want)
+
+	assert.NilError(t, manager.Restore(ctx, snapshot))
+	val, err := manager.GetComponentForEntity(fooComp, id)
+	assert.NilError(t, err)
+	assert.Equal(t, 1, val.(Foo).Value)
+}
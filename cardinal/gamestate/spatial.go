@@ -0,0 +1,438 @@
+package gamestate
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"math"
+	"slices"
+
+	"github.com/rotisserie/eris"
+
+	"pkg.world.dev/world-engine/cardinal/types"
+)
+
+// DefaultSpatialCellSize is the width and height of the cells of a spatial index, unless another size is given when
+// the index is registered.
+const DefaultSpatialCellSize = 32.0
+
+var ErrNoSpatialIndex = errors.New("component does not have a spatial index")
+
+// PositionFn returns the position of an entity from the value of one of its components.
+type PositionFn func(component any) (x, y float64, err error)
+
+type SpatialQueryKind int
+
+const (
+	// SpatialBox selects the entities between MinX, MinY and MaxX, MaxY, inclusive.
+	SpatialBox SpatialQueryKind = iota
+	// SpatialRadius selects the entities that are at most Radius away from X, Y.
+	SpatialRadius
+	// SpatialNearest selects the K entities that are the nearest to X, Y.
+	SpatialNearest
+)
+
+// SpatialQuery selects entities by the position of one of their components. Entities whose position is not finite
+// are never selected.
+type SpatialQuery struct {
+	Kind                   SpatialQueryKind
+	MinX, MinY, MaxX, MaxY float64
+	X, Y                   float64
+	Radius                 float64
+	K                      int
+}
+
+func (q SpatialQuery) validate() error {
+	coordinates := []float64{q.MinX, q.MinY, q.MaxX, q.MaxY, q.X, q.Y, q.Radius}
+	if slices.ContainsFunc(coordinates, func(v float64) bool { return !isFinite(v) }) {
+		return eris.New("spatial query coordinates must be finite")
+	}
+	if q.Radius < 0 {
+		return eris.New("spatial query radius must not be negative")
+	}
+	if q.K < 0 {
+		return eris.New("number of nearest entities must not be negative")
+	}
+	return nil
+}
+
+// bounds returns the box that contains every position selected by a SpatialBox or SpatialRadius query.
+func (q SpatialQuery) bounds() (minX, minY, maxX, maxY float64) {
+	if q.Kind == SpatialRadius {
+		return q.X - q.Radius, q.Y - q.Radius, q.X + q.Radius, q.Y + q.Radius
+	}
+	return q.MinX, q.MinY, q.MaxX, q.MaxY
+}
+
+func (q SpatialQuery) contains(p spatialPoint) bool {
+	if q.Kind == SpatialRadius {
+		return q.distance(p) <= q.Radius*q.Radius
+	}
+	return p.x >= q.MinX && p.x <= q.MaxX && p.y >= q.MinY && p.y <= q.MaxY
+}
+
+// distance returns the squared distance from the center of the query to the position.
+func (q SpatialQuery) distance(p spatialPoint) float64 {
+	dx, dy := p.x-q.X, p.y-q.Y
+	return dx*dx + dy*dy
+}
+
+// selectEntries returns the IDs of the entities that match the query. The entities of a SpatialNearest query are
+// ordered by distance, and then by ID. Otherwise, they are in ascending order.
+func (q SpatialQuery) selectEntries(entries []spatialEntry) []types.EntityID {
+	ids := make([]types.EntityID, 0)
+	if q.Kind == SpatialNearest {
+		slices.SortFunc(entries, func(a, b spatialEntry) int {
+			return cmp.Or(cmp.Compare(q.distance(a.point), q.distance(b.point)), cmp.Compare(a.id, b.id))
+		})
+		for _, entry := range entries[:min(q.K, len(entries))] {
+			ids = append(ids, entry.id)
+		}
+		return ids
+	}
+	for _, entry := range entries {
+		if q.contains(entry.point) {
+			ids = append(ids, entry.id)
+		}
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+type spatialPoint struct {
+	x, y float64
+}
+
+type spatialEntry struct {
+	id    types.EntityID
+	point spatialPoint
+}
+
+type spatialCell struct {
+	x, y int64
+}
+
+// maxCellCoordinate bounds the coordinates of the cells, so positions far from the origin don't overflow.
+const maxCellCoordinate = 1 << 60
+
+// spatialIndex is a uniform grid of the positions of the entities that have a component. The committed positions
+// are built lazily from the values in dbStorage, and are only kept in memory. Changes made in the pending tick are
+// kept separately until the tick is finalized.
+type spatialIndex struct {
+	cType    types.ComponentMetadata
+	position PositionFn
+	cellSize float64
+
+	loaded bool
+	points map[types.EntityID]spatialPoint
+	cells  map[spatialCell]map[types.EntityID]struct{}
+
+	// pending holds the position of every entity whose component changed in the pending tick, or nil if the entity
+	// does not have a position anymore.
+	pending map[types.EntityID]*spatialPoint
+}
+
+func (s *spatialIndex) reset() {
+	s.loaded = false
+	s.points = make(map[types.EntityID]spatialPoint)
+	s.cells = make(map[spatialCell]map[types.EntityID]struct{})
+	clear(s.pending)
+}
+
+// pointOf returns the position of the component value. ok is false if the position is not finite.
+func (s *spatialIndex) pointOf(value any) (point spatialPoint, ok bool, err error) {
+	x, y, err := s.position(value)
+	if err != nil {
+		return spatialPoint{}, false, eris.Wrapf(err, "failed to get the position of component %q", s.cType.Name())
+	}
+	return spatialPoint{x: x, y: y}, isFinite(x) && isFinite(y), nil
+}
+
+func (s *spatialIndex) setPending(value any, ids ...types.EntityID) error {
+	point, ok, err := s.pointOf(value)
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if ok {
+			s.pending[id] = &point
+		} else {
+			s.pending[id] = nil
+		}
+	}
+	return nil
+}
+
+func (s *spatialIndex) cellOf(p spatialPoint) spatialCell {
+	return spatialCell{x: cellCoordinate(p.x / s.cellSize), y: cellCoordinate(p.y / s.cellSize)}
+}
+
+func cellCoordinate(v float64) int64 {
+	return int64(math.Max(-maxCellCoordinate, math.Min(maxCellCoordinate, math.Floor(v))))
+}
+
+// addCommitted adds the entity to the committed positions. The entity must not be in the index.
+func (s *spatialIndex) addCommitted(id types.EntityID, p spatialPoint) {
+	cell := s.cellOf(p)
+	ids, ok := s.cells[cell]
+	if !ok {
+		ids = make(map[types.EntityID]struct{})
+		s.cells[cell] = ids
+	}
+	ids[id] = struct{}{}
+	s.points[id] = p
+}
+
+// removeCommitted removes the entity from the committed positions, if it is in the index.
+func (s *spatialIndex) removeCommitted(id types.EntityID) {
+	p, ok := s.points[id]
+	if !ok {
+		return
+	}
+	delete(s.points, id)
+	cell := s.cellOf(p)
+	delete(s.cells[cell], id)
+	if len(s.cells[cell]) == 0 {
+		delete(s.cells, cell)
+	}
+}
+
+func (s *spatialIndex) commit() {
+	if s.loaded {
+		for id, p := range s.pending {
+			s.removeCommitted(id)
+			if p != nil {
+				s.addCommitted(id, *p)
+			}
+		}
+	}
+	clear(s.pending)
+}
+
+// lookup returns the IDs of the entities that match the query, including the changes of the pending tick.
+func (s *spatialIndex) lookup(q SpatialQuery) []types.EntityID {
+	var entries []spatialEntry
+	for id, p := range s.pending {
+		if p != nil {
+			entries = append(entries, spatialEntry{id: id, point: *p})
+		}
+	}
+	if q.Kind == SpatialNearest {
+		entries = s.appendNearest(entries, q)
+	} else {
+		entries = s.appendInBox(entries, q)
+	}
+	return q.selectEntries(entries)
+}
+
+// appendCell appends the committed positions in the cell that were not changed in the pending tick.
+func (s *spatialIndex) appendCell(entries []spatialEntry, cell spatialCell) []spatialEntry {
+	for id := range s.cells[cell] {
+		if _, changed := s.pending[id]; !changed {
+			entries = append(entries, spatialEntry{id: id, point: s.points[id]})
+		}
+	}
+	return entries
+}
+
+// appendInBox appends the committed positions in the cells that overlap the bounds of the query.
+func (s *spatialIndex) appendInBox(entries []spatialEntry, q SpatialQuery) []spatialEntry {
+	minX, minY, maxX, maxY := q.bounds()
+	low, high := s.cellOf(spatialPoint{x: minX, y: minY}), s.cellOf(spatialPoint{x: maxX, y: maxY})
+	if high.x < low.x || high.y < low.y {
+		return entries
+	}
+	width, height := uint64(high.x-low.x)+1, uint64(high.y-low.y)+1
+	occupied := uint64(len(s.cells))
+	if width > occupied || height > occupied || width*height > occupied {
+		// The box covers more cells than there are occupied cells.
+		for cell := range s.cells {
+			if cell.x >= low.x && cell.x <= high.x && cell.y >= low.y && cell.y <= high.y {
+				entries = s.appendCell(entries, cell)
+			}
+		}
+		return entries
+	}
+	for x := low.x; x <= high.x; x++ {
+		for y := low.y; y <= high.y; y++ {
+			entries = s.appendCell(entries, spatialCell{x: x, y: y})
+		}
+	}
+	return entries
+}
+
+// appendNearest appends the committed positions in rings of cells around the center of the query, until the rings
+// can't contain a position that is nearer than the K nearest positions found so far.
+func (s *spatialIndex) appendNearest(entries []spatialEntry, q SpatialQuery) []spatialEntry {
+	if q.K == 0 {
+		return entries
+	}
+	center := s.cellOf(spatialPoint{x: q.X, y: q.Y})
+	pendingCount := len(entries)
+	visited := 0
+	for ring := int64(0); visited < len(s.points); ring++ {
+		if 8*ring > int64(len(s.cells)) {
+			// The ring has more cells than there are occupied cells.
+			entries = entries[:pendingCount]
+			for cell := range s.cells {
+				entries = s.appendCell(entries, cell)
+			}
+			return entries
+		}
+		for _, cell := range ringCells(center, ring) {
+			visited += len(s.cells[cell])
+			entries = s.appendCell(entries, cell)
+		}
+		// Every position that is not visited yet is at least ring*cellSize away from the center.
+		if len(entries) >= q.K {
+			distances := make([]float64, len(entries))
+			for i, entry := range entries {
+				distances[i] = q.distance(entry.point)
+			}
+			slices.Sort(distances)
+			bound := float64(ring) * s.cellSize
+			if distances[q.K-1] < bound*bound {
+				return entries
+			}
+		}
+	}
+	return entries
+}
+
+// ringCells returns the cells whose distance to the center cell is ring cells, horizontally or vertically.
+func ringCells(center spatialCell, ring int64) []spatialCell {
+	if ring == 0 {
+		return []spatialCell{center}
+	}
+	cells := make([]spatialCell, 0, 8*ring)
+	for d := -ring; d <= ring; d++ {
+		cells = append(cells,
+			spatialCell{x: center.x + d, y: center.y - ring},
+			spatialCell{x: center.x + d, y: center.y + ring})
+	}
+	for d := -ring + 1; d < ring; d++ {
+		cells = append(cells,
+			spatialCell{x: center.x - ring, y: center.y + d},
+			spatialCell{x: center.x + ring, y: center.y + d})
+	}
+	return cells
+}
+
+func isFinite(v float64) bool {
+	return !math.IsNaN(v) && !math.IsInf(v, 0)
+}
+
+// RegisterSpatialIndex maintains an index of the positions of the entities that have the component, which is used by
+// LookupSpatial. position is called with the values of the component.
+func (m *EntityCommandBuffer) RegisterSpatialIndex(
+	cType types.ComponentMetadata, position PositionFn, cellSize float64,
+) error {
+	if !isFinite(cellSize) || cellSize <= 0 {
+		return eris.Errorf("cell size of spatial index must be positive, got %v", cellSize)
+	}
+	if _, ok := m.spatialIndexes[cType.ID()]; ok {
+		return eris.Errorf("component %q already has a spatial index", cType.Name())
+	}
+	s := &spatialIndex{
+		cType:    cType,
+		position: position,
+		cellSize: cellSize,
+		pending:  make(map[types.EntityID]*spatialPoint),
+	}
+	s.reset()
+	m.spatialIndexes[cType.ID()] = s
+	return nil
+}
+
+// LookupSpatial returns the IDs of the entities whose component has a position that matches the query, including the
+// changes of the pending tick.
+func (m *EntityCommandBuffer) LookupSpatial(cType types.ComponentMetadata, query SpatialQuery) (
+	[]types.EntityID, error,
+) {
+	s, ok := m.spatialIndexes[cType.ID()]
+	if !ok {
+		return nil, eris.Wrapf(ErrNoSpatialIndex, "component %q", cType.Name())
+	}
+	if err := query.validate(); err != nil {
+		return nil, err
+	}
+	if !s.loaded {
+		err := eachStoredComponent(context.Background(), m.dbStorage, m.archIDToComps, cType,
+			func(id types.EntityID, bz []byte) error {
+				value, err := cType.Decode(bz)
+				if err != nil {
+					return err
+				}
+				point, ok, err := s.pointOf(value)
+				if err != nil || !ok {
+					return err
+				}
+				s.addCommitted(id, point)
+				return nil
+			})
+		if err != nil {
+			return nil, eris.Wrapf(err, "failed to build spatial index of component %q", cType.Name())
+		}
+		s.loaded = true
+	}
+	return s.lookup(query), nil
+}
+
+// setSpatialValue records the new value of a component in the spatial index of the component, if it has one.
+func (m *EntityCommandBuffer) setSpatialValue(cType types.ComponentMetadata, value any, ids ...types.EntityID) error {
+	s, ok := m.spatialIndexes[cType.ID()]
+	if !ok {
+		return nil
+	}
+	for _, id := range ids {
+		recordMapEntry(m.journal, s.pending, id)
+	}
+	return s.setPending(value, ids...)
+}
+
+// setSpatialDefault records that the components of the entities have the default value in the spatial index of the
+// component, if it has one.
+func (m *EntityCommandBuffer) setSpatialDefault(cType types.ComponentMetadata, ids ...types.EntityID) error {
+	if _, ok := m.spatialIndexes[cType.ID()]; !ok {
+		return nil
+	}
+	bz, err := cType.New()
+	if err != nil {
+		return err
+	}
+	value, err := cType.Decode(bz)
+	if err != nil {
+		return err
+	}
+	return m.setSpatialValue(cType, value, ids...)
+}
+
+// lookupSpatialInStorage answers the query by reading the position of every entity that has the component from
+// storage.
+func lookupSpatialInStorage(
+	ctx context.Context,
+	storage PrimitiveStorage[string],
+	archIDToComps VolatileStorage[types.ArchetypeID, []types.ComponentMetadata],
+	s *spatialIndex,
+	query SpatialQuery,
+) ([]types.EntityID, error) {
+	if err := query.validate(); err != nil {
+		return nil, err
+	}
+	var entries []spatialEntry
+	err := eachStoredComponent(ctx, storage, archIDToComps, s.cType, func(id types.EntityID, bz []byte) error {
+		value, err := s.cType.Decode(bz)
+		if err != nil {
+			return err
+		}
+		point, ok, err := s.pointOf(value)
+		if err == nil && ok {
+			entries = append(entries, spatialEntry{id: id, point: point})
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return query.selectEntries(entries), nil
+}
@@ -0,0 +1,137 @@
+package gamestate_test
+
+import (
+	"cmp"
+	"context"
+	"math"
+	"math/rand"
+	"slices"
+	"testing"
+
+	"pkg.world.dev/world-engine/assert"
+	"pkg.world.dev/world-engine/cardinal/gamestate"
+	"pkg.world.dev/world-engine/cardinal/types"
+)
+
+func cellPosition(value any) (float64, float64, error) {
+	player := value.(Player) //nolint:errcheck // the test only stores Player values
+	return float64(player.Cell.X), float64(player.Cell.Y), nil
+}
+
+// nearest returns the k entities that are the nearest to x, y by checking every position.
+func nearest(positions map[types.EntityID]Cell, x, y float64, k int) []types.EntityID {
+	distance := func(id types.EntityID) float64 {
+		return math.Hypot(float64(positions[id].X)-x, float64(positions[id].Y)-y)
+	}
+	ids := make([]types.EntityID, 0, len(positions))
+	for id := range positions {
+		ids = append(ids, id)
+	}
+	slices.SortFunc(ids, func(a, b types.EntityID) int {
+		return cmp.Or(cmp.Compare(distance(a), distance(b)), cmp.Compare(a, b))
+	})
+	return ids[:min(k, len(ids))]
+}
+
+func TestSpatialIndexMatchesFullScan(t *testing.T) {
+	ctx := context.Background()
+	client := newRedisClientForTest(t)
+	manager, playerComp := newIndexedCmdBuffer(t, client)
+	assert.NilError(t, manager.RegisterSpatialIndex(playerComp, cellPosition, 8))
+	rng := rand.New(rand.NewSource(1)) //nolint:gosec // deterministic test data
+
+	positions := map[types.EntityID]Cell{}
+	ids, err := manager.CreateManyEntities(200, playerComp)
+	assert.NilError(t, err)
+	for _, id := range ids {
+		positions[id] = Cell{}
+	}
+	move := func(n int) {
+		for i := 0; i < n; i++ {
+			id := ids[rng.Intn(len(ids))]
+			if _, ok := positions[id]; !ok {
+				continue
+			}
+			if rng.Intn(10) == 0 {
+				assert.NilError(t, manager.RemoveEntity(id))
+				delete(positions, id)
+				continue
+			}
+			cell := Cell{X: rng.Intn(200) - 100, Y: rng.Intn(200) - 100}
+			assert.NilError(t, manager.SetComponentForEntity(playerComp, id, Player{Cell: cell}))
+			positions[id] = cell
+		}
+	}
+	check := func() {
+		for i := 0; i < 20; i++ {
+			x, y := rng.Float64()*300-150, rng.Float64()*300-150
+			k := rng.Intn(30)
+			got, err := manager.LookupSpatial(playerComp,
+				gamestate.SpatialQuery{Kind: gamestate.SpatialNearest, X: x, Y: y, K: k})
+			assert.NilError(t, err)
+			assert.DeepEqual(t, got, nearest(positions, x, y, k))
+
+			radius := rng.Float64() * 50
+			got, err = manager.LookupSpatial(playerComp,
+				gamestate.SpatialQuery{Kind: gamestate.SpatialRadius, X: x, Y: y, Radius: radius})
+			assert.NilError(t, err)
+			want := make([]types.EntityID, 0)
+			for id, cell := range positions {
+				if math.Hypot(float64(cell.X)-x, float64(cell.Y)-y) <= radius {
+					want = append(want, id)
+				}
+			}
+			slices.Sort(want)
+			assert.DeepEqual(t, got, want)
+		}
+	}
+
+	move(300)
+	check()
+	assert.NilError(t, manager.FinalizeTick(ctx))
+	check()
+	move(100)
+	check()
+
+	// Discarded changes are removed from the index.
+	committed := make(map[types.EntityID]Cell, len(positions))
+	assert.NilError(t, manager.FinalizeTick(ctx))
+	for id, cell := range positions {
+		committed[id] = cell
+	}
+	move(100)
+	assert.NilError(t, manager.DiscardPending())
+	positions = committed
+	check()
+
+	// The index is built from the stored components.
+	manager, playerComp = newIndexedCmdBuffer(t, client)
+	assert.NilError(t, manager.RegisterSpatialIndex(playerComp, cellPosition, 8))
+	check()
+}
+
+func TestSpatialBoxLookup(t *testing.T) {
+	manager, playerComp := newIndexedCmdBuffer(t, newRedisClientForTest(t))
+	assert.NilError(t, manager.RegisterSpatialIndex(playerComp, cellPosition, 1))
+	ids, err := manager.CreateManyEntities(3, playerComp)
+	assert.NilError(t, err)
+	assert.NilError(t, manager.SetComponentForEntity(playerComp, ids[1], Player{Cell: Cell{X: 5, Y: 5}}))
+	assert.NilError(t, manager.SetComponentForEntity(playerComp, ids[2], Player{Cell: Cell{X: 1e9, Y: -1e9}}))
+	assert.NilError(t, manager.FinalizeTick(context.Background()))
+
+	box := func(minX, minY, maxX, maxY float64) []types.EntityID {
+		got, err := manager.LookupSpatial(playerComp, gamestate.SpatialQuery{
+			Kind: gamestate.SpatialBox, MinX: minX, MinY: minY, MaxX: maxX, MaxY: maxY,
+		})
+		assert.NilError(t, err)
+		return got
+	}
+	assert.DeepEqual(t, box(0, 0, 5, 5), ids[:2])
+	assert.DeepEqual(t, box(-1e10, -1e10, 1e10, 1e10), ids)
+	assert.DeepEqual(t, box(1e9, -1e9, 1e9, -1e9), ids[2:])
+	assert.Equal(t, len(box(1, 1, 0, 0)), 0)
+
+	_, err = manager.LookupSpatial(playerComp, gamestate.SpatialQuery{Kind: gamestate.SpatialBox, MaxX: math.Inf(1)})
+	assert.ErrorContains(t, err, "must be finite")
+	assert.ErrorContains(t, manager.RegisterSpatialIndex(playerComp, cellPosition, 1), "already has a spatial index")
+}
@@ -0,0 +1,71 @@
+package gamestate
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"testing"
+
+	"pkg.world.dev/world-engine/assert"
+)
+
+func TestStateRootTreeMatchesTheTreeBuiltFromItsLeaves(t *testing.T) {
+	tree := newStateRootTree()
+	assert.DeepEqual(t, tree.root(), make([]byte, stateRootHashSize))
+
+	// Leaves are added, replaced and removed in a random order, and the root is compared to the root of the tree
+	// built from the remaining leaves.
+	rng := rand.New(rand.NewSource(1))
+	values := make(map[string][]byte)
+	for i := 0; i < 2000; i++ {
+		key := fmt.Sprintf("key-%d", rng.Intn(300))
+		if rng.Intn(3) == 0 {
+			tree.remove(key)
+			delete(values, key)
+		} else {
+			value := []byte(strconv.Itoa(rng.Int()))
+			tree.add(key, value)
+			values[key] = value
+		}
+		if i%100 == 0 {
+			assert.DeepEqual(t, tree.root(), buildStateRoot(values))
+		}
+	}
+	assert.DeepEqual(t, tree.root(), buildStateRoot(values))
+
+	// A clone is not changed by the changes to the tree it was cloned from.
+	clone := tree.clone()
+	root := clone.root()
+	tree.add("another-key", []byte("value"))
+	assert.DeepEqual(t, clone.root(), root)
+	assert.Check(t, string(tree.root()) != string(root))
+}
+
+// buildStateRoot returns the root of the sparse Merkle tree of the given leaves, by splitting the leaves on each bit
+// of their paths until a single leaf is left.
+func buildStateRoot(values map[string][]byte) []byte {
+	leaves := make([]*stateRootNode, 0, len(values))
+	for key, value := range values {
+		leaves = append(leaves, newStateRootLeaf(key, value))
+	}
+	var build func(leaves []*stateRootNode, depth int) *stateRootNode
+	build = func(leaves []*stateRootNode, depth int) *stateRootNode {
+		if len(leaves) == 0 {
+			return nil
+		}
+		if len(leaves) == 1 {
+			return leaves[0]
+		}
+		var left, right []*stateRootNode
+		for _, leaf := range leaves {
+			if stateRootBit(leaf.path, depth) {
+				right = append(right, leaf)
+			} else {
+				left = append(left, leaf)
+			}
+		}
+		return newStateRootInternal(build(left, depth+1), build(right, depth+1))
+	}
+	hash := stateRootHash(build(leaves, 0))
+	return hash[:]
+}
@@ -0,0 +1,242 @@
+package gamestate
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"sync"
+
+	"pkg.world.dev/world-engine/cardinal/filter"
+	"pkg.world.dev/world-engine/cardinal/types"
+)
+
+var _ Manager = &synchronizedManager{}
+
+// synchronizedManager wraps a Manager and serializes every call to it with a mutex. The EntityCommandBuffer is not
+// safe for concurrent use (even reads populate its in-memory caches), so systems that are scheduled to run in
+// parallel must access the game state through this wrapper.
+type synchronizedManager struct {
+	mux   *sync.Mutex
+	inner Manager
+	// journal records the changes made through this wrapper while one of its savepoints is open, so that every writer
+	// only rolls back its own changes. It is nil if the wrapped Manager has no journal.
+	journal *journal
+}
+
+// journaledManager is a Manager that records its pending changes in a journal that can be switched.
+type journaledManager interface {
+	useJournal(j *journal) (previous *journal)
+}
+
+// NewSynchronizedManager returns a Manager that is safe for concurrent use. All calls are forwarded to the given
+// Manager while holding a lock. Slices returned by the wrapped Manager are copied so callers never alias its
+// internal state.
+func NewSynchronizedManager(m Manager) Manager {
+	return NewSynchronizedManagers(m, 1)[0]
+}
+
+// NewSynchronizedManagers returns n Managers that share a lock, as returned by NewSynchronizedManager. Each of them
+// has its own savepoints, so that concurrent writers can roll back their own changes without undoing the changes of
+// the others.
+func NewSynchronizedManagers(m Manager, n int) []Manager {
+	mux := &sync.Mutex{}
+	managers := make([]Manager, n)
+	for i := range managers {
+		s := &synchronizedManager{mux: mux, inner: m}
+		if _, ok := m.(journaledManager); ok {
+			s.journal = &journal{}
+		}
+		managers[i] = s
+	}
+	return managers
+}
+
+// lock locks the wrapped Manager and makes it record its changes in the journal of this wrapper. It returns the
+// function that unlocks it.
+func (s *synchronizedManager) lock() (unlock func()) {
+	s.mux.Lock()
+	if s.journal == nil {
+		return s.mux.Unlock
+	}
+	previous := s.inner.(journaledManager).useJournal(s.journal)
+	return func() {
+		s.inner.(journaledManager).useJournal(previous)
+		s.mux.Unlock()
+	}
+}
+
+func (s *synchronizedManager) GetComponentForEntity(cType types.ComponentMetadata, id types.EntityID) (any, error) {
+	defer s.lock()()
+	return s.inner.GetComponentForEntity(cType, id)
+}
+
+func (s *synchronizedManager) GetComponentForEntityInRawJSON(cType types.ComponentMetadata, id types.EntityID) (
+	json.RawMessage, error,
+) {
+	defer s.lock()()
+	return s.inner.GetComponentForEntityInRawJSON(cType, id)
+}
+
+func (s *synchronizedManager) GetComponentTypesForEntity(id types.EntityID) ([]types.ComponentMetadata, error) {
+	defer s.lock()()
+	comps, err := s.inner.GetComponentTypesForEntity(id)
+	return slices.Clone(comps), err
+}
+
+func (s *synchronizedManager) GetComponentTypesForArchID(archID types.ArchetypeID) (
+	[]types.ComponentMetadata, error,
+) {
+	defer s.lock()()
+	comps, err := s.inner.GetComponentTypesForArchID(archID)
+	return slices.Clone(comps), err
+}
+
+func (s *synchronizedManager) GetArchIDForComponents(components []types.ComponentMetadata) (
+	types.ArchetypeID, error,
+) {
+	defer s.lock()()
+	return s.inner.GetArchIDForComponents(components)
+}
+
+func (s *synchronizedManager) GetEntitiesForArchID(archID types.ArchetypeID) ([]types.EntityID, error) {
+	defer s.lock()()
+	ids, err := s.inner.GetEntitiesForArchID(archID)
+	return slices.Clone(ids), err
+}
+
+func (s *synchronizedManager) SearchFrom(filter filter.ComponentFilter, start int) *ArchetypeIterator {
+	defer s.lock()()
+	return s.inner.SearchFrom(filter, start)
+}
+
+func (s *synchronizedManager) ArchetypeCount() int {
+	defer s.lock()()
+	return s.inner.ArchetypeCount()
+}
+
+func (s *synchronizedManager) LookupIndex(cType types.ComponentMetadata, field string, query IndexQuery) (
+	[]types.EntityID, bool, error,
+) {
+	defer s.lock()()
+	return s.inner.LookupIndex(cType, field, query)
+}
+
+func (s *synchronizedManager) LookupSpatial(cType types.ComponentMetadata, query SpatialQuery) (
+	[]types.EntityID, error,
+) {
+	defer s.lock()()
+	return s.inner.LookupSpatial(cType, query)
+}
+
+func (s *synchronizedManager) RemoveEntity(id types.EntityID) error {
+	defer s.lock()()
+	return s.inner.RemoveEntity(id)
+}
+
+func (s *synchronizedManager) CreateEntity(comps ...types.ComponentMetadata) (types.EntityID, error) {
+	defer s.lock()()
+	return s.inner.CreateEntity(comps...)
+}
+
+func (s *synchronizedManager) CreateManyEntities(num int, comps ...types.ComponentMetadata) (
+	[]types.EntityID, error,
+) {
+	defer s.lock()()
+	return s.inner.CreateManyEntities(num, comps...)
+}
+
+func (s *synchronizedManager) SetComponentForEntity(cType types.ComponentMetadata, id types.EntityID, value any) error {
+	defer s.lock()()
+	return s.inner.SetComponentForEntity(cType, id, value)
+}
+
+func (s *synchronizedManager) AddComponentToEntity(cType types.ComponentMetadata, id types.EntityID) error {
+	defer s.lock()()
+	return s.inner.AddComponentToEntity(cType, id)
+}
+
+func (s *synchronizedManager) RemoveComponentFromEntity(cType types.ComponentMetadata, id types.EntityID) error {
+	defer s.lock()()
+	return s.inner.RemoveComponentFromEntity(cType, id)
+}
+
+func (s *synchronizedManager) Close() error {
+	defer s.lock()()
+	return s.inner.Close()
+}
+
+func (s *synchronizedManager) RegisterSpatialIndex(
+	cType types.ComponentMetadata, position PositionFn, cellSize float64,
+) error {
+	defer s.lock()()
+	return s.inner.RegisterSpatialIndex(cType, position, cellSize)
+}
+
+func (s *synchronizedManager) RegisterComponents(comps []types.ComponentMetadata) error {
+	defer s.lock()()
+	return s.inner.RegisterComponents(comps)
+}
+
+func (s *synchronizedManager) GetLastFinalizedTick() (uint64, error) {
+	defer s.lock()()
+	return s.inner.GetLastFinalizedTick()
+}
+
+func (s *synchronizedManager) FinalizeTick(ctx context.Context) error {
+	defer s.lock()()
+	return s.inner.FinalizeTick(ctx)
+}
+
+func (s *synchronizedManager) DiscardPending() error {
+	defer s.lock()()
+	return s.inner.DiscardPending()
+}
+
+func (s *synchronizedManager) Savepoint() {
+	defer s.lock()()
+	s.inner.Savepoint()
+}
+
+func (s *synchronizedManager) ReleaseSavepoint() error {
+	defer s.lock()()
+	return s.inner.ReleaseSavepoint()
+}
+
+func (s *synchronizedManager) RollbackToSavepoint() error {
+	defer s.lock()()
+	return s.inner.RollbackToSavepoint()
+}
+
+func (s *synchronizedManager) LastTickDiff() *TickDiff {
+	defer s.lock()()
+	return s.inner.LastTickDiff()
+}
+
+func (s *synchronizedManager) ToReadOnly() Reader {
+	defer s.lock()()
+	return s.inner.ToReadOnly()
+}
+
+func (s *synchronizedManager) Snapshot(ctx context.Context) (map[string][]byte, error) {
+	defer s.lock()()
+	return s.inner.Snapshot(ctx)
+}
+
+func (s *synchronizedManager) PinSnapshot(
+	ctx context.Context,
+) (func(context.Context) (map[string][]byte, error), error) {
+	defer s.lock()()
+	return s.inner.PinSnapshot(ctx)
+}
+
+func (s *synchronizedManager) Restore(ctx context.Context, state map[string][]byte) error {
+	defer s.lock()()
+	return s.inner.Restore(ctx, state)
+}
+
+func (s *synchronizedManager) MigrateComponent(
+	ctx context.Context, cType types.ComponentMetadata, migrate func([]byte) ([]byte, error),
+) error {
+	defer s.lock()()
+	return s.inner.MigrateComponent(ctx, cType, migrate)
+}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"errors"
 
-	"github.com/redis/go-redis/v9"
 	"github.com/rotisserie/eris"
 	"go.opentelemetry.io/otel/codes"
 )
@@ -20,9 +19,9 @@ func (m *EntityCommandBuffer) GetLastFinalizedTick() (uint64, error) {
 
 	tick, err := m.dbStorage.GetUInt64(ctx, storageLastFinalizedTickKey())
 	if err != nil {
-		// If the returned error is redis.Nil, it means that the key does not exist yet. In this case, we can infer
-		// that the latest finalized tick is 0. If the return is not redis.Nil, it means that an actual error occurred.
-		if errors.Is(err, redis.Nil) {
+		// If the returned error is ErrKeyNotFound, it means that the key does not exist yet. In this case, we can infer
+		// that the latest finalized tick is 0. Otherwise, it means that an actual error occurred.
+		if errors.Is(err, ErrKeyNotFound) {
 			tick = 0
 		} else {
 			return 0, eris.Wrap(err, "failed to get latest finalized tick")
@@ -58,6 +57,10 @@ func (m *EntityCommandBuffer) FinalizeTick(ctx context.Context) error {
 	}
 
 	m.pendingArchIDs = nil
+	m.stateRoot = m.pendingStateRoot
+	m.stateRootRebuilt = false
+	m.lastDiff = m.pendingDiff
+	m.commitIndexes()
 
 	if err := m.DiscardPending(); err != nil {
 		span.SetStatus(codes.Error, eris.ToString(err, true))
@@ -67,3 +70,9 @@ func (m *EntityCommandBuffer) FinalizeTick(ctx context.Context) error {
 
 	return nil
 }
+
+// LastTickDiff returns the changes committed by the most recent successful call to FinalizeTick, or nil if no tick
+// has been finalized since the EntityCommandBuffer was created.
+func (m *EntityCommandBuffer) LastTickDiff() *TickDiff {
+	return m.lastDiff
+}
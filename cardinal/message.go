@@ -28,6 +28,9 @@ type TxData[In any] struct {
 	Hash types.TxHash
 	Msg  In
 	Tx   *sign.Transaction
+	// Sequence is the position of the transaction in the order of the tick, across all message types, as set by the
+	// ordering policy of the world. It can be used to tell which of two transactions of different messages came first.
+	Sequence uint64
 }
 
 type MessageOption[In, Out any] func(mt *MessageType[In, Out])
@@ -40,6 +43,8 @@ type MessageType[In, Out any] struct {
 	group      string
 	inEVMType  *ethereumAbi.Type
 	outEVMType *ethereumAbi.Type
+	// maxTxsPerTick is the maximum number of transactions of the message that are accepted for a tick, or 0.
+	maxTxsPerTick int
 }
 
 // NewMessageType creates a new message type. It accepts two generic type parameters: the first for the message input,
@@ -129,23 +134,64 @@ func (t *MessageType[In, Out]) GetReceipt(wCtx WorldContext, hash types.TxHash)
 	return value, errs, true
 }
 
+// Each calls fn with every transaction of the message in the tick, and records the result or the error in the
+// receipt of the transaction. Every call runs in its own savepoint, so the state changes made by a call that returns an
+// error are rolled back, and the events it emitted are dropped.
 func (t *MessageType[In, Out]) Each(wCtx WorldContext, fn func(TxData[In]) (Out, error)) {
 	for _, txData := range t.In(wCtx) {
-		if result, err := fn(txData); err != nil {
+		if result, err := t.callInSavepoint(wCtx, txData, fn); err != nil {
 			err = eris.Wrap(err, "")
+			var personaTag string
+			if txData.Tx != nil {
+				personaTag = txData.Tx.PersonaTag
+			}
 			wCtx.Logger().Err(err).Msgf("tx %s from %s encountered an error with message=%+v and stack trace:\n %s",
 				txData.Hash,
-				txData.Tx.PersonaTag,
+				personaTag,
 				txData.Msg,
 				eris.ToString(err, true),
 			)
 			t.AddError(wCtx, txData.Hash, err)
+			wCtx.refundFee(txData.Hash)
 		} else {
 			t.SetResult(wCtx, txData.Hash, result)
 		}
 	}
 }
 
+// callInSavepoint calls fn with the transaction in a savepoint, which is rolled back if fn returns an error. If fn
+// panics, its changes are rolled back before the panic goes on, so that the savepoint isn't left open and the failure
+// policy of the system applies to the state the system started from.
+func (t *MessageType[In, Out]) callInSavepoint(
+	wCtx WorldContext, txData TxData[In], fn func(TxData[In]) (Out, error),
+) (Out, error) {
+	store := wCtx.storeManager()
+	events := wCtx.eventBuffer()
+	eventCount := len(events.Events)
+	rollback := func() {
+		if err := store.RollbackToSavepoint(); err != nil {
+			wCtx.Logger().Err(err).Msgf("failed to roll back the changes of tx %s", txData.Hash)
+		}
+		events.truncateEvents(eventCount)
+	}
+	store.Savepoint()
+	defer func() {
+		if r := recover(); r != nil {
+			rollback()
+			panic(r)
+		}
+	}()
+	result, err := fn(txData)
+	if err != nil {
+		rollback()
+		return result, err
+	}
+	if err := store.ReleaseSavepoint(); err != nil {
+		wCtx.Logger().Err(err).Msgf("failed to release the savepoint of tx %s", txData.Hash)
+	}
+	return result, nil
+}
+
 // In extracts all the TxData in the tx pool that match this MessageType's ID.
 func (t *MessageType[In, Out]) In(wCtx WorldContext) []TxData[In] {
 	tq := wCtx.getTxPool()
@@ -153,9 +199,10 @@ func (t *MessageType[In, Out]) In(wCtx WorldContext) []TxData[In] {
 	for _, txData := range tq.ForID(t.ID()) {
 		if val, ok := txData.Msg.(In); ok {
 			txs = append(txs, TxData[In]{
-				Hash: txData.TxHash,
-				Msg:  val,
-				Tx:   txData.Tx,
+				Hash:     txData.TxHash,
+				Msg:      val,
+				Tx:       txData.Tx,
+				Sequence: txData.Sequence,
 			})
 		}
 	}
@@ -220,6 +267,11 @@ func (t *MessageType[In, Out]) GetInFieldInformation() map[string]any {
 	return types.GetFieldInformation(reflect.TypeOf(new(In)).Elem())
 }
 
+// types returns the Go types of the input and of the output of the message.
+func (t *MessageType[In, Out]) types() (in, out reflect.Type) {
+	return reflect.TypeOf(new(In)).Elem(), reflect.TypeOf(new(Out)).Elem()
+}
+
 // -------------------------- Options --------------------------
 
 func WithMsgEVMSupport[In, Out any]() MessageOption[In, Out] {
@@ -249,6 +301,14 @@ func WithCustomMessageGroup[In, Out any](group string) MessageOption[In, Out] {
 	}
 }
 
+// WithMaxTxsPerTick limits the number of transactions of the message that are accepted for a single tick. Once the
+// limit is reached, transactions of the message are rejected with 429 Too Many Requests until the next tick.
+func WithMaxTxsPerTick[In, Out any](maxTxs int) MessageOption[In, Out] {
+	return func(mt *MessageType[In, Out]) {
+		mt.maxTxsPerTick = maxTxs
+	}
+}
+
 // -------------------------- Helpers --------------------------
 
 func isStruct[T any]() bool {
@@ -1,10 +1,13 @@
 package cardinal
 
 import (
+	"errors"
 	"testing"
 
 	"pkg.world.dev/world-engine/assert"
+	"pkg.world.dev/world-engine/cardinal/filter"
 	"pkg.world.dev/world-engine/cardinal/txpool"
+	"pkg.world.dev/world-engine/cardinal/types"
 	"pkg.world.dev/world-engine/sign"
 )
 
@@ -191,3 +194,131 @@ func TestIsValidMessageText(t *testing.T) {
 		})
 	}
 }
+
+func TestMaxTxsPerTick(t *testing.T) {
+	type FooMsg struct{}
+	tf := NewTestFixture(t, nil)
+	world := tf.World
+	err := RegisterMessage[FooMsg, EmptyMsgResult](world, "foo", WithMaxTxsPerTick[FooMsg, EmptyMsgResult](1))
+	assert.NilError(t, err)
+	fooMsg, ok := world.GetMessageByFullName("game.foo")
+	assert.True(t, ok)
+	tf.DoTick()
+
+	_, _, err = world.SubmitTransaction(fooMsg.ID(), FooMsg{}, &sign.Transaction{PersonaTag: "a"})
+	assert.NilError(t, err)
+	_, _, err = world.SubmitTransaction(fooMsg.ID(), FooMsg{}, &sign.Transaction{PersonaTag: "b"})
+	assert.ErrorIs(t, err, txpool.ErrTxRejected)
+
+	tf.DoTick()
+	_, _, err = world.SubmitTransaction(fooMsg.ID(), FooMsg{}, &sign.Transaction{PersonaTag: "b"})
+	assert.NilError(t, err)
+}
+
+type Loot struct {
+	Cursed bool
+}
+
+func (Loot) Name() string {
+	return "loot"
+}
+
+func TestEachRollsBackTheChangesOfAMessageThatFails(t *testing.T) {
+	type LootMsg struct {
+		Cursed bool
+	}
+	tf := NewTestFixture(t, nil)
+	world := tf.World
+	assert.NilError(t, RegisterComponent[Loot](world))
+	assert.NilError(t, RegisterMessage[LootMsg, EmptyMsgResult](world, "loot"))
+	var events []string
+	assert.NilError(t, RegisterSystems(world, func(wCtx WorldContext) error {
+		return EachMessage[LootMsg, EmptyMsgResult](wCtx, func(tx TxData[LootMsg]) (EmptyMsgResult, error) {
+			if _, err := Create(wCtx, Loot{Cursed: tx.Msg.Cursed}); err != nil {
+				return EmptyMsgResult{}, err
+			}
+			if err := wCtx.EmitStringEvent("looted by " + tx.Tx.PersonaTag); err != nil {
+				return EmptyMsgResult{}, err
+			}
+			if tx.Msg.Cursed {
+				return EmptyMsgResult{}, errors.New("the loot is cursed")
+			}
+			return EmptyMsgResult{}, nil
+		})
+	}, func(wCtx WorldContext) error {
+		events = nil
+		for _, event := range wCtx.eventBuffer().Events {
+			events = append(events, string(event))
+		}
+		return nil
+	}))
+	lootMsg, ok := world.GetMessageByFullName("game.loot")
+	assert.True(t, ok)
+	tf.DoTick()
+
+	tf.AddTransaction(lootMsg.ID(), LootMsg{}, &sign.Transaction{PersonaTag: "a"})
+	cursed := tf.AddTransaction(lootMsg.ID(), LootMsg{Cursed: true}, &sign.Transaction{PersonaTag: "b"})
+	tf.AddTransaction(lootMsg.ID(), LootMsg{}, &sign.Transaction{PersonaTag: "c"})
+	tf.DoTick()
+
+	// Only the loot created by the messages that succeeded remains.
+	wCtx := NewReadOnlyWorldContext(world)
+	var loot []Loot
+	err := NewSearch().Entity(filter.Exact(filter.Component[Loot]())).Each(wCtx, func(id types.EntityID) bool {
+		l, err := GetComponent[Loot](wCtx, id)
+		assert.NilError(t, err)
+		loot = append(loot, *l)
+		return true
+	})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, loot, []Loot{{}, {}})
+	// The event emitted by the message that failed was dropped.
+	assert.DeepEqual(t, events, []string{"looted by a", "looted by c"})
+
+	status, err := world.GetTransactionStatus(cursed)
+	assert.NilError(t, err)
+	assert.Equal(t, status.Status, types.TxStatusFailed)
+}
+
+func TestEachRollsBackAMessageThatPanics(t *testing.T) {
+	type LootMsg struct {
+		Cursed bool
+	}
+	tf := NewTestFixture(t, nil)
+	world := tf.World
+	assert.NilError(t, RegisterComponent[Loot](world))
+	assert.NilError(t, RegisterMessage[LootMsg, EmptyMsgResult](world, "loot"))
+	assert.NilError(t, RegisterSystem(world, func(wCtx WorldContext) error {
+		return EachMessage[LootMsg, EmptyMsgResult](wCtx, func(tx TxData[LootMsg]) (EmptyMsgResult, error) {
+			if _, err := Create(wCtx, Loot{Cursed: tx.Msg.Cursed}); err != nil {
+				return EmptyMsgResult{}, err
+			}
+			if tx.Msg.Cursed {
+				panic("the loot is cursed")
+			}
+			return EmptyMsgResult{}, nil
+		})
+	}, WithFailurePolicy(SkipSystem)))
+	lootMsg, ok := world.GetMessageByFullName("game.loot")
+	assert.True(t, ok)
+	countLoot := func() int {
+		count, err := NewSearch().Entity(filter.Exact(filter.Component[Loot]())).Count(NewReadOnlyWorldContext(world))
+		assert.NilError(t, err)
+		return count
+	}
+	tf.DoTick()
+
+	looted := tf.AddTransaction(lootMsg.ID(), LootMsg{}, &sign.Transaction{PersonaTag: "a"})
+	tf.AddTransaction(lootMsg.ID(), LootMsg{Cursed: true}, &sign.Transaction{PersonaTag: "b"})
+	tf.DoTick()
+
+	// The panic rolled back the changes of the whole system, including those of the message that succeeded.
+	assert.Equal(t, countLoot(), 0)
+	status, err := world.GetTransactionStatus(looted)
+	assert.NilError(t, err)
+	assert.Equal(t, status.Status, types.TxStatusFailed)
+
+	tf.AddTransaction(lootMsg.ID(), LootMsg{}, &sign.Transaction{PersonaTag: "c"})
+	tf.DoTick()
+	assert.Equal(t, countLoot(), 1)
+}
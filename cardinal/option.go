@@ -12,6 +12,7 @@ import (
 	"pkg.world.dev/world-engine/cardinal/receipt"
 	"pkg.world.dev/world-engine/cardinal/router"
 	"pkg.world.dev/world-engine/cardinal/server"
+	"pkg.world.dev/world-engine/cardinal/txpool"
 )
 
 // WorldOption represents an option that can be used to augment how the cardinal.World will be run.
@@ -62,13 +63,23 @@ func WithHashCacheSize(sizeKB uint) WorldOption {
 	}
 }
 
+// WithMaxSubscriptionsPerConnection sets the number of subscriptions a /subscriptions connection can hold at once.
+// The default is 64.
+func WithMaxSubscriptionsPerConnection(limit uint) WorldOption {
+	return WorldOption{
+		serverOption: server.WithMaxSubscriptionsPerConnection(limit),
+	}
+}
+
 // WithTickChannel sets the channel that will be used to decide when world.doTick is executed. If unset, a loop interval
 // of 1 second will be set. To set some other time, use: WithTickChannel(time.Tick(<some-duration>)). Tests can pass
-// in a channel controlled by the test for fine-grained control over when ticks are executed.
+// in a channel controlled by the test for fine-grained control over when ticks are executed. The tick budget is still
+// the period of CARDINAL_TICK_RATE, and the ticks of the channel are never skipped by the tick overrun policy.
 func WithTickChannel(ch <-chan time.Time) WorldOption {
 	return WorldOption{
 		cardinalOption: func(world *World) {
 			world.tickChannel = ch
+			world.ownsTickChannel = false
 		},
 	}
 }
@@ -83,6 +94,17 @@ func WithTickDoneChannel(ch chan<- uint64) WorldOption {
 	}
 }
 
+// WithTxOrderingPolicy sets the policy that orders the transactions of a tick before they are processed. The default,
+// txpool.ArrivalOrder, processes the transactions in the order they were received, which depends on the timing of the
+// requests. Every replica of the world must use the same policy.
+func WithTxOrderingPolicy(policy txpool.OrderingPolicy) WorldOption {
+	return WorldOption{
+		cardinalOption: func(world *World) {
+			world.txPool.SetOrderingPolicy(policy)
+		},
+	}
+}
+
 func WithStoreManager(s gamestate.Manager) WorldOption {
 	return WorldOption{
 		cardinalOption: func(world *World) {
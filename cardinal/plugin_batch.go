@@ -0,0 +1,186 @@
+package cardinal
+
+import (
+	"fmt"
+
+	"github.com/rotisserie/eris"
+
+	personaMsg "pkg.world.dev/world-engine/cardinal/persona/msg"
+	"pkg.world.dev/world-engine/cardinal/receipt"
+	"pkg.world.dev/world-engine/cardinal/txpool"
+	"pkg.world.dev/world-engine/cardinal/types"
+	"pkg.world.dev/world-engine/sign"
+)
+
+var _ Plugin = (*batchPlugin)(nil)
+
+// batchPlugin registers the message of batch transactions. A batch transaction carries an ordered list of messages
+// of any type under a single signature. The messages are processed by the systems of the tick like other
+// transactions, or not at all: when one of them returns an error, the tick is processed again without the batch.
+type batchPlugin struct {
+}
+
+func newBatchPlugin() *batchPlugin {
+	return &batchPlugin{}
+}
+
+func (p *batchPlugin) Register(world *World) error {
+	return RegisterMessage[sign.Batch, types.BatchResult](world, types.BatchMessageName,
+		WithCustomMessageGroup[sign.Batch, types.BatchResult](types.BatchMessageGroup))
+}
+
+// tickBatches holds the messages of the batch transactions of a tick, and the errors of the batches that failed. A
+// batch is invalid if it failed to decode, and failed if one of its messages returned an error.
+type tickBatches struct {
+	msgID   types.MessageID
+	txs     map[types.TxHash][]txpool.TxData
+	invalid map[types.TxHash][]error
+	failed  map[types.TxHash][]error
+}
+
+// decodeBatches decodes the messages of the batch transactions in the pool. A batch fails without being processed if
+// one of its messages isn't a registered message, or can't be decoded.
+func (w *World) decodeBatches(txPool *txpool.TxPool) (*tickBatches, error) {
+	batchMsg, ok := w.GetMessageByFullName(types.BatchMessageGroup + "." + types.BatchMessageName)
+	if !ok {
+		return nil, eris.New("the batch message is not registered")
+	}
+	batches := &tickBatches{
+		msgID:   batchMsg.ID(),
+		txs:     make(map[types.TxHash][]txpool.TxData),
+		invalid: make(map[types.TxHash][]error),
+		failed:  make(map[types.TxHash][]error),
+	}
+	for _, tx := range txPool.ForID(batches.msgID) {
+		batch, _ := tx.Msg.(sign.Batch)
+		if len(batch.Messages) == 0 {
+			batches.invalid[tx.TxHash] = []error{sign.ErrEmptyBatch}
+			continue
+		}
+		txs := make([]txpool.TxData, 0, len(batch.Messages))
+		for i, msg := range batch.Messages {
+			msgTx, err := w.decodeBatchMessage(msg)
+			if err != nil {
+				batches.invalid[tx.TxHash] = []error{eris.Wrapf(err, "message %d of the batch is invalid", i)}
+				break
+			}
+			// The messages of a batch are processed with the signature and the sequence of the batch, so they keep the
+			// position of the batch in the order of the tick.
+			msgTx.TxHash = batchMessageHash(tx.TxHash, i)
+			msgTx.Tx = tx.Tx
+			msgTx.Sequence = tx.Sequence
+			txs = append(txs, msgTx)
+		}
+		batches.txs[tx.TxHash] = txs
+	}
+	return batches, nil
+}
+
+func (w *World) decodeBatchMessage(batchMsg sign.BatchMessage) (txpool.TxData, error) {
+	msgType, ok := w.GetMessageByFullName(batchMsg.Message)
+	if !ok {
+		return txpool.TxData{}, eris.Errorf("message %q is not registered", batchMsg.Message)
+	}
+	// Batches can't be nested, and a persona can't be created by a transaction of the persona.
+	if msgType.FullName() == types.BatchMessageGroup+"."+types.BatchMessageName ||
+		msgType.FullName() == "persona."+personaMsg.CreatePersonaMessageName {
+		return txpool.TxData{}, eris.Errorf("message %q can't be part of a batch", batchMsg.Message)
+	}
+	msg, err := msgType.Decode(batchMsg.Body)
+	if err != nil {
+		return txpool.TxData{}, eris.Wrapf(err, "failed to decode message %q", batchMsg.Message)
+	}
+	return txpool.TxData{MsgID: msgType.ID(), Msg: msg}, nil
+}
+
+// batchMessageHash is the hash the receipt of a message of a batch is recorded under while the systems run. The
+// receipts of the messages are then folded into the receipt of the batch.
+func batchMessageHash(batchHash types.TxHash, i int) types.TxHash {
+	return types.TxHash(fmt.Sprintf("%s/%d", batchHash, i))
+}
+
+// withoutInvalid returns the pool without the batch transactions that failed to decode.
+func (b *tickBatches) withoutInvalid(txPool *txpool.TxPool) *txpool.TxPool {
+	if len(b.invalid) == 0 {
+		return txPool
+	}
+	return txPool.Filter(func(tx txpool.TxData) bool {
+		_, invalid := b.invalid[tx.TxHash]
+		return !invalid
+	})
+}
+
+// expand returns the pool that the systems process, in which each batch transaction is replaced by its messages, and
+// the batches that failed are left out.
+func (b *tickBatches) expand(txPool *txpool.TxPool) *txpool.TxPool {
+	if len(txPool.ForID(b.msgID)) == 0 {
+		return txPool
+	}
+	return txPool.Expand(func(tx txpool.TxData) []txpool.TxData {
+		if tx.MsgID != b.msgID {
+			return []txpool.TxData{tx}
+		}
+		if _, failed := b.failed[tx.TxHash]; failed {
+			return nil
+		}
+		return b.txs[tx.TxHash]
+	})
+}
+
+// foldReceipts replaces the receipts of the messages of each batch the systems processed by a receipt of the batch. It
+// reports whether a batch failed, in which case the tick must be processed again without it. A message that no system
+// processed fails the batch.
+func (b *tickBatches) foldReceipts(w *World, txPool *txpool.TxPool) (failed bool) {
+	for _, tx := range txPool.ForID(b.msgID) {
+		if _, ok := b.failed[tx.TxHash]; ok {
+			continue
+		}
+		txs := b.txs[tx.TxHash]
+		results := make([]any, 0, len(txs))
+		var errs []error
+		for i, msgTx := range txs {
+			rec, ok := w.receiptHistory.TakeReceipt(msgTx.TxHash)
+			if !ok {
+				name := "unknown"
+				if msg, ok := w.GetMessageByID(msgTx.MsgID); ok {
+					name = msg.FullName()
+				}
+				errs = append(errs, eris.Errorf("message %d of the batch failed: no system processed %s in this tick",
+					i, name))
+				continue
+			}
+			for _, err := range rec.Errs {
+				errs = append(errs, eris.Wrapf(err, "message %d of the batch failed", i))
+			}
+			results = append(results, rec.Result)
+		}
+		if len(errs) > 0 {
+			b.failed[tx.TxHash] = errs
+			failed = true
+			continue
+		}
+		w.receiptHistory.SetResult(tx.TxHash, types.BatchResult{Results: results})
+	}
+	return failed
+}
+
+// refundFailed marks the fees of the batches that failed to be refunded. Unlike the batches that failed to decode,
+// they were charged, since their messages were processed.
+func (b *tickBatches) refundFailed(w *World, txPool *txpool.TxPool) {
+	for _, tx := range txPool.ForID(b.msgID) {
+		if _, ok := b.failed[tx.TxHash]; ok {
+			w.refundFee(tx.TxHash)
+		}
+	}
+}
+
+// recordFailures adds the errors of the batches that failed or failed to decode to their receipts.
+func (b *tickBatches) recordFailures(history *receipt.History) {
+	for _, failed := range []map[types.TxHash][]error{b.invalid, b.failed} {
+		for hash, errs := range failed {
+			for _, err := range errs {
+				history.AddError(hash, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,256 @@
+package cardinal_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"pkg.world.dev/world-engine/assert"
+	"pkg.world.dev/world-engine/cardinal"
+	"pkg.world.dev/world-engine/cardinal/filter"
+	"pkg.world.dev/world-engine/cardinal/types"
+	"pkg.world.dev/world-engine/sign"
+)
+
+type Item struct {
+	Kind string
+}
+
+func (Item) Name() string {
+	return "item"
+}
+
+type Equipped struct {
+	Item string
+}
+
+func (Equipped) Name() string {
+	return "equipped"
+}
+
+type CraftMsg struct {
+	Item string
+}
+
+type EquipMsg struct {
+	Item string
+	Fail bool
+}
+
+type CraftResult struct {
+	Crafted string
+}
+
+// setupCraftingWorld registers a craft message that creates an Item, and an equip message that creates an Equipped
+// component, or fails.
+func setupCraftingWorld(t *testing.T) (*cardinal.TestFixture, types.Message) {
+	tf := cardinal.NewTestFixture(t, nil)
+	world := tf.World
+	assert.NilError(t, cardinal.RegisterComponent[Item](world))
+	assert.NilError(t, cardinal.RegisterComponent[Equipped](world))
+	assert.NilError(t, cardinal.RegisterMessage[CraftMsg, CraftResult](world, "craft"))
+	assert.NilError(t, cardinal.RegisterMessage[EquipMsg, CraftResult](world, "equip"))
+	assert.NilError(t, cardinal.RegisterSystems(world,
+		func(wCtx cardinal.WorldContext) error {
+			return cardinal.EachMessage[CraftMsg, CraftResult](wCtx,
+				func(tx cardinal.TxData[CraftMsg]) (CraftResult, error) {
+					_, err := cardinal.Create(wCtx, Item{Kind: tx.Msg.Item})
+					return CraftResult{Crafted: tx.Msg.Item}, err
+				})
+		},
+		func(wCtx cardinal.WorldContext) error {
+			return cardinal.EachMessage[EquipMsg, CraftResult](wCtx,
+				func(tx cardinal.TxData[EquipMsg]) (CraftResult, error) {
+					if tx.Msg.Fail {
+						return CraftResult{}, errors.New("can't equip")
+					}
+					_, err := cardinal.Create(wCtx, Equipped{Item: tx.Msg.Item})
+					return CraftResult{}, err
+				})
+		},
+	))
+	batch, ok := world.GetMessageByFullName("tx.batch")
+	assert.True(t, ok)
+	return tf, batch
+}
+
+func batchMessage(t *testing.T, message string, body any) sign.BatchMessage {
+	msg, err := sign.NewBatchMessage(message, body)
+	assert.NilError(t, err)
+	return msg
+}
+
+func TestBatchIsProcessedAtomically(t *testing.T) {
+	tf, batchMsg := setupCraftingWorld(t)
+	craft, ok := tf.World.GetMessageByFullName("game.craft")
+	assert.True(t, ok)
+	tf.DoTick()
+
+	applied := tf.AddTransaction(batchMsg.ID(), sign.Batch{Messages: []sign.BatchMessage{
+		batchMessage(t, "game.craft", CraftMsg{Item: "sword"}),
+		batchMessage(t, "game.equip", EquipMsg{Item: "sword"}),
+	}}, &sign.Transaction{PersonaTag: "alice"})
+	discarded := tf.AddTransaction(batchMsg.ID(), sign.Batch{Messages: []sign.BatchMessage{
+		batchMessage(t, "game.craft", CraftMsg{Item: "shield"}),
+		batchMessage(t, "game.equip", EquipMsg{Item: "shield", Fail: true}),
+	}}, &sign.Transaction{PersonaTag: "bob"})
+	tf.AddTransaction(craft.ID(), CraftMsg{Item: "bow"}, &sign.Transaction{PersonaTag: "carol"})
+	tf.DoTick()
+
+	// The shield of the failed batch was not crafted.
+	wCtx := cardinal.NewReadOnlyWorldContext(tf.World)
+	var items []string
+	err := cardinal.NewSearch().Entity(filter.Exact(filter.Component[Item]())).Each(wCtx,
+		func(id types.EntityID) bool {
+			item, err := cardinal.GetComponent[Item](wCtx, id)
+			assert.NilError(t, err)
+			items = append(items, item.Kind)
+			return true
+		})
+	assert.NilError(t, err)
+	assert.ElementsMatch(t, items, []string{"sword", "bow"})
+	equipped, err := cardinal.NewSearch().Entity(filter.Exact(filter.Component[Equipped]())).Count(wCtx)
+	assert.NilError(t, err)
+	assert.Equal(t, equipped, 1)
+
+	status, err := tf.World.GetTransactionStatus(applied)
+	assert.NilError(t, err)
+	assert.Equal(t, status.Status, types.TxStatusProcessed)
+	var result types.BatchResult
+	assert.NilError(t, json.Unmarshal(status.Result, &result))
+	assert.Equal(t, len(result.Results), 2)
+
+	status, err = tf.World.GetTransactionStatus(discarded)
+	assert.NilError(t, err)
+	assert.Equal(t, status.Status, types.TxStatusFailed)
+	assert.Equal(t, len(status.Errors), 1)
+	assert.Contains(t, status.Errors[0], "message 1 of the batch failed")
+	assert.Contains(t, status.Errors[0], "can't equip")
+}
+
+func TestBatchWithAnInvalidMessageIsNotProcessed(t *testing.T) {
+	tf, batchMsg := setupCraftingWorld(t)
+	tf.DoTick()
+
+	testCases := []struct {
+		name    string
+		msg     sign.BatchMessage
+		wantErr string
+	}{
+		{"unknown message", batchMessage(t, "game.unknown", CraftMsg{}), "is not registered"},
+		{"nested batch", batchMessage(t, "tx.batch", sign.Batch{}), "can't be part of a batch"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			hash := tf.AddTransaction(batchMsg.ID(), sign.Batch{Messages: []sign.BatchMessage{
+				batchMessage(t, "game.craft", CraftMsg{Item: "sword"}), tc.msg,
+			}}, &sign.Transaction{PersonaTag: "alice"})
+			tf.DoTick()
+
+			status, err := tf.World.GetTransactionStatus(hash)
+			assert.NilError(t, err)
+			assert.Equal(t, status.Status, types.TxStatusFailed)
+			assert.Equal(t, len(status.Errors), 1)
+			assert.Contains(t, status.Errors[0], tc.wantErr)
+
+			count, err := cardinal.NewSearch().Entity(filter.Exact(filter.Component[Item]())).
+				Count(cardinal.NewReadOnlyWorldContext(tf.World))
+			assert.NilError(t, err)
+			assert.Equal(t, count, 0)
+		})
+	}
+}
+
+func TestBatchMessagesAreProcessedByTheSystemsOfTheTick(t *testing.T) {
+	tf := cardinal.NewTestFixture(t, nil)
+	world := tf.World
+	assert.NilError(t, cardinal.RegisterMessage[CraftMsg, CraftResult](world, "craft"))
+	assert.NilError(t, cardinal.RegisterMessage[EquipMsg, CraftResult](world, "equip"))
+	var processed []string
+	runs := 0
+	assert.NilError(t, cardinal.RegisterSystems(world,
+		func(wCtx cardinal.WorldContext) error {
+			runs++
+			processed = nil
+			return cardinal.EachMessage[CraftMsg, CraftResult](wCtx,
+				func(tx cardinal.TxData[CraftMsg]) (CraftResult, error) {
+					processed = append(processed, "craft "+tx.Msg.Item)
+					return CraftResult{Crafted: tx.Msg.Item}, nil
+				})
+		},
+		func(wCtx cardinal.WorldContext) error {
+			return cardinal.EachMessage[EquipMsg, CraftResult](wCtx,
+				func(tx cardinal.TxData[EquipMsg]) (CraftResult, error) {
+					processed = append(processed, "equip "+tx.Msg.Item)
+					if tx.Msg.Fail {
+						return CraftResult{}, errors.New("can't equip")
+					}
+					return CraftResult{}, nil
+				})
+		},
+	))
+	batchMsg, ok := world.GetMessageByFullName("tx.batch")
+	assert.True(t, ok)
+	equip, ok := world.GetMessageByFullName("game.equip")
+	assert.True(t, ok)
+	tf.DoTick()
+	runs = 0
+
+	failed := tf.AddTransaction(batchMsg.ID(), sign.Batch{Messages: []sign.BatchMessage{
+		batchMessage(t, "game.craft", CraftMsg{Item: "axe"}),
+		batchMessage(t, "game.equip", EquipMsg{Item: "axe", Fail: true}),
+	}}, &sign.Transaction{PersonaTag: "alice"})
+	tf.AddTransaction(equip.ID(), EquipMsg{Item: "bow"}, &sign.Transaction{PersonaTag: "bob"})
+	applied := tf.AddTransaction(batchMsg.ID(), sign.Batch{Messages: []sign.BatchMessage{
+		batchMessage(t, "game.equip", EquipMsg{Item: "sword"}),
+		batchMessage(t, "game.craft", CraftMsg{Item: "sword"}),
+		batchMessage(t, "game.equip", EquipMsg{Item: "shield"}),
+	}}, &sign.Transaction{PersonaTag: "carol"})
+	tf.DoTick()
+
+	// The systems ran again without the batch that failed. Each system processed the messages of its type in the order
+	// of the tick, and the messages of the same type of a batch in the order they were signed in.
+	assert.Equal(t, runs, 2)
+	assert.DeepEqual(t, processed, []string{"craft sword", "equip bow", "equip sword", "equip shield"})
+
+	status, err := world.GetTransactionStatus(applied)
+	assert.NilError(t, err)
+	assert.Equal(t, status.Status, types.TxStatusProcessed)
+	var result types.BatchResult
+	assert.NilError(t, json.Unmarshal(status.Result, &result))
+	assert.Equal(t, len(result.Results), 3)
+
+	status, err = world.GetTransactionStatus(failed)
+	assert.NilError(t, err)
+	assert.Equal(t, status.Status, types.TxStatusFailed)
+	assert.Equal(t, len(status.Errors), 1)
+	assert.Contains(t, status.Errors[0], "message 1 of the batch failed")
+}
+
+func TestBatchMessageRunsASystemWithARunCondition(t *testing.T) {
+	tf := cardinal.NewTestFixture(t, nil)
+	world := tf.World
+	assert.NilError(t, cardinal.RegisterMessage[EquipMsg, CraftResult](world, "equip"))
+	var equipped []string
+	assert.NilError(t, cardinal.RegisterSystem(world, func(wCtx cardinal.WorldContext) error {
+		return cardinal.EachMessage[EquipMsg, CraftResult](wCtx,
+			func(tx cardinal.TxData[EquipMsg]) (CraftResult, error) {
+				equipped = append(equipped, tx.Msg.Item)
+				return CraftResult{}, nil
+			})
+	}, cardinal.WithRunCondition(cardinal.WhenMessage[EquipMsg, CraftResult]())))
+	batchMsg, ok := world.GetMessageByFullName("tx.batch")
+	assert.True(t, ok)
+	tf.DoTick()
+
+	// The equip message is only part of a batch, and still runs the system that waits for it.
+	hash := tf.AddTransaction(batchMsg.ID(), sign.Batch{Messages: []sign.BatchMessage{
+		batchMessage(t, "game.equip", EquipMsg{Item: "sword"}),
+	}}, &sign.Transaction{PersonaTag: "alice"})
+	tf.DoTick()
+
+	assert.DeepEqual(t, equipped, []string{"sword"})
+	status, err := world.GetTransactionStatus(hash)
+	assert.NilError(t, err)
+	assert.Equal(t, status.Status, types.TxStatusProcessed)
+}
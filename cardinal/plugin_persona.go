@@ -76,11 +76,13 @@ func (p *personaPlugin) RegisterQueries(world *World) error {
 }
 
 func (p *personaPlugin) RegisterSystems(world *World) error {
-	err := RegisterSystems(world, createPersonaSystem, authorizePersonaAddressSystem)
+	// The persona systems run before the game systems, regardless of the order in which the game registers them.
+	err := RegisterSystem(world, createPersonaSystem, WithSystemStage(PreUpdate))
 	if err != nil {
 		return err
 	}
-	return nil
+	return RegisterSystem(world, authorizePersonaAddressSystem,
+		WithSystemStage(PreUpdate), WithSystemAfter(SystemName(createPersonaSystem)))
 }
 
 func (p *personaPlugin) RegisterComponents(world *World) error {
@@ -125,7 +127,7 @@ func authorizePersonaAddressSystem(wCtx WorldContext) error {
 
 			// Check if the Persona Tag exists
 			lowerPersona := strings.ToLower(tx.PersonaTag)
-			data, ok := globalPersonaTagToAddressIndex[lowerPersona]
+			data, ok := lookupPersonaIndex(wCtx, lowerPersona)
 			if !ok {
 				return result, eris.Errorf("persona %s does not exist", tx.PersonaTag)
 			}
@@ -185,7 +187,7 @@ func createPersonaSystem(wCtx WorldContext) error {
 
 			// Temporarily convert tag to lowercase to check against mapping of lowercase tags
 			lowerPersona := strings.ToLower(txMsg.PersonaTag)
-			if _, ok := globalPersonaTagToAddressIndex[lowerPersona]; ok {
+			if _, ok := lookupPersonaIndex(wCtx, lowerPersona); ok {
 				// This PersonaTag has already been registered. Don't do anything
 				err = eris.Errorf("persona tag %s has already been registered", txMsg.PersonaTag)
 				return result, err
@@ -217,6 +219,22 @@ func createPersonaSystem(wCtx WorldContext) error {
 // Persona Index
 // -----------------------------------------------------------------------------
 
+// lookupPersonaIndex returns the index entry of the given lowercase persona tag. The index isn't rolled back with the
+// game state, e.g. when a tick is aborted, so an entry is only returned if its entity still has the persona tag, and
+// is removed otherwise.
+func lookupPersonaIndex(wCtx WorldContext, lowerPersona string) (personaIndexEntry, bool) {
+	entry, ok := globalPersonaTagToAddressIndex[lowerPersona]
+	if !ok {
+		return entry, false
+	}
+	sc, err := GetComponent[component.SignerComponent](wCtx, entry.EntityID)
+	if err != nil || strings.ToLower(sc.PersonaTag) != lowerPersona {
+		delete(globalPersonaTagToAddressIndex, lowerPersona)
+		return personaIndexEntry{}, false
+	}
+	return entry, true
+}
+
 func buildGlobalPersonaIndex(wCtx WorldContext) error {
 	// Rebuild the index if we haven't built it yet OR if we're in test and the CurrentTick has been reset.
 	if globalPersonaTagToAddressIndex != nil && tickOfPersonaTagToAddressIndex < wCtx.CurrentTick() {
@@ -24,6 +24,8 @@ type query interface {
 	IsEVMCompatible() bool
 	// GetRequestFieldInformation returns a map of the fields of the query's request type and their types.
 	GetRequestFieldInformation() map[string]any
+	// types returns the Go types of the request and of the reply of the query.
+	types() (request, reply reflect.Type)
 
 	// handleQuery handles queries with concrete struct types, rather than encoded bytes.
 	handleQuery(WorldContext, any) (any, error)
@@ -258,6 +260,10 @@ func (r *queryType[Request, Reply]) GetRequestFieldInformation() map[string]any
 	return types.GetFieldInformation(reflect.TypeOf(new(Request)).Elem())
 }
 
+func (r *queryType[Request, Reply]) types() (request, reply reflect.Type) {
+	return reflect.TypeOf(new(Request)).Elem(), reflect.TypeOf(new(Reply)).Elem()
+}
+
 func validateQuery[Request any, Reply any](
 	name string,
 	handler func(wCtx WorldContext, req *Request) (*Reply, error),
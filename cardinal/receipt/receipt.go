@@ -3,6 +3,7 @@
 package receipt
 
 import (
+	"sync"
 	"sync/atomic"
 
 	"github.com/rotisserie/eris"
@@ -23,13 +24,18 @@ type History struct {
 	ticksToStore uint64
 	// Receipts for a given tick are assigned to an index into this history slice which acts as a ring buffer.
 	history []map[types.TxHash]Receipt
+	// mux guards history. Systems that run in parallel may record receipts at the same time.
+	mux *sync.Mutex
 }
 
-// Receipt contains a transaction hash, an arbitrary result, and a list of errors.
+// Receipt contains a transaction hash, an arbitrary result, a list of errors, and the fee that was charged for the
+// transaction.
 type Receipt struct {
-	TxHash types.TxHash
-	Result any
-	Errs   []error
+	TxHash      types.TxHash
+	Result      any
+	Errs        []error
+	Fee         uint64
+	FeeRefunded bool
 }
 
 func (r Receipt) MarshalJSON() ([]byte, error) {
@@ -39,13 +45,17 @@ func (r Receipt) MarshalJSON() ([]byte, error) {
 	}
 
 	return codec.Encode(struct {
-		TxHash types.TxHash `json:"txHash"`
-		Result any          `json:"result"`
-		Errs   []string     `json:"errors"`
+		TxHash      types.TxHash `json:"txHash"`
+		Result      any          `json:"result"`
+		Errs        []string     `json:"errors"`
+		Fee         uint64       `json:"fee,omitempty"`
+		FeeRefunded bool         `json:"feeRefunded,omitempty"`
 	}{
-		TxHash: r.TxHash,
-		Result: r.Result,
-		Errs:   errStrings,
+		TxHash:      r.TxHash,
+		Result:      r.Result,
+		Errs:        errStrings,
+		Fee:         r.Fee,
+		FeeRefunded: r.FeeRefunded,
 	})
 }
 
@@ -57,6 +67,7 @@ func NewHistory(currentTick uint64, ticksToStore int) *History {
 		currTick: &atomic.Uint64{},
 		// Store ticksToStore plus the "current" tick
 		ticksToStore: uint64(ticksToStore),
+		mux:          &sync.Mutex{},
 	}
 	h.history = make([]map[types.TxHash]Receipt, 0, ticksToStore)
 	for range ticksToStore {
@@ -73,6 +84,8 @@ func (h *History) Size() uint64 {
 // NextTick advances the internal History tick by 1. Errors and results can only be set on the current tick. Receipts
 // from ticks in the past are read only.
 func (h *History) NextTick() {
+	h.mux.Lock()
+	defer h.mux.Unlock()
 	newCurr := h.currTick.Add(1)
 	mod := newCurr % h.ticksToStore
 	h.history[mod] = map[types.TxHash]Receipt{}
@@ -85,6 +98,8 @@ func (h *History) SetTick(tick uint64) {
 // AddError associates the given error with the given transaction hash. Calling this multiple times will append
 // the error any previously added errors.
 func (h *History) AddError(hash types.TxHash, err error) {
+	h.mux.Lock()
+	defer h.mux.Unlock()
 	tick := int(h.currTick.Load() % h.ticksToStore) //nolint:gosec
 	rec := h.history[tick][hash]
 	rec.TxHash = hash
@@ -95,6 +110,8 @@ func (h *History) AddError(hash types.TxHash, err error) {
 // SetResult sets the given transaction hash to the given result. Calling this multiple times will replace any previous
 // results.
 func (h *History) SetResult(hash types.TxHash, result any) {
+	h.mux.Lock()
+	defer h.mux.Unlock()
 	tick := int(h.currTick.Load() % h.ticksToStore) //nolint:gosec
 	rec := h.history[tick][hash]
 	rec.TxHash = hash
@@ -102,14 +119,80 @@ func (h *History) SetResult(hash types.TxHash, result any) {
 	h.history[tick][hash] = rec
 }
 
+// SetFee records the fee that was charged for the given transaction hash.
+func (h *History) SetFee(hash types.TxHash, fee uint64) {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+	tick := int(h.currTick.Load() % h.ticksToStore) //nolint:gosec
+	rec := h.history[tick][hash]
+	rec.TxHash = hash
+	rec.Fee = fee
+	h.history[tick][hash] = rec
+}
+
+// RefundFee calls refund with the fee that was charged for the given transaction hash, and marks the fee as refunded
+// unless refund returns an error. Nothing is refunded if no fee was charged, or if it was already refunded, so a fee
+// is refunded at most once.
+func (h *History) RefundFee(hash types.TxHash, refund func(fee uint64) error) error {
+	h.mux.Lock()
+	tick := int(h.currTick.Load() % h.ticksToStore) //nolint:gosec
+	rec, ok := h.history[tick][hash]
+	if !ok || rec.Fee == 0 || rec.FeeRefunded {
+		h.mux.Unlock()
+		return nil
+	}
+	// The lock is not held while refund runs, so that it can read the receipts. The fee is marked as refunded before,
+	// so that it can't be refunded twice.
+	rec.FeeRefunded = true
+	h.history[tick][hash] = rec
+	h.mux.Unlock()
+
+	if err := refund(rec.Fee); err != nil {
+		h.mux.Lock()
+		defer h.mux.Unlock()
+		rec = h.history[tick][hash]
+		rec.FeeRefunded = false
+		h.history[tick][hash] = rec
+		return err
+	}
+	return nil
+}
+
 // GetReceipt gets the receipt (the transaction result and the list of errors) for the given transaction hash in the
 // current tick. To get receipts from previous ticks use GetReceiptsForTick.
 func (h *History) GetReceipt(hash types.TxHash) (Receipt, bool) {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+	tick := int(h.currTick.Load() % h.ticksToStore) //nolint:gosec
+	rec, ok := h.history[tick][hash]
+	return rec, ok
+}
+
+// TakeReceipt removes the receipt of the given transaction hash from the current tick, and returns it.
+func (h *History) TakeReceipt(hash types.TxHash) (Receipt, bool) {
+	h.mux.Lock()
+	defer h.mux.Unlock()
 	tick := int(h.currTick.Load() % h.ticksToStore) //nolint:gosec
 	rec, ok := h.history[tick][hash]
+	delete(h.history[tick], hash)
 	return rec, ok
 }
 
+// SetReceipt replaces the receipt of the given transaction hash in the current tick.
+func (h *History) SetReceipt(hash types.TxHash, rec Receipt) {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+	tick := int(h.currTick.Load() % h.ticksToStore) //nolint:gosec
+	h.history[tick][hash] = rec
+}
+
+// DiscardTick discards the receipts of the current tick, so the tick can be processed again.
+func (h *History) DiscardTick() {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+	h.history[h.currTick.Load()%h.ticksToStore] = map[types.TxHash]Receipt{}
+}
+
 // GetReceiptsForTick gets all receipts for the given tick. If the tick is still active, or if the tick is too
 // far in the past, an error is returned.
 func (h *History) GetReceiptsForTick(tick uint64) ([]Receipt, error) {
@@ -122,6 +205,8 @@ func (h *History) GetReceiptsForTick(tick uint64) ([]Receipt, error) {
 	if currTick-tick >= h.ticksToStore {
 		return nil, ErrOldTickHasBeenDiscarded
 	}
+	h.mux.Lock()
+	defer h.mux.Unlock()
 	mod := tick % h.ticksToStore
 	recs := make([]Receipt, 0, len(h.history[mod]))
 	for _, rec := range h.history[mod] {
@@ -130,3 +215,18 @@ func (h *History) GetReceiptsForTick(tick uint64) ([]Receipt, error) {
 
 	return recs, nil
 }
+
+// FindReceipt searches the processed ticks that are still stored for the receipt of the given transaction hash, and
+// returns the receipt along with the tick it was recorded in.
+func (h *History) FindReceipt(hash types.TxHash) (rec Receipt, tick uint64, ok bool) {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+	currTick := h.currTick.Load()
+	for age := uint64(1); age < h.ticksToStore && age <= currTick; age++ {
+		tick = currTick - age
+		if rec, ok = h.history[tick%h.ticksToStore][hash]; ok {
+			return rec, tick, true
+		}
+	}
+	return Receipt{}, 0, false
+}
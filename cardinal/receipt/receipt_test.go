@@ -170,3 +170,74 @@ func TestReceipt_ReceiptErrorsArePresentInJSON(t *testing.T) {
 	assert.Contains(t, body, receiptResult)
 	assert.Contains(t, body, receiptError)
 }
+
+func TestFindReceiptSearchesTheStoredTicks(t *testing.T) {
+	rh := NewHistory(10, 2)
+	hash := txHash(t)
+	rh.SetResult(hash, "result")
+
+	// The receipt is not found while its tick is being processed.
+	_, _, ok := rh.FindReceipt(hash)
+	assert.Check(t, !ok)
+
+	rh.NextTick()
+	rec, tick, ok := rh.FindReceipt(hash)
+	assert.Check(t, ok)
+	assert.Equal(t, uint64(10), tick)
+	assert.Equal(t, "result", rec.Result)
+
+	rh.NextTick()
+	_, tick, ok = rh.FindReceipt(hash)
+	assert.Check(t, ok)
+	assert.Equal(t, uint64(10), tick)
+
+	// The tick has been discarded.
+	rh.NextTick()
+	_, _, ok = rh.FindReceipt(hash)
+	assert.Check(t, !ok)
+}
+
+func TestFeeIsRefundedAtMostOnce(t *testing.T) {
+	rh := NewHistory(0, 10)
+	hash := txHash(t)
+	var refunds []uint64
+	refund := func(fee uint64) error {
+		refunds = append(refunds, fee)
+		return nil
+	}
+
+	// Nothing is refunded when no fee was charged.
+	assert.NilError(t, rh.RefundFee(hash, refund))
+	assert.Equal(t, 0, len(refunds))
+
+	rh.SetFee(hash, 50)
+	failed := errors.New("refund failed")
+	err := rh.RefundFee(hash, func(uint64) error { return failed })
+	assert.ErrorIs(t, err, failed)
+	rec, _ := rh.GetReceipt(hash)
+	assert.Check(t, !rec.FeeRefunded)
+
+	assert.NilError(t, rh.RefundFee(hash, refund))
+	assert.NilError(t, rh.RefundFee(hash, refund))
+	assert.DeepEqual(t, refunds, []uint64{50})
+	rec, _ = rh.GetReceipt(hash)
+	assert.Equal(t, rec.Fee, uint64(50))
+	assert.Check(t, rec.FeeRefunded)
+}
+
+func TestReceiptsCanBeTakenAndDiscarded(t *testing.T) {
+	rh := NewHistory(0, 5)
+	taken, kept := txHash(t), txHash(t)
+	rh.SetResult(taken, "result")
+	rh.SetResult(kept, "result")
+
+	rec, ok := rh.TakeReceipt(taken)
+	assert.Check(t, ok)
+	assert.Equal(t, "result", rec.Result)
+	_, ok = rh.GetReceipt(taken)
+	assert.Check(t, !ok)
+
+	rh.DiscardTick()
+	_, ok = rh.GetReceipt(kept)
+	assert.Check(t, !ok)
+}
@@ -35,6 +35,8 @@ type TxBatch struct {
 	Tx       *sign.Transaction
 	MsgID    types.MessageID
 	MsgValue any
+	// Sequence is the position of the transaction in the order the tick processed its transactions.
+	Sequence uint64
 }
 
 func New(
@@ -109,6 +111,7 @@ OuterLoop:
 					Tx:       protoTxToSignTx(protoTx),
 					MsgID:    msgType.ID(),
 					MsgValue: msgValue,
+					Sequence: protoTx.GetSequence(),
 				})
 			}
 			if err := fn(batches, tickNumber, timestamp); err != nil {
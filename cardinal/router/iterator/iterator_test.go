@@ -101,11 +101,13 @@ func TestIteratorHappyPath(t *testing.T) {
 	msgBytes, err := fooMsg.Encode(msgValue)
 	assert.NilError(t, err)
 	protoTx := &shard.Transaction{
-		PersonaTag: "ty",
-		Namespace:  namespace,
-		Timestamp:  time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC).UnixMilli(),
-		Signature:  "fo",
-		Body:       msgBytes,
+		PersonaTag:     "ty",
+		Namespace:      namespace,
+		Timestamp:      time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC).UnixMilli(),
+		Signature:      "fo",
+		Body:           msgBytes,
+		Sequence:       3,
+		OrderingPolicy: "arrival",
 	}
 	txBz, err := proto.Marshal(protoTx)
 	assert.NilError(t, err)
@@ -151,6 +153,7 @@ func TestIteratorHappyPath(t *testing.T) {
 		assert.True(t, len(tx.Tx.Hash.Bytes()) > 1)
 		assert.Equal(t, tx.Tx.Namespace, namespace)
 		assert.DeepEqual(t, []byte(tx.Tx.Body), msgBytes)
+		assert.Equal(t, tx.Sequence, protoTx.GetSequence())
 
 		return nil
 	})
@@ -178,11 +181,13 @@ func TestIteratorStopRange(t *testing.T) {
 	msgBytes, err := fooMsg.Encode(msgValue)
 	assert.NilError(t, err)
 	protoTx := &shard.Transaction{
-		PersonaTag: "ty",
-		Namespace:  namespace,
-		Timestamp:  time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC).UnixMilli(),
-		Signature:  "fo",
-		Body:       msgBytes,
+		PersonaTag:     "ty",
+		Namespace:      namespace,
+		Timestamp:      time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC).UnixMilli(),
+		Signature:      "fo",
+		Body:           msgBytes,
+		Sequence:       3,
+		OrderingPolicy: "arrival",
 	}
 	txBz, err := proto.Marshal(protoTx)
 	assert.NilError(t, err)
@@ -39,12 +39,16 @@ type Router interface {
 	// route requests from the EVM to this game shard by using its namespace.
 	RegisterGameShard(context.Context) error
 
-	// SubmitTxBlob submits transactions processed in a tick to the base shard.
+	// SubmitTxBlob submits transactions processed in a tick to the base shard, along with the state root of the game
+	// shard after the tick. Each transaction is submitted with its sequence in the tick and the name of the ordering
+	// policy that set it, so that the transactions are recovered in the same order.
 	SubmitTxBlob(
 		ctx context.Context,
 		processedTxs txpool.TxMap,
+		orderingPolicy string,
 		epoch,
 		unixTimestamp uint64,
+		stateRoot []byte,
 	) error
 
 	TransactionIterator() iterator.Iterator
@@ -131,23 +135,29 @@ func (r *router) RegisterGameShard(ctx context.Context) error {
 func (r *router) SubmitTxBlob(
 	ctx context.Context,
 	processedTxs txpool.TxMap,
+	orderingPolicy string,
 	epoch,
 	unixTimestamp uint64,
+	stateRoot []byte,
 ) error {
 	_, span := r.tracer.Start(ctx, "router.submit-tx-blob")
 	defer span.End()
 
+	// The transactions of each message are submitted in the order they were processed in. The base shard groups the
+	// transactions by message, so the sequence of each transaction records the order of the tick.
 	messageIDtoTxs := make(map[uint64]*shard.Transactions)
 	for msgID, txs := range processedTxs {
 		protoTxs := make([]*shard.Transaction, 0, len(txs))
 		for _, txData := range txs {
 			tx := txData.Tx
 			protoTxs = append(protoTxs, &shard.Transaction{
-				PersonaTag: tx.PersonaTag,
-				Namespace:  tx.Namespace,
-				Timestamp:  tx.Timestamp,
-				Signature:  tx.Signature,
-				Body:       tx.Body,
+				PersonaTag:     tx.PersonaTag,
+				Namespace:      tx.Namespace,
+				Timestamp:      tx.Timestamp,
+				Signature:      tx.Signature,
+				Body:           tx.Body,
+				Sequence:       txData.Sequence,
+				OrderingPolicy: orderingPolicy,
 			})
 		}
 		messageIDtoTxs[uint64(msgID)] = &shard.Transactions{Txs: protoTxs} //nolint:gosec
@@ -158,6 +168,7 @@ func (r *router) SubmitTxBlob(
 		UnixTimestamp: unixTimestamp,
 		Namespace:     r.namespace,
 		Transactions:  messageIDtoTxs,
+		StateRoot:     stateRoot,
 	}
 
 	_, err := r.sequencerJobQueue.Enqueue(&req)
@@ -0,0 +1,186 @@
+package sdkgen_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"pkg.world.dev/world-engine/assert"
+	"pkg.world.dev/world-engine/cardinal/sdkgen"
+	"pkg.world.dev/world-engine/sign"
+)
+
+// The tests in this file run the generated clients. They are skipped if the toolchain of the language, or the
+// dependencies of the client, are not installed.
+
+// largeIntegers holds integers that JavaScript numbers can't represent, next to values they can.
+type largeIntegers struct {
+	MaxInt64  int64             `json:"maxInt64"`
+	MinInt64  int64             `json:"minInt64"`
+	MaxUint64 uint64            `json:"maxUint64"`
+	MaxSafe   int64             `json:"maxSafe"`
+	Fraction  float64           `json:"fraction"`
+	Fees      map[string]uint64 `json:"fees"`
+	Ticks     []uint64          `json:"ticks"`
+}
+
+// signingInput is the input of the sign command of the harnesses.
+type signingInput struct {
+	PrivateKey string          `json:"privateKey"`
+	PersonaTag string          `json:"personaTag"`
+	Namespace  string          `json:"namespace"`
+	Fee        uint64          `json:"fee"`
+	Body       json.RawMessage `json:"body"`
+}
+
+// signingOutput is the output of the sign command of the harnesses: the signed transaction, the hash the client
+// computes for it, and the address of the signer.
+type signingOutput struct {
+	Transaction   json.RawMessage `json:"transaction"`
+	Hash          string          `json:"hash"`
+	SignerAddress string          `json:"signerAddress"`
+}
+
+// copyTestdata copies files of the testdata directory to dir.
+func copyTestdata(t *testing.T, dir string, names ...string) {
+	t.Helper()
+	for _, name := range names {
+		bz, err := os.ReadFile(filepath.Join("testdata", name))
+		assert.NilError(t, err)
+		assert.NilError(t, os.WriteFile(filepath.Join(dir, filepath.Base(name)), bz, 0o600))
+	}
+}
+
+// run runs a harness with stdin as its input, and returns its output.
+func run(t *testing.T, cmd *exec.Cmd, stdin string) string {
+	t.Helper()
+	cmd.Stdin = strings.NewReader(stdin)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	assert.NilError(t, err, stderr.String())
+	return string(out)
+}
+
+// runTypeScript runs testdata/typescript/harness.ts with Node.js, next to the generated client. Node.js runs
+// TypeScript since version 22.7, and the dependencies of the client are installed with npm install in
+// testdata/typescript.
+func runTypeScript(t *testing.T, stdin string, args ...string) string {
+	t.Helper()
+	node, err := exec.LookPath("node")
+	if err != nil {
+		t.Skip("node is not installed")
+	}
+	if err := exec.Command(node, "--experimental-transform-types", "--eval", "").Run(); err != nil {
+		t.Skip("node can't run TypeScript")
+	}
+	modules, err := filepath.Abs(filepath.Join("testdata", "typescript", "node_modules"))
+	assert.NilError(t, err)
+	if _, err := os.Stat(modules); err != nil {
+		t.Skip("the dependencies of the client are not installed, run npm install in testdata/typescript")
+	}
+
+	file, err := sdkgen.Generate(openAPI(t), sdkgen.TypeScript, sdkgen.Options{})
+	assert.NilError(t, err)
+	dir := t.TempDir()
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, file.Name), file.Content, 0o600))
+	copyTestdata(t, dir, "typescript/harness.ts", "typescript/package.json")
+	assert.NilError(t, os.Symlink(modules, filepath.Join(dir, "node_modules")))
+
+	args = append([]string{"--experimental-transform-types", "--no-warnings", filepath.Join(dir, "harness.ts")}, args...)
+	return run(t, exec.Command(node, args...), stdin)
+}
+
+// runCSharp runs testdata/csharp with dotnet, next to the generated client. The dependencies of the client are
+// restored from NuGet.
+func runCSharp(t *testing.T, stdin string, args ...string) string {
+	t.Helper()
+	dotnet, err := exec.LookPath("dotnet")
+	if err != nil {
+		t.Skip("dotnet is not installed")
+	}
+
+	file, err := sdkgen.Generate(openAPI(t), sdkgen.CSharp, sdkgen.Options{CSharpNamespace: "Game.Client"})
+	assert.NilError(t, err)
+	dir := t.TempDir()
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, file.Name), file.Content, 0o600))
+	copyTestdata(t, dir, "csharp/ClientCheck.csproj", "csharp/Program.cs")
+	if out, err := exec.Command(dotnet, "restore", dir).CombinedOutput(); err != nil {
+		t.Skipf("the dependencies of the client can't be restored: %s", out)
+	}
+
+	args = append([]string{"run", "--no-restore", "--project", dir, "--"}, args...)
+	return run(t, exec.Command(dotnet, args...), stdin)
+}
+
+// checkSigning signs a transaction with a client, and checks that Cardinal computes the same hash for it, and
+// verifies its signature.
+func checkSigning(t *testing.T, runHarness func(t *testing.T, stdin string, args ...string) string) {
+	t.Helper()
+	key, err := crypto.GenerateKey()
+	assert.NilError(t, err)
+	input := signingInput{
+		PrivateKey: hex.EncodeToString(crypto.FromECDSA(key)),
+		PersonaTag: "alice",
+		Namespace:  "world",
+		// The fee, and an integer of the body, are beyond the integers that JavaScript numbers represent.
+		Fee:  1<<60 + 1,
+		Body: json.RawMessage(`{"direction":"up","steps":9007199254740993}`),
+	}
+	bz, err := json.Marshal(input)
+	assert.NilError(t, err)
+
+	var output signingOutput
+	assert.NilError(t, json.Unmarshal([]byte(runHarness(t, string(bz), "sign")), &output))
+	tx, err := sign.UnmarshalTransaction(output.Transaction)
+	assert.NilError(t, err)
+	assert.Equal(t, tx.PersonaTag, input.PersonaTag)
+	assert.Equal(t, tx.Namespace, input.Namespace)
+	assert.Equal(t, tx.Fee, input.Fee)
+	assert.Equal(t, string(tx.Body), string(input.Body))
+	assert.Equal(t, output.Hash, tx.HashHex())
+
+	address := crypto.PubkeyToAddress(key.PublicKey).Hex()
+	assert.Equal(t, strings.ToLower(output.SignerAddress), strings.ToLower(address))
+	assert.NilError(t, tx.Verify(address))
+}
+
+func TestTypeScriptClientRoundTripsLargeIntegers(t *testing.T) {
+	want := largeIntegers{
+		MaxInt64:  math.MaxInt64,
+		MinInt64:  math.MinInt64,
+		MaxUint64: math.MaxUint64,
+		MaxSafe:   1<<53 - 1,
+		Fraction:  0.5,
+		Fees:      map[string]uint64{"alice": 1<<53 + 1},
+		Ticks:     []uint64{0, 1 << 63},
+	}
+	bz, err := json.Marshal(want)
+	assert.NilError(t, err)
+	golden, err := os.ReadFile(filepath.Join("testdata", "large_integers.json"))
+	assert.NilError(t, err)
+	assert.Equal(t, strings.TrimSpace(string(golden)), string(bz))
+
+	// The client decodes the integers without losing precision, and encodes them as Go does.
+	out := runTypeScript(t, string(golden), "round-trip")
+	assert.Equal(t, out, string(bz))
+	var got largeIntegers
+	assert.NilError(t, json.Unmarshal([]byte(out), &got))
+	assert.DeepEqual(t, got, want)
+}
+
+func TestTypeScriptClientSignsLikeCardinal(t *testing.T) {
+	checkSigning(t, runTypeScript)
+}
+
+func TestCSharpClientSignsLikeCardinal(t *testing.T) {
+	checkSigning(t, runCSharp)
+}
@@ -0,0 +1,103 @@
+package sdkgen
+
+import (
+	"text/template"
+)
+
+type cSharpRenderer struct {
+	namespace string
+}
+
+func newCSharpRenderer(namespace string) renderer {
+	return cSharpRenderer{namespace: namespace}
+}
+
+func (cSharpRenderer) templateName() string { return "csharp.cs.tmpl" }
+
+func (cSharpRenderer) fileName() string { return "Cardinal.cs" }
+
+func (cSharpRenderer) reserved() []string {
+	return []string{
+		// The names of the client.
+		"BatchMessages", "CardinalClient", "CardinalEvents", "CardinalException", "Components", "EventMessage",
+		"Receipt", "Results", "Signer", "SubmitResponse", "TickResults", "TransactionStatus",
+		// The types used by the client.
+		"Action", "ArraySegment", "CancellationToken", "CancellationTokenSource", "ClientWebSocket", "Convert",
+		"CultureInfo", "DateTimeOffset", "Dictionary", "Encoding", "EthECKey", "Exception", "Formatting",
+		"HttpClient", "HttpResponseMessage", "IDisposable", "InvalidOperationException", "JObject", "JRaw",
+		"JToken", "JValue", "JsonConvert", "JsonProperty", "JsonReaderException", "List", "MemoryStream",
+		"NullValueHandling", "Random", "SemaphoreSlim", "Serializable", "Sha3Keccack", "StringBuilder",
+		"StringContent", "Task", "Type", "Uri", "WebSocketMessageType", "WebSocketReceiveResult",
+		"WebSocketState",
+		// The keywords that can't name a type.
+		"abstract", "as", "base", "bool", "break", "byte", "case", "catch", "char", "checked", "class", "const",
+		"continue", "decimal", "default", "delegate", "do", "double", "else", "enum", "event", "explicit", "extern",
+		"false", "finally", "fixed", "float", "for", "foreach", "goto", "if", "implicit", "in", "int", "interface",
+		"internal", "is", "lock", "long", "namespace", "new", "null", "object", "operator", "out", "override",
+		"params", "private", "protected", "public", "readonly", "ref", "return", "sbyte", "sealed", "short",
+		"sizeof", "stackalloc", "static", "string", "struct", "switch", "this", "throw", "true", "try", "typeof",
+		"uint", "ulong", "unchecked", "unsafe", "ushort", "using", "virtual", "void", "volatile", "while",
+	}
+}
+
+func (r cSharpRenderer) funcs(a *api) template.FuncMap {
+	var csType func(s *schema) string
+	csType = func(s *schema) string {
+		if name, ok := s.refName(); ok {
+			return a.typeName(name)
+		}
+		switch s.Type {
+		case "string":
+			return "string"
+		case "integer":
+			return "long"
+		case "number":
+			return "double"
+		case "boolean":
+			return "bool"
+		case "array":
+			if s.Items == nil {
+				return "List<JToken>"
+			}
+			return "List<" + csType(s.Items) + ">"
+		case "object":
+			if additional := s.additional(); additional != nil && len(s.Properties) == 0 {
+				return "Dictionary<string, " + csType(additional) + ">"
+			}
+			return "JObject"
+		default:
+			// Unions, and values of any type, are left as JSON.
+			return "JToken"
+		}
+	}
+	// csFieldType is the type of a field, which is nullable if the field is optional and its type is a value type.
+	csFieldType := func(f field) string {
+		t := csType(f.Schema)
+		switch t {
+		case "long", "double", "bool":
+			if f.Optional {
+				return t + "?"
+			}
+		}
+		return t
+	}
+	return template.FuncMap{
+		"namespace":    func() string { return r.namespace },
+		"csType":       csType,
+		"csFieldType":  csFieldType,
+		"fields":       csFields,
+		"quote":        quote,
+		"endpointName": endpointName,
+	}
+}
+
+// csFields returns the fields of a class. Members can't have the name of their class, so such fields are renamed.
+func csFields(s *schema, className string) []field {
+	return fields(s, func(jsonName string) string {
+		name := pascalCase(jsonName)
+		if name == className {
+			return name + "Value"
+		}
+		return name
+	})
+}
@@ -0,0 +1,443 @@
+// Package sdkgen generates typed TypeScript and C# clients for a world from its OpenAPI document, which is served by
+// the /openapi.json endpoint and returned by World.OpenAPI.
+//
+// The clients have a method for each message and query of the world, types for the bodies of the messages, the
+// requests and replies of the queries and the components, and the signing of transactions with the same hashing as
+// the sign package. They also decode the receipts of transactions and the tick results of the /events websocket.
+package sdkgen
+
+import (
+	"bytes"
+	"cmp"
+	"embed"
+	"encoding/json"
+	"maps"
+	"slices"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/rotisserie/eris"
+
+	"pkg.world.dev/world-engine/cardinal/types"
+)
+
+// Language is a language clients are generated in.
+type Language string
+
+const (
+	TypeScript Language = "typescript"
+	CSharp     Language = "csharp"
+)
+
+// Languages are the languages clients can be generated in.
+var Languages = []Language{TypeScript, CSharp}
+
+const (
+	schemaRefPrefix = "#/components/schemas/"
+	// createPersonaMessage and batchMessage are signed differently from the other messages, so the clients have
+	// dedicated methods for them.
+	createPersonaMessage = "persona.create-persona"
+	batchMessage         = types.BatchMessageGroup + "." + types.BatchMessageName
+)
+
+//go:embed templates
+var templates embed.FS
+
+// File is a generated source file.
+type File struct {
+	Name    string
+	Content []byte
+}
+
+// Options configures the generated clients.
+type Options struct {
+	// CSharpNamespace is the namespace of the generated C# code. It defaults to "Cardinal".
+	CSharpNamespace string
+}
+
+// Generate generates the client of the world described by the OpenAPI document in the given language.
+func Generate(doc types.OpenAPI, lang Language, opts Options) (File, error) {
+	var r renderer
+	switch lang {
+	case TypeScript:
+		r = newTypeScriptRenderer()
+	case CSharp:
+		r = newCSharpRenderer(cmp.Or(opts.CSharpNamespace, "Cardinal"))
+	default:
+		return File{}, eris.Errorf("unknown language %q", lang)
+	}
+
+	a, err := newAPI(doc, r.reserved())
+	if err != nil {
+		return File{}, err
+	}
+	tmpl, err := template.New(r.templateName()).Funcs(r.funcs(a)).ParseFS(templates, "templates/"+r.templateName())
+	if err != nil {
+		return File{}, eris.Wrap(err, "failed to parse template")
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, a); err != nil {
+		return File{}, eris.Wrapf(err, "failed to generate the %s client", lang)
+	}
+	return File{Name: r.fileName(), Content: buf.Bytes()}, nil
+}
+
+// renderer renders the client of a world in a language.
+type renderer interface {
+	templateName() string
+	fileName() string
+	// reserved returns the names used by the client itself, which the types of the world are renamed not to clash
+	// with.
+	reserved() []string
+	funcs(a *api) template.FuncMap
+}
+
+// api is what the clients are generated from: the types of the world, and its messages, queries and components.
+type api struct {
+	Namespace  string
+	Types      []namedSchema
+	Messages   []endpoint
+	Queries    []endpoint
+	Components []namedSchema
+	// CreatePersona is the message that creates personas, and Batch the message of batch transactions, if they are
+	// registered. BatchType and BatchMessageType are the names of the sign.Batch and sign.BatchMessage types.
+	CreatePersona    *endpoint
+	Batch            *endpoint
+	BatchType        string
+	BatchMessageType string
+
+	// names maps the names of the schemas in the document to the names of the types in the client.
+	names map[string]string
+}
+
+type namedSchema struct {
+	Name   string
+	Schema *schema
+}
+
+// endpoint is a message or a query. The request of a message is its input, and its reply is its output, which is
+// the result of its receipts.
+type endpoint struct {
+	Path     string
+	Group    string
+	Name     string
+	FullName string
+	Request  *schema
+	Reply    *schema
+}
+
+func newAPI(doc types.OpenAPI, reserved []string) (*api, error) {
+	schemas := make(map[string]*schema, len(doc.Components.Schemas))
+	for name, raw := range doc.Components.Schemas {
+		s, err := parseSchema(raw)
+		if err != nil {
+			return nil, eris.Wrapf(err, "schema %q", name)
+		}
+		schemas[name] = s
+	}
+	a := &api{Namespace: doc.Cardinal.Namespace, names: map[string]string{}}
+
+	// The types of the client are the types used by the messages, queries and components, rather than every schema
+	// of the document, which also describes the transactions themselves.
+	used := map[string]bool{}
+	var use func(s *schema)
+	use = func(s *schema) {
+		s.walk(func(s *schema) {
+			name, ok := s.refName()
+			if !ok || used[name] {
+				return
+			}
+			used[name] = true
+			if target, ok := schemas[name]; ok {
+				use(target)
+			}
+		})
+	}
+
+	paths := slices.Sorted(maps.Keys(doc.Paths))
+	for _, path := range paths {
+		op := doc.Paths[path].Post
+		if op == nil {
+			continue
+		}
+		var e endpoint
+		var err error
+		switch {
+		case op.Message != nil:
+			e = endpoint{Path: path, Group: op.Message.Group, Name: op.Message.Name, FullName: op.Message.FullName}
+			if e.Request, err = parseSchema(op.Message.Input); err == nil {
+				e.Reply, err = parseSchema(op.Message.Output)
+			}
+		case op.Query != nil:
+			e = endpoint{Path: path, Group: op.Query.Group, Name: op.Query.Name,
+				FullName: op.Query.Group + "." + op.Query.Name}
+			if e.Request, err = parseSchema(op.RequestBody.Content["application/json"].Schema); err == nil {
+				e.Reply, err = parseSchema(op.Responses["200"].Content["application/json"].Schema)
+			}
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, eris.Wrapf(err, "operation %q", op.OperationID)
+		}
+		use(e.Request)
+		use(e.Reply)
+		switch {
+		case op.Query != nil:
+			a.Queries = append(a.Queries, e)
+		case e.FullName == createPersonaMessage:
+			a.CreatePersona = &e
+		case e.FullName == batchMessage:
+			a.Batch = &e
+		default:
+			a.Messages = append(a.Messages, e)
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(doc.Cardinal.Components)) {
+		s, err := parseSchema(doc.Cardinal.Components[name])
+		if err != nil {
+			return nil, eris.Wrapf(err, "component %q", name)
+		}
+		use(s)
+		a.Components = append(a.Components, namedSchema{Name: name, Schema: s})
+	}
+
+	// Name the types, renaming those that clash with the names of the client.
+	taken := map[string]bool{}
+	for _, name := range reserved {
+		taken[name] = true
+	}
+	for _, name := range slices.Sorted(maps.Keys(used)) {
+		s, ok := schemas[name]
+		if !ok {
+			return nil, eris.Errorf("schema %q is referred to, but it is not in the document", name)
+		}
+		typeName := name
+		for taken[typeName] {
+			typeName += "Type"
+		}
+		taken[typeName] = true
+		a.names[name] = typeName
+		a.Types = append(a.Types, namedSchema{Name: typeName, Schema: s})
+	}
+
+	if a.Batch != nil {
+		batchName, ok := a.Batch.Request.refName()
+		if !ok {
+			return nil, eris.New("the input of the batch message is not a named type")
+		}
+		a.BatchType = a.names[batchName]
+		messages := schemas[batchName].property("messages")
+		if messages == nil || messages.Items == nil {
+			return nil, eris.New("the input of the batch message has no messages")
+		}
+		batchMessageName, ok := messages.Items.refName()
+		if !ok {
+			return nil, eris.New("the messages of the batch message are not a named type")
+		}
+		a.BatchMessageType = a.names[batchMessageName]
+	}
+	return a, nil
+}
+
+// AllMessages returns every message of the world, including the messages that create personas and batches.
+func (a *api) AllMessages() []endpoint {
+	messages := slices.Clone(a.Messages)
+	for _, e := range []*endpoint{a.CreatePersona, a.Batch} {
+		if e != nil {
+			messages = append(messages, *e)
+		}
+	}
+	return messages
+}
+
+// typeName returns the name in the client of a schema of the document.
+func (a *api) typeName(name string) string {
+	if typeName, ok := a.names[name]; ok {
+		return typeName
+	}
+	return name
+}
+
+// -------------------------- Schemas --------------------------
+
+// schema is the part of a JSON schema that the clients are generated from.
+type schema struct {
+	Ref                  string          `json:"$ref"`
+	Type                 string          `json:"type"`
+	Items                *schema         `json:"items"`
+	Properties           properties      `json:"properties"`
+	AdditionalProperties json.RawMessage `json:"additionalProperties"`
+	Required             []string        `json:"required"`
+	AllOf                []*schema       `json:"allOf"`
+	AnyOf                []*schema       `json:"anyOf"`
+	OneOf                []*schema       `json:"oneOf"`
+}
+
+// property is a property of an object schema. Properties keep the order of the fields of the Go struct.
+type property struct {
+	Name   string
+	Schema *schema
+}
+
+type properties []property
+
+func (p *properties) UnmarshalJSON(bz []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(bz))
+	if _, err := dec.Token(); err != nil {
+		return eris.Wrap(err, "failed to decode properties")
+	}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return eris.Wrap(err, "failed to decode properties")
+		}
+		name, ok := tok.(string)
+		if !ok {
+			return eris.Errorf("unexpected property name %v", tok)
+		}
+		var s schema
+		if err := dec.Decode(&s); err != nil {
+			return eris.Wrapf(err, "failed to decode property %q", name)
+		}
+		*p = append(*p, property{Name: name, Schema: &s})
+	}
+	return nil
+}
+
+func (s *schema) UnmarshalJSON(bz []byte) error {
+	// The true schema accepts any value, as does the empty schema.
+	if string(bz) == "true" || string(bz) == "false" {
+		*s = schema{}
+		return nil
+	}
+	type plain schema
+	return json.Unmarshal(bz, (*plain)(s))
+}
+
+func parseSchema(raw json.RawMessage) (*schema, error) {
+	s := &schema{}
+	// A missing schema accepts any value.
+	if len(raw) == 0 {
+		return s, nil
+	}
+	if err := json.Unmarshal(raw, s); err != nil {
+		return nil, eris.Wrap(err, "failed to decode schema")
+	}
+	return s, nil
+}
+
+// refName returns the name of the schema the schema refers to.
+func (s *schema) refName() (string, bool) {
+	if s == nil || !strings.HasPrefix(s.Ref, schemaRefPrefix) {
+		return "", false
+	}
+	return strings.TrimPrefix(s.Ref, schemaRefPrefix), true
+}
+
+// additional returns the schema of the values of a map, or nil if the schema is not a map.
+func (s *schema) additional() *schema {
+	if len(s.AdditionalProperties) == 0 || string(s.AdditionalProperties) == "false" {
+		return nil
+	}
+	additional, err := parseSchema(s.AdditionalProperties)
+	if err != nil {
+		return &schema{}
+	}
+	return additional
+}
+
+func (s *schema) property(name string) *schema {
+	for _, p := range s.Properties {
+		if p.Name == name {
+			return p.Schema
+		}
+	}
+	return nil
+}
+
+// walk calls fn on the schema and on every schema it contains.
+func (s *schema) walk(fn func(s *schema)) {
+	if s == nil {
+		return
+	}
+	fn(s)
+	s.Items.walk(fn)
+	for _, p := range s.Properties {
+		p.Schema.walk(fn)
+	}
+	if additional := s.additional(); additional != nil {
+		additional.walk(fn)
+	}
+	for _, sub := range slices.Concat(s.AllOf, s.AnyOf, s.OneOf) {
+		sub.walk(fn)
+	}
+}
+
+// field is a property of a type of the client.
+type field struct {
+	// JSONName is the name of the property in JSON, and Name the name of the field in the client.
+	JSONName string
+	Name     string
+	Optional bool
+	Schema   *schema
+}
+
+// fields returns the fields of an object schema. The names of the fields are made with the given function, and made
+// unique.
+func fields(s *schema, name func(jsonName string) string) []field {
+	fs := make([]field, 0, len(s.Properties))
+	taken := map[string]bool{}
+	for _, p := range s.Properties {
+		fieldName := name(p.Name)
+		for taken[fieldName] {
+			fieldName += "_"
+		}
+		taken[fieldName] = true
+		fs = append(fs, field{
+			JSONName: p.Name,
+			Name:     fieldName,
+			Optional: !slices.Contains(s.Required, p.Name),
+			Schema:   p.Schema,
+		})
+	}
+	return fs
+}
+
+// -------------------------- Names --------------------------
+
+// pascalCase turns a name such as "create-persona" into "CreatePersona".
+func pascalCase(name string) string {
+	var b strings.Builder
+	for _, word := range strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		runes := []rune(word)
+		b.WriteRune(unicode.ToUpper(runes[0]))
+		b.WriteString(string(runes[1:]))
+	}
+	if b.Len() == 0 || unicode.IsDigit([]rune(b.String())[0]) {
+		return "_" + b.String()
+	}
+	return b.String()
+}
+
+// camelCase turns a name such as "create-persona" into "createPersona".
+func camelCase(name string) string {
+	runes := []rune(pascalCase(name))
+	runes[0] = unicode.ToLower(runes[0])
+	return string(runes)
+}
+
+// endpointName names the method of an endpoint after its group and name, e.g. "GameMove" for game.move.
+func endpointName(e endpoint) string {
+	return pascalCase(e.Group) + pascalCase(e.Name)
+}
+
+// quote returns the name as a string literal, which is the same in TypeScript and C#.
+func quote(s string) string {
+	bz, _ := json.Marshal(s)
+	return string(bz)
+}
@@ -0,0 +1,115 @@
+package sdkgen_test
+
+import (
+	"strings"
+	"testing"
+
+	"pkg.world.dev/world-engine/assert"
+	"pkg.world.dev/world-engine/cardinal"
+	"pkg.world.dev/world-engine/cardinal/sdkgen"
+	"pkg.world.dev/world-engine/cardinal/types"
+)
+
+type Position struct {
+	X, Y int
+}
+
+func (Position) Name() string { return "position" }
+
+type MoveInput struct {
+	Direction string `json:"direction"`
+	Steps     int    `json:"steps,omitempty"`
+}
+
+type MoveOutput struct {
+	Position Position `json:"position"`
+}
+
+type LocationRequest struct {
+	ID types.EntityID `json:"id"`
+}
+
+// Promise clashes with the name of a TypeScript global, and Task with the name of a C# type. In C#, the kept field
+// of Promise clashes with the name of its class.
+type Promise struct {
+	Tags map[string]bool `json:"tags"`
+	Kept bool            `json:"promise"`
+}
+
+type Task struct {
+	Task string `json:"task"`
+}
+
+func openAPI(t *testing.T) types.OpenAPI {
+	tf := cardinal.NewTestFixture(t, nil)
+	world := tf.World
+	assert.NilError(t, cardinal.RegisterComponent[Position](world))
+	assert.NilError(t, cardinal.RegisterMessage[MoveInput, MoveOutput](world, "move",
+		cardinal.WithCustomMessageGroup[MoveInput, MoveOutput]("game")))
+	assert.NilError(t, cardinal.RegisterMessage[Task, Promise](world, "promise",
+		cardinal.WithCustomMessageGroup[Task, Promise]("game")))
+	assert.NilError(t, cardinal.RegisterQuery[LocationRequest, Position](world, "location",
+		func(cardinal.WorldContext, *LocationRequest) (*Position, error) { return &Position{}, nil },
+		cardinal.WithCustomQueryGroup[LocationRequest, Position]("game")))
+	doc, err := world.OpenAPI()
+	assert.NilError(t, err)
+	return doc
+}
+
+func TestGenerateTypeScript(t *testing.T) {
+	file, err := sdkgen.Generate(openAPI(t), sdkgen.TypeScript, sdkgen.Options{})
+	assert.NilError(t, err)
+	assert.Equal(t, file.Name, "cardinal.ts")
+	content := string(file.Content)
+
+	for _, want := range []string{
+		"export interface MoveInput {\n  direction: string;\n  steps?: Integer;\n}",
+		"export interface MoveOutput {\n  position: Position;\n}",
+		"export interface PromiseType {\n  tags: Record<string, boolean>;\n  promise: boolean;\n}",
+		`"position": Position;`,
+		`"game.move": MoveOutput;`,
+		"submitGameMove(body: MoveInput, options: TransactionOptions = {}): Promise<SubmitResponse> {",
+		`return this.submit("/tx/game/move", body, options);`,
+		"submitGamePromise(body: Task, options: TransactionOptions = {}): Promise<SubmitResponse> {",
+		"queryGameLocation(request: LocationRequest): Promise<Position> {",
+		`return this.post("/query/game/location", stringifyJSON(request));`,
+		"async createPersona(personaTag: string): Promise<SubmitResponse> {",
+		"export function transactionHash(",
+		"submitBatch(messages: BatchMessage[], options: TransactionOptions = {}): Promise<SubmitResponse> {",
+		"gameMove: (body: MoveInput): BatchMessage => ({",
+	} {
+		assert.Check(t, strings.Contains(content, want), "missing %q", want)
+	}
+	// The messages that create personas and batches can't be batched.
+	assert.Check(t, !strings.Contains(content, "personaCreatePersona: (body"))
+	assert.Check(t, !strings.Contains(content, "txBatch: (body"))
+}
+
+func TestGenerateCSharp(t *testing.T) {
+	file, err := sdkgen.Generate(openAPI(t), sdkgen.CSharp, sdkgen.Options{CSharpNamespace: "Game.Client"})
+	assert.NilError(t, err)
+	assert.Equal(t, file.Name, "Cardinal.cs")
+	content := string(file.Content)
+
+	for _, want := range []string{
+		"namespace Game.Client\n",
+		`[JsonProperty("steps", NullValueHandling = NullValueHandling.Ignore)]` + "\n        public long? Steps { get; set; }",
+		"public class TaskType\n    {\n" +
+			`        [JsonProperty("task")]` + "\n        public string Task { get; set; }",
+		"public Dictionary<string, bool> Tags { get; set; }",
+		`[JsonProperty("promise")]` + "\n        public bool PromiseValue { get; set; }",
+		`["position"] = typeof(Position),`,
+		"public Task<SubmitResponse> SubmitGameMoveAsync(MoveInput body, ulong fee = 0) =>",
+		"public Task<SubmitResponse> SubmitGamePromiseAsync(TaskType body, ulong fee = 0) =>",
+		"public Task<Position> QueryGameLocationAsync(LocationRequest request) =>",
+		"public static MoveOutput GameMove(JToken result) => result.ToObject<MoveOutput>();",
+		"public static string TransactionHash(",
+	} {
+		assert.Check(t, strings.Contains(content, want), "missing %q", want)
+	}
+}
+
+func TestGenerateUnknownLanguage(t *testing.T) {
+	_, err := sdkgen.Generate(types.OpenAPI{}, "cobol", sdkgen.Options{})
+	assert.ErrorContains(t, err, `unknown language "cobol"`)
+}
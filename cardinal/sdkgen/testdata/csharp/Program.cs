@@ -0,0 +1,32 @@
+// The tests of the C# client run this harness with dotnet, next to the generated client. It reads its input from
+// stdin, and writes its output to stdout.
+
+using System;
+using Game.Client;
+using Newtonsoft.Json;
+using Newtonsoft.Json.Linq;
+
+var input = JObject.Parse(Console.In.ReadToEnd());
+switch (args[0])
+{
+    case "sign":
+        var privateKey = (string)input["privateKey"];
+        var personaTag = (string)input["personaTag"];
+        var ns = (string)input["namespace"];
+        var fee = (ulong)input["fee"];
+        var body = input["body"];
+        var tx = Signer.SignTransaction(privateKey, personaTag, ns, body, fee);
+        var signed = JObject.Parse(tx);
+        var hash = Signer.TransactionHash(personaTag, ns, (long)signed["timestamp"], (int)signed["salt"], fee,
+            JsonConvert.SerializeObject(body, Formatting.None));
+        var output = new JObject
+        {
+            ["transaction"] = new JRaw(tx),
+            ["hash"] = hash,
+            ["signerAddress"] = Signer.SignerAddress(privateKey),
+        };
+        Console.Write(output.ToString(Formatting.None));
+        break;
+    default:
+        throw new ArgumentException($"unknown command {args[0]}");
+}
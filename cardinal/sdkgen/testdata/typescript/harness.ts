@@ -0,0 +1,31 @@
+// The tests of the TypeScript client run this harness with Node.js, next to the generated client. It reads its input
+// from stdin, and writes its output to stdout.
+
+import { readFileSync } from "node:fs";
+import { parseJSON, signerAddress, signTransaction, stringifyJSON, transactionHash } from "./cardinal.ts";
+
+const input = readFileSync(0, "utf8");
+
+switch (process.argv[2]) {
+  case "round-trip":
+    process.stdout.write(stringifyJSON(parseJSON(input)));
+    break;
+  case "sign": {
+    const { privateKey, personaTag, namespace, fee, body } = parseJSON(input) as {
+      privateKey: string;
+      personaTag: string;
+      namespace: string;
+      fee: number | bigint;
+      body: unknown;
+    };
+    const tx = signTransaction(privateKey, personaTag, namespace, body, fee);
+    const { timestamp, salt } = parseJSON(tx) as { timestamp: number; salt: number };
+    const hash = transactionHash(personaTag, namespace, timestamp, salt, fee, stringifyJSON(body));
+    // The transaction is written as it was signed.
+    const rest = stringifyJSON({ hash, signerAddress: signerAddress(privateKey) });
+    process.stdout.write(`{"transaction":${tx},${rest.slice(1)}`);
+    break;
+  }
+  default:
+    throw new Error(`unknown command ${process.argv[2]}`);
+}
@@ -0,0 +1,124 @@
+package sdkgen
+
+import (
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+var tsIdentifier = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+type typeScriptRenderer struct{}
+
+func newTypeScriptRenderer() renderer {
+	return typeScriptRenderer{}
+}
+
+func (typeScriptRenderer) templateName() string { return "typescript.ts.tmpl" }
+
+func (typeScriptRenderer) fileName() string { return "cardinal.ts" }
+
+func (typeScriptRenderer) reserved() []string {
+	return []string{
+		// The names of the client.
+		"CardinalClient", "CardinalEvents", "CardinalError", "ClientOptions", "Components", "EventMessage",
+		"Integer", "MessageResults", "Receipt", "SubmitResponse", "TickResults", "TransactionOptions",
+		"TransactionStatus", "batchMessages", "decodeEvents", "encodeJSON", "parseJSON", "results",
+		"signTransaction", "signerAddress", "transactionHash", "stringifyJSON", "JSON_LITERAL", "JSON_NUMBER", "JSON_SPACE",
+		"JSON_STRING",
+		// The globals and imports used by the client.
+		"Array", "BigInt", "Boolean", "Date", "Error", "JSON", "Math", "Number", "Object", "Promise", "Record",
+		"RegExp", "RegExpExecArray", "String", "SyntaxError", "TextDecoder", "Uint8Array", "WebSocket", "atob",
+		"bytesToHex", "concatBytes", "fetch", "hexToBytes", "NAMESPACE", "RequestInit", "SYSTEM_PERSONA_TAG",
+		"keccak_256", "secp256k1", "strip0x", "utf8ToBytes",
+		// The reserved words that can't name a type.
+		"any", "bigint", "boolean", "break", "case", "catch", "class", "const", "continue", "debugger", "default",
+		"delete", "do", "else", "enum", "export", "extends", "false", "finally", "for", "function", "if",
+		"implements", "import", "in", "instanceof", "interface", "let", "never", "new", "null", "number", "object",
+		"package", "private", "protected", "public", "return", "static", "string", "super", "switch", "symbol",
+		"this", "throw", "true", "try", "typeof", "undefined", "unknown", "var", "void", "while", "with", "yield",
+	}
+}
+
+func (typeScriptRenderer) funcs(a *api) template.FuncMap {
+	var tsType func(s *schema) string
+	tsType = func(s *schema) string {
+		if name, ok := s.refName(); ok {
+			return a.typeName(name)
+		}
+		union := func(subs []*schema, sep string) string {
+			types := make([]string, len(subs))
+			for i, sub := range subs {
+				types[i] = tsType(sub)
+			}
+			return "(" + strings.Join(types, sep) + ")"
+		}
+		switch {
+		case len(s.OneOf) > 0:
+			return union(s.OneOf, " | ")
+		case len(s.AnyOf) > 0:
+			return union(s.AnyOf, " | ")
+		case len(s.AllOf) > 0:
+			return union(s.AllOf, " & ")
+		}
+		switch s.Type {
+		case "string":
+			return "string"
+		case "integer":
+			// Integers of the world are 64-bit, so they can be beyond Number.MAX_SAFE_INTEGER.
+			return "Integer"
+		case "number":
+			return "number"
+		case "boolean":
+			return "boolean"
+		case "null":
+			return "null"
+		case "array":
+			if s.Items == nil {
+				return "unknown[]"
+			}
+			return tsType(s.Items) + "[]"
+		case "object":
+			if len(s.Properties) > 0 {
+				fs := tsFields(s)
+				props := make([]string, len(fs))
+				for i, f := range fs {
+					props[i] = f.Name + optionalMark(f.Optional) + ": " + tsType(f.Schema)
+				}
+				return "{ " + strings.Join(props, "; ") + " }"
+			}
+			if additional := s.additional(); additional != nil {
+				return "Record<string, " + tsType(additional) + ">"
+			}
+			return "Record<string, unknown>"
+		default:
+			return "unknown"
+		}
+	}
+	return template.FuncMap{
+		"tsType":       tsType,
+		"fields":       tsFields,
+		"isInterface":  func(s *schema) bool { return s.Type == "object" && len(s.Properties) > 0 },
+		"optional":     optionalMark,
+		"quote":        quote,
+		"camelCase":    camelCase,
+		"endpointName": endpointName,
+	}
+}
+
+// tsFields returns the fields of an object schema, whose names are quoted if they are not identifiers.
+func tsFields(s *schema) []field {
+	return fields(s, func(jsonName string) string {
+		if tsIdentifier.MatchString(jsonName) {
+			return jsonName
+		}
+		return quote(jsonName)
+	})
+}
+
+func optionalMark(optional bool) string {
+	if optional {
+		return "?"
+	}
+	return ""
+}
@@ -4,8 +4,6 @@ import (
 	"math"
 	"slices"
 
-	"github.com/rotisserie/eris"
-
 	"pkg.world.dev/world-engine/cardinal/filter"
 	"pkg.world.dev/world-engine/cardinal/types"
 )
@@ -21,6 +19,7 @@ type cache struct {
 type EntitySearch interface {
 	Searchable
 	Where(componentFilter FilterFn) EntitySearch
+	WhereField(fieldFilter *FieldFilter) EntitySearch
 }
 
 type Searchable interface {
@@ -44,6 +43,9 @@ type Search struct {
 	archMatches             *cache
 	filter                  filter.ComponentFilter
 	componentPropertyFilter FilterFn
+	// fieldFilters are the filters on component fields passed to WhereField. They are answered with an index on the
+	// field when one is declared, and evaluated like any other filter otherwise.
+	fieldFilters []*FieldFilter
 }
 
 // interfaces restrict order of operations.
@@ -140,6 +142,18 @@ func (s *Search) Where(componentFilter FilterFn) EntitySearch {
 		archMatches:             &cache{},
 		filter:                  s.filter,
 		componentPropertyFilter: componentPropertyFilter,
+		fieldFilters:            s.fieldFilters,
+	}
+}
+
+// WhereField is like Where for a filter on a component field. If the field is indexed, the search only visits the
+// entities found in the index.
+func (s *Search) WhereField(fieldFilter *FieldFilter) EntitySearch {
+	return &Search{
+		archMatches:             &cache{},
+		filter:                  s.filter,
+		componentPropertyFilter: s.componentPropertyFilter,
+		fieldFilters:            append(slices.Clip(s.fieldFilters), fieldFilter),
 	}
 }
 
@@ -148,6 +162,30 @@ func (s *Search) Where(componentFilter FilterFn) EntitySearch {
 func (s *Search) Each(wCtx WorldContext, callback CallbackFn) (err error) {
 	defer func() { defer panicOnFatalError(wCtx, err) }()
 
+	return s.eachMatch(wCtx, callback)
+}
+
+// eachMatch calls the callback with every entity that matches the search, until the callback returns false. If the
+// search filters on indexed component fields, only the entities found in the indexes are checked. Otherwise, every
+// entity of the matching archetypes is checked.
+func (s *Search) eachMatch(wCtx WorldContext, callback CallbackFn) error {
+	candidates, unindexed := s.lookupIndexes(wCtx)
+	if candidates != nil {
+		for _, id := range candidates {
+			comps, err := wCtx.storeReader().GetComponentTypesForEntity(id)
+			if err != nil {
+				return err
+			}
+			if !s.filter.MatchesComponents(types.ConvertComponentMetadatasToComponents(comps)) {
+				continue
+			}
+			if s.matches(wCtx, id, unindexed) && !callback(id) {
+				return nil
+			}
+		}
+		return nil
+	}
+
 	result := s.evaluateSearch(wCtx)
 	iter := newSearchIterator(wCtx.storeReader(), result)
 	for iter.HasNext() {
@@ -156,27 +194,54 @@ func (s *Search) Each(wCtx WorldContext, callback CallbackFn) (err error) {
 			return err
 		}
 		for _, id := range entities {
-			var filterValue bool
-			if s.componentPropertyFilter != nil {
-				filterValue, err = s.componentPropertyFilter(wCtx, id)
-				if err != nil {
-					continue
-				}
-			} else {
-				filterValue = true
-			}
-
-			if filterValue {
-				cont := callback(id)
-				if !cont {
-					return nil
-				}
+			if s.matches(wCtx, id, unindexed) && !callback(id) {
+				return nil
 			}
 		}
 	}
 	return nil
 }
 
+// lookupIndexes returns the entities that match every field filter that can be answered by an index, in ascending
+// order, and the field filters that can't. The returned entities are nil if no filter can be answered by an index.
+func (s *Search) lookupIndexes(wCtx WorldContext) ([]types.EntityID, []*FieldFilter) {
+	var candidates []types.EntityID
+	var unindexed []*FieldFilter
+	for _, f := range s.fieldFilters {
+		ids, ok := f.lookup(wCtx)
+		if !ok {
+			unindexed = append(unindexed, f)
+			continue
+		}
+		if candidates == nil {
+			candidates = ids
+			continue
+		}
+		candidates = slices.DeleteFunc(candidates, func(id types.EntityID) bool {
+			_, found := slices.BinarySearch(ids, id)
+			return !found
+		})
+	}
+	return candidates, unindexed
+}
+
+// matches evaluates the Where filters that are not answered by an index. Quarantined entities never match.
+func (s *Search) matches(wCtx WorldContext, id types.EntityID, fieldFilters []*FieldFilter) bool {
+	if wCtx.isQuarantined(id) {
+		return false
+	}
+	for _, f := range fieldFilters {
+		if ok, err := f.Evaluate(wCtx, id); err != nil || !ok {
+			return false
+		}
+	}
+	if s.componentPropertyFilter == nil {
+		return true
+	}
+	ok, err := s.componentPropertyFilter(wCtx, id)
+	return err == nil && ok
+}
+
 func fastSortIDs(ids []types.EntityID) {
 	slices.Sort(ids)
 }
@@ -198,27 +263,12 @@ func (s *Search) Collect(wCtx WorldContext) ([]types.EntityID, error) {
 func (s *Search) Count(wCtx WorldContext) (ret int, err error) {
 	defer func() { defer panicOnFatalError(wCtx, err) }()
 
-	result := s.evaluateSearch(wCtx)
-	iter := newSearchIterator(wCtx.storeReader(), result)
-	for iter.HasNext() {
-		entities, err := iter.Next()
-		if err != nil {
-			return 0, err
-		}
-		for _, id := range entities {
-			var filterValue bool
-			if s.componentPropertyFilter != nil {
-				filterValue, err = s.componentPropertyFilter(wCtx, id)
-				if err != nil {
-					continue
-				}
-			} else {
-				filterValue = true
-			}
-			if filterValue {
-				ret++
-			}
-		}
+	err = s.eachMatch(wCtx, func(types.EntityID) bool {
+		ret++
+		return true
+	})
+	if err != nil {
+		return 0, err
 	}
 	return ret, nil
 }
@@ -227,32 +277,15 @@ func (s *Search) Count(wCtx WorldContext) (ret int, err error) {
 func (s *Search) First(wCtx WorldContext) (id types.EntityID, err error) {
 	defer func() { defer panicOnFatalError(wCtx, err) }()
 
-	result := s.evaluateSearch(wCtx)
-	iter := newSearchIterator(wCtx.storeReader(), result)
-	if !iter.HasNext() {
-		return badEntityID, eris.Wrap(err, "")
-	}
-	for iter.HasNext() {
-		entities, err := iter.Next()
-		if err != nil {
-			return 0, err
-		}
-		for _, id := range entities {
-			var filterValue bool
-			if s.componentPropertyFilter != nil {
-				filterValue, err = s.componentPropertyFilter(wCtx, id)
-				if err != nil {
-					continue
-				}
-			} else {
-				filterValue = true
-			}
-			if filterValue {
-				return id, nil
-			}
-		}
+	id = badEntityID
+	err = s.eachMatch(wCtx, func(matched types.EntityID) bool {
+		id = matched
+		return false
+	})
+	if err != nil {
+		return 0, err
 	}
-	return badEntityID, eris.Wrap(err, "")
+	return id, nil
 }
 
 func (s *Search) MustFirst(wCtx WorldContext) types.EntityID {
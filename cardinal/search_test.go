@@ -1,10 +1,12 @@
 package cardinal_test
 
 import (
+	"fmt"
 	"testing"
 
 	"pkg.world.dev/world-engine/assert"
 	"pkg.world.dev/world-engine/cardinal"
+	"pkg.world.dev/world-engine/cardinal/component"
 	"pkg.world.dev/world-engine/cardinal/filter"
 	"pkg.world.dev/world-engine/cardinal/types"
 )
@@ -496,3 +498,169 @@ func TestWhereClauseOnSearch(t *testing.T) {
 	assert.NilError(t, err)
 	assert.Equal(t, amt, 40)
 }
+
+type ItemTest struct {
+	Owner string
+	Cell  int
+}
+
+func (ItemTest) Name() string {
+	return "item"
+}
+
+func TestSearchWhereComponentField(t *testing.T) {
+	for _, indexed := range []bool{true, false} {
+		t.Run(fmt.Sprintf("indexed=%v", indexed), func(t *testing.T) {
+			tf := cardinal.NewTestFixture(t, nil)
+			world := tf.World
+			var opts []component.Option[ItemTest]
+			if indexed {
+				opts = append(opts,
+					component.WithHashIndex[ItemTest]("Owner"), component.WithOrderedIndex[ItemTest]("Cell"))
+			}
+			assert.NilError(t, cardinal.RegisterComponent[ItemTest](world, opts...))
+			assert.NilError(t, cardinal.RegisterComponent[AlphaTest](world))
+			tf.StartWorld()
+
+			wCtx := cardinal.NewWorldContext(world)
+			for i := 0; i < 10; i++ {
+				owner := []string{"alice", "bob"}[i%2]
+				_, err := cardinal.Create(wCtx, ItemTest{Owner: owner, Cell: i})
+				assert.NilError(t, err)
+				_, err = cardinal.Create(wCtx, ItemTest{Owner: owner, Cell: i}, AlphaTest{})
+				assert.NilError(t, err)
+			}
+
+			count := func(wCtx cardinal.WorldContext, f filter.ComponentFilter, where ...*cardinal.FieldFilter) int {
+				search := cardinal.NewSearch().Entity(f)
+				for _, fieldFilter := range where {
+					search = search.WhereField(fieldFilter)
+				}
+				n, err := search.Count(wCtx)
+				assert.NilError(t, err)
+				return n
+			}
+			check := func(wCtx cardinal.WorldContext) {
+				items := filter.Contains(filter.Component[ItemTest]())
+				onlyItems := filter.Exact(filter.Component[ItemTest]())
+				assert.Equal(t, count(wCtx, items, cardinal.FieldEquals[ItemTest]("Owner", "alice")), 10)
+				assert.Equal(t, count(wCtx, onlyItems, cardinal.FieldEquals[ItemTest]("Owner", "alice")), 5)
+				assert.Equal(t, count(wCtx, items, cardinal.FieldEquals[ItemTest]("Owner", "alice", "bob")), 20)
+				assert.Equal(t, count(wCtx, onlyItems, cardinal.FieldInRange[ItemTest]("Cell", 2, 5)), 4)
+				assert.Equal(t, count(wCtx, onlyItems, cardinal.FieldInRange[ItemTest]("Cell", nil, 2)), 3)
+				assert.Equal(t, count(wCtx, onlyItems,
+					cardinal.FieldInRange[ItemTest]("Cell", 2, 5),
+					cardinal.FieldEquals[ItemTest]("Owner", "bob"),
+				), 2)
+
+				// Field filters can be combined with other filters, and are evaluated for each entity there.
+				n, err := cardinal.NewSearch().Entity(items).
+					WhereField(cardinal.FieldEquals[ItemTest]("Owner", "bob")).
+					Where(cardinal.ComponentFilter[ItemTest](func(item ItemTest) bool { return item.Cell > 4 })).
+					Count(wCtx)
+				assert.NilError(t, err)
+				assert.Equal(t, n, 6)
+				n, err = cardinal.NewSearch().Entity(onlyItems).
+					Where(cardinal.OrFilter(
+						cardinal.FieldEquals[ItemTest]("Owner", "alice").Evaluate,
+						cardinal.FieldInRange[ItemTest]("Cell", 9, 9).Evaluate,
+					)).
+					Count(wCtx)
+				assert.NilError(t, err)
+				assert.Equal(t, n, 6)
+			}
+
+			// The pending changes are searchable before the tick is finalized.
+			check(wCtx)
+			tf.DoTick()
+			check(cardinal.NewWorldContext(world))
+			check(cardinal.NewReadOnlyWorldContext(world))
+
+			id, err := cardinal.NewSearch().Entity(filter.Exact(filter.Component[ItemTest]())).
+				WhereField(cardinal.FieldEquals[ItemTest]("Owner", "bob")).
+				WhereField(cardinal.FieldInRange[ItemTest]("Cell", 9, 9)).
+				First(wCtx)
+			assert.NilError(t, err)
+			assert.NilError(t, cardinal.SetComponent(wCtx, id, &ItemTest{Owner: "carol", Cell: 9}))
+			assert.Equal(t, count(wCtx, filter.All(), cardinal.FieldEquals[ItemTest]("Owner", "carol")), 1)
+			assert.Equal(t, count(wCtx, filter.All(), cardinal.FieldEquals[ItemTest]("Owner", "bob")), 9)
+		})
+	}
+}
+
+type LocationTest struct {
+	X, Y int
+}
+
+func (LocationTest) Name() string {
+	return "location"
+}
+
+func TestSpatialSearch(t *testing.T) {
+	tf := cardinal.NewTestFixture(t, nil)
+	world := tf.World
+	assert.NilError(t, cardinal.RegisterComponent[LocationTest](world))
+	assert.NilError(t, cardinal.RegisterComponent[AlphaTest](world))
+	assert.NilError(t, cardinal.RegisterSpatialIndex(world, func(loc LocationTest) (float64, float64) {
+		return float64(loc.X), float64(loc.Y)
+	}, cardinal.WithSpatialCellSize(4)))
+	tf.StartWorld()
+
+	// Entities on a 10x10 grid. The entities on even rows also have an AlphaTest component.
+	wCtx := cardinal.NewWorldContext(world)
+	at := map[[2]int]types.EntityID{}
+	for x := 0; x < 10; x++ {
+		for y := 0; y < 10; y++ {
+			comps := []types.Component{LocationTest{X: x, Y: y}}
+			if y%2 == 0 {
+				comps = append(comps, AlphaTest{})
+			}
+			id, err := cardinal.Create(wCtx, comps...)
+			assert.NilError(t, err)
+			at[[2]int{x, y}] = id
+		}
+	}
+
+	count := func(wCtx cardinal.WorldContext, search cardinal.Searchable) int {
+		n, err := search.Count(wCtx)
+		assert.NilError(t, err)
+		return n
+	}
+	alphas := cardinal.NewSearch().Entity(filter.Contains(filter.Component[AlphaTest]()))
+	check := func(wCtx cardinal.WorldContext) {
+		assert.Equal(t, count(wCtx, cardinal.WithinRadius[LocationTest](0, 0, 1.5)), 4)
+		assert.Equal(t, count(wCtx, cardinal.WithinRadius[LocationTest](5, 5, 2)), 13)
+		assert.Equal(t, count(wCtx, cardinal.WithinBox[LocationTest](2, 2, 4, 3)), 6)
+		assert.Equal(t, count(wCtx, cardinal.WithinBox[LocationTest](-100, -100, 100, 100)), 100)
+		assert.Equal(t, count(wCtx, cardinal.WithinBox[LocationTest](4, 4, 2, 2)), 0)
+		assert.Equal(t, count(wCtx, cardinal.And(cardinal.WithinBox[LocationTest](2, 2, 4, 3), alphas)), 3)
+		assert.Equal(t, count(wCtx, cardinal.Not(cardinal.WithinRadius[LocationTest](0, 0, 1.5))), 96)
+
+		ids, err := cardinal.KNearest[LocationTest](5.1, 5, 4).Collect(wCtx)
+		assert.NilError(t, err)
+		assert.DeepEqual(t, ids, []types.EntityID{at[[2]int{5, 5}], at[[2]int{6, 5}], at[[2]int{5, 4}], at[[2]int{5, 6}]})
+		ids, err = cardinal.KNearest[LocationTest](100, 100, 2).Collect(wCtx)
+		assert.NilError(t, err)
+		assert.DeepEqual(t, ids, []types.EntityID{at[[2]int{9, 9}], at[[2]int{8, 9}]})
+		assert.Equal(t, count(wCtx, cardinal.KNearest[LocationTest](0, 0, 1000)), 100)
+	}
+
+	// The pending changes are searchable before the tick is finalized.
+	check(wCtx)
+	tf.DoTick()
+	check(cardinal.NewWorldContext(world))
+	check(cardinal.NewReadOnlyWorldContext(world))
+
+	wCtx = cardinal.NewWorldContext(world)
+	assert.NilError(t, cardinal.SetComponent(wCtx, at[[2]int{9, 9}], &LocationTest{X: 0, Y: 1}))
+	assert.NilError(t, cardinal.Remove(wCtx, at[[2]int{1, 1}]))
+	id, err := cardinal.KNearest[LocationTest](100, 100, 1).First(wCtx)
+	assert.NilError(t, err)
+	assert.Equal(t, id, at[[2]int{8, 9}])
+	assert.Equal(t, count(wCtx, cardinal.WithinRadius[LocationTest](0, 0, 1.5)), 4)
+	tf.DoTick()
+	assert.Equal(t, count(cardinal.NewReadOnlyWorldContext(world), cardinal.WithinRadius[LocationTest](0, 0, 1.5)), 4)
+
+	_, err = cardinal.WithinRadius[AlphaTest](0, 0, 1).Count(cardinal.NewReadOnlyWorldContext(world))
+	assert.ErrorContains(t, err, "does not have a spatial index")
+}
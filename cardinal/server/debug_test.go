@@ -1,7 +1,12 @@
 package server_test
 
 import (
+	"bufio"
+	"bytes"
+	"context"
 	"encoding/json"
+	"net/http"
+	"strconv"
 
 	"pkg.world.dev/world-engine/cardinal"
 	"pkg.world.dev/world-engine/cardinal/server/handler"
@@ -60,3 +65,86 @@ func (s *ServerTestSuite) TestDebugStateQuery_NoState() {
 
 	s.Require().Equal(len(results), 0)
 }
+
+func (s *ServerTestSuite) TestDebugStateQuery_Pagination() {
+	s.setupWorld()
+	s.fixture.DoTick()
+	wCtx := cardinal.NewWorldContext(s.world)
+	ids, err := cardinal.CreateMany(wCtx, 10, LocationComponent{})
+	s.Require().NoError(err)
+	s.fixture.DoTick()
+
+	getPage := func(req handler.DebugStateRequest) ([]types.EntityID, string) {
+		res := s.fixture.Post("debug/state", req)
+		s.Require().Equal(200, res.StatusCode)
+		var results []types.DebugStateElement
+		s.Require().NoError(json.NewDecoder(res.Body).Decode(&results))
+		pageIDs := make([]types.EntityID, 0, len(results))
+		for _, result := range results {
+			s.Require().Contains(result.Components, "location")
+			pageIDs = append(pageIDs, result.ID)
+		}
+		return pageIDs, res.Header.Get(handler.NextCursorHeader)
+	}
+
+	var got []types.EntityID
+	req := handler.DebugStateRequest{Limit: 4, Components: []string{"location"}}
+	for pages := 1; ; pages++ {
+		pageIDs, cursor := getPage(req)
+		s.Require().LessOrEqual(len(pageIDs), 4)
+		got = append(got, pageIDs...)
+		if cursor == "" {
+			s.Require().Equal(3, pages)
+			break
+		}
+		req.Cursor = cursor
+	}
+	s.Require().Equal(ids, got)
+
+	pageIDs, cursor := getPage(handler.DebugStateRequest{Limit: 2, Descending: true})
+	s.Require().Equal([]types.EntityID{ids[9], ids[8]}, pageIDs)
+	pageIDs, _ = getPage(handler.DebugStateRequest{Limit: 2, Descending: true, Cursor: cursor})
+	s.Require().Equal([]types.EntityID{ids[7], ids[6]}, pageIDs)
+
+	for _, req := range []handler.DebugStateRequest{
+		{Cursor: "not-a-cursor"},
+		{Components: []string{"unknown"}},
+		{Limit: -1},
+	} {
+		res := s.fixture.Post("debug/state", req)
+		s.Require().Equal(400, res.StatusCode)
+	}
+}
+
+func (s *ServerTestSuite) TestDebugStateQuery_NDJSON() {
+	s.setupWorld()
+	s.fixture.DoTick()
+	wCtx := cardinal.NewWorldContext(s.world)
+	ids, err := cardinal.CreateMany(wCtx, 5, LocationComponent{})
+	s.Require().NoError(err)
+	s.fixture.DoTick()
+
+	body, err := json.Marshal(handler.DebugStateRequest{Limit: 3, Components: []string{"location"}})
+	s.Require().NoError(err)
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost,
+		"http://"+s.fixture.BaseURL+"/debug/state", bytes.NewReader(body))
+	s.Require().NoError(err)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", handler.NDJSONContentType)
+	res, err := http.DefaultClient.Do(req)
+	s.Require().NoError(err)
+	defer res.Body.Close()
+	s.Require().Equal(200, res.StatusCode)
+	s.Require().Equal(handler.NDJSONContentType, res.Header.Get("Content-Type"))
+	s.Require().Equal(strconv.FormatUint(uint64(ids[2]), 10), res.Header.Get(handler.NextCursorHeader))
+
+	var got []types.EntityID
+	scanner := bufio.NewScanner(res.Body)
+	for scanner.Scan() {
+		var element types.DebugStateElement
+		s.Require().NoError(json.Unmarshal(scanner.Bytes(), &element))
+		got = append(got, element.ID)
+	}
+	s.Require().NoError(scanner.Err())
+	s.Require().Equal(ids[:3], got)
+}
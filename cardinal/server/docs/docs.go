@@ -59,7 +59,8 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "application/x-ndjson"
                 ],
                 "tags": [
                     "debug"
@@ -91,7 +92,7 @@ const docTemplate = `{
         },
         "/events": {
             "get": {
-                "description": "Establishes a new websocket connection to retrieve system events",
+                "description": "Receives the results of every tick. Events sent to a persona, and the receipts of its transactions,\nare only received after authenticating as the persona: send {\"type\": \"challenge\"}, then\n{\"type\": \"authenticate\", \"transaction\": ...} with a transaction signed by the persona whose body is\n{\"challenge\": ...}. Send {\"type\": \"subscribe\", \"topic\": ...} to receive the events of a topic.",
                 "produces": [
                     "application/json"
                 ],
@@ -108,7 +109,7 @@ const docTemplate = `{
         },
         "/health": {
             "get": {
-                "description": "Retrieves the status of the server and game loop",
+                "description": "Retrieves the status of the server and game loop, the failures of the systems, and the durations of\nthe ticks",
                 "produces": [
                     "application/json"
                 ],
@@ -123,6 +124,46 @@ const docTemplate = `{
                 }
             }
         },
+        "/metrics": {
+            "get": {
+                "description": "Retrieves the durations of the ticks and of the systems, the size of the transaction pool, the\ntransactions and receipt errors of each message, the number of entities and archetypes, the latency\nof the redis pipelines and the number of websocket clients",
+                "produces": [
+                    "text/plain"
+                ],
+                "summary": "Retrieves the metrics of the world in the Prometheus text format",
+                "responses": {
+                    "200": {
+                        "description": "Metrics in the Prometheus text format",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/openapi.json": {
+            "get": {
+                "description": "Retrieves an OpenAPI 3.1 document generated from the registered messages, queries and components,\nwith the schemas of the bodies of the transactions, of the requests and replies of the queries, and\nof the components",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Retrieves the OpenAPI document of the game",
+                "responses": {
+                    "200": {
+                        "description": "OpenAPI document of the game",
+                        "schema": {
+                            "type": "object"
+                        }
+                    },
+                    "500": {
+                        "description": "The schema of a registered type could not be generated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
         "/query/receipts/list": {
             "post": {
                 "description": "Retrieves all transaction receipts",
@@ -160,6 +201,195 @@ const docTemplate = `{
                 }
             }
         },
+        "/query/receipts/persona": {
+            "post": {
+                "description": "Retrieves the receipts of the transactions of a persona in a range of ticks from the receipt log.\nThe request is a transaction signed by the persona, whose body is a ListPersonaReceiptsRequest.\nRequires CARDINAL_RECEIPT_LOG.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Retrieves the receipts of the transactions of a persona from the receipt log",
+                "parameters": [
+                    {
+                        "description": "Transaction signed by the persona",
+                        "name": "txBody",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/sign.Transaction"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "List of receipts",
+                        "schema": {
+                            "$ref": "#/definitions/cardinal_server_handler.ListPersonaReceiptsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - signature was invalid",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - duplicate message",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "408": {
+                        "description": "Request Timeout - message expired",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "501": {
+                        "description": "Receipt log is disabled",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/query/receipts/range": {
+            "post": {
+                "description": "Retrieves the events sent to every client, and the receipts of the transactions of a persona, of a\nrange of ticks from the receipt log. The request is a transaction signed by the persona, whose body\nis a ListTickHistoryRequest. Requires CARDINAL_RECEIPT_LOG.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Retrieves the events and the receipts of a persona of a range of ticks from the receipt log",
+                "parameters": [
+                    {
+                        "description": "Transaction signed by the persona",
+                        "name": "txBody",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/sign.Transaction"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "List of ticks",
+                        "schema": {
+                            "$ref": "#/definitions/cardinal_server_handler.ListTickHistoryResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - signature was invalid",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - duplicate message",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "408": {
+                        "description": "Request Timeout - message expired",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "501": {
+                        "description": "Receipt log is disabled",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/query/receipts/tx": {
+            "post": {
+                "description": "Retrieves the receipt of a transaction from the receipt log. The request is a transaction signed by\nthe persona that submitted the transaction, whose body is a GetReceiptRequest.\nRequires CARDINAL_RECEIPT_LOG.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Retrieves the receipt of a transaction from the receipt log",
+                "parameters": [
+                    {
+                        "description": "Transaction signed by the persona",
+                        "name": "txBody",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/sign.Transaction"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Receipt of the transaction",
+                        "schema": {
+                            "$ref": "#/definitions/storage.ReceiptRecord"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - signature was invalid",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden - duplicate message",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Receipt not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "408": {
+                        "description": "Request Timeout - message expired",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "501": {
+                        "description": "Receipt log is disabled",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
         "/query/{queryGroup}/{queryName}": {
             "post": {
                 "description": "Executes a query",
@@ -211,6 +441,84 @@ const docTemplate = `{
                 }
             }
         },
+        "/subscriptions": {
+            "get": {
+                "description": "Send {\"type\": \"subscribe\", \"id\": ..., \"cql\": ...} or {\"type\": \"subscribe\", \"id\": ...,\n\"components\": [...]} to receive a snapshot of the selected entities, and then the changes of their\ncomponents after every tick. Send {\"type\": \"unsubscribe\", \"id\": ...} to stop.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Establishes a new websocket connection to subscribe to the changes of entities",
+                "responses": {
+                    "101": {
+                        "description": "Switch protocol to ws",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/tx/batch": {
+            "post": {
+                "description": "Submits an ordered list of messages of any type under a single signature. The body of the\ntransaction is a sign.Batch. The messages are processed in the same tick, or not at all if one of\nthem returns an error.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Submits a batch transaction",
+                "parameters": [
+                    {
+                        "description": "Transaction details \u0026 batch to be submitted",
+                        "name": "txBody",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/sign.Transaction"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Transaction hash and tick",
+                        "schema": {
+                            "$ref": "#/definitions/cardinal_server_handler.PostTransactionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request parameter",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - signature was invalid",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "408": {
+                        "description": "Request Timeout - message expired",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests - rejected by the tx pool limits",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
         "/tx/game/{txName}": {
             "post": {
                 "description": "Submits a transaction",
@@ -263,6 +571,12 @@ const docTemplate = `{
                         "schema": {
                             "type": "string"
                         }
+                    },
+                    "429": {
+                        "description": "Too Many Requests - rejected by the tx pool limits",
+                        "schema": {
+                            "type": "string"
+                        }
                     }
                 }
             }
@@ -319,6 +633,12 @@ const docTemplate = `{
                             "type": "string"
                         }
                     },
+                    "429": {
+                        "description": "Too Many Requests - rejected by the tx pool limits",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error - unexpected cache errors",
                         "schema": {
@@ -387,6 +707,44 @@ const docTemplate = `{
                         "schema": {
                             "type": "string"
                         }
+                    },
+                    "429": {
+                        "description": "Too Many Requests - rejected by the tx pool limits",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/tx/{txHash}": {
+            "get": {
+                "description": "Reports whether a transaction is pending, processed, failed or unknown, along with the tick it was\nprocessed in and its receipt. Receipts older than the receipt history are only found when\nCARDINAL_RECEIPT_LOG is enabled.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Retrieves the status of a transaction",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Hash of the transaction",
+                        "name": "txHash",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Status of the transaction",
+                        "schema": {
+                            "$ref": "#/definitions/pkg_world_dev_world-engine_cardinal_types.TransactionStatus"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
                     }
                 }
             }
@@ -439,16 +797,70 @@ const docTemplate = `{
             }
         },
         "cardinal_server_handler.DebugStateRequest": {
-            "type": "object"
+            "type": "object",
+            "properties": {
+                "components": {
+                    "description": "Components only returns the entities that have every one of the named components.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "cursor": {
+                    "description": "Cursor is the value of the X-Next-Cursor header of the previous page. The first page is returned if it is empty.",
+                    "type": "string"
+                },
+                "descending": {
+                    "description": "Descending orders the entities by descending ID instead of ascending ID.",
+                    "type": "boolean"
+                },
+                "limit": {
+                    "description": "Limit is the maximum number of entities to return. Every entity is returned if it is 0.",
+                    "type": "integer"
+                }
+            }
         },
         "cardinal_server_handler.GetHealthResponse": {
             "type": "object",
             "properties": {
+                "abortedTicks": {
+                    "type": "integer"
+                },
                 "isGameLoopRunning": {
                     "type": "boolean"
                 },
+                "isHealthy": {
+                    "description": "IsHealthy is false if the last tick was aborted by a failing system, or if the circuit of a system is open.",
+                    "type": "boolean"
+                },
                 "isServerRunning": {
                     "type": "boolean"
+                },
+                "lastAbortError": {
+                    "type": "string"
+                },
+                "lastAbortedTick": {
+                    "type": "integer"
+                },
+                "quarantinedEntities": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "systems": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/pkg_world_dev_world-engine_cardinal_types.SystemHealth"
+                    }
+                },
+                "ticks": {
+                    "description": "Ticks reports the durations of the ticks against the budget of the tick rate, and the ticks that overran it.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/pkg_world_dev_world-engine_cardinal_types.TickHealth"
+                        }
+                    ]
                 }
             }
         },
@@ -479,6 +891,46 @@ const docTemplate = `{
                 }
             }
         },
+        "cardinal_server_handler.ListPersonaReceiptsResponse": {
+            "type": "object",
+            "properties": {
+                "endTick": {
+                    "type": "integer"
+                },
+                "personaTag": {
+                    "type": "string"
+                },
+                "receipts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/storage.ReceiptRecord"
+                    }
+                },
+                "startTick": {
+                    "type": "integer"
+                }
+            }
+        },
+        "cardinal_server_handler.ListTickHistoryResponse": {
+            "type": "object",
+            "properties": {
+                "endTick": {
+                    "type": "integer"
+                },
+                "personaTag": {
+                    "type": "string"
+                },
+                "startTick": {
+                    "type": "integer"
+                },
+                "ticks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/storage.TickRecord"
+                    }
+                }
+            }
+        },
         "cardinal_server_handler.ListTxReceiptsRequest": {
             "type": "object",
             "properties": {
@@ -524,6 +976,12 @@ const docTemplate = `{
                         "type": "string"
                     }
                 },
+                "fee": {
+                    "type": "integer"
+                },
+                "feeRefunded": {
+                    "type": "boolean"
+                },
                 "result": {},
                 "tick": {
                     "type": "integer"
@@ -572,6 +1030,136 @@ const docTemplate = `{
                 }
             }
         },
+        "pkg_world_dev_world-engine_cardinal_types.SystemDuration": {
+            "type": "object",
+            "properties": {
+                "durationMs": {
+                    "type": "number"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "pkg_world_dev_world-engine_cardinal_types.SystemHealth": {
+            "type": "object",
+            "properties": {
+                "circuitOpen": {
+                    "description": "CircuitOpen reports whether the system is not run because it failed too many times in a row.",
+                    "type": "boolean"
+                },
+                "consecutiveFailures": {
+                    "type": "integer"
+                },
+                "failures": {
+                    "type": "integer"
+                },
+                "lastError": {
+                    "type": "string"
+                },
+                "lastFailedTick": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "policy": {
+                    "description": "Policy is the failure policy of the system.",
+                    "type": "string"
+                }
+            }
+        },
+        "pkg_world_dev_world-engine_cardinal_types.TickHealth": {
+            "type": "object",
+            "properties": {
+                "budgetMs": {
+                    "type": "number"
+                },
+                "caughtUpTicks": {
+                    "type": "integer"
+                },
+                "lastOverrunTick": {
+                    "description": "LastOverrunTick is the last tick that overran its budget, and SlowestSystems holds its slowest systems.",
+                    "type": "integer"
+                },
+                "lastTickMs": {
+                    "type": "number"
+                },
+                "maxTickMs": {
+                    "type": "number"
+                },
+                "overrunPolicy": {
+                    "description": "OverrunPolicy decides what happens to the ticks that should have started while a tick overran its budget.",
+                    "type": "string"
+                },
+                "overruns": {
+                    "description": "Overruns is the number of ticks that took longer than the budget. SkippedTicks and CaughtUpTicks are the\nnumber of ticks that should have started during them, and were skipped or run late.",
+                    "type": "integer"
+                },
+                "skippedTicks": {
+                    "type": "integer"
+                },
+                "slowestSystems": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/pkg_world_dev_world-engine_cardinal_types.SystemDuration"
+                    }
+                }
+            }
+        },
+        "pkg_world_dev_world-engine_cardinal_types.TransactionStatus": {
+            "type": "object",
+            "properties": {
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "fee": {
+                    "description": "Fee is the fee that was charged for the transaction.",
+                    "type": "integer"
+                },
+                "feeRefunded": {
+                    "description": "FeeRefunded reports whether the fee was refunded because the message returned an error.",
+                    "type": "boolean"
+                },
+                "result": {
+                    "type": "object"
+                },
+                "status": {
+                    "$ref": "#/definitions/pkg_world_dev_world-engine_cardinal_types.TxStatus"
+                },
+                "tick": {
+                    "description": "Tick is the tick the transaction was processed in. It is only set once the transaction was processed.",
+                    "type": "integer"
+                },
+                "txHash": {
+                    "type": "string"
+                }
+            }
+        },
+        "pkg_world_dev_world-engine_cardinal_types.TxStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "processed",
+                "failed",
+                "unknown"
+            ],
+            "x-enum-comments": {
+                "TxStatusFailed": "TxStatusFailed transactions were processed, and their receipt has errors.",
+                "TxStatusPending": "TxStatusPending transactions are waiting for, or being processed in, a tick.",
+                "TxStatusProcessed": "TxStatusProcessed transactions were processed without errors.",
+                "TxStatusUnknown": "TxStatusUnknown transactions were never received, or their receipt is no longer kept."
+            },
+            "x-enum-varnames": [
+                "TxStatusPending",
+                "TxStatusProcessed",
+                "TxStatusFailed",
+                "TxStatusUnknown"
+            ]
+        },
         "sign.Transaction": {
             "type": "object",
             "properties": {
@@ -579,6 +1167,10 @@ const docTemplate = `{
                     "description": "json string",
                     "type": "object"
                 },
+                "fee": {
+                    "description": "an optional fee paid to process the transaction",
+                    "type": "integer"
+                },
                 "namespace": {
                     "type": "string"
                 },
@@ -598,6 +1190,57 @@ const docTemplate = `{
                     "type": "integer"
                 }
             }
+        },
+        "storage.ReceiptRecord": {
+            "type": "object",
+            "properties": {
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "fee": {
+                    "description": "Fee is the fee that was charged for the transaction.",
+                    "type": "integer"
+                },
+                "feeRefunded": {
+                    "description": "FeeRefunded reports whether the fee was refunded because the message returned an error.",
+                    "type": "boolean"
+                },
+                "personaTag": {
+                    "type": "string"
+                },
+                "result": {
+                    "type": "object"
+                },
+                "tick": {
+                    "type": "integer"
+                },
+                "txHash": {
+                    "type": "string"
+                }
+            }
+        },
+        "storage.TickRecord": {
+            "type": "object",
+            "properties": {
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "type": "object"
+                    }
+                },
+                "receipts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/storage.ReceiptRecord"
+                    }
+                },
+                "tick": {
+                    "type": "integer"
+                }
+            }
         }
     }
 }`
@@ -6,11 +6,15 @@ import (
 	"sync"
 	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/gorilla/websocket"
 
 	"pkg.world.dev/world-engine/assert"
 	"pkg.world.dev/world-engine/cardinal"
+	"pkg.world.dev/world-engine/cardinal/server/handler"
+	"pkg.world.dev/world-engine/sign"
 )
 
 type SendEnergyTx struct {
@@ -116,3 +120,138 @@ func TestEventsThroughSystems(t *testing.T) {
 func wsURL(addr, path string) string {
 	return fmt.Sprintf("ws://%s/%s", addr, path)
 }
+
+func (s *ServerTestSuite) TestEventsAreRoutedToPersonasAndTopics() {
+	s.setupWorld()
+	personaTag := "alice"
+	s.Require().NoError(cardinal.RegisterSystems(s.world, func(wCtx cardinal.WorldContext) error {
+		if err := wCtx.EmitEvent(map[string]any{"public": true}); err != nil {
+			return err
+		}
+		if err := wCtx.EmitEventToPersona(personaTag, map[string]any{"persona": true}); err != nil {
+			return err
+		}
+		return wCtx.EmitEventToTopic("match", map[string]any{"topic": true})
+	}))
+	s.fixture.DoTick()
+	s.createPersona(personaTag)
+
+	persona := s.dialEvents()
+	defer persona.Close()
+	s.authenticateEvents(persona, personaTag)
+
+	subscriber := s.dialEvents()
+	defer subscriber.Close()
+	s.Require().NoError(subscriber.WriteJSON(handler.EventRequestMessage{
+		Type:  handler.EventMessageSubscribe,
+		Topic: "match",
+	}))
+	s.Require().Equal(handler.EventMessageSubscribed, s.readEventMessage(subscriber).Type)
+
+	moveMessage, ok := s.world.GetMessageByFullName("game." + moveMsgName)
+	s.Require().True(ok)
+	s.runTx(personaTag, moveMessage, MoveMsgInput{Direction: "up"})
+
+	results := s.readTickResults(persona)
+	s.Require().Equal([]string{`{"public":true}`, `{"persona":true}`}, eventStrings(results))
+	s.Require().Len(results.Receipts, 1)
+
+	results = s.readTickResults(subscriber)
+	s.Require().Equal([]string{`{"public":true}`, `{"topic":true}`}, eventStrings(results))
+	s.Require().Empty(results.Receipts)
+}
+
+func (s *ServerTestSuite) TestEventsDoNotIncludeTheStateDiff() {
+	s.setupWorld()
+	s.fixture.DoTick()
+	personaTag := s.CreateRandomPersona()
+
+	conn := s.dialEvents()
+	defer conn.Close()
+
+	moveMessage, ok := s.world.GetMessageByFullName("game." + moveMsgName)
+	s.Require().True(ok)
+	s.runTx(personaTag, moveMessage, MoveMsgInput{Direction: "up"})
+
+	s.Require().NoError(conn.SetReadDeadline(time.Now().Add(5 * time.Second)))
+	var results map[string]json.RawMessage
+	s.Require().NoError(conn.ReadJSON(&results))
+	s.Require().NotContains(results, "Diff")
+	s.Require().NotEqual(`""`, string(results["StateRoot"]))
+}
+
+func (s *ServerTestSuite) TestEventsAuthenticationRequiresTheChallenge() {
+	s.setupWorld()
+	s.fixture.DoTick()
+	personaTag := s.CreateRandomPersona()
+
+	conn := s.dialEvents()
+	defer conn.Close()
+
+	// Without a challenge
+	tx, err := sign.NewTransaction(s.privateKey, personaTag, s.world.Namespace(),
+		handler.EventChallengeBody{Challenge: "guess"})
+	s.Require().NoError(err)
+	s.Require().NoError(conn.WriteJSON(map[string]any{"type": handler.EventMessageAuthenticate, "transaction": tx}))
+	s.Require().Equal(handler.EventMessageError, s.readEventMessage(conn).Type)
+
+	// With the wrong answer
+	s.Require().NoError(conn.WriteJSON(handler.EventRequestMessage{Type: handler.EventMessageChallenge}))
+	s.Require().Equal(handler.EventMessageChallenge, s.readEventMessage(conn).Type)
+	s.Require().NoError(conn.WriteJSON(map[string]any{"type": handler.EventMessageAuthenticate, "transaction": tx}))
+	s.Require().Equal(handler.EventMessageError, s.readEventMessage(conn).Type)
+
+	// Signed by another key
+	s.Require().NoError(conn.WriteJSON(handler.EventRequestMessage{Type: handler.EventMessageChallenge}))
+	challenge := s.readEventMessage(conn).Challenge
+	otherKey, err := crypto.GenerateKey()
+	s.Require().NoError(err)
+	tx, err = sign.NewTransaction(otherKey, personaTag, s.world.Namespace(),
+		handler.EventChallengeBody{Challenge: challenge})
+	s.Require().NoError(err)
+	s.Require().NoError(conn.WriteJSON(map[string]any{"type": handler.EventMessageAuthenticate, "transaction": tx}))
+	s.Require().Equal(handler.EventMessageError, s.readEventMessage(conn).Type)
+}
+
+func (s *ServerTestSuite) dialEvents() *websocket.Conn {
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL(s.fixture.BaseURL, "events"), nil)
+	s.Require().NoError(err)
+	return conn
+}
+
+// authenticateEvents answers a challenge with a transaction signed by the persona.
+func (s *ServerTestSuite) authenticateEvents(conn *websocket.Conn, personaTag string) {
+	s.Require().NoError(conn.WriteJSON(handler.EventRequestMessage{Type: handler.EventMessageChallenge}))
+	msg := s.readEventMessage(conn)
+	s.Require().Equal(handler.EventMessageChallenge, msg.Type)
+
+	tx, err := sign.NewTransaction(s.privateKey, personaTag, s.world.Namespace(),
+		handler.EventChallengeBody{Challenge: msg.Challenge})
+	s.Require().NoError(err)
+	s.Require().NoError(conn.WriteJSON(map[string]any{"type": handler.EventMessageAuthenticate, "transaction": tx}))
+	msg = s.readEventMessage(conn)
+	s.Require().Equal(handler.EventMessageAuthenticated, msg.Type, msg.Error)
+	s.Require().Equal(personaTag, msg.PersonaTag)
+}
+
+func (s *ServerTestSuite) readEventMessage(conn *websocket.Conn) handler.EventMessage {
+	s.Require().NoError(conn.SetReadDeadline(time.Now().Add(5 * time.Second)))
+	var msg handler.EventMessage
+	s.Require().NoError(conn.ReadJSON(&msg))
+	return msg
+}
+
+func (s *ServerTestSuite) readTickResults(conn *websocket.Conn) cardinal.TickResults {
+	s.Require().NoError(conn.SetReadDeadline(time.Now().Add(5 * time.Second)))
+	var results cardinal.TickResults
+	s.Require().NoError(conn.ReadJSON(&results))
+	return results
+}
+
+func eventStrings(results cardinal.TickResults) []string {
+	events := make([]string, len(results.Events))
+	for i, event := range results.Events {
+		events[i] = string(event)
+	}
+	return events
+}
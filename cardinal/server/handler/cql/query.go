@@ -0,0 +1,418 @@
+//nolint:govet // there is too much issues with incompatible struct tags
+package cql
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/alecthomas/participle/v2"
+	"github.com/rotisserie/eris"
+
+	"pkg.world.dev/world-engine/cardinal/filter"
+	"pkg.world.dev/world-engine/cardinal/types"
+)
+
+var internalCQLQueryParser = participle.MustBuild[cqlQuery](participle.Unquote("String"))
+
+// cqlQuery is a full CQL query. Only the term is required, e.g.
+//
+//	SELECT health FROM CONTAINS(health) WHERE health.HP < 10 ORDER BY health.HP DESC LIMIT 5 OFFSET 10
+type cqlQuery struct {
+	Select  []*cqlComponent `("SELECT" @@ ("," @@)* "FROM")?`
+	Term    *cqlTerm        `@@`
+	Where   *cqlPredicate   `("WHERE" @@)?`
+	OrderBy []*cqlOrder     `("ORDER" "BY" @@ ("," @@)*)?`
+	Limit   *int            `("LIMIT" @Int)?`
+	Offset  *int            `("OFFSET" @Int)?`
+}
+
+type cqlPredicate struct {
+	Left  *cqlAndPredicate   `@@`
+	Right []*cqlAndPredicate `("OR" @@)*`
+}
+
+type cqlAndPredicate struct {
+	Left  *cqlUnaryPredicate   `@@`
+	Right []*cqlUnaryPredicate `("AND" @@)*`
+}
+
+type cqlUnaryPredicate struct {
+	Not           *cqlUnaryPredicate `  "NOT" @@`
+	Subexpression *cqlPredicate      `| "(" @@ ")"`
+	Comparison    *cqlComparison     `| @@`
+}
+
+type cqlComparison struct {
+	Field   *cqlField     `@@`
+	Between *cqlBetween   `( "BETWEEN" @@`
+	In      []*cqlLiteral `| "IN" "(" @@ ("," @@)* ")"`
+	Op      string        `| @("<" "=" | ">" "=" | "!" "=" | "=" | "<" | ">")`
+	Value   *cqlLiteral   `  @@ )`
+}
+
+type cqlBetween struct {
+	Low  *cqlLiteral `@@`
+	High *cqlLiteral `"AND" @@`
+}
+
+type cqlField struct {
+	Component string   `@Ident`
+	Path      []string `("." @Ident)+`
+}
+
+type cqlOrder struct {
+	Field     *cqlField `@@`
+	Direction string    `@("ASC" | "DESC")?`
+}
+
+type cqlLiteral struct {
+	String *string     `  @String`
+	Number *float64    `| @("-"? (Float | Int))`
+	Bool   *cqlBoolean `| @("true" | "false")`
+}
+
+type cqlBoolean bool
+
+func (b *cqlBoolean) Capture(values []string) error {
+	*b = values[0] == "true"
+	return nil
+}
+
+func (l *cqlLiteral) value() any {
+	switch {
+	case l.String != nil:
+		return *l.String
+	case l.Number != nil:
+		return *l.Number
+	case l.Bool != nil:
+		return bool(*l.Bool)
+	}
+	return nil
+}
+
+// ComponentGetter returns the JSON value of the named component of an entity. ok is false if the entity does not
+// have the component.
+type ComponentGetter func(name string) (value json.RawMessage, ok bool, err error)
+
+// Field is a path to a value nested inside a component, e.g. health.Stats.HP.
+type Field struct {
+	Component string
+	Path      []string
+}
+
+func (f Field) String() string {
+	return f.Component + "." + strings.Join(f.Path, ".")
+}
+
+// Value returns the value of the field for an entity. Numbers are returned as float64. ok is false if the entity
+// does not have the component, or the component does not have the field.
+func (f Field) Value(getComponent ComponentGetter) (value any, ok bool, err error) {
+	bz, ok, err := getComponent(f.Component)
+	if err != nil || !ok {
+		return nil, false, err
+	}
+	if err := json.Unmarshal(bz, &value); err != nil {
+		return nil, false, eris.Wrapf(err, "failed to unmarshal component %q", f.Component)
+	}
+	for _, name := range f.Path {
+		object, isObject := value.(map[string]any)
+		if !isObject {
+			return nil, false, nil
+		}
+		if value, ok = object[name]; !ok {
+			return nil, false, nil
+		}
+	}
+	return value, true, nil
+}
+
+// Predicate is a condition on the component values of an entity.
+type Predicate interface {
+	Evaluate(getComponent ComponentGetter) (bool, error)
+}
+
+type andPredicate []Predicate
+
+func (p andPredicate) Evaluate(getComponent ComponentGetter) (bool, error) {
+	for _, predicate := range p {
+		ok, err := predicate.Evaluate(getComponent)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+type orPredicate []Predicate
+
+func (p orPredicate) Evaluate(getComponent ComponentGetter) (bool, error) {
+	for _, predicate := range p {
+		ok, err := predicate.Evaluate(getComponent)
+		if err != nil || ok {
+			return ok, err
+		}
+	}
+	return false, nil
+}
+
+type notPredicate struct {
+	predicate Predicate
+}
+
+func (p notPredicate) Evaluate(getComponent ComponentGetter) (bool, error) {
+	ok, err := p.predicate.Evaluate(getComponent)
+	return !ok, err
+}
+
+// comparisonPredicate is true if the field exists and match returns true for the result of comparing the field to
+// any of the values.
+type comparisonPredicate struct {
+	field  Field
+	values []any
+	match  func(values []comparison) bool
+}
+
+func (p comparisonPredicate) Evaluate(getComponent ComponentGetter) (bool, error) {
+	value, ok, err := p.field.Value(getComponent)
+	if err != nil || !ok {
+		return false, err
+	}
+	comparisons := make([]comparison, 0, len(p.values))
+	for _, other := range p.values {
+		comparisons = append(comparisons, compare(value, other))
+	}
+	return p.match(comparisons), nil
+}
+
+// comparison is the result of comparing two values. Values of different types can only be compared for inequality.
+type comparison struct {
+	order      int
+	comparable bool
+}
+
+func compare(a, b any) comparison {
+	switch a := a.(type) {
+	case float64:
+		if b, ok := b.(float64); ok {
+			return comparison{order: compareOrdered(a, b), comparable: true}
+		}
+	case string:
+		if b, ok := b.(string); ok {
+			return comparison{order: strings.Compare(a, b), comparable: true}
+		}
+	case bool:
+		if b, ok := b.(bool); ok {
+			return comparison{order: compareBool(a, b), comparable: true}
+		}
+	}
+	return comparison{}
+}
+
+func compareOrdered[T float64 | int](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func compareBool(a, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case b:
+		return -1
+	}
+	return 1
+}
+
+// CompareValues orders two field values. Numbers, strings and booleans are compared with values of the same type;
+// values of different types are ordered by type, and missing values (nil) come last.
+func CompareValues(a, b any) int {
+	if c := compare(a, b); c.comparable {
+		return c.order
+	}
+	return compareOrdered(typeRank(a), typeRank(b))
+}
+
+func typeRank(value any) int {
+	switch value.(type) {
+	case bool:
+		return 0
+	case float64:
+		return 1
+	case string:
+		return 2
+	case nil:
+		return 4
+	}
+	return 3
+}
+
+var comparisonOperators = map[string]func(c comparison) bool{
+	"=":  func(c comparison) bool { return c.comparable && c.order == 0 },
+	"!=": func(c comparison) bool { return !c.comparable || c.order != 0 },
+	"<":  func(c comparison) bool { return c.comparable && c.order < 0 },
+	"<=": func(c comparison) bool { return c.comparable && c.order <= 0 },
+	">":  func(c comparison) bool { return c.comparable && c.order > 0 },
+	">=": func(c comparison) bool { return c.comparable && c.order >= 0 },
+}
+
+// Order is a field to sort the results of a query by.
+type Order struct {
+	Field      Field
+	Descending bool
+}
+
+// Compare orders two values of the field according to the sort direction. Missing values (nil) always come last.
+func (o Order) Compare(a, b any) int {
+	if o.Descending && a != nil && b != nil {
+		return CompareValues(b, a)
+	}
+	return CompareValues(a, b)
+}
+
+// Query is a parsed CQL query.
+type Query struct {
+	// Filter selects the entities by their archetype.
+	Filter filter.ComponentFilter
+	// Where is the condition on the component values of the entities. It is nil if the query has no WHERE clause.
+	Where Predicate
+	// OrderBy is the list of fields to sort the results by, in order of priority.
+	OrderBy []Order
+	// Limit is the maximum number of results, or nil if there is no limit.
+	Limit *int
+	// Offset is the number of results to skip.
+	Offset int
+	// Select is the list of components to return for each entity. It is empty if all components are returned.
+	Select []types.Component
+}
+
+// ParseQuery parses a full CQL query, which is an archetype filter as accepted by Parse, optionally followed by
+// WHERE, ORDER BY, LIMIT and OFFSET clauses, and optionally preceded by SELECT ... FROM.
+func ParseQuery(cqlText string, stringToComponent componentByName) (*Query, error) {
+	parsed, err := internalCQLQueryParser.ParseString("", cqlText)
+	if err != nil {
+		return nil, eris.Wrap(err, "failed to parse CQL string")
+	}
+	resultFilter, err := termToComponentFilter(parsed.Term, stringToComponent)
+	if err != nil {
+		return nil, err
+	}
+	query := &Query{Filter: resultFilter, Limit: parsed.Limit}
+
+	if parsed.Where != nil {
+		if query.Where, err = predicateToPredicate(parsed.Where, stringToComponent); err != nil {
+			return nil, err
+		}
+	}
+	for _, order := range parsed.OrderBy {
+		field, err := toField(order.Field, stringToComponent)
+		if err != nil {
+			return nil, err
+		}
+		query.OrderBy = append(query.OrderBy, Order{Field: field, Descending: order.Direction == "DESC"})
+	}
+	if query.Limit != nil && *query.Limit < 0 {
+		return nil, eris.New("LIMIT cannot be negative")
+	}
+	if parsed.Offset != nil {
+		if *parsed.Offset < 0 {
+			return nil, eris.New("OFFSET cannot be negative")
+		}
+		query.Offset = *parsed.Offset
+	}
+	for _, componentName := range parsed.Select {
+		comp, err := stringToComponent(componentName.Name)
+		if err != nil {
+			return nil, eris.Wrap(err, "")
+		}
+		query.Select = append(query.Select, comp)
+	}
+	return query, nil
+}
+
+func predicateToPredicate(predicate *cqlPredicate, stringToComponent componentByName) (Predicate, error) {
+	terms := make(orPredicate, 0, len(predicate.Right)+1)
+	for _, term := range append([]*cqlAndPredicate{predicate.Left}, predicate.Right...) {
+		factors := make(andPredicate, 0, len(term.Right)+1)
+		for _, factor := range append([]*cqlUnaryPredicate{term.Left}, term.Right...) {
+			result, err := unaryToPredicate(factor, stringToComponent)
+			if err != nil {
+				return nil, err
+			}
+			factors = append(factors, result)
+		}
+		terms = append(terms, factors)
+	}
+	return terms, nil
+}
+
+func unaryToPredicate(unary *cqlUnaryPredicate, stringToComponent componentByName) (Predicate, error) {
+	switch {
+	case unary.Not != nil:
+		result, err := unaryToPredicate(unary.Not, stringToComponent)
+		if err != nil {
+			return nil, err
+		}
+		return notPredicate{predicate: result}, nil
+	case unary.Subexpression != nil:
+		return predicateToPredicate(unary.Subexpression, stringToComponent)
+	case unary.Comparison != nil:
+		return comparisonToPredicate(unary.Comparison, stringToComponent)
+	}
+	return nil, eris.New("unknown error during conversion from CQL AST to predicate")
+}
+
+func comparisonToPredicate(cmp *cqlComparison, stringToComponent componentByName) (Predicate, error) {
+	field, err := toField(cmp.Field, stringToComponent)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case cmp.Between != nil:
+		return comparisonPredicate{
+			field:  field,
+			values: []any{cmp.Between.Low.value(), cmp.Between.High.value()},
+			match: func(c []comparison) bool {
+				return c[0].comparable && c[0].order >= 0 && c[1].comparable && c[1].order <= 0
+			},
+		}, nil
+	case len(cmp.In) > 0:
+		values := make([]any, 0, len(cmp.In))
+		for _, literal := range cmp.In {
+			values = append(values, literal.value())
+		}
+		return comparisonPredicate{
+			field:  field,
+			values: values,
+			match: func(c []comparison) bool {
+				for _, result := range c {
+					if result.comparable && result.order == 0 {
+						return true
+					}
+				}
+				return false
+			},
+		}, nil
+	}
+	operator, ok := comparisonOperators[cmp.Op]
+	if !ok || cmp.Value == nil {
+		return nil, eris.Errorf("invalid comparison operator %q", cmp.Op)
+	}
+	return comparisonPredicate{
+		field:  field,
+		values: []any{cmp.Value.value()},
+		match:  func(c []comparison) bool { return operator(c[0]) },
+	}, nil
+}
+
+func toField(field *cqlField, stringToComponent componentByName) (Field, error) {
+	comp, err := stringToComponent(field.Component)
+	if err != nil {
+		return Field{}, eris.Wrap(err, "")
+	}
+	return Field{Component: comp.Name(), Path: field.Path}, nil
+}
@@ -0,0 +1,129 @@
+package cql
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/rotisserie/eris"
+
+	"pkg.world.dev/world-engine/assert"
+	"pkg.world.dev/world-engine/cardinal/types"
+)
+
+type HealthComponent struct{}
+
+func (HealthComponent) Name() string { return "health" }
+
+func componentsByName(name string) (types.Component, error) {
+	switch name {
+	case "health":
+		return HealthComponent{}, nil
+	case "emptyComponent":
+		return EmptyComponent{}, nil
+	}
+	return nil, eris.Errorf("component %q not found", name)
+}
+
+func entityWith(components map[string]string) ComponentGetter {
+	return func(name string) (json.RawMessage, bool, error) {
+		value, ok := components[name]
+		return json.RawMessage(value), ok, nil
+	}
+}
+
+func TestParseQueryClauses(t *testing.T) {
+	query, err := ParseQuery(
+		"SELECT health, emptyComponent FROM CONTAINS(health) WHERE health.HP < 10 "+
+			"ORDER BY health.HP DESC, health.Name LIMIT 5 OFFSET 2",
+		componentsByName,
+	)
+	assert.NilError(t, err)
+	assert.Check(t, query.Filter != nil)
+	assert.Check(t, query.Where != nil)
+	assert.DeepEqual(t, query.OrderBy, []Order{
+		{Field: Field{Component: "health", Path: []string{"HP"}}, Descending: true},
+		{Field: Field{Component: "health", Path: []string{"Name"}}, Descending: false},
+	})
+	assert.Equal(t, *query.Limit, 5)
+	assert.Equal(t, query.Offset, 2)
+	assert.DeepEqual(t, query.Select, []types.Component{HealthComponent{}, EmptyComponent{}})
+
+	query, err = ParseQuery("ALL()", componentsByName)
+	assert.NilError(t, err)
+	assert.Check(t, query.Where == nil)
+	assert.Check(t, query.Limit == nil)
+	assert.Equal(t, len(query.Select), 0)
+}
+
+func TestParseQueryErrors(t *testing.T) {
+	invalid := []string{
+		"CONTAINS(health) WHERE meow.HP < 10",
+		"CONTAINS(health) WHERE health < 10",
+		"CONTAINS(health) WHERE health.HP <> 10",
+		"CONTAINS(health) ORDER BY meow.HP",
+		"SELECT meow FROM CONTAINS(health)",
+		"CONTAINS(health) LIMIT -1",
+		"CONTAINS(health) WHERE",
+	}
+	for _, text := range invalid {
+		_, err := ParseQuery(text, componentsByName)
+		assert.Check(t, err != nil, text)
+	}
+}
+
+func TestWherePredicates(t *testing.T) {
+	entity := entityWith(map[string]string{
+		"health": `{"HP": 5, "Name": "orc", "Alive": true, "Stats": {"Armor": {"Value": 3}}}`,
+	})
+	testCases := []struct {
+		where string
+		want  bool
+	}{
+		{"health.HP < 10", true},
+		{"health.HP <= 5", true},
+		{"health.HP > 5", false},
+		{"health.HP >= 5.0", true},
+		{"health.HP = 5", true},
+		{"health.HP != 5", false},
+		{"health.HP > -1", true},
+		{`health.Name = "orc"`, true},
+		{`health.Name < "pig"`, true},
+		{"health.Alive = true", true},
+		{"health.Stats.Armor.Value = 3", true},
+		{"health.HP IN (1, 3, 5)", true},
+		{`health.Name IN ("elf", "dwarf")`, false},
+		{"health.HP BETWEEN 1 AND 5", true},
+		{"health.HP BETWEEN 6 AND 10", false},
+		{"health.HP BETWEEN 1 AND 5 AND health.Alive = false", false},
+		{"health.HP > 10 OR health.Alive = true", true},
+		{"NOT health.HP > 10", true},
+		{"NOT (health.HP < 10 AND health.Alive = true)", false},
+		// Comparisons with missing fields and values of another type are never true, except for !=.
+		{"health.Missing = 1", false},
+		{"health.Missing != 1", false},
+		{"health.HP.Value = 1", false},
+		{`health.HP = "5"`, false},
+		{`health.HP != "5"`, true},
+		{`health.HP < "5"`, false},
+		{"emptyComponent.HP < 10", false},
+	}
+	for _, tc := range testCases {
+		query, err := ParseQuery("ALL() WHERE "+tc.where, componentsByName)
+		assert.NilError(t, err, tc.where)
+		got, err := query.Where.Evaluate(entity)
+		assert.NilError(t, err, tc.where)
+		assert.Equal(t, got, tc.want, tc.where)
+	}
+}
+
+func TestOrderCompare(t *testing.T) {
+	asc := Order{}
+	desc := Order{Descending: true}
+	assert.Equal(t, asc.Compare(1.0, 2.0), -1)
+	assert.Equal(t, desc.Compare(1.0, 2.0), 1)
+	assert.Equal(t, asc.Compare("b", "a"), 1)
+	assert.Equal(t, asc.Compare(nil, 1.0), 1)
+	assert.Equal(t, desc.Compare(nil, 1.0), 1)
+	assert.Equal(t, desc.Compare(1.0, nil), -1)
+	assert.Equal(t, asc.Compare(nil, nil), 0)
+}
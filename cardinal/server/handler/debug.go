@@ -1,32 +1,119 @@
 package handler
 
 import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"strings"
+
 	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
 
 	servertypes "pkg.world.dev/world-engine/cardinal/server/types"
 	"pkg.world.dev/world-engine/cardinal/types"
 )
 
-type DebugStateRequest struct{}
+const (
+	// NextCursorHeader holds the cursor of the next page of the debug state. It is not set on the last page.
+	NextCursorHeader = "X-Next-Cursor"
+	// NDJSONContentType is accepted by /debug/state to stream the entities as newline delimited JSON.
+	NDJSONContentType = "application/x-ndjson"
+)
+
+type DebugStateRequest struct {
+	// Cursor is the value of the X-Next-Cursor header of the previous page. The first page is returned if it is empty.
+	Cursor string `json:"cursor,omitempty"`
+	// Limit is the maximum number of entities to return. Every entity is returned if it is 0.
+	Limit int `json:"limit,omitempty"`
+	// Descending orders the entities by descending ID instead of ascending ID.
+	Descending bool `json:"descending,omitempty"`
+	// Components only returns the entities that have every one of the named components.
+	Components []string `json:"components,omitempty"`
+}
 
 type DebugStateResponse = []types.DebugStateElement
 
 // @Summary Get the debug state of the world.
-// @Description Get the debug state of the world.
+// @Description Get the entities of the world and their components, ordered by ID. Use limit and the X-Next-Cursor
+// @Description response header to page through the entities. Send "Accept: application/x-ndjson" to stream the
+// @Description entities as newline delimited JSON instead.
 // @Accept json
-// @Produce json
+// @Produce json,application/x-ndjson
 // @Param request body DebugStateRequest true "Debug state request"
 // @Success 200 {array} types.DebugStateElement
+// @Header 200 {string} X-Next-Cursor "Cursor of the next page"
+// @Failure 400 {string} string "Invalid request parameters"
 // @Failure 500 {object} error
 // @Router /debug/state [post].
 func GetState(world servertypes.ProviderWorld) func(*fiber.Ctx) error {
 	return func(ctx *fiber.Ctx) error {
-		var result DebugStateResponse
-		result, err := world.GetDebugState()
-		if err != nil {
+		req := new(DebugStateRequest)
+		if len(ctx.Body()) > 0 {
+			if err := ctx.BodyParser(req); err != nil {
+				return fiber.NewError(fiber.StatusBadRequest, "Bad Request - failed to parse request: "+err.Error())
+			}
+		}
+
+		ids, nextCursor, err := world.GetDebugStatePage(types.EntityPageOptions{
+			Cursor:     req.Cursor,
+			Limit:      req.Limit,
+			Descending: req.Descending,
+			Components: req.Components,
+		})
+		if errors.Is(err, types.ErrInvalidEntityPage) {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		} else if err != nil {
 			return err
 		}
+		if nextCursor != "" {
+			ctx.Set(NextCursorHeader, nextCursor)
+		}
+
+		if strings.Contains(ctx.Get(fiber.HeaderAccept), NDJSONContentType) {
+			ctx.Set(fiber.HeaderContentType, NDJSONContentType)
+			ctx.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+				streamDebugState(world, ids, w)
+			})
+			return nil
+		}
 
+		result := make(DebugStateResponse, 0, len(ids))
+		for _, id := range ids {
+			element, ok, err := world.GetDebugStateElement(id)
+			if err != nil {
+				return err
+			}
+			if ok {
+				result = append(result, element)
+			}
+		}
 		return ctx.JSON(&result)
 	}
 }
+
+// streamDebugState writes the entities one per line, so they are never all held in memory. The status of the
+// response is already sent, so an error is written as a final {"error": ...} line.
+func streamDebugState(world servertypes.ProviderWorld, ids []types.EntityID, w *bufio.Writer) {
+	encoder := json.NewEncoder(w)
+	for _, id := range ids {
+		element, ok, err := world.GetDebugStateElement(id)
+		if err != nil {
+			log.Error().Err(err).Msg("failed to stream debug state")
+			_ = encoder.Encode(map[string]string{"error": err.Error()})
+			break
+		}
+		if !ok {
+			continue
+		}
+		if err := encoder.Encode(element); err != nil {
+			// The client went away.
+			return
+		}
+		if w.Buffered() > w.Size()/2 {
+			if err := w.Flush(); err != nil {
+				return
+			}
+		}
+	}
+	_ = w.Flush()
+}
@@ -1,25 +1,211 @@
 package handler
 
 import (
-	"github.com/gofiber/contrib/socketio"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+
 	"github.com/gofiber/contrib/websocket"
 	"github.com/gofiber/fiber/v2"
+	"github.com/rotisserie/eris"
 	"github.com/rs/zerolog/log"
+
+	servertypes "pkg.world.dev/world-engine/cardinal/server/types"
+	"pkg.world.dev/world-engine/cardinal/server/validator"
+	"pkg.world.dev/world-engine/sign"
+)
+
+const challengeSize = 32
+
+const (
+	EventMessageChallenge     = "challenge"
+	EventMessageAuthenticate  = "authenticate"
+	EventMessageAuthenticated = "authenticated"
+	EventMessageSubscribe     = "subscribe"
+	EventMessageSubscribed    = "subscribed"
+	EventMessageUnsubscribe   = "unsubscribe"
+	EventMessageUnsubscribed  = "unsubscribed"
+	EventMessageError         = "error"
 )
 
+// EventRequestMessage is sent by a client of /events to authenticate as a persona, or to subscribe to a topic.
+type EventRequestMessage struct {
+	Type string `json:"type"`
+	// Topic is the topic to subscribe to, or to unsubscribe from.
+	Topic string `json:"topic,omitempty"`
+	// Transaction is signed by the persona to authenticate as, and its body is an EventChallengeBody.
+	Transaction json.RawMessage `json:"transaction,omitempty" swaggertype:"object"`
+}
+
+// EventChallengeBody is the body of the transaction that authenticates a client of /events.
+type EventChallengeBody struct {
+	Challenge string `json:"challenge"`
+}
+
+// EventMessage is sent by the server in reply to an EventRequestMessage. The tick results are sent as they are.
+type EventMessage struct {
+	Type       string `json:"type"`
+	Challenge  string `json:"challenge,omitempty"`
+	PersonaTag string `json:"personaTag,omitempty"`
+	Topic      string `json:"topic,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// EventClients holds the open /events connections, so that each client can be sent the events it may see.
+type EventClients struct {
+	wsClients[*eventClient]
+}
+
+func NewEventClients() *EventClients {
+	return &EventClients{newWSClients[*eventClient]()}
+}
+
+// Publish sends every client the message returned by messageFor. A client is sent nothing if messageFor returns nil.
+func (ec *EventClients) Publish(messageFor func(client servertypes.EventClient) any) {
+	ec.each(func(c *eventClient) {
+		if msg := messageFor(c); msg != nil {
+			c.sendJSON(msg)
+		}
+	})
+}
+
+// Broadcast sends the message to every client.
+func (ec *EventClients) Broadcast(bz []byte) {
+	ec.each(func(c *eventClient) {
+		c.send(bz)
+	})
+}
+
 // WebSocketEvents godoc
 //
 //	@Summary      Establishes a new websocket connection to retrieve system events
-//	@Description  Establishes a new websocket connection to retrieve system events
+//	@Description  Receives the results of every tick. Events sent to a persona, and the receipts of its transactions,
+//	@Description  are only received after authenticating as the persona: send {"type": "challenge"}, then
+//	@Description  {"type": "authenticate", "transaction": ...} with a transaction signed by the persona whose body is
+//	@Description  {"challenge": ...}. Send {"type": "subscribe", "topic": ...} to receive the events of a topic.
 //	@Produce      application/json
 //	@Success      101  {string}  string  "Switch protocol to ws"
 //	@Router       /events [get]
-func WebSocketEvents() func(c *fiber.Ctx) error {
-	return socketio.New(func(_ *socketio.Websocket) {
+func WebSocketEvents(clients *EventClients, sigValidator *validator.SignatureValidator) func(c *fiber.Ctx) error {
+	return websocket.New(func(conn *websocket.Conn) {
 		log.Debug().Msg("new websocket connection established")
+		c := &eventClient{
+			wsClient: newWSClient(conn),
+			topics:   make(map[string]struct{}),
+		}
+		clients.add(c)
+		defer clients.remove(c)
+
+		c.serve(func(bz []byte) {
+			var msg EventRequestMessage
+			if err := json.Unmarshal(bz, &msg); err != nil {
+				c.sendError("invalid message: " + err.Error())
+				return
+			}
+			c.handle(sigValidator, msg)
+		})
 	})
 }
 
+// eventClient is a client of /events. It only receives the events of the persona it authenticated as.
+type eventClient struct {
+	*wsClient
+
+	mux        sync.Mutex
+	challenge  string
+	personaTag string
+	topics     map[string]struct{}
+}
+
+var _ servertypes.EventClient = (*eventClient)(nil)
+
+func (c *eventClient) PersonaTag() string {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	return c.personaTag
+}
+
+func (c *eventClient) IsSubscribed(topic string) bool {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	_, ok := c.topics[topic]
+	return ok
+}
+
+func (c *eventClient) handle(sigValidator *validator.SignatureValidator, msg EventRequestMessage) {
+	switch msg.Type {
+	case EventMessageChallenge:
+		bz := make([]byte, challengeSize)
+		if _, err := rand.Read(bz); err != nil {
+			c.sendError("failed to create challenge")
+			return
+		}
+		challenge := hex.EncodeToString(bz)
+		c.mux.Lock()
+		c.challenge = challenge
+		c.mux.Unlock()
+		c.sendJSON(EventMessage{Type: EventMessageChallenge, Challenge: challenge})
+	case EventMessageAuthenticate:
+		personaTag, err := c.authenticate(sigValidator, msg.Transaction)
+		if err != nil {
+			c.sendError("failed to authenticate: " + err.Error())
+			return
+		}
+		c.sendJSON(EventMessage{Type: EventMessageAuthenticated, PersonaTag: personaTag})
+	case EventMessageSubscribe, EventMessageUnsubscribe:
+		if msg.Topic == "" {
+			c.sendError("topic is required")
+			return
+		}
+		reply := EventMessageSubscribed
+		c.mux.Lock()
+		if msg.Type == EventMessageSubscribe {
+			c.topics[msg.Topic] = struct{}{}
+		} else {
+			delete(c.topics, msg.Topic)
+			reply = EventMessageUnsubscribed
+		}
+		c.mux.Unlock()
+		c.sendJSON(EventMessage{Type: reply, Topic: msg.Topic})
+	default:
+		c.sendError("unknown message type " + msg.Type)
+	}
+}
+
+// authenticate checks that the transaction was signed by its persona, and answers the challenge that was last sent to
+// the client. A challenge can only be answered once.
+func (c *eventClient) authenticate(sigValidator *validator.SignatureValidator, bz json.RawMessage) (string, error) {
+	c.mux.Lock()
+	challenge := c.challenge
+	c.challenge = ""
+	c.mux.Unlock()
+	if challenge == "" {
+		return "", eris.New("request a challenge first")
+	}
+
+	tx, err := sign.UnmarshalTransaction(bz)
+	if err != nil {
+		return "", err
+	}
+	var body EventChallengeBody
+	if err := json.Unmarshal(tx.Body, &body); err != nil || body.Challenge != challenge {
+		return "", eris.New("the transaction does not answer the challenge")
+	}
+	if err := sigValidator.ValidateTransactionSignature(tx, ""); err != nil {
+		return "", err
+	}
+
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	c.personaTag = tx.PersonaTag
+	return tx.PersonaTag, nil
+}
+
+func (c *eventClient) sendError(err string) {
+	c.sendJSON(EventMessage{Type: EventMessageError, Error: err})
+}
+
 func WebSocketUpgrader(c *fiber.Ctx) error {
 	// IsWebSocketUpgrade returns true if the client
 	// requested upgrade to the WebSocket protocol.
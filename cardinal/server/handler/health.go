@@ -2,26 +2,47 @@ package handler
 
 import (
 	"github.com/gofiber/fiber/v2"
+
+	servertypes "pkg.world.dev/world-engine/cardinal/server/types"
+	"pkg.world.dev/world-engine/cardinal/types"
 )
 
 type GetHealthResponse struct {
 	IsServerRunning   bool `json:"isServerRunning"`
 	IsGameLoopRunning bool `json:"isGameLoopRunning"`
+	// IsHealthy is false if the last tick was aborted by a failing system, or if the circuit of a system is open.
+	IsHealthy           bool                 `json:"isHealthy"`
+	AbortedTicks        uint64               `json:"abortedTicks"`
+	LastAbortedTick     *uint64              `json:"lastAbortedTick,omitempty"`
+	LastAbortError      string               `json:"lastAbortError,omitempty"`
+	Systems             []types.SystemHealth `json:"systems"`
+	QuarantinedEntities []types.EntityID     `json:"quarantinedEntities"`
+	// Ticks reports the durations of the ticks against the budget of the tick rate, and the ticks that overran it.
+	Ticks types.TickHealth `json:"ticks"`
 }
 
 // GetHealth godoc
 //
 //	@Summary      Retrieves the status of the server and game loop
-//	@Description  Retrieves the status of the server and game loop
+//	@Description  Retrieves the status of the server and game loop, the failures of the systems, and the durations of
+//	@Description  the ticks
 //	@Produce      application/json
 //	@Success      200  {object}  GetHealthResponse  "Server and game loop status"
 //	@Router       /health [get]
-func GetHealth() func(c *fiber.Ctx) error {
+func GetHealth(world servertypes.ProviderWorld) func(c *fiber.Ctx) error {
 	return func(ctx *fiber.Ctx) error {
+		health := world.Health()
 		return ctx.JSON(GetHealthResponse{
 			IsServerRunning: true,
 			// TODO(scott): reconsider whether we need this. Intuitively server running implies game loop running.
-			IsGameLoopRunning: true,
+			IsGameLoopRunning:   true,
+			IsHealthy:           health.Healthy,
+			AbortedTicks:        health.AbortedTicks,
+			LastAbortedTick:     health.LastAbortedTick,
+			LastAbortError:      health.LastAbortError,
+			Systems:             health.Systems,
+			QuarantinedEntities: health.QuarantinedEntities,
+			Ticks:               health.Ticks,
 		})
 	}
 }
@@ -0,0 +1,41 @@
+package handler
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// GetMetrics godoc
+//
+//	@Summary      Retrieves the metrics of the world in the Prometheus text format
+//	@Description  Retrieves the durations of the ticks and of the systems, the size of the transaction pool, the
+//	@Description  transactions and receipt errors of each message, the number of entities and archetypes, the latency
+//	@Description  of the redis pipelines and the number of websocket clients
+//	@Produce      text/plain
+//	@Success      200  {string}  string  "Metrics in the Prometheus text format"
+//	@Router       /metrics [get]
+func GetMetrics(registry *prometheus.Registry) func(c *fiber.Ctx) error {
+	return adaptor.HTTPHandler(promhttp.HandlerFor(registry, promhttp.HandlerOpts{Registry: registry}))
+}
+
+// websocketClientsCollector returns a gauge of the number of clients connected to a websocket endpoint.
+func websocketClientsCollector(endpoint string, count func() int) prometheus.Collector {
+	return prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Name:        "cardinal_websocket_clients",
+		Help:        "Number of clients connected to a websocket endpoint.",
+		ConstLabels: prometheus.Labels{"endpoint": endpoint},
+	}, func() float64 {
+		return float64(count())
+	})
+}
+
+// RegisterWebSocketMetrics registers the gauges of the number of clients connected to the /events and /subscriptions
+// websockets.
+func RegisterWebSocketMetrics(registry prometheus.Registerer, events *EventClients, subscribers *Subscribers) error {
+	if err := registry.Register(websocketClientsCollector("events", events.Len)); err != nil {
+		return err
+	}
+	return registry.Register(websocketClientsCollector("subscriptions", subscribers.Len))
+}
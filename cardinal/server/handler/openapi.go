@@ -0,0 +1,28 @@
+package handler
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	servertypes "pkg.world.dev/world-engine/cardinal/server/types"
+)
+
+// GetOpenAPI godoc
+//
+//	@Summary      Retrieves the OpenAPI document of the game
+//	@Description  Retrieves an OpenAPI 3.1 document generated from the registered messages, queries and components,
+//	@Description  with the schemas of the bodies of the transactions, of the requests and replies of the queries, and
+//	@Description  of the components
+//	@Produce      application/json
+//	@Success      200  {object}  object  "OpenAPI document of the game"
+//	@Failure      500  {string}  string  "The schema of a registered type could not be generated"
+//	@Router       /openapi.json [get]
+func GetOpenAPI(world servertypes.ProviderWorld) func(*fiber.Ctx) error {
+	// The messages, queries and components can't change once the game started, so the document is generated once.
+	doc, err := world.OpenAPI()
+	return func(ctx *fiber.Ctx) error {
+		if err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+		}
+		return ctx.JSON(doc)
+	}
+}
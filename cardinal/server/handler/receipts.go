@@ -1,9 +1,15 @@
 package handler
 
 import (
+	"encoding/json"
+	"errors"
+	"slices"
+
 	"github.com/gofiber/fiber/v2"
 
 	"pkg.world.dev/world-engine/cardinal/server/types"
+	"pkg.world.dev/world-engine/cardinal/server/validator"
+	"pkg.world.dev/world-engine/cardinal/storage"
 )
 
 type ListTxReceiptsRequest struct {
@@ -20,12 +26,15 @@ type ListTxReceiptsResponse struct {
 	Receipts  []ReceiptEntry `json:"receipts"`
 }
 
-// ReceiptEntry represents a single transaction receipt. It contains an ID, a result, and a list of errors.
+// ReceiptEntry represents a single transaction receipt. It contains an ID, a result, a list of errors, and the fee
+// that was charged for the transaction.
 type ReceiptEntry struct {
-	TxHash string   `json:"txHash"`
-	Tick   uint64   `json:"tick"`
-	Result any      `json:"result"`
-	Errors []string `json:"errors"`
+	TxHash      string   `json:"txHash"`
+	Tick        uint64   `json:"tick"`
+	Result      any      `json:"result"`
+	Errors      []string `json:"errors"`
+	Fee         uint64   `json:"fee,omitempty"`
+	FeeRefunded bool     `json:"feeRefunded,omitempty"`
 }
 
 // GetReceipts godoc
@@ -68,10 +77,12 @@ func GetReceipts(world types.ProviderWorld) func(*fiber.Ctx) error {
 			}
 			for _, r := range currReceipts {
 				reply.Receipts = append(reply.Receipts, ReceiptEntry{
-					TxHash: string(r.TxHash),
-					Tick:   t,
-					Result: r.Result,
-					Errors: convertErrorsToStrings(r.Errs),
+					TxHash:      string(r.TxHash),
+					Tick:        t,
+					Result:      r.Result,
+					Errors:      convertErrorsToStrings(r.Errs),
+					Fee:         r.Fee,
+					FeeRefunded: r.FeeRefunded,
 				})
 			}
 		}
@@ -89,3 +100,206 @@ func convertErrorsToStrings(errs []error) []string {
 	}
 	return result
 }
+
+// MaxReceiptLogTicks is the largest range of ticks that can be read from the receipt log in a single request.
+const MaxReceiptLogTicks = 1000
+
+// GetReceiptRequest is the body of a transaction signed by the persona that submitted the transaction whose receipt
+// is read.
+type GetReceiptRequest struct {
+	TxHash string `json:"txHash"`
+}
+
+// ListPersonaReceiptsRequest is the body of a transaction signed by the persona whose receipts are read.
+type ListPersonaReceiptsRequest struct {
+	StartTick uint64 `json:"startTick"`
+	// EndTick is the tick after the last tick to read. If it is 0, the receipts up to the last tick are read.
+	EndTick uint64 `json:"endTick"`
+}
+
+// ListPersonaReceiptsResponse returns the receipts of the transactions a persona submitted in the range of ticks
+// [StartTick, EndTick). At most MaxReceiptLogTicks are read at once: use the returned EndTick as the StartTick in the
+// next request to read the following ticks.
+type ListPersonaReceiptsResponse struct {
+	PersonaTag string                  `json:"personaTag"`
+	StartTick  uint64                  `json:"startTick"`
+	EndTick    uint64                  `json:"endTick"`
+	Receipts   []storage.ReceiptRecord `json:"receipts"`
+}
+
+// ListTickHistoryRequest is the body of a transaction signed by the persona whose receipts are read.
+type ListTickHistoryRequest struct {
+	StartTick uint64 `json:"startTick"`
+	// EndTick is the tick after the last tick to read. If it is 0, the ticks up to the last tick are read.
+	EndTick uint64 `json:"endTick"`
+}
+
+// ListTickHistoryResponse returns the events, and the receipts of the transactions of a persona, of the ticks in the
+// range [StartTick, EndTick). At most MaxReceiptLogTicks are read at once: use the returned EndTick as the StartTick in
+// the next request to read the following ticks.
+type ListTickHistoryResponse struct {
+	PersonaTag string               `json:"personaTag"`
+	StartTick  uint64               `json:"startTick"`
+	EndTick    uint64               `json:"endTick"`
+	Ticks      []storage.TickRecord `json:"ticks"`
+}
+
+// GetReceiptByTxHash godoc
+//
+//	@Summary      Retrieves the receipt of a transaction from the receipt log
+//	@Description  Retrieves the receipt of a transaction from the receipt log. The request is a transaction signed by
+//	@Description  the persona that submitted the transaction, whose body is a GetReceiptRequest.
+//	@Description  Requires CARDINAL_RECEIPT_LOG.
+//	@Accept       application/json
+//	@Produce      application/json
+//	@Param        txBody  body      sign.Transaction       true  "Transaction signed by the persona"
+//	@Success      200     {object}  storage.ReceiptRecord  "Receipt of the transaction"
+//	@Failure      400     {string}  string                 "Invalid request body"
+//	@Failure      401     {string}  string                 "Unauthorized - signature was invalid"
+//	@Failure      403     {string}  string                 "Forbidden - duplicate message"
+//	@Failure      404     {string}  string                 "Receipt not found"
+//	@Failure      408     {string}  string                 "Request Timeout - message expired"
+//	@Failure      501     {string}  string                 "Receipt log is disabled"
+//	@Router       /query/receipts/tx [post]
+func GetReceiptByTxHash(world types.ProviderWorld, validator *validator.SignatureValidator) func(*fiber.Ctx) error {
+	return func(ctx *fiber.Ctx) error {
+		receiptLog, _, err := getReceiptLog(world)
+		if err != nil {
+			return err
+		}
+		req := new(GetReceiptRequest)
+		personaTag, err := extractPersonaRequest(ctx, validator, req)
+		if err != nil {
+			return err
+		}
+		rec, err := receiptLog.GetReceipt(req.TxHash)
+		// The receipts of other personas are reported as missing, so that they can't be told apart from unknown
+		// transactions.
+		if errors.Is(err, storage.ErrReceiptNotFound) || (err == nil && rec.PersonaTag != personaTag) {
+			return fiber.NewError(fiber.StatusNotFound, "Not Found - receipt not found")
+		} else if err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+		}
+		return ctx.JSON(rec)
+	}
+}
+
+// GetPersonaReceipts godoc
+//
+//	@Summary      Retrieves the receipts of the transactions of a persona from the receipt log
+//	@Description  Retrieves the receipts of the transactions of a persona in a range of ticks from the receipt log.
+//	@Description  The request is a transaction signed by the persona, whose body is a ListPersonaReceiptsRequest.
+//	@Description  Requires CARDINAL_RECEIPT_LOG.
+//	@Accept       application/json
+//	@Produce      application/json
+//	@Param        txBody  body      sign.Transaction             true  "Transaction signed by the persona"
+//	@Success      200     {object}  ListPersonaReceiptsResponse  "List of receipts"
+//	@Failure      400     {string}  string                       "Invalid request body"
+//	@Failure      401     {string}  string                       "Unauthorized - signature was invalid"
+//	@Failure      403     {string}  string                       "Forbidden - duplicate message"
+//	@Failure      408     {string}  string                       "Request Timeout - message expired"
+//	@Failure      501     {string}  string                       "Receipt log is disabled"
+//	@Router       /query/receipts/persona [post]
+func GetPersonaReceipts(world types.ProviderWorld, validator *validator.SignatureValidator) func(*fiber.Ctx) error {
+	return func(ctx *fiber.Ctx) error {
+		receiptLog, lastTick, err := getReceiptLog(world)
+		if err != nil {
+			return err
+		}
+		req := new(ListPersonaReceiptsRequest)
+		personaTag, err := extractPersonaRequest(ctx, validator, req)
+		if err != nil {
+			return err
+		}
+		reply := ListPersonaReceiptsResponse{PersonaTag: personaTag}
+		reply.StartTick, reply.EndTick = receiptLogRange(req.StartTick, req.EndTick, lastTick)
+		reply.Receipts, err = receiptLog.GetReceiptsForPersona(personaTag, reply.StartTick, reply.EndTick)
+		if err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+		}
+		return ctx.JSON(reply)
+	}
+}
+
+// GetTickHistory godoc
+//
+//	@Summary      Retrieves the events and the receipts of a persona of a range of ticks from the receipt log
+//	@Description  Retrieves the events sent to every client, and the receipts of the transactions of a persona, of a
+//	@Description  range of ticks from the receipt log. The request is a transaction signed by the persona, whose body
+//	@Description  is a ListTickHistoryRequest. Requires CARDINAL_RECEIPT_LOG.
+//	@Accept       application/json
+//	@Produce      application/json
+//	@Param        txBody  body      sign.Transaction         true  "Transaction signed by the persona"
+//	@Success      200     {object}  ListTickHistoryResponse  "List of ticks"
+//	@Failure      400     {string}  string                   "Invalid request body"
+//	@Failure      401     {string}  string                   "Unauthorized - signature was invalid"
+//	@Failure      403     {string}  string                   "Forbidden - duplicate message"
+//	@Failure      408     {string}  string                   "Request Timeout - message expired"
+//	@Failure      501     {string}  string                   "Receipt log is disabled"
+//	@Router       /query/receipts/range [post]
+func GetTickHistory(world types.ProviderWorld, validator *validator.SignatureValidator) func(*fiber.Ctx) error {
+	return func(ctx *fiber.Ctx) error {
+		receiptLog, lastTick, err := getReceiptLog(world)
+		if err != nil {
+			return err
+		}
+		req := new(ListTickHistoryRequest)
+		personaTag, err := extractPersonaRequest(ctx, validator, req)
+		if err != nil {
+			return err
+		}
+		reply := ListTickHistoryResponse{PersonaTag: personaTag}
+		reply.StartTick, reply.EndTick = receiptLogRange(req.StartTick, req.EndTick, lastTick)
+		reply.Ticks, err = receiptLog.GetTicks(reply.StartTick, reply.EndTick)
+		if err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+		}
+		for i := range reply.Ticks {
+			reply.Ticks[i].Receipts = slices.DeleteFunc(reply.Ticks[i].Receipts, func(rec storage.ReceiptRecord) bool {
+				return rec.PersonaTag != personaTag
+			})
+		}
+		return ctx.JSON(reply)
+	}
+}
+
+// extractPersonaRequest decodes the body of a transaction signed by a persona into req, and returns the persona tag.
+// The receipt log holds the receipts of every persona, so each persona may only read its own.
+func extractPersonaRequest(ctx *fiber.Ctx, validator *validator.SignatureValidator, req any) (string, error) {
+	tx, err := extractTx(ctx, validator)
+	if err != nil {
+		return "", err
+	}
+	if err = validator.ValidateTransactionTTL(tx); err != nil {
+		return "", httpResultFromError(err, false)
+	}
+	if err = validator.ValidateTransactionSignature(tx, ""); err != nil {
+		return "", httpResultFromError(err, true)
+	}
+	if err = json.Unmarshal(tx.Body, req); err != nil {
+		return "", fiber.NewError(fiber.StatusBadRequest, "Bad Request - failed to decode the request")
+	}
+	return tx.PersonaTag, nil
+}
+
+func getReceiptLog(world types.ProviderWorld) (storage.ReceiptLog, uint64, error) {
+	receiptLog, endTick := world.ReceiptLog()
+	if receiptLog == nil {
+		return nil, 0, fiber.NewError(fiber.StatusNotImplemented,
+			"Not Implemented - the receipt log is disabled, set CARDINAL_RECEIPT_LOG to enable it")
+	}
+	return receiptLog, endTick, nil
+}
+
+// receiptLogRange narrows the requested range of ticks down to the ticks in the receipt log, and to at most
+// MaxReceiptLogTicks ticks.
+func receiptLogRange(startTick, endTick, lastTick uint64) (uint64, uint64) {
+	if endTick == 0 || endTick > lastTick {
+		endTick = lastTick
+	}
+	startTick = min(startTick, endTick)
+	if endTick-startTick > MaxReceiptLogTicks {
+		endTick = startTick + MaxReceiptLogTicks
+	}
+	return startTick, endTick
+}
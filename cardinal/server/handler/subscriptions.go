@@ -0,0 +1,145 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/gofiber/contrib/websocket"
+	"github.com/gofiber/fiber/v2"
+
+	servertypes "pkg.world.dev/world-engine/cardinal/server/types"
+	"pkg.world.dev/world-engine/cardinal/types"
+)
+
+const (
+	SubscriptionMessageSubscribe   = "subscribe"
+	SubscriptionMessageUnsubscribe = "unsubscribe"
+	SubscriptionMessageSnapshot    = "snapshot"
+	SubscriptionMessageUpdate      = "update"
+	SubscriptionMessageError       = "error"
+)
+
+// SubscriptionRequestMessage is sent by a client to subscribe to, or unsubscribe from, the changes of entities. ID is
+// chosen by the client, and identifies the subscription in the messages sent by the server.
+type SubscriptionRequestMessage struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+	types.SubscriptionRequest
+}
+
+// SubscriptionMessage is sent by the server with the snapshot, and then the updates, of a subscription, or an error.
+type SubscriptionMessage struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+	*types.SubscriptionUpdate
+	Error string `json:"error,omitempty"`
+}
+
+// Subscribers holds the open subscription connections, so they can be closed when the server shuts down.
+type Subscribers struct {
+	wsClients[*subscriber]
+}
+
+func NewSubscribers() *Subscribers {
+	return &Subscribers{newWSClients[*subscriber]()}
+}
+
+// WebSocketSubscriptions godoc
+//
+//	@Summary      Establishes a new websocket connection to subscribe to the changes of entities
+//	@Description  Send {"type": "subscribe", "id": ..., "cql": ...} or {"type": "subscribe", "id": ...,
+//	@Description  "components": [...]} to receive a snapshot of the selected entities, and then the changes of their
+//	@Description  components after every tick. Send {"type": "unsubscribe", "id": ...} to stop.
+//	@Produce      application/json
+//	@Success      101  {string}  string  "Switch protocol to ws"
+//	@Router       /subscriptions [get]
+func WebSocketSubscriptions(
+	world servertypes.ProviderWorld, subscribers *Subscribers, maxSubscriptions uint,
+) func(c *fiber.Ctx) error {
+	return websocket.New(func(conn *websocket.Conn) {
+		s := &subscriber{
+			wsClient:         newWSClient(conn),
+			subscriptions:    make(map[string]func()),
+			maxSubscriptions: maxSubscriptions,
+		}
+		subscribers.add(s)
+		defer subscribers.remove(s)
+		defer s.unsubscribeAll()
+
+		s.serve(func(bz []byte) {
+			var msg SubscriptionRequestMessage
+			if err := json.Unmarshal(bz, &msg); err != nil {
+				s.sendJSON(SubscriptionMessage{Type: SubscriptionMessageError, Error: "invalid message: " + err.Error()})
+				return
+			}
+			s.handle(world, msg)
+		})
+	})
+}
+
+// subscriber holds the subscriptions of a websocket connection.
+type subscriber struct {
+	*wsClient
+
+	mux              sync.Mutex
+	subscriptions    map[string]func()
+	maxSubscriptions uint
+}
+
+func (s *subscriber) handle(world servertypes.ProviderWorld, msg SubscriptionRequestMessage) {
+	switch msg.Type {
+	case SubscriptionMessageSubscribe:
+		s.mux.Lock()
+		_, exists := s.subscriptions[msg.ID]
+		full := uint(len(s.subscriptions)) >= s.maxSubscriptions
+		s.mux.Unlock()
+		if exists {
+			s.sendError(msg.ID, "subscription already exists")
+			return
+		}
+		if full {
+			s.sendError(msg.ID, fmt.Sprintf("a connection can't hold more than %d subscriptions", s.maxSubscriptions))
+			return
+		}
+		unsubscribe, err := world.Subscribe(msg.SubscriptionRequest, func(update types.SubscriptionUpdate) {
+			msgType := SubscriptionMessageUpdate
+			if update.Snapshot {
+				msgType = SubscriptionMessageSnapshot
+			}
+			s.sendJSON(SubscriptionMessage{Type: msgType, ID: msg.ID, SubscriptionUpdate: &update})
+		})
+		if err != nil {
+			s.sendError(msg.ID, err.Error())
+			return
+		}
+		s.mux.Lock()
+		s.subscriptions[msg.ID] = unsubscribe
+		s.mux.Unlock()
+	case SubscriptionMessageUnsubscribe:
+		s.mux.Lock()
+		unsubscribe, ok := s.subscriptions[msg.ID]
+		delete(s.subscriptions, msg.ID)
+		s.mux.Unlock()
+		if !ok {
+			s.sendError(msg.ID, "subscription does not exist")
+			return
+		}
+		unsubscribe()
+	default:
+		s.sendError(msg.ID, "unknown message type "+msg.Type)
+	}
+}
+
+func (s *subscriber) sendError(id string, err string) {
+	s.sendJSON(SubscriptionMessage{Type: SubscriptionMessageError, ID: id, Error: err})
+}
+
+func (s *subscriber) unsubscribeAll() {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	for _, unsubscribe := range s.subscriptions {
+		unsubscribe()
+	}
+	clear(s.subscriptions)
+}
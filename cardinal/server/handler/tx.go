@@ -1,6 +1,9 @@
 package handler
 
 import (
+	"errors"
+	"strings"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/log"
 	"github.com/rotisserie/eris"
@@ -8,6 +11,7 @@ import (
 	personaMsg "pkg.world.dev/world-engine/cardinal/persona/msg"
 	servertypes "pkg.world.dev/world-engine/cardinal/server/types"
 	"pkg.world.dev/world-engine/cardinal/server/validator"
+	"pkg.world.dev/world-engine/cardinal/txpool"
 	"pkg.world.dev/world-engine/cardinal/types"
 	"pkg.world.dev/world-engine/sign"
 )
@@ -31,6 +35,7 @@ type PostTransactionResponse struct {
 //	@Failure      400      {string}  string                   "Invalid request parameter"
 //	@Failure      403      {string}  string                   "Forbidden"
 //	@Failure      408      {string}  string                   "Request Timeout - message expired"
+//	@Failure      429      {string}  string                   "Too Many Requests - rejected by the tx pool limits"
 //	@Router       /tx/{txGroup}/{txName} [post]
 func PostTransaction(
 	world servertypes.ProviderWorld, msgs map[string]map[string]types.Message, validator *validator.SignatureValidator,
@@ -75,17 +80,108 @@ func PostTransaction(
 			return httpResultFromError(err, true)
 		}
 
-		// Add the transaction to the engine
-		// TODO(scott): this should just deal with txpool instead of having to go through engine
-		tick, hash := world.AddTransaction(msgType.ID(), msg, tx)
+		return submitTransaction(ctx, world, msgType.ID(), msg, tx)
+	}
+}
+
+// PostBatchTransaction godoc
+//
+//	@Summary      Submits a batch transaction
+//	@Description  Submits an ordered list of messages of any type under a single signature. The body of the
+//	@Description  transaction is a sign.Batch. The messages are processed in the same tick, or not at all if one of
+//	@Description  them returns an error.
+//	@Accept       application/json
+//	@Produce      application/json
+//	@Param        txBody  body      sign.Transaction         true  "Transaction details & batch to be submitted"
+//	@Success      200     {object}  PostTransactionResponse  "Transaction hash and tick"
+//	@Failure      400     {string}  string                   "Invalid request parameter"
+//	@Failure      401     {string}  string                   "Unauthorized - signature was invalid"
+//	@Failure      403     {string}  string                   "Forbidden"
+//	@Failure      408     {string}  string                   "Request Timeout - message expired"
+//	@Failure      429     {string}  string                   "Too Many Requests - rejected by the tx pool limits"
+//	@Router       /tx/batch [post]
+func PostBatchTransaction(
+	world servertypes.ProviderWorld, msgs map[string]map[string]types.Message, validator *validator.SignatureValidator,
+) func(*fiber.Ctx) error {
+	return func(ctx *fiber.Ctx) error {
+		batchType, ok := msgs[types.BatchMessageGroup][types.BatchMessageName]
+		if !ok {
+			return fiber.NewError(fiber.StatusNotFound, "Not Found - batches are not supported")
+		}
+
+		tx, err := extractTx(ctx, validator)
+		if err != nil {
+			return err
+		}
+
+		if err = validator.ValidateTransactionTTL(tx); err != nil {
+			return httpResultFromError(err, false)
+		}
 
-		return ctx.JSON(&PostTransactionResponse{
-			TxHash: string(hash),
-			Tick:   tick,
-		})
+		msg, err := batchType.Decode(tx.Body)
+		if err != nil {
+			log.Errorf("batch %s Decode failed: %v", tx.Hash.String(), err)
+			return fiber.NewError(fiber.StatusBadRequest, "Bad Request - failed to decode tx batch")
+		}
+		batch, ok := msg.(sign.Batch)
+		if !ok {
+			return fiber.NewError(fiber.StatusInternalServerError, "Internal Server Error - bad message type")
+		}
+		if err = validateBatch(batch, msgs); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "Bad Request - "+err.Error())
+		}
+
+		if err = validator.ValidateTransactionSignature(tx, ""); err != nil {
+			return httpResultFromError(err, true)
+		}
+
+		return submitTransaction(ctx, world, batchType.ID(), batch, tx)
 	}
 }
 
+// validateBatch checks that the batch has messages, and that each of them is a registered message that can be part
+// of a batch and can be decoded.
+func validateBatch(batch sign.Batch, msgs map[string]map[string]types.Message) error {
+	if len(batch.Messages) == 0 {
+		return sign.ErrEmptyBatch
+	}
+	for i, batchMsg := range batch.Messages {
+		group, name, _ := strings.Cut(batchMsg.Message, ".")
+		msgType, ok := msgs[group][name]
+		if !ok {
+			return eris.Errorf("message %d: unknown message %q", i, batchMsg.Message)
+		}
+		if group == types.BatchMessageGroup && name == types.BatchMessageName ||
+			batchMsg.Message == "persona."+personaMsg.CreatePersonaMessageName {
+			return eris.Errorf("message %d: %q can't be part of a batch", i, batchMsg.Message)
+		}
+		if _, err := msgType.Decode(batchMsg.Body); err != nil {
+			return eris.Errorf("message %d: failed to decode %q", i, batchMsg.Message)
+		}
+	}
+	return nil
+}
+
+// submitTransaction adds the transaction to the tx pool of the world, and responds with its hash and tick.
+func submitTransaction(
+	ctx *fiber.Ctx, world servertypes.ProviderWorld, id types.MessageID, msg any, tx *sign.Transaction,
+) error {
+	// TODO(scott): this should just deal with txpool instead of having to go through engine
+	tick, hash, err := world.SubmitTransaction(id, msg, tx)
+	if errors.Is(err, txpool.ErrTxRejected) {
+		// The tx pool is emptied by every tick, which runs at least once per second.
+		ctx.Set(fiber.HeaderRetryAfter, "1")
+		return fiber.NewError(fiber.StatusTooManyRequests, "Too Many Requests - "+err.Error())
+	} else if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "Internal Server Error - "+err.Error())
+	}
+
+	return ctx.JSON(&PostTransactionResponse{
+		TxHash: string(hash),
+		Tick:   tick,
+	})
+}
+
 // NOTE: duplication for cleaner swagger docs
 // PostTransaction godoc
 //
@@ -99,6 +195,7 @@ func PostTransaction(
 //	@Failure      400     {string}  string                   "Invalid request parameter"
 //	@Failure      403     {string}  string                   "Forbidden"
 //	@Failure      408     {string}  string                   "Request Timeout - message expired"
+//	@Failure      429     {string}  string                   "Too Many Requests - rejected by the tx pool limits"
 //	@Router       /tx/game/{txName} [post]
 func PostGameTransaction(
 	world servertypes.ProviderWorld, msgs map[string]map[string]types.Message, validator *validator.SignatureValidator,
@@ -119,6 +216,7 @@ func PostGameTransaction(
 //	@Failure      401     {string}  string                   "Unauthorized - signature was invalid"
 //	@Failure      403     {string}  string                   "Forbidden"
 //	@Failure      408     {string}  string                   "Request Timeout - message expired"
+//	@Failure      429     {string}  string                   "Too Many Requests - rejected by the tx pool limits"
 //	@Failure      500     {string}  string                   "Internal Server Error - unexpected cache errors"
 //	@Router       /tx/persona/create-persona [post]
 func PostPersonaTransaction(
@@ -127,6 +225,27 @@ func PostPersonaTransaction(
 	return PostTransaction(world, msgs, validator)
 }
 
+// GetTransactionStatus godoc
+//
+//	@Summary      Retrieves the status of a transaction
+//	@Description  Reports whether a transaction is pending, processed, failed or unknown, along with the tick it was
+//	@Description  processed in and its receipt. Receipts older than the receipt history are only found when
+//	@Description  CARDINAL_RECEIPT_LOG is enabled.
+//	@Produce      application/json
+//	@Param        txHash  path      string                   true  "Hash of the transaction"
+//	@Success      200     {object}  types.TransactionStatus  "Status of the transaction"
+//	@Failure      500     {string}  string                   "Internal Server Error"
+//	@Router       /tx/{txHash} [get]
+func GetTransactionStatus(world servertypes.ProviderWorld) func(*fiber.Ctx) error {
+	return func(ctx *fiber.Ctx) error {
+		status, err := world.GetTransactionStatus(types.TxHash(ctx.Params("hash")))
+		if err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+		}
+		return ctx.JSON(status)
+	}
+}
+
 func extractTx(ctx *fiber.Ctx, validator *validator.SignatureValidator) (*sign.Transaction, error) {
 	var tx *sign.Transaction
 	var err error
@@ -0,0 +1,159 @@
+package handler
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/gofiber/contrib/websocket"
+	"github.com/rs/zerolog/log"
+)
+
+// wsBufferSize is the number of messages that can be queued for a websocket client. A client that falls further
+// behind is disconnected, so a slow client never delays a tick.
+const wsBufferSize = 64
+
+// closeTimeout is how long to wait to tell the client that the connection is being closed.
+const closeTimeout = time.Second
+
+// wsClient queues the messages sent to a websocket connection, and writes them from a single goroutine, so they can
+// be sent while a tick is being published.
+type wsClient struct {
+	conn     *websocket.Conn
+	out      chan []byte
+	done     chan struct{}
+	closed   chan struct{}
+	stopOnce sync.Once
+}
+
+func newWSClient(conn *websocket.Conn) *wsClient {
+	return &wsClient{
+		conn:   conn,
+		out:    make(chan []byte, wsBufferSize),
+		done:   make(chan struct{}),
+		closed: make(chan struct{}),
+	}
+}
+
+// serve writes the queued messages, and calls handle with every message read from the connection, until the
+// connection is closed.
+func (c *wsClient) serve(handle func(bz []byte)) {
+	var writer sync.WaitGroup
+	writer.Add(1)
+	go func() {
+		defer writer.Done()
+		c.writeMessages()
+	}()
+	defer func() {
+		c.stop()
+		writer.Wait()
+		<-c.closed
+	}()
+
+	for {
+		_, bz, err := c.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		handle(bz)
+	}
+}
+
+// send queues the message without blocking. The connection is closed if the queue is full.
+func (c *wsClient) send(bz []byte) {
+	select {
+	case <-c.done:
+	case c.out <- bz:
+	default:
+		log.Warn().Msg("closing websocket connection because the client is not reading its messages")
+		c.stop()
+	}
+}
+
+func (c *wsClient) sendJSON(msg any) {
+	bz, err := json.Marshal(msg)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to encode websocket message")
+		return
+	}
+	c.send(bz)
+}
+
+func (c *wsClient) writeMessages() {
+	for {
+		select {
+		case <-c.done:
+			return
+		case bz := <-c.out:
+			if err := c.conn.WriteMessage(websocket.TextMessage, bz); err != nil {
+				c.stop()
+				return
+			}
+		}
+	}
+}
+
+// stop stops writing messages, and closes the connection so that the read loop returns. The connection is closed in
+// the background, so stopping a client never blocks the caller.
+func (c *wsClient) stop() {
+	c.stopOnce.Do(func() {
+		close(c.done)
+		go func() {
+			defer close(c.closed)
+			_ = c.conn.WriteControl(websocket.CloseMessage, []byte{}, time.Now().Add(closeTimeout))
+			_ = c.conn.Close()
+		}()
+	})
+}
+
+// wsClients holds the open connections of an endpoint.
+type wsClients[C interface {
+	comparable
+	stop()
+}] struct {
+	mux    sync.Mutex
+	active map[C]struct{}
+}
+
+func newWSClients[C interface {
+	comparable
+	stop()
+}]() wsClients[C] {
+	return wsClients[C]{active: make(map[C]struct{})}
+}
+
+// CloseAll closes every open connection.
+func (cs *wsClients[C]) CloseAll() {
+	cs.mux.Lock()
+	defer cs.mux.Unlock()
+	for c := range cs.active {
+		c.stop()
+	}
+}
+
+// Len returns the number of open connections.
+func (cs *wsClients[C]) Len() int {
+	cs.mux.Lock()
+	defer cs.mux.Unlock()
+	return len(cs.active)
+}
+
+func (cs *wsClients[C]) add(c C) {
+	cs.mux.Lock()
+	defer cs.mux.Unlock()
+	cs.active[c] = struct{}{}
+}
+
+func (cs *wsClients[C]) remove(c C) {
+	cs.mux.Lock()
+	defer cs.mux.Unlock()
+	delete(cs.active, c)
+}
+
+func (cs *wsClients[C]) each(fn func(c C)) {
+	cs.mux.Lock()
+	defer cs.mux.Unlock()
+	for c := range cs.active {
+		fn(c)
+	}
+}
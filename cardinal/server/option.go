@@ -36,3 +36,11 @@ func WithHashCacheSize(sizeKB uint) Option {
 		s.config.messageHashCacheSizeKB = sizeKB
 	}
 }
+
+// WithMaxSubscriptionsPerConnection sets the number of subscriptions a /subscriptions connection can hold at once.
+// The default is 64.
+func WithMaxSubscriptionsPerConnection(limit uint) Option {
+	return func(s *Server) {
+		s.config.maxSubscriptions = limit
+	}
+}
@@ -5,8 +5,12 @@ import (
 	"errors"
 	"net/http"
 
+	"github.com/ethereum/go-ethereum/crypto"
+
 	"pkg.world.dev/world-engine/cardinal"
 	"pkg.world.dev/world-engine/cardinal/server/handler"
+	"pkg.world.dev/world-engine/cardinal/storage"
+	"pkg.world.dev/world-engine/cardinal/types"
 	"pkg.world.dev/world-engine/sign"
 )
 
@@ -77,3 +81,212 @@ func (s *ServerTestSuite) TestReceiptsQuery() {
 	s.Require().Equal(string(expectedJSON1), string(json1))
 	s.Require().Equal(string(expectedJSON2), string(json2))
 }
+
+func (s *ServerTestSuite) TestReceiptLogQueries() {
+	s.T().Setenv("CARDINAL_RECEIPT_LOG", "true")
+	// Only the receipts of the last tick are kept in memory, the receipt log keeps the rest.
+	s.setupWorld(cardinal.WithReceiptHistorySize(1))
+	world := s.world
+	type fooIn struct{ X int }
+	type fooOut struct{ Y int }
+	msgName := "foo"
+	s.Require().NoError(cardinal.RegisterMessage[fooIn, fooOut](world, msgName))
+	err := cardinal.RegisterSystems(world, func(ctx cardinal.WorldContext) error {
+		return cardinal.EachMessage[fooIn, fooOut](ctx, func(tx cardinal.TxData[fooIn]) (fooOut, error) {
+			if err := ctx.EmitEvent(map[string]any{"x": tx.Msg.X}); err != nil {
+				return fooOut{}, err
+			}
+			if err := ctx.EmitEventToPersona(tx.Tx.PersonaTag, map[string]any{"secret": tx.Msg.X}); err != nil {
+				return fooOut{}, err
+			}
+			return fooOut{Y: tx.Msg.X * 2}, nil
+		})
+	})
+	s.Require().NoError(err)
+
+	fooMsg, ok := world.GetMessageByFullName("game." + msgName)
+	s.Require().True(ok)
+	_, alphaHash := world.AddTransaction(fooMsg.ID(), fooIn{X: 1}, &sign.Transaction{PersonaTag: "alpha"})
+	s.fixture.DoTick()
+	s.fixture.DoTick()
+	_, betaHash := world.AddTransaction(fooMsg.ID(), fooIn{X: 2}, &sign.Transaction{PersonaTag: "beta"})
+	s.fixture.DoTick()
+	_, alphaHash2 := world.AddTransaction(fooMsg.ID(), fooIn{X: 3}, &sign.Transaction{PersonaTag: "alpha", Salt: 1})
+	s.fixture.DoTick()
+	lastTick := world.CurrentTick()
+	s.createPersona("alpha")
+	s.createPersona("beta")
+
+	res := s.postSigned("query/receipts/tx", "alpha", handler.GetReceiptRequest{TxHash: string(alphaHash)})
+	s.Require().Equal(http.StatusOK, res.StatusCode)
+	var rec storage.ReceiptRecord
+	s.Require().NoError(json.NewDecoder(res.Body).Decode(&rec))
+	s.Require().Equal(string(alphaHash), rec.TxHash)
+	s.Require().Equal(uint64(0), rec.Tick)
+	s.Require().Equal("alpha", rec.PersonaTag)
+	s.Require().JSONEq(`{"Y": 2}`, string(rec.Result))
+
+	res = s.postSigned("query/receipts/tx", "alpha", handler.GetReceiptRequest{TxHash: "unknown"})
+	s.Require().Equal(http.StatusNotFound, res.StatusCode)
+	// The receipt of another persona can't be read.
+	res = s.postSigned("query/receipts/tx", "alpha", handler.GetReceiptRequest{TxHash: string(betaHash)})
+	s.Require().Equal(http.StatusNotFound, res.StatusCode)
+	// A request that is not signed is rejected.
+	res = s.fixture.Post("query/receipts/tx", handler.GetReceiptRequest{TxHash: string(alphaHash)})
+	s.Require().NotEqual(http.StatusOK, res.StatusCode)
+
+	res = s.postSigned("query/receipts/range", "beta", handler.ListTickHistoryRequest{StartTick: 1, EndTick: 4})
+	s.Require().Equal(http.StatusOK, res.StatusCode)
+	var rangeReply handler.ListTickHistoryResponse
+	s.Require().NoError(json.NewDecoder(res.Body).Decode(&rangeReply))
+	s.Require().Equal("beta", rangeReply.PersonaTag)
+	s.Require().Equal(uint64(1), rangeReply.StartTick)
+	s.Require().Equal(uint64(4), rangeReply.EndTick)
+	// Tick 1 had no transactions, so it is not in the log.
+	s.Require().Len(rangeReply.Ticks, 2)
+	tick := rangeReply.Ticks[0]
+	s.Require().Equal(uint64(2), tick.Tick)
+	s.Require().Len(tick.Receipts, 1)
+	s.Require().Equal(string(betaHash), tick.Receipts[0].TxHash)
+	// Events sent to a persona are not kept in the log.
+	s.Require().Len(tick.Events, 1)
+	s.Require().JSONEq(`{"x": 2}`, string(tick.Events[0]))
+	// The receipts of other personas are left out, but the events are not.
+	tick = rangeReply.Ticks[1]
+	s.Require().Equal(uint64(3), tick.Tick)
+	s.Require().Empty(tick.Receipts)
+	s.Require().Len(tick.Events, 1)
+	s.Require().JSONEq(`{"x": 3}`, string(tick.Events[0]))
+
+	// The receipts of a persona can only be read with a request signed by the persona.
+	res = s.postSigned("query/receipts/persona", "alpha", handler.ListPersonaReceiptsRequest{EndTick: lastTick})
+	s.Require().Equal(http.StatusOK, res.StatusCode)
+	var personaReply handler.ListPersonaReceiptsResponse
+	s.Require().NoError(json.NewDecoder(res.Body).Decode(&personaReply))
+	s.Require().Equal("alpha", personaReply.PersonaTag)
+	s.Require().Equal(uint64(0), personaReply.StartTick)
+	s.Require().Equal(lastTick, personaReply.EndTick)
+	s.Require().Len(personaReply.Receipts, 2)
+	s.Require().Equal(string(alphaHash), personaReply.Receipts[0].TxHash)
+	s.Require().Equal(string(alphaHash2), personaReply.Receipts[1].TxHash)
+
+	otherKey, err := crypto.GenerateKey()
+	s.Require().NoError(err)
+	for _, path := range []string{"query/receipts/tx", "query/receipts/persona", "query/receipts/range"} {
+		tx, err := sign.NewTransaction(otherKey, "alpha", world.Namespace(), &handler.ListPersonaReceiptsRequest{})
+		s.Require().NoError(err)
+		res = s.fixture.Post(path, tx)
+		s.Require().Equal(http.StatusUnauthorized, res.StatusCode, path)
+	}
+}
+
+// postSigned posts a transaction signed by the persona, whose body is the request.
+func (s *ServerTestSuite) postSigned(path, personaTag string, request any) *http.Response {
+	tx, err := sign.NewTransaction(s.privateKey, personaTag, s.world.Namespace(), request)
+	s.Require().NoError(err)
+	return s.fixture.Post(path, tx)
+}
+
+func (s *ServerTestSuite) TestReceiptLogIsDisabledByDefault() {
+	s.setupWorld()
+	s.fixture.DoTick()
+	res := s.fixture.Post("query/receipts/range", handler.ListTickHistoryRequest{})
+	s.Require().Equal(http.StatusNotImplemented, res.StatusCode)
+}
+
+func (s *ServerTestSuite) TestReceiptLogKeepsTheRetainedTicks() {
+	s.T().Setenv("CARDINAL_RECEIPT_LOG", "true")
+	s.T().Setenv("CARDINAL_RECEIPT_LOG_RETENTION", "2")
+	s.setupWorld()
+	world := s.world
+	type fooIn struct{}
+	type fooOut struct{}
+	s.Require().NoError(cardinal.RegisterMessage[fooIn, fooOut](world, "foo"))
+	err := cardinal.RegisterSystems(world, func(ctx cardinal.WorldContext) error {
+		return cardinal.EachMessage[fooIn, fooOut](ctx, func(cardinal.TxData[fooIn]) (fooOut, error) {
+			return fooOut{}, nil
+		})
+	})
+	s.Require().NoError(err)
+	fooMsg, ok := world.GetMessageByFullName("game.foo")
+	s.Require().True(ok)
+	s.fixture.StartWorld()
+	s.createPersona("alpha")
+	var hashes []types.TxHash
+	for i := 0; i < 4; i++ {
+		_, txHash := world.AddTransaction(fooMsg.ID(), fooIn{}, &sign.Transaction{PersonaTag: "alpha", Salt: uint16(i)})
+		hashes = append(hashes, txHash)
+		s.fixture.DoTick()
+	}
+
+	res := s.postSigned("query/receipts/range", "alpha", &handler.ListTickHistoryRequest{})
+	s.Require().Equal(http.StatusOK, res.StatusCode)
+	var reply handler.ListTickHistoryResponse
+	s.Require().NoError(json.NewDecoder(res.Body).Decode(&reply))
+	s.Require().Len(reply.Ticks, 2)
+	s.Require().Equal(uint64(3), reply.Ticks[0].Tick)
+	s.Require().Equal(uint64(4), reply.Ticks[1].Tick)
+
+	res = s.postSigned("query/receipts/tx", "alpha", handler.GetReceiptRequest{TxHash: string(hashes[0])})
+	s.Require().Equal(http.StatusNotFound, res.StatusCode)
+	res = s.postSigned("query/receipts/tx", "alpha", handler.GetReceiptRequest{TxHash: string(hashes[3])})
+	s.Require().Equal(http.StatusOK, res.StatusCode)
+}
+
+func (s *ServerTestSuite) TestTransactionStatus() {
+	s.T().Setenv("CARDINAL_RECEIPT_LOG", "true")
+	s.setupWorld(cardinal.WithReceiptHistorySize(1))
+	world := s.world
+	type fooIn struct{ Fail bool }
+	type fooOut struct{ Y int }
+	s.Require().NoError(cardinal.RegisterMessage[fooIn, fooOut](world, "foo"))
+	err := cardinal.RegisterSystems(world, func(ctx cardinal.WorldContext) error {
+		return cardinal.EachMessage[fooIn, fooOut](ctx, func(tx cardinal.TxData[fooIn]) (fooOut, error) {
+			if tx.Msg.Fail {
+				return fooOut{}, errors.New("failed on purpose")
+			}
+			return fooOut{Y: 7}, nil
+		})
+	})
+	s.Require().NoError(err)
+	fooMsg, ok := world.GetMessageByFullName("game.foo")
+	s.Require().True(ok)
+
+	getStatus := func(hash types.TxHash) types.TransactionStatus {
+		res := s.fixture.Get("tx/" + string(hash))
+		s.Require().Equal(http.StatusOK, res.StatusCode)
+		var status types.TransactionStatus
+		s.Require().NoError(json.NewDecoder(res.Body).Decode(&status))
+		s.Require().Equal(hash, status.TxHash)
+		return status
+	}
+
+	s.fixture.DoTick()
+	_, okHash := world.AddTransaction(fooMsg.ID(), fooIn{}, &sign.Transaction{PersonaTag: "alpha"})
+	_, failHash := world.AddTransaction(fooMsg.ID(), fooIn{Fail: true}, &sign.Transaction{PersonaTag: "beta"})
+	status := getStatus(okHash)
+	s.Require().Equal(types.TxStatusPending, status.Status)
+	s.Require().Nil(status.Tick)
+
+	s.fixture.DoTick()
+	status = getStatus(okHash)
+	s.Require().Equal(types.TxStatusProcessed, status.Status)
+	s.Require().Equal(uint64(1), *status.Tick)
+	s.Require().JSONEq(`{"Y": 7}`, string(status.Result))
+	s.Require().Empty(status.Errors)
+
+	status = getStatus(failHash)
+	s.Require().Equal(types.TxStatusFailed, status.Status)
+	s.Require().Equal([]string{"failed on purpose"}, status.Errors)
+
+	// Once the receipt leaves the receipt history, it is read from the receipt log.
+	s.fixture.DoTick()
+	s.fixture.DoTick()
+	status = getStatus(failHash)
+	s.Require().Equal(types.TxStatusFailed, status.Status)
+	s.Require().Equal(uint64(1), *status.Tick)
+	s.Require().Equal([]string{"failed on purpose"}, status.Errors)
+
+	status = getStatus("0xunknown")
+	s.Require().Equal(types.TxStatusUnknown, status.Status)
+}
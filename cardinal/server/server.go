@@ -9,7 +9,6 @@ import (
 	"encoding/json"
 	"time"
 
-	"github.com/gofiber/contrib/socketio"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
 	"github.com/gofiber/swagger"
@@ -29,6 +28,8 @@ const (
 	shutdownTimeout          = 5 * time.Second
 	defaultMessageExpiration = 10   // seconds
 	defaultHashCacheSizeKB   = 1024 // default to 1MB hash cache
+	// defaultMaxSubscriptions is the default number of subscriptions a /subscriptions connection can hold.
+	defaultMaxSubscriptions = 64
 )
 
 type config struct {
@@ -37,12 +38,15 @@ type config struct {
 	isSignatureValidationDisabled bool
 	messageExpirationSeconds      uint
 	messageHashCacheSizeKB        uint
+	maxSubscriptions              uint
 }
 
 type Server struct {
-	app       *fiber.App
-	config    config
-	validator *validator.SignatureValidator
+	app         *fiber.App
+	config      config
+	validator   *validator.SignatureValidator
+	events      *handler.EventClients
+	subscribers *handler.Subscribers
 }
 
 // New returns an HTTP server with handlers for all QueryTypes and MessageTypes.
@@ -58,13 +62,16 @@ func New(
 	})
 
 	s := &Server{
-		app: app,
+		app:         app,
+		events:      handler.NewEventClients(),
+		subscribers: handler.NewSubscribers(),
 		config: config{
 			port:                          defaultPort,
 			isSwaggerDisabled:             false,
 			isSignatureValidationDisabled: false,
 			messageExpirationSeconds:      defaultMessageExpiration,
 			messageHashCacheSizeKB:        defaultHashCacheSizeKB,
+			maxSubscriptions:              defaultMaxSubscriptions,
 		},
 	}
 	for _, opt := range opts {
@@ -80,8 +87,12 @@ func New(
 		world, // world is a provider of signature addresses
 	)
 
-	// Enable CORS
-	app.Use(cors.New())
+	if err := handler.RegisterWebSocketMetrics(world.MetricsRegistry(), s.events, s.subscribers); err != nil {
+		return nil, eris.Wrap(err, "failed to register the websocket metrics")
+	}
+
+	// Enable CORS, and let browsers read the header that pages the debug state
+	app.Use(cors.New(cors.Config{ExposeHeaders: handler.NextCursorHeader}))
 
 	// Register routes
 	s.setupRoutes(world, messages, components)
@@ -115,22 +126,29 @@ func (s *Server) Serve(ctx context.Context) error {
 	return nil
 }
 
+// BroadcastEvent sends the event to every client of /events.
 func (s *Server) BroadcastEvent(event any) error {
 	eventBz, err := json.Marshal(event)
 	if err != nil {
 		return err
 	}
-	socketio.Broadcast(eventBz)
+	s.events.Broadcast(eventBz)
 	return nil
 }
 
+// PublishEvent sends each client of /events the event returned by eventFor, which depends on the persona the client
+// authenticated as and the topics it subscribed to. A client is sent nothing if eventFor returns nil.
+func (s *Server) PublishEvent(eventFor func(client servertypes.EventClient) any) {
+	s.events.Publish(eventFor)
+}
+
 // Shutdown gracefully shuts down the server and closes all active websocket connections.
 func (s *Server) shutdown() error {
 	log.Info().Msg("Shutting down server")
 
 	// Close websocket connections
-	socketio.Broadcast([]byte(""), socketio.CloseMessage)
-	socketio.Fire(socketio.EventClose, nil)
+	s.events.CloseAll()
+	s.subscribers.CloseAll()
 
 	// Gracefully shutdown Fiber server
 	if err := s.app.ShutdownWithTimeout(shutdownTimeout); err != nil {
@@ -167,21 +185,34 @@ func (s *Server) setupRoutes(
 
 	// Route: /events/
 	s.app.Use("/events", handler.WebSocketUpgrader)
-	s.app.Get("/events", handler.WebSocketEvents())
+	s.app.Get("/events", handler.WebSocketEvents(s.events, s.validator))
+
+	// Route: /subscriptions/
+	s.app.Use("/subscriptions", handler.WebSocketUpgrader)
+	s.app.Get("/subscriptions", handler.WebSocketSubscriptions(world, s.subscribers, s.config.maxSubscriptions))
 
 	// Route: /world
 	s.app.Get("/world", handler.GetWorld(world, components, messages, world.Namespace()))
 
+	// Route: /openapi.json
+	s.app.Get("/openapi.json", handler.GetOpenAPI(world))
+
 	// Route: /...
-	s.app.Get("/health", handler.GetHealth())
+	s.app.Get("/health", handler.GetHealth(world))
+	s.app.Get("/metrics", handler.GetMetrics(world.MetricsRegistry()))
 
 	// Route: /query/...
 	query := s.app.Group("/query")
 	query.Post("/receipts/list", handler.GetReceipts(world))
+	query.Post("/receipts/tx", handler.GetReceiptByTxHash(world, s.validator))
+	query.Post("/receipts/persona", handler.GetPersonaReceipts(world, s.validator))
+	query.Post("/receipts/range", handler.GetTickHistory(world, s.validator))
 	query.Post("/:group/:name", handler.PostQuery(world))
 
 	// Route: /tx/...
 	tx := s.app.Group("/tx")
+	tx.Get("/:hash", handler.GetTransactionStatus(world))
+	tx.Post("/batch", handler.PostBatchTransaction(world, msgIndex, s.validator))
 	tx.Post("/:group/:name", handler.PostTransaction(world, msgIndex, s.validator))
 
 	// Route: /cql
@@ -3,6 +3,7 @@ package server_test
 import (
 	"crypto/ecdsa"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"math/rand"
@@ -124,6 +125,122 @@ func (s *ServerTestSuite) TestGetWorld() {
 	assert.Equal(s.T(), s.world.Namespace(), result.Namespace)
 }
 
+func (s *ServerTestSuite) TestHealthReportsFailingSystems() {
+	s.setupWorld()
+	err := cardinal.RegisterSystem(s.world, func(cardinal.WorldContext) error {
+		return errors.New("out of mana")
+	}, cardinal.WithFailurePolicy(cardinal.SkipSystem), cardinal.WithCircuitBreaker(1, 10))
+	s.Require().NoError(err)
+	s.fixture.DoTick()
+
+	res := s.fixture.Get("/health")
+	s.Require().Equal(fiber.StatusOK, res.StatusCode)
+	var result handler.GetHealthResponse
+	s.Require().NoError(json.Unmarshal([]byte(s.readBody(res.Body)), &result))
+	s.Require().True(result.IsServerRunning)
+	s.Require().False(result.IsHealthy)
+	s.Require().Len(result.Systems, 1)
+	s.Require().Equal("skip_system", result.Systems[0].Policy)
+	s.Require().True(result.Systems[0].CircuitOpen)
+	s.Require().Contains(result.Systems[0].LastError, "out of mana")
+}
+
+func (s *ServerTestSuite) TestHealthReportsSlowTicks() {
+	// A tick rate of 20 ticks per second gives each tick a budget of 50ms.
+	s.T().Setenv("CARDINAL_TICK_RATE", "20")
+	s.setupWorld()
+	slowSystem := func(cardinal.WorldContext) error {
+		time.Sleep(60 * time.Millisecond)
+		return nil
+	}
+	s.Require().NoError(cardinal.RegisterSystems(s.world, slowSystem))
+	s.fixture.DoTick()
+
+	res := s.fixture.Get("/health")
+	s.Require().Equal(fiber.StatusOK, res.StatusCode)
+	var result handler.GetHealthResponse
+	s.Require().NoError(json.Unmarshal([]byte(s.readBody(res.Body)), &result))
+	s.Require().True(result.IsHealthy)
+	s.Require().InDelta(50, result.Ticks.BudgetMs, 0)
+	s.Require().Equal(cardinal.TickOverrunSkip, result.Ticks.OverrunPolicy)
+	s.Require().Equal(uint64(1), result.Ticks.Overruns)
+	s.Require().GreaterOrEqual(result.Ticks.SkippedTicks, uint64(1))
+	s.Require().Equal(uint64(0), *result.Ticks.LastOverrunTick)
+	s.Require().NotEmpty(result.Ticks.SlowestSystems)
+	s.Require().Equal(cardinal.SystemName(slowSystem), result.Ticks.SlowestSystems[0].Name)
+	s.Require().GreaterOrEqual(result.Ticks.SlowestSystems[0].DurationMs, float64(60))
+}
+
+func (s *ServerTestSuite) TestMetrics() {
+	s.setupWorld()
+	s.fixture.DoTick()
+	personaTag := s.CreateRandomPersona()
+	moveMessage, ok := s.world.GetMessageByFullName("game." + moveMsgName)
+	s.Require().True(ok)
+	s.runTx(personaTag, moveMessage, MoveMsgInput{Direction: "up"})
+
+	res := s.fixture.Get("/metrics")
+	s.Require().Equal(fiber.StatusOK, res.StatusCode)
+	body := s.readBody(res.Body)
+	for _, metric := range []string{
+		"cardinal_tick_duration_seconds_count 3",
+		"cardinal_system_duration_seconds_count{system=",
+		"cardinal_tick 3",
+		"cardinal_tx_pool_size 0",
+		`cardinal_transactions_total{message="game.move"} 1`,
+		`cardinal_transactions_total{message="persona.create-persona"} 1`,
+		"cardinal_archetypes ",
+		"cardinal_entities ",
+		"cardinal_redis_pipeline_duration_seconds_count ",
+		`cardinal_websocket_clients{endpoint="events"} 0`,
+		`cardinal_websocket_clients{endpoint="subscriptions"} 0`,
+	} {
+		s.Require().Contains(body, metric)
+	}
+	s.Require().NotContains(body, "cardinal_receipt_errors_total{")
+}
+
+func (s *ServerTestSuite) TestOpenAPIDescribesRegisteredTypes() {
+	s.setupWorld()
+	s.fixture.DoTick()
+
+	res := s.fixture.Get("/openapi.json")
+	s.Require().Equal(fiber.StatusOK, res.StatusCode)
+	var doc types.OpenAPI
+	s.Require().NoError(json.Unmarshal([]byte(s.readBody(res.Body)), &doc))
+	s.Require().Equal(types.OpenAPIVersion, doc.OpenAPI)
+	s.Require().Equal(s.world.Namespace(), doc.Cardinal.Namespace)
+
+	move, ok := doc.Paths["/tx/game/move"]
+	s.Require().True(ok)
+	s.Require().Equal("game.move", move.Post.Message.FullName)
+	s.Require().JSONEq(`{"$ref":"#/components/schemas/MoveMsgInput"}`, string(move.Post.Message.Input))
+	s.Require().JSONEq(`{"$ref":"#/components/schemas/MoveMessageOutput"}`, string(move.Post.Message.Output))
+	s.Require().Contains(string(move.Post.RequestBody.Content["application/json"].Schema),
+		`"body":{"$ref":"#/components/schemas/MoveMsgInput"}`)
+	_, ok = doc.Paths["/tx/persona/create-persona"]
+	s.Require().True(ok)
+	_, ok = doc.Paths["/tx/batch"]
+	s.Require().True(ok)
+
+	location, ok := doc.Paths["/query/game/location"]
+	s.Require().True(ok)
+	s.Require().JSONEq(`{"$ref":"#/components/schemas/QueryLocationResponse"}`,
+		string(location.Post.Responses["200"].Content["application/json"].Schema))
+
+	s.Require().JSONEq(`{"$ref":"#/components/schemas/LocationComponent"}`,
+		string(doc.Cardinal.Components["location"]))
+	s.Require().JSONEq(`{
+		"type": "object",
+		"properties": {"Direction": {"type": "string"}},
+		"additionalProperties": false,
+		"required": ["Direction"]
+	}`, string(doc.Components.Schemas["MoveMsgInput"]))
+	for _, name := range []string{"Transaction", "PostTransactionResponse", "LocationComponent", "QueryLocationRequest"} {
+		s.Require().Contains(doc.Components.Schemas, name)
+	}
+}
+
 // TestSwaggerEndpointsAreActuallyCreated verifies the non-variable endpoints that are declared in the swagger.yml file
 // actually have endpoints when the cardinal server starts.
 func (s *ServerTestSuite) TestSwaggerEndpointsAreActuallyCreated() {
@@ -336,6 +453,91 @@ func (s *ServerTestSuite) TestRejectDuplicateTransactionHash() {
 	s.Require().Equal(fiber.StatusForbidden, res.StatusCode, s.readBody(res.Body))
 }
 
+func (s *ServerTestSuite) TestRejectTransactionsOverThePersonaLimit() {
+	s.T().Setenv("CARDINAL_TX_PERSONA_LIMIT", "1")
+	s.setupWorld(cardinal.WithDisableSignatureVerification())
+	s.fixture.DoTick()
+	persona := s.CreateRandomPersona()
+	s.createPersona(persona)
+	moveMessage, ok := s.world.GetMessageByFullName("game." + moveMsgName)
+	s.Require().True(ok)
+	url := utils.GetTxURL(moveMessage.Group(), moveMessage.Name())
+
+	newTx := func(salt uint16) *sign.Transaction {
+		msgBz, err := json.Marshal(MoveMsgInput{Direction: "up"})
+		s.Require().NoError(err)
+		return &sign.Transaction{PersonaTag: persona, Salt: salt, Body: msgBz}
+	}
+
+	res := s.fixture.Post(url, newTx(1))
+	s.Require().Equal(fiber.StatusOK, res.StatusCode, s.readBody(res.Body))
+
+	// The persona already has a transaction waiting for the next tick.
+	res = s.fixture.Post(url, newTx(2))
+	s.Require().Equal(fiber.StatusTooManyRequests, res.StatusCode, s.readBody(res.Body))
+	s.Require().Equal("1", res.Header.Get(fiber.HeaderRetryAfter))
+
+	// The limit applies again from the next tick.
+	s.fixture.DoTick()
+	res = s.fixture.Post(url, newTx(3))
+	s.Require().Equal(fiber.StatusOK, res.StatusCode, s.readBody(res.Body))
+}
+
+func (s *ServerTestSuite) TestCanSendBatchTransaction() {
+	s.setupWorld()
+	s.fixture.DoTick()
+	persona := s.CreateRandomPersona()
+	up, err := sign.NewBatchMessage("game."+moveMsgName, MoveMsgInput{Direction: "up"})
+	s.Require().NoError(err)
+	right, err := sign.NewBatchMessage("game."+moveMsgName, MoveMsgInput{Direction: "right"})
+	s.Require().NoError(err)
+	tx, err := sign.NewBatchTransaction(s.privateKey, persona, s.world.Namespace(), up, right)
+	s.Require().NoError(err)
+
+	res := s.fixture.Post("/tx/batch", tx)
+	body := s.readBody(res.Body)
+	s.Require().Equal(fiber.StatusOK, res.StatusCode, body)
+	var txRes handler.PostTransactionResponse
+	s.Require().NoError(json.Unmarshal([]byte(body), &txRes))
+	s.fixture.DoTick()
+
+	res = s.fixture.Post("query/game/location", QueryLocationRequest{Persona: persona})
+	var loc LocationComponent
+	s.Require().NoError(json.Unmarshal([]byte(s.readBody(res.Body)), &loc))
+	s.Require().Equal(LocationComponent{1, 1}, loc)
+
+	res = s.fixture.Get("/tx/" + txRes.TxHash)
+	var status types.TransactionStatus
+	s.Require().NoError(json.Unmarshal([]byte(s.readBody(res.Body)), &status))
+	s.Require().Equal(types.TxStatusProcessed, status.Status)
+	var result types.BatchResult
+	s.Require().NoError(json.Unmarshal(status.Result, &result))
+	s.Require().Len(result.Results, 2)
+}
+
+func (s *ServerTestSuite) TestRejectBatchWithInvalidMessages() {
+	s.setupWorld()
+	s.fixture.DoTick()
+	persona := s.CreateRandomPersona()
+	move, err := sign.NewBatchMessage("game."+moveMsgName, MoveMsgInput{Direction: "up"})
+	s.Require().NoError(err)
+
+	testCases := []struct {
+		name string
+		msg  sign.BatchMessage
+	}{
+		{"unknown message", sign.BatchMessage{Message: "game.unknown", Body: move.Body}},
+		{"undecodable body", sign.BatchMessage{Message: move.Message, Body: json.RawMessage(`{"Direction":1}`)}},
+		{"nested batch", sign.BatchMessage{Message: "tx.batch", Body: json.RawMessage(`{"messages":[]}`)}},
+	}
+	for _, tc := range testCases {
+		tx, err := sign.NewBatchTransaction(s.privateKey, persona, s.world.Namespace(), move, tc.msg)
+		s.Require().NoError(err)
+		res := s.fixture.Post("/tx/batch", tx)
+		s.Require().Equal(fiber.StatusBadRequest, res.StatusCode, tc.name)
+	}
+}
+
 // Creates a transaction with the given message, and runs it in a tick.
 func (s *ServerTestSuite) runTx(personaTag string, msg types.Message, payload any) {
 	tx, err := sign.NewTransaction(s.privateKey, personaTag, s.world.Namespace(), payload)
@@ -493,3 +695,48 @@ func (s *ServerTestSuite) TestCQL_NonExistentComponent() {
 	err = json.Unmarshal([]byte(s.readBody(res.Body)), &result)
 	s.Require().Error(err)
 }
+
+func (s *ServerTestSuite) TestCQL_WhereOrderByLimit() {
+	s.setupWorld()
+	s.fixture.DoTick()
+
+	wCtx := cardinal.NewWorldContext(s.world)
+	for i := uint64(0); i < 10; i++ {
+		_, err := cardinal.Create(wCtx, LocationComponent{X: i, Y: i % 2})
+		assert.NilError(s.T(), err)
+	}
+
+	s.fixture.DoTick()
+
+	res := s.fixture.Post("/cql", handler.CQLQueryRequest{
+		CQL: "SELECT location FROM CONTAINS(location) WHERE location.X >= 2 AND location.Y = 0 " +
+			"ORDER BY location.X DESC LIMIT 2 OFFSET 1",
+	})
+	var result handler.CQLQueryResponse
+	err := json.Unmarshal([]byte(s.readBody(res.Body)), &result)
+	s.Require().NoError(err)
+	s.Require().Len(result.Results, 2)
+	for i, wantX := range []uint64{6, 4} {
+		s.Require().Len(result.Results[i].Data, 1)
+		var location LocationComponent
+		s.Require().NoError(json.Unmarshal(result.Results[i].Data[0], &location))
+		s.Require().Equal(LocationComponent{X: wantX, Y: 0}, location)
+	}
+}
+
+func (s *ServerTestSuite) TestCQL_WhereNonExistentField() {
+	s.setupWorld()
+	s.fixture.DoTick()
+
+	wCtx := cardinal.NewWorldContext(s.world)
+	_, err := cardinal.CreateMany(wCtx, 10, LocationComponent{})
+	assert.NilError(s.T(), err)
+
+	s.fixture.DoTick()
+
+	res := s.fixture.Post("/cql", handler.CQLQueryRequest{CQL: "CONTAINS(location) WHERE location.Z = 0"})
+	var result handler.CQLQueryResponse
+	err = json.Unmarshal([]byte(s.readBody(res.Body)), &result)
+	s.Require().NoError(err)
+	s.Require().Len(result.Results, 0)
+}
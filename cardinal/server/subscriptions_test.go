@@ -0,0 +1,108 @@
+package server_test
+
+import (
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"pkg.world.dev/world-engine/cardinal"
+	"pkg.world.dev/world-engine/cardinal/server/handler"
+	"pkg.world.dev/world-engine/cardinal/types"
+)
+
+func (s *ServerTestSuite) TestSubscriptions() {
+	s.setupWorld()
+	s.fixture.DoTick()
+
+	wCtx := cardinal.NewWorldContext(s.world)
+	still, err := cardinal.Create(wCtx, LocationComponent{X: 1, Y: 0})
+	s.Require().NoError(err)
+	moving, err := cardinal.Create(wCtx, LocationComponent{X: 2, Y: 5})
+	s.Require().NoError(err)
+	s.fixture.DoTick()
+
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL(s.fixture.BaseURL, "subscriptions"), nil)
+	s.Require().NoError(err)
+	defer conn.Close()
+
+	s.Require().NoError(conn.WriteJSON(handler.SubscriptionRequestMessage{
+		Type:                handler.SubscriptionMessageSubscribe,
+		ID:                  "moving",
+		SubscriptionRequest: types.SubscriptionRequest{CQL: "CONTAINS(location) WHERE location.Y > 0"},
+	}))
+	snapshot := s.readSubscriptionMessage(conn)
+	s.Require().Equal(handler.SubscriptionMessageSnapshot, snapshot.Type)
+	s.Require().Equal("moving", snapshot.ID)
+	s.Require().Len(snapshot.Entities, 1)
+	s.Require().Equal(moving, snapshot.Entities[0].ID)
+	s.Require().JSONEq(`{"X":2,"Y":5}`, string(snapshot.Entities[0].Components["location"]))
+
+	// The still entity starts moving, and the moving entity is removed.
+	wCtx = cardinal.NewWorldContext(s.world)
+	s.Require().NoError(cardinal.SetComponent[LocationComponent](wCtx, still, &LocationComponent{X: 1, Y: 1}))
+	s.Require().NoError(cardinal.Remove(wCtx, moving))
+	s.fixture.DoTick()
+
+	update := s.readSubscriptionMessage(conn)
+	s.Require().Equal(handler.SubscriptionMessageUpdate, update.Type)
+	s.Require().Equal("moving", update.ID)
+	s.Require().Len(update.Entities, 1)
+	s.Require().Equal(still, update.Entities[0].ID)
+	s.Require().JSONEq(`{"X":1,"Y":1}`, string(update.Entities[0].Components["location"]))
+	s.Require().Equal([]types.EntityID{moving}, update.Removed)
+
+	s.Require().NoError(conn.WriteJSON(handler.SubscriptionRequestMessage{
+		Type: handler.SubscriptionMessageUnsubscribe,
+		ID:   "moving",
+	}))
+	wCtx = cardinal.NewWorldContext(s.world)
+	s.Require().NoError(cardinal.SetComponent[LocationComponent](wCtx, still, &LocationComponent{X: 1, Y: 2}))
+	s.fixture.DoTick()
+
+	// Messages are sent in order, so the error is the next message only if no update was sent after unsubscribing.
+	s.Require().NoError(conn.WriteJSON(handler.SubscriptionRequestMessage{
+		Type:                handler.SubscriptionMessageSubscribe,
+		ID:                  "invalid",
+		SubscriptionRequest: types.SubscriptionRequest{Components: []string{"missing"}},
+	}))
+	msg := s.readSubscriptionMessage(conn)
+	s.Require().Equal(handler.SubscriptionMessageError, msg.Type)
+	s.Require().Equal("invalid", msg.ID)
+	s.Require().NotEmpty(msg.Error)
+}
+
+func (s *ServerTestSuite) readSubscriptionMessage(conn *websocket.Conn) handler.SubscriptionMessage {
+	s.Require().NoError(conn.SetReadDeadline(time.Now().Add(5 * time.Second)))
+	var msg handler.SubscriptionMessage
+	s.Require().NoError(conn.ReadJSON(&msg))
+	return msg
+}
+
+func (s *ServerTestSuite) TestSubscriptionsPerConnectionAreLimited() {
+	s.setupWorld(cardinal.WithMaxSubscriptionsPerConnection(1))
+	s.fixture.DoTick()
+
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL(s.fixture.BaseURL, "subscriptions"), nil)
+	s.Require().NoError(err)
+	defer conn.Close()
+
+	subscribe := func(id string) handler.SubscriptionMessage {
+		s.Require().NoError(conn.WriteJSON(handler.SubscriptionRequestMessage{
+			Type:                handler.SubscriptionMessageSubscribe,
+			ID:                  id,
+			SubscriptionRequest: types.SubscriptionRequest{Components: []string{"location"}},
+		}))
+		return s.readSubscriptionMessage(conn)
+	}
+	s.Require().Equal(handler.SubscriptionMessageSnapshot, subscribe("first").Type)
+	msg := subscribe("second")
+	s.Require().Equal(handler.SubscriptionMessageError, msg.Type)
+	s.Require().Contains(msg.Error, "more than 1 subscriptions")
+
+	// Unsubscribing makes room for another subscription.
+	s.Require().NoError(conn.WriteJSON(handler.SubscriptionRequestMessage{
+		Type: handler.SubscriptionMessageUnsubscribe,
+		ID:   "first",
+	}))
+	s.Require().Equal(handler.SubscriptionMessageSnapshot, subscribe("second").Type)
+}
@@ -1,9 +1,12 @@
 package types
 
 import (
+	"github.com/prometheus/client_golang/prometheus"
+
 	"pkg.world.dev/world-engine/cardinal/gamestate"
 	"pkg.world.dev/world-engine/cardinal/receipt"
 	"pkg.world.dev/world-engine/cardinal/server/validator"
+	"pkg.world.dev/world-engine/cardinal/storage"
 	"pkg.world.dev/world-engine/cardinal/types"
 	"pkg.world.dev/world-engine/sign"
 )
@@ -12,7 +15,7 @@ type ProviderWorld interface {
 	validator.SignerAddressProvider
 	UseNonce(signerAddress string, nonce uint64) error
 	GetSignerForPersonaTag(personaTag string, tick uint64) (addr string, err error)
-	AddTransaction(id types.MessageID, v any, sig *sign.Transaction) (uint64, types.TxHash)
+	SubmitTransaction(id types.MessageID, v any, sig *sign.Transaction) (uint64, types.TxHash, error)
 	Namespace() string
 	GetComponentByName(name string) (types.ComponentMetadata, error)
 	StoreReader() gamestate.Reader
@@ -20,7 +23,22 @@ type ProviderWorld interface {
 	CurrentTick() uint64
 	ReceiptHistorySize() uint64
 	GetTransactionReceiptsForTick(tick uint64) ([]receipt.Receipt, error)
+	ReceiptLog() (receiptLog storage.ReceiptLog, endTick uint64)
+	GetTransactionStatus(hash types.TxHash) (types.TransactionStatus, error)
+	Health() types.WorldHealth
 	EvaluateCQL(cql string) ([]types.EntityStateElement, error)
-	GetDebugState() ([]types.DebugStateElement, error)
+	GetDebugStatePage(opts types.EntityPageOptions) (ids []types.EntityID, nextCursor string, err error)
+	GetDebugStateElement(id types.EntityID) (element types.DebugStateElement, ok bool, err error)
 	BuildQueryFields() []types.FieldDetail
+	Subscribe(req types.SubscriptionRequest, send func(types.SubscriptionUpdate)) (unsubscribe func(), err error)
+	MetricsRegistry() *prometheus.Registry
+	OpenAPI() (types.OpenAPI, error)
+}
+
+// EventClient is a client of the /events websocket.
+type EventClient interface {
+	// PersonaTag returns the persona the client authenticated as, or an empty string.
+	PersonaTag() string
+	// IsSubscribed reports whether the client subscribed to the topic.
+	IsSubscribed(topic string) bool
 }
@@ -0,0 +1,112 @@
+package cardinal
+
+import (
+	"github.com/rotisserie/eris"
+
+	"pkg.world.dev/world-engine/cardinal/filter"
+	"pkg.world.dev/world-engine/cardinal/gamestate"
+	"pkg.world.dev/world-engine/cardinal/types"
+)
+
+// SpatialIndexOption is an option that can be passed to RegisterSpatialIndex.
+type SpatialIndexOption func(config *spatialIndexConfig)
+
+type spatialIndexConfig struct {
+	cellSize float64
+}
+
+// WithSpatialCellSize sets the width and height of the cells of the grid that holds the positions. Searches are
+// fastest when the cells are about the size of the regions that are searched.
+func WithSpatialCellSize(size float64) SpatialIndexOption {
+	return func(config *spatialIndexConfig) {
+		config.cellSize = size
+	}
+}
+
+// SpatialSearch is a search for entities by the position of their component T. The component must have a spatial
+// index, see RegisterSpatialIndex. It can be composed with other searches with And, Or and Not.
+type SpatialSearch[T types.Component] struct {
+	query gamestate.SpatialQuery
+}
+
+var _ Searchable = &SpatialSearch[types.Component]{}
+
+// WithinRadius searches for the entities whose position is at most radius away from x, y.
+func WithinRadius[T types.Component](x, y, radius float64) *SpatialSearch[T] {
+	return &SpatialSearch[T]{query: gamestate.SpatialQuery{Kind: gamestate.SpatialRadius, X: x, Y: y, Radius: radius}}
+}
+
+// WithinBox searches for the entities whose position is between minX, minY and maxX, maxY, inclusive.
+func WithinBox[T types.Component](minX, minY, maxX, maxY float64) *SpatialSearch[T] {
+	return &SpatialSearch[T]{query: gamestate.SpatialQuery{
+		Kind: gamestate.SpatialBox, MinX: minX, MinY: minY, MaxX: maxX, MaxY: maxY,
+	}}
+}
+
+// KNearest searches for the k entities that are the nearest to x, y. Each and Collect return the entities ordered by
+// distance, and then by ID.
+func KNearest[T types.Component](x, y float64, k int) *SpatialSearch[T] {
+	return &SpatialSearch[T]{query: gamestate.SpatialQuery{Kind: gamestate.SpatialNearest, X: x, Y: y, K: k}}
+}
+
+func (s *SpatialSearch[T]) lookup(wCtx WorldContext) ([]types.EntityID, error) {
+	var t T
+	c, err := wCtx.getComponentByName(t.Name())
+	if err != nil {
+		return nil, err
+	}
+	if err := wCtx.systemAccess().checkRead(c.Name()); err != nil {
+		return nil, err
+	}
+	return wCtx.storeReader().LookupSpatial(c, s.query)
+}
+
+func (s *SpatialSearch[T]) Each(wCtx WorldContext, callback CallbackFn) (err error) {
+	defer func() { defer panicOnFatalError(wCtx, err) }()
+
+	ids, err := s.lookup(wCtx)
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if !callback(id) {
+			return nil
+		}
+	}
+	return nil
+}
+
+func (s *SpatialSearch[T]) Collect(wCtx WorldContext) ([]types.EntityID, error) {
+	return s.lookup(wCtx)
+}
+
+func (s *SpatialSearch[T]) First(wCtx WorldContext) (types.EntityID, error) {
+	ids, err := s.lookup(wCtx)
+	if err != nil {
+		return 0, err
+	}
+	if len(ids) == 0 {
+		return 0, eris.New("No search results")
+	}
+	return ids[0], nil
+}
+
+func (s *SpatialSearch[T]) MustFirst(wCtx WorldContext) types.EntityID {
+	id, err := s.First(wCtx)
+	if err != nil {
+		panic("no search results")
+	}
+	return id
+}
+
+func (s *SpatialSearch[T]) Count(wCtx WorldContext) (int, error) {
+	ids, err := s.lookup(wCtx)
+	if err != nil {
+		return 0, err
+	}
+	return len(ids), nil
+}
+
+func (s *SpatialSearch[T]) evaluateSearch(wCtx WorldContext) []types.ArchetypeID {
+	return NewSearch().Entity(filter.Contains(filter.Component[T]())).evaluateSearch(wCtx)
+}
@@ -0,0 +1,51 @@
+package badger
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+/*
+	NONCE STORAGE:      USED_NONCES_<ADDRESS>/<NONCE> -> Empty value marking the nonce as used.
+	The nonce is encoded as a big endian uint64 so keys for a single address are sorted by nonce.
+
+	SCHEMA STORAGE:     COMPONENT_NAME_TO_SCHEMA_DATA/<COMPONENT_NAME> -> Schema of the component.
+
+	RECEIPT LOG:        RECEIPT_LOG_TICK/<TICK> -> JSON encoded storage.TickRecord.
+	                    RECEIPT_LOG_TX/<TX_HASH> -> The tick the transaction was processed in.
+	                    RECEIPT_LOG_PERSONA_<PERSONA_TAG>/<TICK><TX_HASH> -> Empty value indexing the receipts of a
+	                    persona.
+	Ticks are encoded as big endian uint64s so keys are sorted by tick.
+*/
+
+func (r *NonceStorage) noncePrefix(signerAddress string) []byte {
+	return []byte(fmt.Sprintf("USED_NONCES_%s/", signerAddress))
+}
+
+func (r *NonceStorage) nonceKey(signerAddress string, nonce uint64) []byte {
+	return binary.BigEndian.AppendUint64(r.noncePrefix(signerAddress), nonce)
+}
+
+func (r *SchemaStorage) schemaStorageKey(componentName string) []byte {
+	return []byte(fmt.Sprintf("COMPONENT_NAME_TO_SCHEMA_DATA/%s", componentName))
+}
+
+func (r *ReceiptLog) tickPrefix() []byte {
+	return []byte("RECEIPT_LOG_TICK/")
+}
+
+func (r *ReceiptLog) tickKey(tick uint64) []byte {
+	return binary.BigEndian.AppendUint64(r.tickPrefix(), tick)
+}
+
+func (r *ReceiptLog) txKey(txHash string) []byte {
+	return []byte(fmt.Sprintf("RECEIPT_LOG_TX/%s", txHash))
+}
+
+func (r *ReceiptLog) personaPrefix(personaTag string) []byte {
+	return []byte(fmt.Sprintf("RECEIPT_LOG_PERSONA_%s/", personaTag))
+}
+
+func (r *ReceiptLog) personaKey(personaTag string, tick uint64, txHash string) []byte {
+	return append(binary.BigEndian.AppendUint64(r.personaPrefix(personaTag), tick), txHash...)
+}
@@ -0,0 +1,155 @@
+package badger
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"sync"
+
+	"github.com/dgraph-io/badger/v4"
+	"github.com/rotisserie/eris"
+	"github.com/rs/zerolog/log"
+
+	"pkg.world.dev/world-engine/cardinal/storage"
+)
+
+const (
+	// numOfNoncesToTriggerCleanup is the number of nonces stored for a signer address required for a cleanup pass to
+	// be initiated. A cleanup removes all nonces that are beyond the NonceSlidingWindowSize from the maximum seen nonce.
+	numOfNoncesToTriggerCleanup = storage.NonceSlidingWindowSize * 1.5
+)
+
+type NonceStorage struct {
+	DB *badger.DB
+	// mutex locks the UseNonce function to make it safe for concurrent access. This is a single lock for all signer
+	// addresses.
+	mutex *sync.Mutex
+	// maxNonce tracks the highest nonce seen for a particular signer address
+	maxNonce map[string]uint64
+	// countNonce tracks the number of nonces stored for each signer address. This count will increase as nonces are
+	// used and decrease as out-of-window nonces are removed.
+	countNonce map[string]int
+}
+
+func NewNonceStorage(db *badger.DB) NonceStorage {
+	return NonceStorage{
+		DB:         db,
+		mutex:      &sync.Mutex{},
+		maxNonce:   map[string]uint64{},
+		countNonce: map[string]int{},
+	}
+}
+
+// UseNonce atomically marks the given nonce as used. The nonce is valid if nil is returned. A non-nil error means
+// there was an error verifying the nonce, or the nonce was already used.
+func (r *NonceStorage) UseNonce(signerAddress string, nonce uint64) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	maxNonce, err := r.getMaxNonce(signerAddress)
+	if err != nil {
+		return eris.Wrap(err, "failed to get max nonce for signer address")
+	}
+
+	// Nonces beyond the sliding window are invalid and can be rejected outright.
+	if nonce < maxNonce && maxNonce-nonce >= storage.NonceSlidingWindowSize {
+		return eris.New("nonce is too old")
+	}
+
+	key := r.nonceKey(signerAddress, nonce)
+	err = r.DB.Update(func(txn *badger.Txn) error {
+		_, err := txn.Get(key)
+		if err == nil {
+			return eris.Wrapf(storage.ErrNonceHasAlreadyBeenUsed, "signer %q has already used nonce %d",
+				signerAddress, nonce)
+		} else if !errors.Is(err, badger.ErrKeyNotFound) {
+			return err
+		}
+		return txn.Set(key, nil)
+	})
+	if err != nil {
+		return eris.Wrap(err, "failed to add nonce")
+	}
+
+	r.maxNonce[signerAddress] = max(r.maxNonce[signerAddress], nonce)
+	r.countNonce[signerAddress]++
+
+	if r.countNonce[signerAddress] > numOfNoncesToTriggerCleanup {
+		r.cleanupOldNonces(signerAddress, r.maxNonce[signerAddress])
+	}
+
+	return nil
+}
+
+// cleanupOldNonces removes the record of all nonces that are older than NonceSlidingWindowSize. Nonces in that range
+// can be rejected without checking storage.
+func (r *NonceStorage) cleanupOldNonces(signerAddress string, currMax uint64) {
+	if currMax < storage.NonceSlidingWindowSize {
+		return
+	}
+	prefix := r.noncePrefix(signerAddress)
+	limit := r.nonceKey(signerAddress, currMax-storage.NonceSlidingWindowSize)
+
+	var keys [][]byte
+	err := r.DB.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.PrefetchValues = false
+		opts.Prefix = prefix
+		it := txn.NewIterator(opts)
+		defer it.Close()
+		for it.Rewind(); it.Valid(); it.Next() {
+			key := it.Item().KeyCopy(nil)
+			if bytes.Compare(key, limit) > 0 {
+				break
+			}
+			keys = append(keys, key)
+		}
+		return nil
+	})
+	if err != nil {
+		log.Err(err).Msg("failed to find old nonces")
+		return
+	}
+
+	wb := r.DB.NewWriteBatch()
+	defer wb.Cancel()
+	for _, key := range keys {
+		if err := wb.Delete(key); err != nil {
+			log.Err(err).Msg("failed to remove old nonces")
+			return
+		}
+	}
+	if err := wb.Flush(); err != nil {
+		log.Err(err).Msg("failed to remove old nonces")
+		return
+	}
+	r.countNonce[signerAddress] -= len(keys)
+}
+
+// getMaxNonce returns the highest used nonce for the given signer address.
+func (r *NonceStorage) getMaxNonce(signerAddress string) (uint64, error) {
+	maxNonce, ok := r.maxNonce[signerAddress]
+	if ok {
+		return maxNonce, nil
+	}
+	// There isn't a max nonce in memory. Fetch it from the DB by seeking to the last key with the address prefix.
+	prefix := r.noncePrefix(signerAddress)
+	err := r.DB.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.PrefetchValues = false
+		opts.Prefix = prefix
+		opts.Reverse = true
+		it := txn.NewIterator(opts)
+		defer it.Close()
+		it.Seek(append(bytes.Clone(prefix), 0xFF))
+		if it.Valid() {
+			maxNonce = binary.BigEndian.Uint64(it.Item().Key()[len(prefix):])
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, eris.Wrap(err, "failed to get max nonce")
+	}
+	r.maxNonce[signerAddress] = maxNonce
+	return maxNonce, nil
+}
@@ -0,0 +1,193 @@
+package badger
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+
+	"github.com/dgraph-io/badger/v4"
+	"github.com/rotisserie/eris"
+
+	"pkg.world.dev/world-engine/cardinal/storage"
+)
+
+type ReceiptLog struct {
+	DB *badger.DB
+}
+
+func NewReceiptLog(db *badger.DB) ReceiptLog {
+	return ReceiptLog{
+		DB: db,
+	}
+}
+
+// AppendTick stores the tick, and indexes its receipts by transaction hash and by persona tag, in a single
+// transaction.
+func (r *ReceiptLog) AppendTick(record storage.TickRecord) error {
+	bz, err := json.Marshal(record)
+	if err != nil {
+		return eris.Wrap(err, "failed to encode tick record")
+	}
+	tick := binary.BigEndian.AppendUint64(nil, record.Tick)
+	wb := r.DB.NewWriteBatch()
+	defer wb.Cancel()
+	if err := wb.Set(r.tickKey(record.Tick), bz); err != nil {
+		return eris.Wrap(err, "")
+	}
+	for _, rec := range record.Receipts {
+		if err := wb.Set(r.txKey(rec.TxHash), tick); err != nil {
+			return eris.Wrap(err, "")
+		}
+		if rec.PersonaTag == "" {
+			continue
+		}
+		if err := wb.Set(r.personaKey(rec.PersonaTag, record.Tick, rec.TxHash), nil); err != nil {
+			return eris.Wrap(err, "")
+		}
+	}
+	return eris.Wrap(wb.Flush(), "failed to append tick to the receipt log")
+}
+
+func (r *ReceiptLog) GetReceipt(txHash string) (storage.ReceiptRecord, error) {
+	var found storage.ReceiptRecord
+	err := r.DB.View(func(txn *badger.Txn) error {
+		item, err := txn.Get(r.txKey(txHash))
+		if errors.Is(err, badger.ErrKeyNotFound) {
+			return eris.Wrapf(storage.ErrReceiptNotFound, "transaction %q", txHash)
+		} else if err != nil {
+			return eris.Wrap(err, "")
+		}
+		bz, err := item.ValueCopy(nil)
+		if err != nil {
+			return eris.Wrap(err, "")
+		}
+		record, err := r.getTick(txn, binary.BigEndian.Uint64(bz))
+		if err != nil {
+			return err
+		}
+		for _, rec := range record.Receipts {
+			if rec.TxHash == txHash {
+				found = rec
+				return nil
+			}
+		}
+		return eris.Wrapf(storage.ErrReceiptNotFound, "transaction %q", txHash)
+	})
+	return found, err
+}
+
+func (r *ReceiptLog) GetReceiptsForPersona(
+	personaTag string, startTick, endTick uint64,
+) ([]storage.ReceiptRecord, error) {
+	receipts := []storage.ReceiptRecord{}
+	prefix := r.personaPrefix(personaTag)
+	err := r.DB.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.PrefetchValues = false
+		opts.Prefix = prefix
+		it := txn.NewIterator(opts)
+		defer it.Close()
+
+		// The receipts of a persona are sorted by tick, so the record of each tick is only read once.
+		var record storage.TickRecord
+		loaded := false
+		for it.Seek(binary.BigEndian.AppendUint64(bytes.Clone(prefix), startTick)); it.Valid(); it.Next() {
+			key := it.Item().Key()[len(prefix):]
+			tick := binary.BigEndian.Uint64(key)
+			if tick >= endTick {
+				break
+			}
+			txHash := string(key[8:])
+			if !loaded || record.Tick != tick {
+				var err error
+				if record, err = r.getTick(txn, tick); err != nil {
+					return err
+				}
+				loaded = true
+			}
+			for _, rec := range record.Receipts {
+				if rec.TxHash == txHash {
+					receipts = append(receipts, rec)
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return receipts, nil
+}
+
+func (r *ReceiptLog) GetTicks(startTick, endTick uint64) ([]storage.TickRecord, error) {
+	records := []storage.TickRecord{}
+	if startTick >= endTick {
+		return records, nil
+	}
+	limit := r.tickKey(endTick)
+	err := r.DB.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.Prefix = r.tickPrefix()
+		it := txn.NewIterator(opts)
+		defer it.Close()
+		for it.Seek(r.tickKey(startTick)); it.Valid(); it.Next() {
+			if bytes.Compare(it.Item().Key(), limit) >= 0 {
+				break
+			}
+			var record storage.TickRecord
+			err := it.Item().Value(func(bz []byte) error {
+				return json.Unmarshal(bz, &record)
+			})
+			if err != nil {
+				return eris.Wrap(err, "failed to decode tick record")
+			}
+			records = append(records, record)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// PruneTicks deletes the ticks before beforeTick, and removes their receipts from the indexes. The receipts of a tick
+// are removed from the indexes before the tick itself is deleted, so that the indexes never refer to a deleted tick.
+func (r *ReceiptLog) PruneTicks(beforeTick uint64) error {
+	records, err := r.GetTicks(0, beforeTick)
+	if err != nil {
+		return err
+	}
+	wb := r.DB.NewWriteBatch()
+	defer wb.Cancel()
+	for _, record := range records {
+		for _, rec := range record.Receipts {
+			if err := wb.Delete(r.txKey(rec.TxHash)); err != nil {
+				return eris.Wrap(err, "")
+			}
+			if rec.PersonaTag == "" {
+				continue
+			}
+			if err := wb.Delete(r.personaKey(rec.PersonaTag, record.Tick, rec.TxHash)); err != nil {
+				return eris.Wrap(err, "")
+			}
+		}
+		if err := wb.Delete(r.tickKey(record.Tick)); err != nil {
+			return eris.Wrap(err, "")
+		}
+	}
+	return eris.Wrap(wb.Flush(), "failed to prune the receipt log")
+}
+
+func (r *ReceiptLog) getTick(txn *badger.Txn, tick uint64) (storage.TickRecord, error) {
+	var record storage.TickRecord
+	item, err := txn.Get(r.tickKey(tick))
+	if err != nil {
+		return record, eris.Wrapf(err, "failed to get tick %d from the receipt log", tick)
+	}
+	err = item.Value(func(bz []byte) error {
+		return json.Unmarshal(bz, &record)
+	})
+	return record, eris.Wrap(err, "failed to decode tick record")
+}
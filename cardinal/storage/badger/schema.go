@@ -0,0 +1,44 @@
+package badger
+
+import (
+	"errors"
+
+	"github.com/dgraph-io/badger/v4"
+	"github.com/rotisserie/eris"
+
+	"pkg.world.dev/world-engine/cardinal/storage"
+)
+
+type SchemaStorage struct {
+	DB *badger.DB
+}
+
+func NewSchemaStorage(db *badger.DB) SchemaStorage {
+	return SchemaStorage{
+		DB: db,
+	}
+}
+
+func (r *SchemaStorage) GetSchema(componentName string) ([]byte, error) {
+	var schemaBytes []byte
+	err := r.DB.View(func(txn *badger.Txn) error {
+		item, err := txn.Get(r.schemaStorageKey(componentName))
+		if err != nil {
+			return err
+		}
+		schemaBytes, err = item.ValueCopy(nil)
+		return err
+	})
+	if errors.Is(err, badger.ErrKeyNotFound) {
+		return nil, eris.Wrap(storage.ErrNoSchemaFound, "")
+	} else if err != nil {
+		return nil, eris.Wrap(err, "")
+	}
+	return schemaBytes, nil
+}
+
+func (r *SchemaStorage) SetSchema(componentName string, schemaData []byte) error {
+	return eris.Wrap(r.DB.Update(func(txn *badger.Txn) error {
+		return txn.Set(r.schemaStorageKey(componentName), schemaData)
+	}), "")
+}
@@ -0,0 +1,76 @@
+package badger
+
+import (
+	"github.com/dgraph-io/badger/v4"
+	"github.com/rotisserie/eris"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+
+	"pkg.world.dev/world-engine/cardinal/storage"
+)
+
+var _ storage.Storage = &Storage{}
+
+// Storage is an embedded alternative to the redis storage. It keeps nonces, component schemas, the receipt log and
+// (through gamestate.BadgerStorage) the game state in a single Badger database.
+type Storage struct {
+	DB  *badger.DB
+	Log zerolog.Logger
+	NonceStorage
+	SchemaStorage
+	ReceiptLog
+}
+
+// NewBadgerStorage opens the Badger database at the given directory, creating it if necessary. If path is empty, the
+// database is kept in memory and all data is lost when it is closed.
+func NewBadgerStorage(path string) (Storage, error) {
+	logger := log.With().Str("module", "badger").Logger()
+	opts := badger.DefaultOptions(path).
+		WithInMemory(path == "").
+		WithLogger(badgerLogger{logger})
+	db, err := badger.Open(opts)
+	if err != nil {
+		return Storage{}, eris.Wrapf(err, "failed to open badger db at %q", path)
+	}
+	return Storage{
+		DB:            db,
+		Log:           logger,
+		NonceStorage:  NewNonceStorage(db),
+		SchemaStorage: NewSchemaStorage(db),
+		ReceiptLog:    NewReceiptLog(db),
+	}, nil
+}
+
+func (r *Storage) Close() error {
+	log.Debug().Msg("Closing storage connection")
+
+	err := r.DB.Close()
+	if err != nil {
+		return eris.Wrap(err, "")
+	}
+
+	log.Debug().Msg("Successfully closed storage connection")
+	return nil
+}
+
+// badgerLogger forwards badger's logs to zerolog. Badger is chatty at the info level, so info logs are demoted to
+// debug.
+type badgerLogger struct {
+	zerolog.Logger
+}
+
+func (l badgerLogger) Errorf(format string, args ...any) {
+	l.Error().Msgf(format, args...)
+}
+
+func (l badgerLogger) Warningf(format string, args ...any) {
+	l.Warn().Msgf(format, args...)
+}
+
+func (l badgerLogger) Infof(format string, args ...any) {
+	l.Debug().Msgf(format, args...)
+}
+
+func (l badgerLogger) Debugf(format string, args ...any) {
+	l.Debug().Msgf(format, args...)
+}
@@ -0,0 +1,76 @@
+package storage_test
+
+import (
+	"testing"
+
+	"pkg.world.dev/world-engine/assert"
+	"pkg.world.dev/world-engine/cardinal/storage"
+	"pkg.world.dev/world-engine/cardinal/storage/badger"
+)
+
+func GetBadgerStorage(t *testing.T, path string) badger.Storage {
+	bs, err := badger.NewBadgerStorage(path)
+	assert.NilError(t, err)
+	t.Cleanup(func() {
+		assert.NilError(t, bs.Close())
+	})
+	return bs
+}
+
+func TestBadgerCannotReuseNonce(t *testing.T) {
+	bs := GetBadgerStorage(t, "")
+	addr := "some-addr"
+	for i := uint64(0); i < 100; i++ {
+		assert.NilError(t, bs.UseNonce(addr, i))
+	}
+	for i := uint64(0); i < 100; i++ {
+		err := bs.UseNonce(addr, i)
+		assert.ErrorIs(t, err, storage.ErrNonceHasAlreadyBeenUsed)
+	}
+}
+
+func TestBadgerCannotReuseNonceAfterPrune(t *testing.T) {
+	bs := GetBadgerStorage(t, "")
+	total := 3 * storage.NonceSlidingWindowSize
+	addr := "some-addr"
+	for i := 0; i < total; i++ {
+		assert.NilError(t, bs.UseNonce(addr, uint64(i)))
+		if i > storage.NonceSlidingWindowSize+1 {
+			alreadyUsed := uint64(i - storage.NonceSlidingWindowSize)
+			assert.IsError(t, bs.UseNonce(addr, alreadyUsed-1), "%d was already used", alreadyUsed-1)
+			assert.IsError(t, bs.UseNonce(addr, alreadyUsed), "%d was already used", alreadyUsed)
+			assert.IsError(t, bs.UseNonce(addr, alreadyUsed+1), "%d was already used", alreadyUsed+1)
+		}
+	}
+}
+
+func TestBadgerUsedNoncesAreRememberedAcrossRestart(t *testing.T) {
+	path := t.TempDir()
+	bsOne, err := badger.NewBadgerStorage(path)
+	assert.NilError(t, err)
+
+	addr := "some-addr"
+	for i := 0; i < 10; i++ {
+		assert.NilError(t, bsOne.UseNonce(addr, uint64(i)))
+	}
+	assert.NilError(t, bsOne.Close())
+
+	bsTwo := GetBadgerStorage(t, path)
+	for i := 0; i < 10; i++ {
+		err := bsTwo.UseNonce(addr, uint64(i))
+		assert.ErrorIs(t, err, storage.ErrNonceHasAlreadyBeenUsed)
+	}
+	assert.NilError(t, bsTwo.UseNonce(addr, 10))
+}
+
+func TestBadgerSetAndGetSchema(t *testing.T) {
+	bs := GetBadgerStorage(t, "")
+
+	_, err := bs.GetSchema("missing")
+	assert.ErrorIs(t, err, storage.ErrNoSchemaFound)
+
+	assert.NilError(t, bs.SetSchema("some-component", []byte("schema")))
+	got, err := bs.GetSchema("some-component")
+	assert.NilError(t, err)
+	assert.Equal(t, "schema", string(got))
+}
@@ -0,0 +1,143 @@
+package storage_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"pkg.world.dev/world-engine/assert"
+	"pkg.world.dev/world-engine/cardinal/storage"
+	"pkg.world.dev/world-engine/cardinal/storage/badger"
+)
+
+func TestReceiptLog(t *testing.T) {
+	badgerStorage := GetBadgerStorage(t, "")
+	redisStorage := GetRedisStorage(t)
+	testCases := []struct {
+		name string
+		log  storage.ReceiptLog
+	}{
+		{name: "badger", log: &badgerStorage},
+		{name: "redis", log: &redisStorage},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			appendTestTicks(t, tc.log)
+
+			rec, err := tc.log.GetReceipt("0xb")
+			assert.NilError(t, err)
+			assert.Equal(t, uint64(3), rec.Tick)
+			assert.Equal(t, "beta", rec.PersonaTag)
+			assert.DeepEqual(t, []string{"failed"}, rec.Errors)
+
+			_, err = tc.log.GetReceipt("0xz")
+			assert.ErrorIs(t, err, storage.ErrReceiptNotFound)
+
+			receipts, err := tc.log.GetReceiptsForPersona("alpha", 0, 100)
+			assert.NilError(t, err)
+			assert.Equal(t, 3, len(receipts))
+			assert.Equal(t, "0xa", receipts[0].TxHash)
+			assert.Equal(t, "0xc", receipts[1].TxHash)
+			assert.Equal(t, "0xd", receipts[2].TxHash)
+
+			// The end of the range is excluded.
+			receipts, err = tc.log.GetReceiptsForPersona("alpha", 2, 5)
+			assert.NilError(t, err)
+			assert.Equal(t, 1, len(receipts))
+			assert.Equal(t, "0xc", receipts[0].TxHash)
+
+			ticks, err := tc.log.GetTicks(1, 5)
+			assert.NilError(t, err)
+			assert.Equal(t, 2, len(ticks))
+			assert.Equal(t, uint64(1), ticks[0].Tick)
+			assert.Equal(t, uint64(3), ticks[1].Tick)
+			assert.Equal(t, `{"x":1}`, string(ticks[0].Events[0]))
+
+			ticks, err = tc.log.GetTicks(5, 5)
+			assert.NilError(t, err)
+			assert.Equal(t, 0, len(ticks))
+		})
+	}
+}
+
+func TestReceiptLogPruneTicks(t *testing.T) {
+	badgerStorage := GetBadgerStorage(t, "")
+	redisStorage := GetRedisStorage(t)
+	testCases := []struct {
+		name string
+		log  storage.ReceiptLog
+	}{
+		{name: "badger", log: &badgerStorage},
+		{name: "redis", log: &redisStorage},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			appendTestTicks(t, tc.log)
+
+			// Ticks 1 and 3 are before tick 4, and are deleted along with their receipts.
+			assert.NilError(t, tc.log.PruneTicks(4))
+			for _, txHash := range []string{"0xa", "0xb", "0xc"} {
+				_, err := tc.log.GetReceipt(txHash)
+				assert.ErrorIs(t, err, storage.ErrReceiptNotFound)
+			}
+			receipts, err := tc.log.GetReceiptsForPersona("alpha", 0, 100)
+			assert.NilError(t, err)
+			assert.Equal(t, 1, len(receipts))
+			assert.Equal(t, "0xd", receipts[0].TxHash)
+			receipts, err = tc.log.GetReceiptsForPersona("beta", 0, 100)
+			assert.NilError(t, err)
+			assert.Equal(t, 0, len(receipts))
+			ticks, err := tc.log.GetTicks(0, 100)
+			assert.NilError(t, err)
+			assert.Equal(t, 1, len(ticks))
+			assert.Equal(t, uint64(5), ticks[0].Tick)
+
+			// Pruning again is a no-op.
+			assert.NilError(t, tc.log.PruneTicks(4))
+			rec, err := tc.log.GetReceipt("0xd")
+			assert.NilError(t, err)
+			assert.Equal(t, uint64(5), rec.Tick)
+		})
+	}
+}
+
+func TestBadgerReceiptLogIsKeptAcrossRestart(t *testing.T) {
+	path := t.TempDir()
+	bsOne, err := badger.NewBadgerStorage(path)
+	assert.NilError(t, err)
+	appendTestTicks(t, &bsOne)
+	assert.NilError(t, bsOne.Close())
+
+	bsTwo := GetBadgerStorage(t, path)
+	rec, err := bsTwo.GetReceipt("0xd")
+	assert.NilError(t, err)
+	assert.Equal(t, uint64(5), rec.Tick)
+}
+
+// appendTestTicks appends the receipts of alpha in ticks 1, 3 and 5, and the receipt of beta in tick 3.
+func appendTestTicks(t *testing.T, receiptLog storage.ReceiptLog) {
+	records := []storage.TickRecord{
+		{
+			Tick: 1,
+			Receipts: []storage.ReceiptRecord{
+				{TxHash: "0xa", Tick: 1, PersonaTag: "alpha", Result: json.RawMessage(`{"y":2}`)},
+			},
+			Events: []json.RawMessage{json.RawMessage(`{"x":1}`)},
+		},
+		{
+			Tick: 3,
+			Receipts: []storage.ReceiptRecord{
+				{TxHash: "0xb", Tick: 3, PersonaTag: "beta", Result: json.RawMessage(`null`), Errors: []string{"failed"}},
+				{TxHash: "0xc", Tick: 3, PersonaTag: "alpha", Result: json.RawMessage(`{"y":4}`)},
+			},
+		},
+		{
+			Tick: 5,
+			Receipts: []storage.ReceiptRecord{
+				{TxHash: "0xd", Tick: 5, PersonaTag: "alpha", Result: json.RawMessage(`{"y":6}`)},
+			},
+		},
+	}
+	for _, record := range records {
+		assert.NilError(t, receiptLog.AppendTick(record))
+	}
+}
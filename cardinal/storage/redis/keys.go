@@ -5,6 +5,12 @@ import "fmt"
 /*
 	NONCE STORAGE:      ADDRESS_TO_NONCE -> Nonce used for verifying signatures.
 	Hash set of signature address to uint64 nonce
+
+	RECEIPT LOG:        RECEIPT_LOG_TICKS -> Sorted set of the ticks in the log, scored by tick.
+	                    RECEIPT_LOG_TICK_<TICK> -> JSON encoded storage.TickRecord.
+	                    RECEIPT_LOG_TX -> Hash set of transaction hash to the tick it was processed in.
+	                    RECEIPT_LOG_PERSONA_<PERSONA_TAG> -> Sorted set of the transaction hashes of a persona, scored
+	                    by tick.
 */
 
 func (r *NonceStorage) nonceSetKey(str string) string {
@@ -14,3 +20,19 @@ func (r *NonceStorage) nonceSetKey(str string) string {
 func (r *SchemaStorage) schemaStorageKey() string {
 	return "COMPONENT_NAME_TO_SCHEMA_DATA"
 }
+
+func (r *ReceiptLog) ticksKey() string {
+	return "RECEIPT_LOG_TICKS"
+}
+
+func (r *ReceiptLog) tickKey(tick uint64) string {
+	return fmt.Sprintf("RECEIPT_LOG_TICK_%d", tick)
+}
+
+func (r *ReceiptLog) txKey() string {
+	return "RECEIPT_LOG_TX"
+}
+
+func (r *ReceiptLog) personaKey(personaTag string) string {
+	return fmt.Sprintf("RECEIPT_LOG_PERSONA_%s", personaTag)
+}
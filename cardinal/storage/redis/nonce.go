@@ -2,19 +2,20 @@ package redis
 
 import (
 	"context"
-	"errors"
 	"strconv"
 	"sync"
 
 	"github.com/redis/go-redis/v9"
 	"github.com/rotisserie/eris"
 	"github.com/rs/zerolog/log"
+
+	"pkg.world.dev/world-engine/cardinal/storage"
 )
 
 const (
 	// NonceSlidingWindowSize is the maximum distance a new nonce can be from the max nonce before it is rejected
 	// outright.
-	NonceSlidingWindowSize = 1000
+	NonceSlidingWindowSize = storage.NonceSlidingWindowSize
 
 	// numOfNoncesToTriggerCleanup is the number of nonces in redis required for a cleanup pass to be initiated.
 	// A cleanup consists of removing all nonces that are beyond the NonceSlidingWindowSize from the maximum seen nonce.
@@ -32,7 +33,7 @@ const (
 	float64MantissaSize = 52
 )
 
-var ErrNonceHasAlreadyBeenUsed = errors.New("nonce has already been used")
+var ErrNonceHasAlreadyBeenUsed = storage.ErrNonceHasAlreadyBeenUsed
 
 type NonceStorage struct {
 	Client *redis.Client
@@ -0,0 +1,212 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rotisserie/eris"
+
+	"pkg.world.dev/world-engine/cardinal/storage"
+)
+
+// pruneBatchSize is the number of ticks PruneTicks deletes at a time.
+const pruneBatchSize = 100
+
+type ReceiptLog struct {
+	Client *redis.Client
+}
+
+func NewReceiptLog(client *redis.Client) ReceiptLog {
+	return ReceiptLog{
+		Client: client,
+	}
+}
+
+// AppendTick stores the tick, and indexes its receipts by transaction hash and by persona tag, in a single
+// transaction.
+func (r *ReceiptLog) AppendTick(record storage.TickRecord) error {
+	ctx := context.Background()
+	bz, err := json.Marshal(record)
+	if err != nil {
+		return eris.Wrap(err, "failed to encode tick record")
+	}
+	score := float64(record.Tick)
+	_, err = r.Client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, r.tickKey(record.Tick), bz, 0)
+		pipe.ZAdd(ctx, r.ticksKey(), redis.Z{Score: score, Member: strconv.FormatUint(record.Tick, 10)})
+		for _, rec := range record.Receipts {
+			pipe.HSet(ctx, r.txKey(), rec.TxHash, record.Tick)
+			if rec.PersonaTag != "" {
+				pipe.ZAdd(ctx, r.personaKey(rec.PersonaTag), redis.Z{Score: score, Member: rec.TxHash})
+			}
+		}
+		return nil
+	})
+	return eris.Wrap(err, "failed to append tick to the receipt log")
+}
+
+func (r *ReceiptLog) GetReceipt(txHash string) (storage.ReceiptRecord, error) {
+	ctx := context.Background()
+	tick, err := r.Client.HGet(ctx, r.txKey(), txHash).Uint64()
+	if eris.Is(err, redis.Nil) {
+		return storage.ReceiptRecord{}, eris.Wrapf(storage.ErrReceiptNotFound, "transaction %q", txHash)
+	} else if err != nil {
+		return storage.ReceiptRecord{}, eris.Wrap(err, "")
+	}
+	records, err := r.getTicks(ctx, []uint64{tick})
+	if err != nil {
+		return storage.ReceiptRecord{}, err
+	}
+	for _, rec := range records[0].Receipts {
+		if rec.TxHash == txHash {
+			return rec, nil
+		}
+	}
+	return storage.ReceiptRecord{}, eris.Wrapf(storage.ErrReceiptNotFound, "transaction %q", txHash)
+}
+
+func (r *ReceiptLog) GetReceiptsForPersona(
+	personaTag string, startTick, endTick uint64,
+) ([]storage.ReceiptRecord, error) {
+	ctx := context.Background()
+	receipts := []storage.ReceiptRecord{}
+	if startTick >= endTick {
+		return receipts, nil
+	}
+	members, err := r.Client.ZRangeByScoreWithScores(ctx, r.personaKey(personaTag), tickRange(startTick, endTick)).
+		Result()
+	if err != nil {
+		return nil, eris.Wrap(err, "")
+	}
+
+	// The receipts of a persona are sorted by tick, so the record of each tick is only read once.
+	var ticks []uint64
+	txHashes := make(map[uint64]map[string]struct{})
+	for _, member := range members {
+		txHash, ok := member.Member.(string)
+		if !ok {
+			return nil, eris.Errorf("unexpected member %v in the receipt log", member.Member)
+		}
+		tick := uint64(member.Score)
+		if _, ok := txHashes[tick]; !ok {
+			ticks = append(ticks, tick)
+			txHashes[tick] = make(map[string]struct{})
+		}
+		txHashes[tick][txHash] = struct{}{}
+	}
+	records, err := r.getTicks(ctx, ticks)
+	if err != nil {
+		return nil, err
+	}
+	for _, record := range records {
+		for _, rec := range record.Receipts {
+			if _, ok := txHashes[record.Tick][rec.TxHash]; ok {
+				receipts = append(receipts, rec)
+			}
+		}
+	}
+	return receipts, nil
+}
+
+func (r *ReceiptLog) GetTicks(startTick, endTick uint64) ([]storage.TickRecord, error) {
+	ctx := context.Background()
+	if startTick >= endTick {
+		return []storage.TickRecord{}, nil
+	}
+	members, err := r.Client.ZRangeByScore(ctx, r.ticksKey(), tickRange(startTick, endTick)).Result()
+	if err != nil {
+		return nil, eris.Wrap(err, "")
+	}
+	ticks := make([]uint64, 0, len(members))
+	for _, member := range members {
+		tick, err := strconv.ParseUint(member, 10, 64)
+		if err != nil {
+			return nil, eris.Wrap(err, "")
+		}
+		ticks = append(ticks, tick)
+	}
+	return r.getTicks(ctx, ticks)
+}
+
+// PruneTicks deletes the ticks before beforeTick, and removes their receipts from the indexes. The ticks are deleted
+// pruneBatchSize at a time, each batch in a single transaction.
+func (r *ReceiptLog) PruneTicks(beforeTick uint64) error {
+	ctx := context.Background()
+	for {
+		byScore := tickRange(0, beforeTick)
+		byScore.Count = pruneBatchSize
+		members, err := r.Client.ZRangeByScore(ctx, r.ticksKey(), byScore).Result()
+		if err != nil {
+			return eris.Wrap(err, "")
+		}
+		if len(members) == 0 {
+			return nil
+		}
+		ticks := make([]uint64, 0, len(members))
+		for _, member := range members {
+			tick, err := strconv.ParseUint(member, 10, 64)
+			if err != nil {
+				return eris.Wrap(err, "")
+			}
+			ticks = append(ticks, tick)
+		}
+		records, err := r.getTicks(ctx, ticks)
+		if err != nil {
+			return err
+		}
+		_, err = r.Client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			for i, record := range records {
+				for _, rec := range record.Receipts {
+					pipe.HDel(ctx, r.txKey(), rec.TxHash)
+					if rec.PersonaTag != "" {
+						pipe.ZRem(ctx, r.personaKey(rec.PersonaTag), rec.TxHash)
+					}
+				}
+				pipe.Del(ctx, r.tickKey(record.Tick))
+				pipe.ZRem(ctx, r.ticksKey(), members[i])
+			}
+			return nil
+		})
+		if err != nil {
+			return eris.Wrap(err, "failed to prune the receipt log")
+		}
+	}
+}
+
+// getTicks reads the records of the given ticks, in the same order.
+func (r *ReceiptLog) getTicks(ctx context.Context, ticks []uint64) ([]storage.TickRecord, error) {
+	records := make([]storage.TickRecord, 0, len(ticks))
+	if len(ticks) == 0 {
+		return records, nil
+	}
+	keys := make([]string, 0, len(ticks))
+	for _, tick := range ticks {
+		keys = append(keys, r.tickKey(tick))
+	}
+	values, err := r.Client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, eris.Wrap(err, "")
+	}
+	for i, value := range values {
+		bz, ok := value.(string)
+		if !ok {
+			return nil, eris.Errorf("tick %d is missing from the receipt log", ticks[i])
+		}
+		var record storage.TickRecord
+		if err := json.Unmarshal([]byte(bz), &record); err != nil {
+			return nil, eris.Wrap(err, "failed to decode tick record")
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// tickRange selects the members of a sorted set scored by tick in [startTick, endTick).
+func tickRange(startTick, endTick uint64) *redis.ZRangeBy {
+	return &redis.ZRangeBy{
+		Min: strconv.FormatUint(startTick, 10),
+		Max: "(" + strconv.FormatUint(endTick, 10),
+	}
+}
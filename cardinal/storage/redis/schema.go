@@ -2,14 +2,15 @@ package redis
 
 import (
 	"context"
-	"errors"
 
 	"github.com/redis/go-redis/v9"
 	"github.com/rotisserie/eris"
+
+	"pkg.world.dev/world-engine/cardinal/storage"
 )
 
 var (
-	ErrNoSchemaFound = errors.New("no schema found")
+	ErrNoSchemaFound = storage.ErrNoSchemaFound
 )
 
 type SchemaStorage struct {
@@ -7,14 +7,19 @@ import (
 	"github.com/rotisserie/eris"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
+
+	"pkg.world.dev/world-engine/cardinal/storage"
 )
 
+var _ storage.Storage = &Storage{}
+
 type Storage struct {
 	Namespace string
 	Client    *redis.Client
 	Log       zerolog.Logger
 	NonceStorage
 	SchemaStorage
+	ReceiptLog
 }
 
 type Options = redis.Options
@@ -27,6 +32,7 @@ func NewRedisStorage(options Options, namespace string) Storage {
 		Log:           zerolog.New(os.Stdout),
 		NonceStorage:  NewNonceStorage(client),
 		SchemaStorage: NewSchemaStorage(client),
+		ReceiptLog:    NewReceiptLog(client),
 	}
 }
 
@@ -1,5 +1,20 @@
 package storage
 
+import (
+	"encoding/json"
+	"errors"
+)
+
+// NonceSlidingWindowSize is the maximum distance a new nonce can be from the max nonce before it is rejected
+// outright.
+const NonceSlidingWindowSize = 1000
+
+var (
+	ErrNoSchemaFound           = errors.New("no schema found")
+	ErrNonceHasAlreadyBeenUsed = errors.New("nonce has already been used")
+	ErrReceiptNotFound         = errors.New("receipt not found")
+)
+
 type NonceStorage interface {
 	UseNonce(signerAddress string, nonce uint64) error
 }
@@ -9,8 +24,42 @@ type SchemaStorage interface {
 	SetSchema(componentName string, schemaData []byte) error
 }
 
+// ReceiptRecord is a transaction receipt kept by a ReceiptLog.
+type ReceiptRecord struct {
+	TxHash     string          `json:"txHash"`
+	Tick       uint64          `json:"tick"`
+	PersonaTag string          `json:"personaTag"`
+	Result     json.RawMessage `json:"result" swaggertype:"object"`
+	Errors     []string        `json:"errors"`
+	// Fee is the fee that was charged for the transaction.
+	Fee uint64 `json:"fee,omitempty"`
+	// FeeRefunded reports whether the fee was refunded because the message returned an error.
+	FeeRefunded bool `json:"feeRefunded,omitempty"`
+}
+
+// TickRecord holds the receipts of the transactions processed in a tick, and the events that were sent to every
+// client of /events. Events sent to a persona or a topic are not kept, as every persona can read the events in the log.
+type TickRecord struct {
+	Tick     uint64            `json:"tick"`
+	Receipts []ReceiptRecord   `json:"receipts"`
+	Events   []json.RawMessage `json:"events" swaggertype:"array,object"`
+}
+
+// ReceiptLog is a durable, append-only log of the receipts and events of every tick. Unlike receipt.History, it is
+// not limited to the most recent ticks, and it is kept across restarts, until the oldest ticks are pruned. Tick ranges
+// are closed on startTick and open on endTick: i.e. [startTick, endTick).
+type ReceiptLog interface {
+	AppendTick(record TickRecord) error
+	GetReceipt(txHash string) (ReceiptRecord, error)
+	GetReceiptsForPersona(personaTag string, startTick, endTick uint64) ([]ReceiptRecord, error)
+	GetTicks(startTick, endTick uint64) ([]TickRecord, error)
+	// PruneTicks deletes the ticks before beforeTick, along with their receipts.
+	PruneTicks(beforeTick uint64) error
+}
+
 type Storage interface {
 	NonceStorage
 	SchemaStorage
+	ReceiptLog
 	Close() error
 }
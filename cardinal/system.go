@@ -2,15 +2,21 @@ package cardinal
 
 import (
 	"context"
-	"path/filepath"
-	"reflect"
-	"runtime"
+	"errors"
 	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/rotisserie/eris"
+	"github.com/rs/zerolog"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/trace"
+
+	"pkg.world.dev/world-engine/cardinal/gamestate"
+	"pkg.world.dev/world-engine/cardinal/types"
 )
 
 const (
@@ -26,8 +32,25 @@ type System func(ctx WorldContext) error
 type systemType struct {
 	Name string
 	Fn   System
+	// access is the set of components the system declared it will touch. A nil access means the system did not
+	// declare anything, and it will never run concurrently with other systems.
+	access *systemAccess
+	// policy decides what happens to the tick when the system fails, and breaker stops running the system when it
+	// fails too often. health holds the failures of the system, and is shared by every copy of the entry.
+	policy  FailurePolicy
+	breaker *circuitBreaker
+	health  *systemHealth
+	// stage is the stage of the tick in which the system runs. before and after hold the names of the systems of the
+	// same stage that the system must run before and after, and conditions decide in which ticks the system runs.
+	stage      SystemStage
+	before     []string
+	after      []string
+	conditions []RunCondition
 }
 
+// SystemOption is an option that can be passed to RegisterSystem to augment how the system is registered.
+type SystemOption func(sys *systemType)
+
 type SystemManager interface {
 	// GetRegisteredSystems returns a slice of all registered systems' name.
 	GetRegisteredSystems() []string
@@ -39,8 +62,14 @@ type SystemManager interface {
 	// These methods are intentionally made private to avoid other
 	// packages from trying to modify the system manager in the middle of a tick.
 	registerSystems(isInit bool, systems ...System) error
-	registerSystem(isInit bool, systemName string, systemFunc System) error
+	registerSystem(isInit bool, systemName string, systemFunc System, opts ...SystemOption) error
 	runSystems(ctx context.Context, wCtx WorldContext) error
+	planSystems() error
+	saveFailures(wCtx WorldContext) error
+	isQuarantined(id types.EntityID) bool
+	quarantinedEntities() []types.EntityID
+	systemsHealth() (health []types.SystemHealth, circuitOpen bool)
+	takeSystemDurations() map[string]time.Duration
 }
 
 type systemManager struct {
@@ -49,9 +78,29 @@ type systemManager struct {
 	registeredSystems     []systemType
 	registeredInitSystems []systemType
 
+	// schedule and initSchedule group the registered systems into batches that can run concurrently, in the order of
+	// their stages and constraints. They are computed by planSystems and reset every time a new system is registered.
+	schedule     [][]systemType
+	initSchedule [][]systemType
+
 	// currentSystem is the name of the system that is currently running.
 	currentSystem string
 
+	// failures holds the failure state of the tick that is running, which is read from the game state before the
+	// systems run and written back once they ran. failuresID is the entity that holds it, or nil if no system failed
+	// yet. The systems of a batch record their failures concurrently, hence failuresMux.
+	failures        failureState
+	failuresID      *types.EntityID
+	failuresChanged bool
+	failuresMux     sync.Mutex
+	// quarantined holds the entities quarantined by systems with the QuarantineEntity failure policy.
+	quarantined atomic.Pointer[map[types.EntityID]struct{}]
+
+	// durations holds the time each system ran for since the last call to takeSystemDurations. It is guarded by
+	// durationsMux, since the systems of a batch record their durations concurrently.
+	durations    map[string]time.Duration
+	durationsMux sync.Mutex
+
 	tracer trace.Tracer
 }
 
@@ -60,6 +109,7 @@ func newSystemManager() SystemManager {
 		registeredSystems:     make([]systemType, 0),
 		registeredInitSystems: make([]systemType, 0),
 		currentSystem:         noActiveSystemName,
+		durations:             make(map[string]time.Duration),
 		tracer:                otel.Tracer("system"),
 	}
 	return sm
@@ -78,7 +128,7 @@ func (m *systemManager) registerSystems(isInit bool, systemFuncs ...System) erro
 	// 2) Create a new system entry for each one.
 	for _, systemFunc := range systemFuncs {
 		// Obtain the name of the system function using reflection.
-		systemName := filepath.Base(runtime.FuncForPC(reflect.ValueOf(systemFunc).Pointer()).Name())
+		systemName := SystemName(systemFunc)
 
 		// Check for duplicate system names within the list of systems to be registered
 		if slices.ContainsFunc(
@@ -111,7 +161,9 @@ func (m *systemManager) registerSystems(isInit bool, systemFuncs ...System) erro
 }
 
 // registerSystem is an internal function that allows us to register a system with a custom system name.
-func (m *systemManager) registerSystem(isInit bool, systemName string, systemFunc System) error {
+func (m *systemManager) registerSystem(
+	isInit bool, systemName string, systemFunc System, opts ...SystemOption,
+) error {
 	// TODO: there is duplication in check in registerSystems and this function.
 	//  We should refactor this, but we are doing it this way to err on the side of safety.
 
@@ -123,50 +175,71 @@ func (m *systemManager) registerSystem(isInit bool, systemName string, systemFun
 		return eris.Errorf("System %q is already registered", systemName)
 	}
 
-	systemToRegister := systemType{Name: systemName, Fn: systemFunc}
+	systemToRegister := systemType{Name: systemName, Fn: systemFunc, health: &systemHealth{}, stage: Update}
+	for _, opt := range opts {
+		opt(&systemToRegister)
+	}
 	if isInit {
 		m.registeredInitSystems = append(m.registeredInitSystems, systemToRegister)
 	} else {
 		m.registeredSystems = append(m.registeredSystems, systemToRegister)
 	}
 
+	// The schedule is no longer valid now that there is a new system.
+	m.schedule = nil
+	m.initSchedule = nil
+
 	return nil
 }
 
-// RunSystems runs all the registered system in the order that they were registered.
+// planSystems orders the registered systems by stage, constraints and registration order, and groups them into
+// batches. It returns an error if the constraints of the systems can't be satisfied.
+func (m *systemManager) planSystems() error {
+	initSchedule, schedule, err := orderSystems(m.registeredInitSystems, m.registeredSystems)
+	if err != nil {
+		return err
+	}
+	m.initSchedule, m.schedule = initSchedule, schedule
+	return nil
+}
+
+// RunSystems runs all the registered system stage by stage. Within a stage, systems run in the order of their
+// constraints and then of their registration. Systems that declared their component access and do not conflict with
+// each other are run concurrently; see buildSchedule for details.
 func (m *systemManager) runSystems(ctx context.Context, wCtx WorldContext) error {
 	ctx, span := m.tracer.Start(ctx, "system.run")
 	defer span.End()
 
-	var systemsToRun []systemType
+	if m.schedule == nil {
+		if err := m.planSystems(); err != nil {
+			return eris.Wrap(err, "failed to plan the systems")
+		}
+	}
+	batches := m.schedule
 	if wCtx.CurrentTick() == 0 {
-		systemsToRun = slices.Concat(m.registeredInitSystems, m.registeredSystems)
-	} else {
-		systemsToRun = m.registeredSystems
+		batches = m.initSchedule
+	}
+	if err := m.loadFailures(wCtx); err != nil {
+		return err
 	}
 
 	// Store the original logger so that it can be reset to its original value
 	logger := wCtx.Logger()
 
-	for _, sys := range systemsToRun {
-		// Explicit memory aliasing
-		m.currentSystem = sys.Name
-
-		// Inject the system name into the logger
-		wCtx.setLogger(logger.With().Str("system", sys.Name).Logger())
-
-		// Executes the system function that the user registered
-		_, systemFnSpan := m.tracer.Start(ctx, "system.run."+sys.Name)
-		if err := sys.Fn(wCtx); err != nil {
-			m.currentSystem = ""
+	for _, batch := range batches {
+		var err error
+		if len(batch) == 1 && batch[0].access == nil {
+			err = m.runSystem(ctx, wCtx, logger, batch[0])
+		} else {
+			wCtx.setLogger(*logger)
+			err = m.runBatch(ctx, wCtx, batch)
+		}
+		if err != nil {
+			m.currentSystem = noActiveSystemName
 			span.SetStatus(codes.Error, eris.ToString(err, true))
 			span.RecordError(err)
-			systemFnSpan.SetStatus(codes.Error, eris.ToString(err, true))
-			systemFnSpan.RecordError(err)
-			systemFnSpan.End()
-			return eris.Wrapf(err, "System %s generated an error", sys.Name)
+			return err
 		}
-		systemFnSpan.End()
 	}
 
 	// Reset the logger to the original logger
@@ -175,7 +248,116 @@ func (m *systemManager) runSystems(ctx context.Context, wCtx WorldContext) error
 	// Indicate that no system is currently running
 	m.currentSystem = noActiveSystemName
 
-	return nil
+	return m.saveFailures(wCtx)
+}
+
+// runSystem runs a single system on the given world context, and applies its failure policy if it fails. It only
+// returns an error if the tick must be aborted.
+func (m *systemManager) runSystem(
+	ctx context.Context, wCtx WorldContext, logger *zerolog.Logger, sys systemType,
+) error {
+	// Explicit memory aliasing
+	m.currentSystem = sys.Name
+
+	// Inject the system name into the logger
+	wCtx.setLogger(logger.With().Str("system", sys.Name).Logger())
+
+	if !m.shouldRun(wCtx, sys) {
+		return nil
+	}
+	for {
+		run, err := m.runInSavepoint(ctx, wCtx, sys)
+		if err == nil {
+			return nil
+		}
+		retry, err := m.handleFailure(wCtx, wCtx, sys, run, err)
+		if !retry {
+			return err
+		}
+	}
+}
+
+// runBatch runs a batch of non-conflicting systems concurrently. Each system gets its own world context so that
+// loggers, random number generators and emitted events are not shared, and so that the system's declared component
+// access is enforced. Once every system in the batch has completed, the failure policies of the systems that failed
+// are applied, the events are flushed and the errors are joined in registration order, which keeps the outcome of
+// the tick deterministic regardless of how the goroutines were scheduled.
+func (m *systemManager) runBatch(ctx context.Context, wCtx WorldContext, batch []systemType) error {
+	names := make([]string, len(batch))
+	for i, sys := range batch {
+		names[i] = sys.Name
+	}
+	m.currentSystem = strings.Join(names, ",")
+
+	// Every system writes through its own synchronized manager, so that it has its own savepoints.
+	stores := gamestate.NewSynchronizedManagers(wCtx.storeManager(), len(batch))
+	sysCtxs := make([]WorldContext, len(batch))
+	runs := make([]*systemRun, len(batch))
+	errs := make([]error, len(batch))
+
+	var wg sync.WaitGroup
+	for i, sys := range batch {
+		sysCtxs[i] = wCtx.forSystem(sys.Name, sys.access, stores[i])
+		if !m.shouldRun(wCtx, sys) {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			runs[i], errs[i] = m.runInSavepoint(ctx, sysCtxs[i], sys)
+		}()
+	}
+	wg.Wait()
+
+	// A system that must be run again without a quarantined entity is run on its own, which is equivalent since it
+	// does not conflict with the other systems of the batch.
+	for i, sys := range batch {
+		for errs[i] != nil {
+			retry, err := m.handleFailure(wCtx, sysCtxs[i], sys, runs[i], errs[i])
+			if !retry {
+				errs[i] = err
+				break
+			}
+			runs[i], errs[i] = m.runInSavepoint(ctx, sysCtxs[i], sys)
+		}
+	}
+
+	for _, sysCtx := range sysCtxs {
+		wCtx.eventBuffer().appendEvents(sysCtx.eventBuffer())
+	}
+
+	return errors.Join(errs...)
+}
+
+// shouldRun reports whether the system runs in the current tick, which is the case if its circuit is not open and all
+// of its run conditions are met.
+func (m *systemManager) shouldRun(wCtx WorldContext, sys systemType) bool {
+	if !m.canRun(sys, wCtx.CurrentTick()) {
+		return false
+	}
+	for _, cond := range sys.conditions {
+		if !cond(wCtx) {
+			return false
+		}
+	}
+	return true
+}
+
+// recordDuration adds the time a system ran for to its duration.
+func (m *systemManager) recordDuration(name string, d time.Duration) {
+	m.durationsMux.Lock()
+	defer m.durationsMux.Unlock()
+	m.durations[name] += d
+}
+
+// takeSystemDurations returns the time each system ran for since the last call, and resets the durations. A system
+// that ran several times, for instance because the tick was processed again, has the sum of its runs.
+func (m *systemManager) takeSystemDurations() map[string]time.Duration {
+	m.durationsMux.Lock()
+	defer m.durationsMux.Unlock()
+	durations := m.durations
+	m.durations = make(map[string]time.Duration, len(durations))
+	return durations
 }
 
 func (m *systemManager) GetRegisteredSystems() []string {
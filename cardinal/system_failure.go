@@ -0,0 +1,510 @@
+package cardinal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/rotisserie/eris"
+	"go.opentelemetry.io/otel/codes"
+
+	"pkg.world.dev/world-engine/cardinal/filter"
+	"pkg.world.dev/world-engine/cardinal/receipt"
+	"pkg.world.dev/world-engine/cardinal/types"
+)
+
+// FailurePolicy decides what happens to a tick when a system returns an error or panics.
+type FailurePolicy int
+
+const (
+	// CrashWorld fails the tick with the error of the system, and the world panics. When the world restarts, it
+	// recovers from the last tick that completed. This is the default policy.
+	CrashWorld FailurePolicy = iota
+	// AbortTick discards the state changes, events and receipts of the whole tick, and adds the error of the system
+	// to the receipt of every transaction of the tick. The tick still counts as a tick, so the world keeps running.
+	AbortTick
+	// SkipSystem rolls back the state changes and events of the system, and the rest of the tick goes on. The error
+	// is added to the receipts of the transactions the system processed, and their fees are refunded.
+	SkipSystem
+	// QuarantineEntity quarantines the entity of an EntityError returned by the system, and runs the system again
+	// without it. Quarantined entities are skipped by the searches of every system. The quarantine is kept in the game
+	// state, so it outlives a restart of the world. A failure that is not caused by an entity is handled like
+	// SkipSystem.
+	QuarantineEntity
+)
+
+func (p FailurePolicy) String() string {
+	switch p {
+	case CrashWorld:
+		return "crash_world"
+	case AbortTick:
+		return "abort_tick"
+	case SkipSystem:
+		return "skip_system"
+	case QuarantineEntity:
+		return "quarantine_entity"
+	default:
+		return fmt.Sprintf("FailurePolicy(%d)", int(p))
+	}
+}
+
+// EntityError is an error of a system that is caused by a single entity. Systems registered with the
+// QuarantineEntity failure policy return it to have the entity quarantined.
+type EntityError struct {
+	ID  types.EntityID
+	Err error
+}
+
+// NewEntityError returns an error of a system that is caused by the given entity.
+func NewEntityError(id types.EntityID, err error) error {
+	return &EntityError{ID: id, Err: err}
+}
+
+func (e *EntityError) Error() string {
+	return fmt.Sprintf("entity %d: %v", e.ID, e.Err)
+}
+
+func (e *EntityError) Unwrap() error {
+	return e.Err
+}
+
+// abortError is the error of a system with the AbortTick failure policy, which aborts the tick instead of failing it.
+type abortError struct {
+	err error
+}
+
+func (e *abortError) Error() string {
+	return e.err.Error()
+}
+
+func (e *abortError) Unwrap() error {
+	return e.err
+}
+
+// abortsTick reports whether the tick must be aborted rather than fail, which is the case if every system that failed
+// has the AbortTick failure policy.
+func abortsTick(err error) bool {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		for _, err := range joined.Unwrap() {
+			if !abortsTick(err) {
+				return false
+			}
+		}
+		return true
+	}
+	var abortErr *abortError
+	return errors.As(err, &abortErr)
+}
+
+// circuitBreaker stops running a system that failed in too many ticks in a row.
+type circuitBreaker struct {
+	maxFailures int
+	cooldown    uint64
+}
+
+var _ Plugin = (*systemFailurePlugin)(nil)
+
+// systemFailurePlugin registers the component that holds the failure state of the systems.
+type systemFailurePlugin struct{}
+
+func newSystemFailurePlugin() *systemFailurePlugin {
+	return &systemFailurePlugin{}
+}
+
+func (*systemFailurePlugin) Register(w *World) error {
+	return RegisterComponent[failureState](w)
+}
+
+// failureState is an internal component that holds the entities quarantined by the systems, and the circuits of the
+// systems that failed. It is kept in the game state, on a single entity, so that every replica, and the recovery of
+// the world, skips the same entities and runs the same systems.
+type failureState struct {
+	// Quarantined holds the quarantined entities in increasing order.
+	Quarantined []types.EntityID
+	Circuits    map[string]circuitState
+}
+
+func (failureState) Name() string {
+	return "failureState"
+}
+
+// circuitState holds the failures in a row of a system, and whether its circuit is open.
+type circuitState struct {
+	ConsecutiveFailures int
+	LastFailedTick      uint64
+	Open                bool
+	// RetryTick is the tick at which a system whose circuit is open is run again.
+	RetryTick uint64
+}
+
+// clone returns a copy of the state that doesn't share its slice and map, since the game state keeps the value it is
+// given until the tick is finalized.
+func (s failureState) clone() failureState {
+	return failureState{Quarantined: slices.Clone(s.Quarantined), Circuits: maps.Clone(s.Circuits)}
+}
+
+// systemHealth holds the failures of a system that are reported by the health endpoint, which reads them while the
+// tick is running. Unlike the circuit of the system, they are not part of the game state.
+type systemHealth struct {
+	mux            sync.Mutex
+	failures       uint64
+	lastFailedTick uint64
+	lastError      string
+}
+
+// recordFailure records a failure of the system. Failures in the same tick, for instance when the system is run again
+// without a quarantined entity, count as one.
+func (h *systemHealth) recordFailure(tick uint64, err error) {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+	h.lastError = err.Error()
+	if h.failures > 0 && h.lastFailedTick == tick {
+		return
+	}
+	h.failures++
+	h.lastFailedTick = tick
+}
+
+func (h *systemHealth) report(name string, policy FailurePolicy, circuit circuitState) (types.SystemHealth, bool) {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+	return types.SystemHealth{
+		Name:                name,
+		Policy:              policy.String(),
+		Failures:            h.failures,
+		ConsecutiveFailures: circuit.ConsecutiveFailures,
+		LastFailedTick:      max(h.lastFailedTick, circuit.LastFailedTick),
+		LastError:           h.lastError,
+		CircuitOpen:         circuit.Open,
+	}, h.failures > 0 || circuit.ConsecutiveFailures > 0
+}
+
+// loadFailures reads the failure state from the game state. It is read before every run of the systems, so that the
+// failures recorded by a run whose changes were discarded are forgotten along with them.
+func (m *systemManager) loadFailures(wCtx WorldContext) error {
+	state := failureState{}
+	var stateID *types.EntityID
+	id, err := NewSearch().Entity(filter.Exact(filter.Component[failureState]())).First(wCtx)
+	if err != nil {
+		return eris.Wrap(err, "failed to search for the failures of the systems")
+	}
+	if id != badEntityID {
+		stored, err := GetComponent[failureState](wCtx, id)
+		if err != nil {
+			return eris.Wrap(err, "failed to read the failures of the systems")
+		}
+		state, stateID = stored.clone(), &id
+	}
+
+	m.failuresMux.Lock()
+	defer m.failuresMux.Unlock()
+	m.failures, m.failuresID, m.failuresChanged = state, stateID, false
+	m.storeQuarantined()
+	return nil
+}
+
+// saveFailures writes the failure state to the game state if the systems changed it. The entity that holds it is
+// created by the first failure.
+func (m *systemManager) saveFailures(wCtx WorldContext) error {
+	m.failuresMux.Lock()
+	defer m.failuresMux.Unlock()
+	if !m.failuresChanged {
+		return nil
+	}
+	m.failuresChanged = false
+	if m.failuresID == nil {
+		id, err := Create(wCtx, m.failures.clone())
+		if err != nil {
+			return eris.Wrap(err, "failed to save the failures of the systems")
+		}
+		m.failuresID = &id
+		return nil
+	}
+	state := m.failures.clone()
+	if err := SetComponent(wCtx, *m.failuresID, &state); err != nil {
+		return eris.Wrap(err, "failed to save the failures of the systems")
+	}
+	return nil
+}
+
+// canRun reports whether the system can run in the given tick. A system whose circuit is open is run again once the
+// cooldown has elapsed. A single failure then opens the circuit again, and a success closes it.
+func (m *systemManager) canRun(sys systemType, tick uint64) bool {
+	m.failuresMux.Lock()
+	defer m.failuresMux.Unlock()
+	circuit := m.failures.Circuits[sys.Name]
+	return !circuit.Open || tick >= circuit.RetryTick
+}
+
+func (m *systemManager) recordSuccess(sys systemType) {
+	m.failuresMux.Lock()
+	defer m.failuresMux.Unlock()
+	if _, ok := m.failures.Circuits[sys.Name]; ok {
+		delete(m.failures.Circuits, sys.Name)
+		m.failuresChanged = true
+	}
+}
+
+// recordFailure records a failure of the system in its circuit, which opens if the system failed in too many ticks in
+// a row. Failures in the same tick count as one.
+func (m *systemManager) recordFailure(sys systemType, tick uint64, err error) {
+	sys.health.recordFailure(tick, err)
+	m.failuresMux.Lock()
+	defer m.failuresMux.Unlock()
+	circuit, failed := m.failures.Circuits[sys.Name]
+	if failed && circuit.LastFailedTick == tick {
+		return
+	}
+	circuit.ConsecutiveFailures++
+	circuit.LastFailedTick = tick
+	if sys.breaker != nil && (circuit.Open || circuit.ConsecutiveFailures >= sys.breaker.maxFailures) {
+		circuit.Open = true
+		circuit.RetryTick = tick + sys.breaker.cooldown + 1
+	}
+	if m.failures.Circuits == nil {
+		m.failures.Circuits = make(map[string]circuitState)
+	}
+	m.failures.Circuits[sys.Name] = circuit
+	m.failuresChanged = true
+}
+
+// systemRun is a run of a system in a savepoint, whose changes can be rolled back.
+type systemRun struct {
+	store interface {
+		RollbackToSavepoint() error
+	}
+	receipts   *receiptJournal
+	events     *TickResults
+	eventCount int
+}
+
+func (r *systemRun) rollback() error {
+	if err := r.store.RollbackToSavepoint(); err != nil {
+		return err
+	}
+	r.receipts.rollback()
+	r.events.truncateEvents(r.eventCount)
+	return nil
+}
+
+// runInSavepoint runs the system in a savepoint of its store, unless the failure policy of the system is CrashWorld or
+// AbortTick, since the whole tick then fails or is discarded if the system fails. A panic of the system is returned as
+// an error. If the system fails, the returned run can roll back the state changes, receipts and events of the system.
+func (m *systemManager) runInSavepoint(ctx context.Context, wCtx WorldContext, sys systemType) (*systemRun, error) {
+	if sys.policy == CrashWorld || sys.policy == AbortTick {
+		if err := m.callSystem(ctx, wCtx, sys); err != nil {
+			return nil, err
+		}
+		m.recordSuccess(sys)
+		return nil, nil
+	}
+
+	store := wCtx.storeManager()
+	run := &systemRun{
+		store:      store,
+		receipts:   wCtx.startReceiptJournal(),
+		events:     wCtx.eventBuffer(),
+		eventCount: len(wCtx.eventBuffer().Events),
+	}
+	defer wCtx.stopReceiptJournal()
+
+	store.Savepoint()
+	if err := m.callSystem(ctx, wCtx, sys); err != nil {
+		return run, err
+	}
+	if err := store.ReleaseSavepoint(); err != nil {
+		return nil, eris.Wrapf(err, "failed to release the savepoint of system %s", sys.Name)
+	}
+	m.recordSuccess(sys)
+	return nil, nil
+}
+
+// callSystem calls the function of the system, and turns a panic into an error.
+func (m *systemManager) callSystem(ctx context.Context, wCtx WorldContext, sys systemType) (err error) {
+	_, systemFnSpan := m.tracer.Start(ctx, "system.run."+sys.Name)
+	defer systemFnSpan.End()
+	defer func(start time.Time) {
+		m.recordDuration(sys.Name, time.Since(start))
+	}(time.Now())
+	defer func() {
+		if r := recover(); r != nil {
+			if panicErr, ok := r.(error); ok {
+				err = eris.Wrapf(panicErr, "System %s panicked", sys.Name)
+			} else {
+				err = eris.Errorf("System %s panicked: %v", sys.Name, r)
+			}
+		}
+		if err != nil {
+			systemFnSpan.SetStatus(codes.Error, eris.ToString(err, true))
+			systemFnSpan.RecordError(err)
+		}
+	}()
+	if err := sys.Fn(wCtx); err != nil {
+		return eris.Wrapf(err, "System %s generated an error", sys.Name)
+	}
+	return nil
+}
+
+// handleFailure applies the failure policy of a system that failed. wCtx is the context of the tick, and sysCtx the
+// context the system ran with. It reports whether the system must be run again, and returns the error of the system if
+// the tick must fail or be aborted.
+func (m *systemManager) handleFailure(
+	wCtx, sysCtx WorldContext, sys systemType, run *systemRun, err error,
+) (retry bool, tickErr error) {
+	m.recordFailure(sys, wCtx.CurrentTick(), err)
+	logger := sysCtx.Logger()
+	switch sys.policy {
+	case CrashWorld:
+		return false, err
+	case AbortTick:
+		return false, &abortError{err: err}
+	}
+	if rollbackErr := run.rollback(); rollbackErr != nil {
+		return false, eris.Wrapf(rollbackErr, "failed to roll back the changes of system %s", sys.Name)
+	}
+
+	var entityErr *EntityError
+	if sys.policy == QuarantineEntity && errors.As(err, &entityErr) && !m.isQuarantined(entityErr.ID) {
+		m.quarantine(entityErr.ID)
+		logger.Warn().Err(err).Msgf("Entity %d was quarantined and system %s is run again", entityErr.ID, sys.Name)
+		return true, nil
+	}
+
+	logger.Error().Err(err).Msgf("System %s failed and its changes were rolled back", sys.Name)
+	hashes := run.receipts.hashes
+	if len(hashes) == 0 {
+		return false, nil
+	}
+	txErr := eris.Wrap(err, "the changes of the transaction were rolled back")
+	for _, hash := range hashes {
+		wCtx.addMessageError(hash, txErr)
+		wCtx.refundFee(hash)
+	}
+	return false, nil
+}
+
+func (m *systemManager) isQuarantined(id types.EntityID) bool {
+	quarantined := m.quarantined.Load()
+	if quarantined == nil {
+		return false
+	}
+	_, ok := (*quarantined)[id]
+	return ok
+}
+
+// quarantine adds the entity to the quarantined entities.
+func (m *systemManager) quarantine(id types.EntityID) {
+	m.failuresMux.Lock()
+	defer m.failuresMux.Unlock()
+	if i, found := slices.BinarySearch(m.failures.Quarantined, id); !found {
+		m.failures.Quarantined = slices.Insert(m.failures.Quarantined, i, id)
+		m.failuresChanged = true
+	}
+	m.storeQuarantined()
+}
+
+// storeQuarantined replaces the set of quarantined entities with those of the failure state. The set is replaced
+// rather than modified, so that searches can read it without locking.
+func (m *systemManager) storeQuarantined() {
+	quarantined := make(map[types.EntityID]struct{}, len(m.failures.Quarantined))
+	for _, id := range m.failures.Quarantined {
+		quarantined[id] = struct{}{}
+	}
+	m.quarantined.Store(&quarantined)
+}
+
+func (m *systemManager) quarantinedEntities() []types.EntityID {
+	quarantined := m.quarantined.Load()
+	if quarantined == nil {
+		return []types.EntityID{}
+	}
+	return slices.Sorted(maps.Keys(*quarantined))
+}
+
+// systemsHealth returns the health of the systems that failed at least once, and whether the circuit of one of them
+// is open.
+func (m *systemManager) systemsHealth() (health []types.SystemHealth, circuitOpen bool) {
+	m.failuresMux.Lock()
+	defer m.failuresMux.Unlock()
+	health = []types.SystemHealth{}
+	for _, sys := range slices.Concat(m.registeredInitSystems, m.registeredSystems) {
+		report, failed := sys.health.report(sys.Name, sys.policy, m.failures.Circuits[sys.Name])
+		if failed {
+			health = append(health, report)
+			circuitOpen = circuitOpen || report.CircuitOpen
+		}
+	}
+	return health, circuitOpen
+}
+
+// receiptJournal records the receipts of the current tick that a system changed, and their previous value, so that
+// they can be restored if the changes of the system are rolled back.
+type receiptJournal struct {
+	history *receipt.History
+	// hashes holds the hashes of the changed receipts, in the order they were first changed.
+	hashes   []types.TxHash
+	previous map[types.TxHash]*receipt.Receipt
+}
+
+func newReceiptJournal(history *receipt.History) *receiptJournal {
+	return &receiptJournal{
+		history:  history,
+		hashes:   nil,
+		previous: make(map[types.TxHash]*receipt.Receipt),
+	}
+}
+
+// record records the receipt of the given hash before it is changed. A nil journal records nothing.
+func (j *receiptJournal) record(hash types.TxHash) {
+	if j == nil {
+		return
+	}
+	if _, ok := j.previous[hash]; ok {
+		return
+	}
+	j.hashes = append(j.hashes, hash)
+	if rec, ok := j.history.GetReceipt(hash); ok {
+		j.previous[hash] = &rec
+	} else {
+		j.previous[hash] = nil
+	}
+}
+
+func (j *receiptJournal) rollback() {
+	for _, hash := range j.hashes {
+		if rec := j.previous[hash]; rec != nil {
+			j.history.SetReceipt(hash, *rec)
+		} else {
+			j.history.TakeReceipt(hash)
+		}
+	}
+}
+
+// -------------------------- Options --------------------------
+
+// WithFailurePolicy sets what happens to a tick when the system returns an error or panics. The default policy is
+// CrashWorld.
+func WithFailurePolicy(policy FailurePolicy) SystemOption {
+	return func(sys *systemType) {
+		sys.policy = policy
+	}
+}
+
+// WithCircuitBreaker stops running the system once it failed in maxFailures ticks in a row. The system is run again
+// after cooldownTicks ticks, and stopped again if it fails once more. While the circuit of a system is open, the
+// world reports that it is not healthy.
+//
+// Usage:
+//
+//	cardinal.RegisterSystem(world, LeaderboardSystem,
+//		cardinal.WithFailurePolicy(cardinal.SkipSystem),
+//		cardinal.WithCircuitBreaker(3, 100))
+func WithCircuitBreaker(maxFailures int, cooldownTicks uint64) SystemOption {
+	return func(sys *systemType) {
+		sys.breaker = &circuitBreaker{maxFailures: max(maxFailures, 1), cooldown: cooldownTicks}
+	}
+}
@@ -0,0 +1,273 @@
+package cardinal_test
+
+import (
+	"errors"
+	"testing"
+
+	"pkg.world.dev/world-engine/assert"
+	"pkg.world.dev/world-engine/cardinal"
+	"pkg.world.dev/world-engine/cardinal/filter"
+	"pkg.world.dev/world-engine/cardinal/types"
+	"pkg.world.dev/world-engine/sign"
+)
+
+type FailMsg struct {
+	Fail bool
+}
+
+// countItems returns the number of Item entities of the given kind.
+func countItems(t *testing.T, world *cardinal.World, kind string) int {
+	wCtx := cardinal.NewReadOnlyWorldContext(world)
+	count, err := cardinal.NewSearch().Entity(filter.Exact(filter.Component[Item]())).
+		Where(cardinal.FilterFunction[Item](func(item Item) bool { return item.Kind == kind })).
+		Count(wCtx)
+	assert.NilError(t, err)
+	return count
+}
+
+func TestFailingSystemAbortsTheTick(t *testing.T) {
+	tf := cardinal.NewTestFixture(t, nil)
+	world := tf.World
+	assert.NilError(t, cardinal.RegisterComponent[Item](world))
+	assert.NilError(t, cardinal.RegisterMessage[FailMsg, EmptyMsgResult](world, "fail"))
+	assert.NilError(t, cardinal.RegisterSystems(world, func(wCtx cardinal.WorldContext) error {
+		_, err := cardinal.Create(wCtx, Item{Kind: "coin"})
+		return err
+	}))
+	assert.NilError(t, cardinal.RegisterSystem(world, func(wCtx cardinal.WorldContext) error {
+		fail := false
+		err := cardinal.EachMessage[FailMsg, EmptyMsgResult](wCtx,
+			func(tx cardinal.TxData[FailMsg]) (EmptyMsgResult, error) {
+				fail = fail || tx.Msg.Fail
+				return EmptyMsgResult{}, nil
+			})
+		if err == nil && fail {
+			err = errors.New("the system failed")
+		}
+		return err
+	}, cardinal.WithFailurePolicy(cardinal.AbortTick)))
+	failMsg, ok := world.GetMessageByFullName("game.fail")
+	assert.True(t, ok)
+	tf.DoTick()
+	assert.Equal(t, countItems(t, world, "coin"), 1)
+
+	hash := tf.AddTransaction(failMsg.ID(), FailMsg{Fail: true}, &sign.Transaction{PersonaTag: "alice"})
+	tf.DoTick()
+
+	// The world keeps running, but the tick made no change.
+	assert.Equal(t, countItems(t, world, "coin"), 1)
+	status, err := world.GetTransactionStatus(hash)
+	assert.NilError(t, err)
+	assert.Equal(t, status.Status, types.TxStatusFailed)
+	assert.Equal(t, len(status.Errors), 1)
+	assert.Contains(t, status.Errors[0], "the tick was aborted")
+	assert.Contains(t, status.Errors[0], "the system failed")
+
+	health := world.Health()
+	assert.False(t, health.Healthy)
+	assert.Equal(t, health.AbortedTicks, uint64(1))
+	assert.Equal(t, *health.LastAbortedTick, uint64(1))
+	assert.Equal(t, len(health.Systems), 1)
+	assert.Equal(t, health.Systems[0].Policy, "abort_tick")
+
+	tf.DoTick()
+	assert.Equal(t, countItems(t, world, "coin"), 2)
+	assert.True(t, world.Health().Healthy)
+}
+
+func TestSkippedSystemIsRolledBack(t *testing.T) {
+	testCases := []struct {
+		name string
+		opts []cardinal.SystemOption
+	}{
+		{
+			name: "alone",
+			opts: nil,
+		},
+		{
+			name: "in parallel",
+			opts: []cardinal.SystemOption{
+				cardinal.WithSystemWrites(filter.Component[Item]()),
+				cardinal.WithSystemStructuralChanges(),
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tf := cardinal.NewTestFixture(t, nil)
+			world := tf.World
+			assert.NilError(t, cardinal.RegisterComponent[Item](world))
+			assert.NilError(t, cardinal.RegisterComponent[Health](world))
+			assert.NilError(t, cardinal.RegisterMessage[FailMsg, EmptyMsgResult](world, "fail"))
+			assert.NilError(t, cardinal.RegisterSystem(world, func(wCtx cardinal.WorldContext) error {
+				fail := false
+				err := cardinal.EachMessage[FailMsg, EmptyMsgResult](wCtx,
+					func(tx cardinal.TxData[FailMsg]) (EmptyMsgResult, error) {
+						fail = fail || tx.Msg.Fail
+						_, err := cardinal.Create(wCtx, Item{Kind: "gem"})
+						return EmptyMsgResult{}, err
+					})
+				if err != nil {
+					return err
+				}
+				if fail {
+					panic("the system failed")
+				}
+				return nil
+			}, append(tc.opts, cardinal.WithFailurePolicy(cardinal.SkipSystem))...))
+			assert.NilError(t, cardinal.RegisterSystem(world, func(wCtx cardinal.WorldContext) error {
+				_, err := cardinal.Create(wCtx, Health{})
+				return err
+			}, cardinal.WithSystemWrites(filter.Component[Health]()), cardinal.WithSystemStructuralChanges()))
+			failMsg, ok := world.GetMessageByFullName("game.fail")
+			assert.True(t, ok)
+			tf.DoTick()
+
+			processed := tf.AddTransaction(failMsg.ID(), FailMsg{}, &sign.Transaction{PersonaTag: "alice"})
+			failed := tf.AddTransaction(failMsg.ID(), FailMsg{Fail: true}, &sign.Transaction{PersonaTag: "bob"})
+			tf.DoTick()
+
+			// The changes of the skipped system were rolled back, and the other system still ran.
+			assert.Equal(t, countItems(t, world, "gem"), 0)
+			count, err := cardinal.NewSearch().Entity(filter.Exact(filter.Component[Health]())).
+				Count(cardinal.NewReadOnlyWorldContext(world))
+			assert.NilError(t, err)
+			assert.Equal(t, count, 2)
+
+			for _, hash := range []types.TxHash{processed, failed} {
+				status, err := world.GetTransactionStatus(hash)
+				assert.NilError(t, err)
+				assert.Equal(t, status.Status, types.TxStatusFailed)
+				assert.Equal(t, len(status.Errors), 1)
+				assert.Contains(t, status.Errors[0], "panicked: the system failed")
+			}
+
+			health := world.Health()
+			assert.True(t, health.Healthy)
+			assert.Equal(t, health.AbortedTicks, uint64(0))
+			assert.Equal(t, len(health.Systems), 1)
+			assert.Equal(t, health.Systems[0].Failures, uint64(1))
+			assert.Equal(t, health.Systems[0].Policy, "skip_system")
+		})
+	}
+}
+
+func TestFailingEntityIsQuarantined(t *testing.T) {
+	tf := cardinal.NewTestFixture(t, nil)
+	world := tf.World
+	assert.NilError(t, cardinal.RegisterComponent[Health](world))
+	var ids []types.EntityID
+	assert.NilError(t, cardinal.RegisterInitSystems(world, func(wCtx cardinal.WorldContext) error {
+		var err error
+		ids, err = cardinal.CreateMany(wCtx, 3, Health{Value: 1})
+		return err
+	}))
+	assert.NilError(t, cardinal.RegisterSystem(world, func(wCtx cardinal.WorldContext) error {
+		var errs []error
+		errs = append(errs, cardinal.NewSearch().Entity(filter.Exact(filter.Component[Health]())).Each(wCtx,
+			func(id types.EntityID) bool {
+				if id == ids[1] {
+					errs = append(errs, cardinal.NewEntityError(id, errors.New("bad entity")))
+					return false
+				}
+				errs = append(errs, cardinal.UpdateComponent[Health](wCtx, id, func(h *Health) *Health {
+					h.Value++
+					return h
+				}))
+				return true
+			}))
+		return errors.Join(errs...)
+	}, cardinal.WithFailurePolicy(cardinal.QuarantineEntity)))
+	tf.DoTick()
+	tf.DoTick()
+
+	// The system ran again without the quarantined entity in both ticks.
+	wCtx := cardinal.NewReadOnlyWorldContext(world)
+	for i, want := range []int{3, 1, 3} {
+		health, err := cardinal.GetComponent[Health](wCtx, ids[i])
+		assert.NilError(t, err)
+		assert.Equal(t, health.Value, want)
+	}
+	health := world.Health()
+	assert.DeepEqual(t, health.QuarantinedEntities, []types.EntityID{ids[1]})
+	assert.Equal(t, len(health.Systems), 1)
+	assert.Equal(t, health.Systems[0].Failures, uint64(1))
+	assert.Contains(t, health.Systems[0].LastError, "bad entity")
+}
+
+func TestCircuitBreakerStopsAFailingSystem(t *testing.T) {
+	tf := cardinal.NewTestFixture(t, nil)
+	world := tf.World
+	var runs []uint64
+	assert.NilError(t, cardinal.RegisterSystem(world, func(wCtx cardinal.WorldContext) error {
+		runs = append(runs, wCtx.CurrentTick())
+		return errors.New("the system failed")
+	}, cardinal.WithFailurePolicy(cardinal.SkipSystem), cardinal.WithCircuitBreaker(2, 3)))
+
+	for range 8 {
+		tf.DoTick()
+	}
+
+	// The circuit opened after the failures of ticks 0 and 1, was tried again after 3 ticks, and opened again.
+	assert.DeepEqual(t, runs, []uint64{0, 1, 5})
+	health := world.Health()
+	assert.False(t, health.Healthy)
+	assert.Equal(t, len(health.Systems), 1)
+	assert.True(t, health.Systems[0].CircuitOpen)
+	assert.Equal(t, health.Systems[0].Failures, uint64(3))
+	assert.Equal(t, health.Systems[0].ConsecutiveFailures, 3)
+}
+
+func TestQuarantineAndCircuitsAreKeptInTheGameState(t *testing.T) {
+	var ids []types.EntityID
+	var seen []types.EntityID
+	var circuitRuns int
+	setup := func(tf *cardinal.TestFixture, fail bool) {
+		world := tf.World
+		assert.NilError(t, cardinal.RegisterComponent[Health](world))
+		assert.NilError(t, cardinal.RegisterInitSystems(world, func(wCtx cardinal.WorldContext) error {
+			var err error
+			ids, err = cardinal.CreateMany(wCtx, 3, Health{Value: 1})
+			return err
+		}))
+		assert.NilError(t, cardinal.RegisterSystem(world, func(wCtx cardinal.WorldContext) error {
+			seen = nil
+			var entityErr error
+			err := cardinal.NewSearch().Entity(filter.Exact(filter.Component[Health]())).Each(wCtx,
+				func(id types.EntityID) bool {
+					if fail && id == ids[1] {
+						entityErr = cardinal.NewEntityError(id, errors.New("bad entity"))
+						return false
+					}
+					seen = append(seen, id)
+					return true
+				})
+			return errors.Join(err, entityErr)
+		}, cardinal.WithFailurePolicy(cardinal.QuarantineEntity)))
+		assert.NilError(t, cardinal.RegisterSystem(world, func(cardinal.WorldContext) error {
+			circuitRuns++
+			if fail {
+				return errors.New("the system failed")
+			}
+			return nil
+		}, cardinal.WithFailurePolicy(cardinal.SkipSystem), cardinal.WithCircuitBreaker(1, 100)))
+	}
+
+	tf1 := cardinal.NewTestFixture(t, nil)
+	setup(tf1, true)
+	tf1.DoTick()
+	tf1.DoTick()
+	assert.Equal(t, circuitRuns, 1)
+
+	// A world that restarts from the same state skips the same entities and systems, although nothing fails anymore.
+	tf2 := cardinal.NewTestFixture(t, tf1.Redis)
+	setup(tf2, false)
+	tf2.DoTick()
+
+	assert.DeepEqual(t, seen, []types.EntityID{ids[0], ids[2]})
+	assert.Equal(t, circuitRuns, 1)
+	health := tf2.World.Health()
+	assert.DeepEqual(t, health.QuarantinedEntities, []types.EntityID{ids[1]})
+	assert.Equal(t, len(health.Systems), 1)
+	assert.True(t, health.Systems[0].CircuitOpen)
+}
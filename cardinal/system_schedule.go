@@ -0,0 +1,172 @@
+package cardinal
+
+import (
+	"errors"
+	"slices"
+
+	"github.com/rotisserie/eris"
+
+	"pkg.world.dev/world-engine/cardinal/filter"
+	"pkg.world.dev/world-engine/cardinal/types"
+)
+
+var ErrSystemAccessViolation = errors.New("system accessed a component it did not declare")
+
+// systemAccess is the set of components a system declared it reads and writes. It is used to decide which systems
+// can safely run concurrently, and to enforce the declaration at runtime.
+type systemAccess struct {
+	reads  map[string]struct{}
+	writes map[string]struct{}
+	// structural is true when the system creates or removes entities, or adds or removes components from entities.
+	// Structural changes allocate entity and archetype IDs, so they can only happen one system at a time.
+	structural bool
+}
+
+func newSystemAccess() *systemAccess {
+	return &systemAccess{
+		reads:      make(map[string]struct{}),
+		writes:     make(map[string]struct{}),
+		structural: false,
+	}
+}
+
+// conflictsWith reports whether two systems may not run at the same time. A system that did not declare its access
+// conflicts with every other system.
+func (a *systemAccess) conflictsWith(other *systemAccess) bool {
+	if a == nil || other == nil {
+		return true
+	}
+	if a.structural && other.structural {
+		return true
+	}
+	for name := range a.writes {
+		if _, ok := other.writes[name]; ok {
+			return true
+		}
+		if _, ok := other.reads[name]; ok {
+			return true
+		}
+	}
+	for name := range other.writes {
+		if _, ok := a.reads[name]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// checkRead returns an error if the system did not declare a read or a write on the component.
+// A nil access is unrestricted.
+func (a *systemAccess) checkRead(name string) error {
+	if a == nil {
+		return nil
+	}
+	if _, ok := a.reads[name]; ok {
+		return nil
+	}
+	if _, ok := a.writes[name]; ok {
+		return nil
+	}
+	return eris.Wrapf(ErrSystemAccessViolation, "component %q was read but not declared", name)
+}
+
+// checkWrite returns an error if the system did not declare a write on the component.
+// A nil access is unrestricted.
+func (a *systemAccess) checkWrite(name string) error {
+	if a == nil {
+		return nil
+	}
+	if _, ok := a.writes[name]; ok {
+		return nil
+	}
+	return eris.Wrapf(ErrSystemAccessViolation, "component %q was written but not declared", name)
+}
+
+// checkStructural returns an error if the system did not declare structural changes, or if it did not declare a
+// write on every component of the entity being changed. A nil access is unrestricted.
+func (a *systemAccess) checkStructural(comps ...types.ComponentMetadata) error {
+	if a == nil {
+		return nil
+	}
+	if !a.structural {
+		return eris.Wrap(ErrSystemAccessViolation, "structural change was made but not declared")
+	}
+	for _, comp := range comps {
+		if err := a.checkWrite(comp.Name()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// buildSchedule groups the given systems into batches. Systems in the same batch do not conflict with each other and
+// are run concurrently, while batches are run one after the other. A system is always placed in a later batch than
+// every previous system it conflicts with, and than the systems it must run after, which after holds the indices of.
+// Conflicting systems therefore keep running in the given order. Systems that did not declare their access conflict
+// with everything and always end up in a batch of their own.
+func buildSchedule(systems []systemType, after [][]int) [][]systemType {
+	batchOf := make([]int, len(systems))
+	var batches [][]systemType
+	for i, sys := range systems {
+		batch := 0
+		for j := range i {
+			mustFollow := slices.Contains(after[i], j) || sys.access.conflictsWith(systems[j].access)
+			if batchOf[j] >= batch && mustFollow {
+				batch = batchOf[j] + 1
+			}
+		}
+		if batch == len(batches) {
+			batches = append(batches, nil)
+		}
+		batchOf[i] = batch
+		batches[batch] = append(batches[batch], sys)
+	}
+	return batches
+}
+
+// -------------------------- Options --------------------------
+
+// WithSystemReads declares the components a system reads. Together with WithSystemWrites, it allows the system to be
+// run concurrently with other systems that do not write to these components. Once a system declares its access,
+// reading a component that was not declared returns ErrSystemAccessViolation.
+//
+// Usage:
+//
+//	cardinal.RegisterSystem(world, MoveSystem,
+//		cardinal.WithSystemReads(filter.Component[Velocity]()),
+//		cardinal.WithSystemWrites(filter.Component[Position]()))
+func WithSystemReads(components ...filter.ComponentWrapper) SystemOption {
+	return func(sys *systemType) {
+		if sys.access == nil {
+			sys.access = newSystemAccess()
+		}
+		for _, comp := range components {
+			sys.access.reads[comp.Component.Name()] = struct{}{}
+		}
+	}
+}
+
+// WithSystemWrites declares the components a system writes. A write also allows the component to be read.
+// Writing a component that was not declared returns ErrSystemAccessViolation.
+func WithSystemWrites(components ...filter.ComponentWrapper) SystemOption {
+	return func(sys *systemType) {
+		if sys.access == nil {
+			sys.access = newSystemAccess()
+		}
+		for _, comp := range components {
+			sys.access.writes[comp.Component.Name()] = struct{}{}
+		}
+	}
+}
+
+// WithSystemStructuralChanges declares that a system creates or removes entities, or adds or removes components.
+// The components of the affected entities must also be declared with WithSystemWrites. Systems that make structural
+// changes never run concurrently with each other, which keeps the assignment of entity IDs deterministic.
+func WithSystemStructuralChanges() SystemOption {
+	return func(sys *systemType) {
+		if sys.access == nil {
+			sys.access = newSystemAccess()
+		}
+		sys.access.structural = true
+	}
+}
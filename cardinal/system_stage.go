@@ -0,0 +1,264 @@
+package cardinal
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/rotisserie/eris"
+)
+
+// SystemStage is a phase of a tick. The stages of a tick run one after the other, and the systems of a stage are
+// ordered by their Before and After constraints, and then by registration order.
+type SystemStage int
+
+const (
+	// PreUpdate is the stage for systems that prepare the tick, such as the systems of the persona plugin.
+	PreUpdate SystemStage = iota
+	// Update is the stage for the game logic. It is the default stage of a system.
+	Update
+	// PostUpdate is the stage for systems that react to the changes of the update stage.
+	PostUpdate
+	// Cleanup is the last stage of a tick, for systems that remove expired entities and the like.
+	Cleanup
+)
+
+func (s SystemStage) String() string {
+	switch s {
+	case PreUpdate:
+		return "pre_update"
+	case Update:
+		return "update"
+	case PostUpdate:
+		return "post_update"
+	case Cleanup:
+		return "cleanup"
+	default:
+		return fmt.Sprintf("SystemStage(%d)", int(s))
+	}
+}
+
+// RunCondition decides whether a system runs in the current tick. It is called before the system runs, and must not
+// change the state of the world.
+type RunCondition func(wCtx WorldContext) bool
+
+// EveryNTicks returns a run condition that runs a system every n ticks, starting at tick 0.
+func EveryNTicks(n uint64) RunCondition {
+	n = max(n, 1)
+	return func(wCtx WorldContext) bool {
+		return wCtx.CurrentTick()%n == 0
+	}
+}
+
+// WhenMessage returns a run condition that runs a system only in the ticks that have at least one transaction of the
+// given message.
+func WhenMessage[In, Out any]() RunCondition {
+	msgType := reflect.TypeOf(MessageType[In, Out]{})
+	return func(wCtx WorldContext) bool {
+		msg, ok := wCtx.getMessageByType(msgType)
+		if !ok || wCtx.getTxPool() == nil {
+			return false
+		}
+		return len(wCtx.getTxPool().ForID(msg.ID())) > 0
+	}
+}
+
+// phase returns the position of the system in a tick. Init systems run before every stage.
+func (sys *systemType) phase(isInit bool) int {
+	if isInit {
+		return 0
+	}
+	return int(sys.stage) + 1
+}
+
+// orderSystems orders the init systems and the systems, and groups them into the batches of the first tick and of the
+// other ticks. It returns an error if a constraint refers to a system that is not registered, if a constraint
+// contradicts the stages of the systems, or if the constraints between the systems of a stage form a cycle.
+func orderSystems(initSystems, systems []systemType) (initSchedule, schedule [][]systemType, err error) {
+	all := slices.Concat(initSystems, systems)
+	phases := make([]int, len(all))
+	index := make(map[string]int, len(all))
+	for i := range all {
+		if all[i].stage < PreUpdate || all[i].stage > Cleanup {
+			return nil, nil, eris.Errorf("system %q has an unknown stage %s", all[i].Name, all[i].stage)
+		}
+		phases[i] = all[i].phase(i < len(initSystems))
+		index[all[i].Name] = i
+	}
+
+	// edges[i] holds the systems that must run after system i in the same phase. Constraints between systems of
+	// different phases are satisfied by the order of the phases.
+	edges := make([][]int, len(all))
+	addEdge := func(from, to int) error {
+		if phases[from] > phases[to] {
+			return eris.Errorf("system %q must run before system %q, but it runs in a later stage",
+				all[from].Name, all[to].Name)
+		}
+		if phases[from] == phases[to] && !slices.Contains(edges[from], to) {
+			edges[from] = append(edges[from], to)
+		}
+		return nil
+	}
+	for i, sys := range all {
+		for _, name := range sys.before {
+			j, ok := index[name]
+			if !ok {
+				return nil, nil, eris.Errorf("system %q must run before system %q, which is not registered",
+					sys.Name, name)
+			}
+			if err := addEdge(i, j); err != nil {
+				return nil, nil, err
+			}
+		}
+		for _, name := range sys.after {
+			j, ok := index[name]
+			if !ok {
+				return nil, nil, eris.Errorf("system %q must run after system %q, which is not registered",
+					sys.Name, name)
+			}
+			if err := addEdge(j, i); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+
+	for phase := range int(Cleanup) + 2 {
+		var members []int
+		for i := range all {
+			if phases[i] == phase {
+				members = append(members, i)
+			}
+		}
+		ordered, err := sortSystems(all, members, edges)
+		if err != nil {
+			return nil, nil, err
+		}
+		// Translate the constraints into positions in the ordered systems.
+		position := make(map[int]int, len(ordered))
+		for pos, i := range ordered {
+			position[i] = pos
+		}
+		sorted := make([]systemType, len(ordered))
+		after := make([][]int, len(ordered))
+		for pos, i := range ordered {
+			sorted[pos] = all[i]
+			for _, j := range edges[i] {
+				after[position[j]] = append(after[position[j]], pos)
+			}
+		}
+		batches := buildSchedule(sorted, after)
+		initSchedule = append(initSchedule, batches...)
+		if phase > 0 {
+			schedule = append(schedule, batches...)
+		}
+	}
+	return initSchedule, schedule, nil
+}
+
+// sortSystems orders the given systems so that every system comes after the systems it must run after. Among the
+// systems that can run next, the one that was registered first is picked, so systems without constraints keep running
+// in registration order.
+func sortSystems(all []systemType, members []int, edges [][]int) ([]int, error) {
+	inDegree := make(map[int]int, len(members))
+	for _, i := range members {
+		inDegree[i] = 0
+	}
+	for _, i := range members {
+		for _, j := range edges[i] {
+			inDegree[j]++
+		}
+	}
+	ordered := make([]int, 0, len(members))
+	for len(ordered) < len(members) {
+		next := -1
+		for _, i := range members {
+			if d, ok := inDegree[i]; ok && d == 0 {
+				next = i
+				break
+			}
+		}
+		if next == -1 {
+			return nil, findCycle(all, inDegree, edges)
+		}
+		delete(inDegree, next)
+		for _, j := range edges[next] {
+			inDegree[j]--
+		}
+		ordered = append(ordered, next)
+	}
+	return ordered, nil
+}
+
+// findCycle returns an error that describes a cycle among the systems that could not be ordered. Each of them must
+// run after another one of them, so walking back from any of them ends in a cycle.
+func findCycle(all []systemType, remaining map[int]int, edges [][]int) error {
+	members := slices.Sorted(maps.Keys(remaining))
+	predecessor := make(map[int]int, len(members))
+	for _, i := range members {
+		for _, j := range edges[i] {
+			if _, ok := remaining[j]; !ok {
+				continue
+			}
+			if _, ok := predecessor[j]; !ok {
+				predecessor[j] = i
+			}
+		}
+	}
+	visited := map[int]bool{}
+	i := members[0]
+	for !visited[i] {
+		visited[i] = true
+		i = predecessor[i]
+	}
+	cycle := []string{all[i].Name}
+	for j := predecessor[i]; j != i; j = predecessor[j] {
+		cycle = append(cycle, all[j].Name)
+	}
+	cycle = append(cycle, all[i].Name)
+	slices.Reverse(cycle)
+	return eris.Errorf("the order of the systems has a cycle: %s", strings.Join(cycle, " -> "))
+}
+
+// -------------------------- Options --------------------------
+
+// WithSystemStage sets the stage of the tick in which the system runs. The default stage is Update.
+func WithSystemStage(stage SystemStage) SystemOption {
+	return func(sys *systemType) {
+		sys.stage = stage
+	}
+}
+
+// WithSystemBefore makes the system run before the systems with the given names. The names of systems registered
+// with RegisterSystem are returned by SystemName. The constraints are validated when the game starts.
+//
+// Usage:
+//
+//	cardinal.RegisterSystem(world, MoveSystem,
+//		cardinal.WithSystemBefore(cardinal.SystemName(CollisionSystem)))
+func WithSystemBefore(systems ...string) SystemOption {
+	return func(sys *systemType) {
+		sys.before = append(sys.before, systems...)
+	}
+}
+
+// WithSystemAfter makes the system run after the systems with the given names. The constraints are validated when
+// the game starts.
+func WithSystemAfter(systems ...string) SystemOption {
+	return func(sys *systemType) {
+		sys.after = append(sys.after, systems...)
+	}
+}
+
+// WithRunCondition makes the system run only in the ticks for which the condition returns true. When several
+// conditions are given, the system runs only if all of them return true.
+//
+// Usage:
+//
+//	cardinal.RegisterSystem(world, RegenSystem, cardinal.WithRunCondition(cardinal.EveryNTicks(10)))
+func WithRunCondition(cond RunCondition) SystemOption {
+	return func(sys *systemType) {
+		sys.conditions = append(sys.conditions, cond)
+	}
+}
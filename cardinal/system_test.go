@@ -2,7 +2,9 @@ package cardinal_test
 
 import (
 	"errors"
+	"strings"
 	"testing"
+	"time"
 
 	"pkg.world.dev/world-engine/assert"
 	"pkg.world.dev/world-engine/cardinal"
@@ -102,3 +104,199 @@ func TestInitSystemRunsOnce(t *testing.T) {
 	assert.Equal(t, count, 1)
 	assert.Equal(t, count2, 2)
 }
+
+func TestSystemsWithDisjointAccessRunConcurrently(t *testing.T) {
+	tf := cardinal.NewTestFixture(t, nil)
+	world, doTick := tf.World, tf.DoTick
+	assert.NilError(t, cardinal.RegisterComponent[Foo](world))
+	assert.NilError(t, cardinal.RegisterComponent[Bar](world))
+
+	// Each system waits for the other one to start. This can only succeed if both systems run at the same time.
+	fooStarted, barStarted := make(chan struct{}), make(chan struct{})
+	var fooSawBar, barSawFoo bool
+	fooSystem := func(cardinal.WorldContext) error {
+		close(fooStarted)
+		select {
+		case <-barStarted:
+			fooSawBar = true
+		case <-time.After(5 * time.Second):
+		}
+		return nil
+	}
+	barSystem := func(cardinal.WorldContext) error {
+		close(barStarted)
+		select {
+		case <-fooStarted:
+			barSawFoo = true
+		case <-time.After(5 * time.Second):
+		}
+		return nil
+	}
+	assert.NilError(t, cardinal.RegisterSystem(world, fooSystem, cardinal.WithSystemWrites(filter.Component[Foo]())))
+	assert.NilError(t, cardinal.RegisterSystem(world, barSystem, cardinal.WithSystemWrites(filter.Component[Bar]())))
+
+	doTick()
+	assert.Check(t, fooSawBar)
+	assert.Check(t, barSawFoo)
+}
+
+func TestSystemsWithConflictingAccessRunInOrder(t *testing.T) {
+	tf := cardinal.NewTestFixture(t, nil)
+	world, doTick := tf.World, tf.DoTick
+	assert.NilError(t, cardinal.RegisterComponent[Health](world))
+
+	var order []string
+	writer := func(cardinal.WorldContext) error {
+		order = append(order, "writer")
+		return nil
+	}
+	reader := func(cardinal.WorldContext) error {
+		order = append(order, "reader")
+		return nil
+	}
+	undeclared := func(cardinal.WorldContext) error {
+		order = append(order, "undeclared")
+		return nil
+	}
+	assert.NilError(t, cardinal.RegisterSystem(world, writer, cardinal.WithSystemWrites(filter.Component[Health]())))
+	assert.NilError(t, cardinal.RegisterSystem(world, reader, cardinal.WithSystemReads(filter.Component[Health]())))
+	assert.NilError(t, cardinal.RegisterSystems(world, undeclared))
+
+	doTick()
+	assert.DeepEqual(t, []string{"writer", "reader", "undeclared"}, order)
+}
+
+func TestSystemAccessIsEnforced(t *testing.T) {
+	tf := cardinal.NewTestFixture(t, nil)
+	world, doTick := tf.World, tf.DoTick
+	assert.NilError(t, cardinal.RegisterComponent[Foo](world))
+	assert.NilError(t, cardinal.RegisterComponent[Bar](world))
+
+	var id types.EntityID
+	assert.NilError(t, cardinal.RegisterInitSystems(world, func(wCtx cardinal.WorldContext) error {
+		var err error
+		id, err = cardinal.Create(wCtx, Foo{}, Bar{})
+		return err
+	}))
+
+	var readErr, writeErr, createErr, removeErr, allowedErr error
+	assert.NilError(t, cardinal.RegisterSystem(world, func(wCtx cardinal.WorldContext) error {
+		_, allowedErr = cardinal.GetComponent[Foo](wCtx, id)
+		_, readErr = cardinal.GetComponent[Bar](wCtx, id)
+		writeErr = cardinal.SetComponent[Foo](wCtx, id, &Foo{})
+		_, createErr = cardinal.Create(wCtx, Foo{})
+		removeErr = cardinal.Remove(wCtx, id)
+		return nil
+	}, cardinal.WithSystemReads(filter.Component[Foo]())))
+
+	doTick()
+	assert.NilError(t, allowedErr)
+	assert.ErrorIs(t, readErr, cardinal.ErrSystemAccessViolation)
+	assert.ErrorIs(t, writeErr, cardinal.ErrSystemAccessViolation)
+	assert.ErrorIs(t, createErr, cardinal.ErrSystemAccessViolation)
+	assert.ErrorIs(t, removeErr, cardinal.ErrSystemAccessViolation)
+}
+
+func TestSystemsRunByStageAndConstraints(t *testing.T) {
+	tf := cardinal.NewTestFixture(t, nil)
+	world, doTick := tf.World, tf.DoTick
+
+	// Every system needs its own function literal, since systems are named after their function.
+	var order []string
+	cleanup := func(cardinal.WorldContext) error { order = append(order, "cleanup"); return nil }
+	post := func(cardinal.WorldContext) error { order = append(order, "post"); return nil }
+	spawn := func(cardinal.WorldContext) error { order = append(order, "spawn"); return nil }
+	move := func(cardinal.WorldContext) error { order = append(order, "move"); return nil }
+	collide := func(cardinal.WorldContext) error { order = append(order, "collide"); return nil }
+	input := func(cardinal.WorldContext) error { order = append(order, "input"); return nil }
+	assert.NilError(t, cardinal.RegisterSystem(world, cleanup, cardinal.WithSystemStage(cardinal.Cleanup)))
+	assert.NilError(t, cardinal.RegisterSystem(world, post, cardinal.WithSystemStage(cardinal.PostUpdate)))
+	assert.NilError(t, cardinal.RegisterSystem(world, collide, cardinal.WithSystemAfter(cardinal.SystemName(move))))
+	assert.NilError(t, cardinal.RegisterSystem(world, spawn))
+	assert.NilError(t, cardinal.RegisterSystem(world, move, cardinal.WithSystemBefore(cardinal.SystemName(spawn))))
+	assert.NilError(t, cardinal.RegisterSystem(world, input, cardinal.WithSystemStage(cardinal.PreUpdate)))
+
+	doTick()
+	assert.DeepEqual(t, []string{"input", "move", "collide", "spawn", "post", "cleanup"}, order)
+}
+
+func TestSystemOrderIsValidatedAtStart(t *testing.T) {
+	first := func(cardinal.WorldContext) error { return nil }
+	second := func(cardinal.WorldContext) error { return nil }
+	third := func(cardinal.WorldContext) error { return nil }
+
+	testCases := []struct {
+		name     string
+		register func(world *cardinal.World) error
+		wantErr  string
+	}{
+		{
+			name: "cycle",
+			register: func(world *cardinal.World) error {
+				return errors.Join(
+					cardinal.RegisterSystem(world, first, cardinal.WithSystemAfter(cardinal.SystemName(third))),
+					cardinal.RegisterSystem(world, second, cardinal.WithSystemAfter(cardinal.SystemName(first))),
+					cardinal.RegisterSystem(world, third, cardinal.WithSystemAfter(cardinal.SystemName(second))),
+				)
+			},
+			wantErr: "cycle: " + strings.Join([]string{
+				cardinal.SystemName(first), cardinal.SystemName(second), cardinal.SystemName(third),
+				cardinal.SystemName(first),
+			}, " -> "),
+		},
+		{
+			name: "unknown system",
+			register: func(world *cardinal.World) error {
+				return cardinal.RegisterSystem(world, first, cardinal.WithSystemBefore("main.MissingSystem"))
+			},
+			wantErr: `"main.MissingSystem", which is not registered`,
+		},
+		{
+			name: "later stage",
+			register: func(world *cardinal.World) error {
+				return errors.Join(
+					cardinal.RegisterSystem(world, first, cardinal.WithSystemStage(cardinal.Cleanup),
+						cardinal.WithSystemBefore(cardinal.SystemName(second))),
+					cardinal.RegisterSystem(world, second),
+				)
+			},
+			wantErr: "but it runs in a later stage",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tf := cardinal.NewTestFixture(t, nil)
+			assert.NilError(t, tc.register(tf.World))
+			assert.ErrorContains(t, tf.World.StartGame(), tc.wantErr)
+		})
+	}
+}
+
+func TestSystemRunConditions(t *testing.T) {
+	tf := cardinal.NewTestFixture(t, nil)
+	world, doTick := tf.World, tf.DoTick
+	type FooMsg struct{}
+	assert.NilError(t, cardinal.RegisterMessage[FooMsg, EmptyMsgResult](world, "foo"))
+
+	var everyThirdTick, onFoo []uint64
+	assert.NilError(t, cardinal.RegisterSystem(world, func(wCtx cardinal.WorldContext) error {
+		everyThirdTick = append(everyThirdTick, wCtx.CurrentTick())
+		return nil
+	}, cardinal.WithRunCondition(cardinal.EveryNTicks(3))))
+	assert.NilError(t, cardinal.RegisterSystem(world, func(wCtx cardinal.WorldContext) error {
+		onFoo = append(onFoo, wCtx.CurrentTick())
+		return nil
+	}, cardinal.WithRunCondition(cardinal.WhenMessage[FooMsg, EmptyMsgResult]())))
+	fooMsg, ok := world.GetMessageByFullName("game.foo")
+	assert.True(t, ok)
+
+	for tick := range 7 {
+		if tick == 2 || tick == 5 {
+			tf.AddTransaction(fooMsg.ID(), FooMsg{})
+		}
+		doTick()
+	}
+
+	assert.DeepEqual(t, []uint64{0, 3, 6}, everyThirdTick)
+	assert.DeepEqual(t, []uint64{2, 5}, onFoo)
+}
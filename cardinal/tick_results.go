@@ -1,17 +1,51 @@
 package cardinal
 
 import (
+	"encoding/hex"
 	"encoding/json"
 
 	"github.com/rotisserie/eris"
 
+	"pkg.world.dev/world-engine/cardinal/gamestate"
 	"pkg.world.dev/world-engine/cardinal/receipt"
+	servertypes "pkg.world.dev/world-engine/cardinal/server/types"
+	"pkg.world.dev/world-engine/cardinal/storage"
+	"pkg.world.dev/world-engine/cardinal/txpool"
+	"pkg.world.dev/world-engine/cardinal/types"
 )
 
 type TickResults struct {
 	Tick     uint64
 	Receipts []receipt.Receipt
 	Events   [][]byte
+	// StateRoot is the hex encoded state root after the tick. See gamestate.TickDiff.
+	StateRoot string
+	// Diff is the set of state changes made in the tick. It holds the values of components that may be hidden from some
+	// personas, so it is never sent to the clients of /events.
+	Diff *gamestate.TickDiff `json:"-"`
+
+	// audiences holds the clients that may receive each of the Events.
+	audiences []eventAudience
+	// receiptOwners holds the persona tag that submitted the transaction of each receipt.
+	receiptOwners map[types.TxHash]string
+}
+
+// eventAudience selects the clients of /events that receive an event. The zero value sends the event to every
+// client.
+type eventAudience struct {
+	personaTag string
+	topic      string
+}
+
+func (a eventAudience) includes(client servertypes.EventClient) bool {
+	switch {
+	case a.personaTag != "":
+		return a.personaTag == client.PersonaTag()
+	case a.topic != "":
+		return client.IsSubscribed(a.topic)
+	default:
+		return true
+	}
 }
 
 func NewTickResults(initialTick uint64) *TickResults {
@@ -23,29 +57,152 @@ func NewTickResults(initialTick uint64) *TickResults {
 }
 
 func (tr *TickResults) AddEvent(event any) error {
+	return tr.addEvent(eventAudience{}, event)
+}
+
+func (tr *TickResults) AddStringEvent(e string) error {
+	tr.appendEvent(eventAudience{}, []byte(e))
+	return nil
+}
+
+// AddPersonaEvent adds an event that is only sent to the clients that authenticated as the persona.
+func (tr *TickResults) AddPersonaEvent(personaTag string, event any) error {
+	if personaTag == "" {
+		return eris.New("persona tag is required")
+	}
+	return tr.addEvent(eventAudience{personaTag: personaTag}, event)
+}
+
+// AddTopicEvent adds an event that is only sent to the clients that subscribed to the topic.
+func (tr *TickResults) AddTopicEvent(topic string, event any) error {
+	if topic == "" {
+		return eris.New("topic is required")
+	}
+	return tr.addEvent(eventAudience{topic: topic}, event)
+}
+
+func (tr *TickResults) addEvent(audience eventAudience, event any) error {
 	data, err := json.Marshal(event)
 	if err != nil {
 		return eris.Wrap(err, "must use a json serializable type for emitting events")
 	}
-	tr.Events = append(tr.Events, data)
+	tr.appendEvent(audience, data)
 	return nil
 }
 
-func (tr *TickResults) AddStringEvent(e string) error {
-	tr.Events = append(tr.Events, []byte(e))
-	return nil
+func (tr *TickResults) appendEvent(audience eventAudience, data []byte) {
+	tr.Events = append(tr.Events, data)
+	tr.audiences = append(tr.audiences, audience)
+}
+
+// appendEvents adds the events of other, in order, along with their audiences.
+func (tr *TickResults) appendEvents(other *TickResults) {
+	for i, data := range other.Events {
+		tr.appendEvent(other.audiences[i], data)
+	}
+}
+
+// truncateEvents discards the events that were added after the first n events.
+func (tr *TickResults) truncateEvents(n int) {
+	tr.Events = tr.Events[:n]
+	tr.audiences = tr.audiences[:n]
 }
 
 func (tr *TickResults) SetReceipts(newReceipts []receipt.Receipt) {
 	tr.Receipts = newReceipts
 }
 
+// SetReceiptOwners records the persona that submitted each of the transactions, so that its receipt is only sent to
+// that persona.
+func (tr *TickResults) SetReceiptOwners(txs txpool.TxMap) {
+	tr.receiptOwners = make(map[types.TxHash]string)
+	for _, txData := range txs {
+		for _, tx := range txData {
+			if tx.Tx != nil {
+				tr.receiptOwners[tx.TxHash] = tx.Tx.PersonaTag
+			}
+		}
+	}
+}
+
 func (tr *TickResults) SetTick(tick uint64) {
 	tr.Tick = tick
 }
 
+func (tr *TickResults) SetDiff(diff *gamestate.TickDiff) {
+	tr.Diff = diff
+	tr.StateRoot = ""
+	if diff != nil {
+		tr.StateRoot = hex.EncodeToString(diff.StateRoot)
+	}
+}
+
+// ForClient returns the results that the client may see: the events sent to every client, to the persona the client
+// authenticated as and to the topics it subscribed to, and the receipts of the transactions the persona submitted. The
+// state root is sent to every client, the diff to none.
+func (tr *TickResults) ForClient(client servertypes.EventClient) *TickResults {
+	results := &TickResults{
+		Tick:      tr.Tick,
+		Receipts:  []receipt.Receipt{},
+		Events:    [][]byte{},
+		StateRoot: tr.StateRoot,
+	}
+	for i, data := range tr.Events {
+		if tr.audiences[i].includes(client) {
+			results.Events = append(results.Events, data)
+		}
+	}
+	if personaTag := client.PersonaTag(); personaTag != "" {
+		for _, rec := range tr.Receipts {
+			if tr.receiptOwners[rec.TxHash] == personaTag {
+				results.Receipts = append(results.Receipts, rec)
+			}
+		}
+	}
+	return results
+}
+
+// tickRecord returns the results to keep in the receipt log. Every persona can read the events in the log, so only the
+// events sent to every client are kept.
+func (tr *TickResults) tickRecord() (storage.TickRecord, error) {
+	record := storage.TickRecord{
+		Tick:     tr.Tick,
+		Receipts: make([]storage.ReceiptRecord, 0, len(tr.Receipts)),
+		Events:   []json.RawMessage{},
+	}
+	for _, rec := range tr.Receipts {
+		result, err := json.Marshal(rec.Result)
+		if err != nil {
+			return record, eris.Wrapf(err, "failed to encode the result of transaction %q", rec.TxHash)
+		}
+		errs := make([]string, 0, len(rec.Errs))
+		for _, err := range rec.Errs {
+			errs = append(errs, err.Error())
+		}
+		record.Receipts = append(record.Receipts, storage.ReceiptRecord{
+			TxHash:      string(rec.TxHash),
+			Tick:        tr.Tick,
+			PersonaTag:  tr.receiptOwners[rec.TxHash],
+			Result:      result,
+			Errors:      errs,
+			Fee:         rec.Fee,
+			FeeRefunded: rec.FeeRefunded,
+		})
+	}
+	for i, data := range tr.Events {
+		if tr.audiences[i] == (eventAudience{}) {
+			record.Events = append(record.Events, data)
+		}
+	}
+	return record, nil
+}
+
 func (tr *TickResults) Clear() {
 	tr.Tick = 0
 	tr.Receipts = nil
 	tr.Events = nil
+	tr.StateRoot = ""
+	tr.Diff = nil
+	tr.audiences = nil
+	tr.receiptOwners = nil
 }
@@ -0,0 +1,99 @@
+package txpool
+
+import (
+	"cmp"
+	"slices"
+)
+
+// OrderingPolicy orders the transactions of a tick when the pool is copied. Fees are charged in the resulting order,
+// systems process the transactions of each message in it, and every transaction is submitted to the base shard with
+// its position in the order of the tick, and the name of the policy.
+//
+// A policy must be deterministic, and ordering transactions it already ordered must not change their order, so that
+// every replica, and the recovery from the base shard, processes the transactions in the same order.
+type OrderingPolicy struct {
+	// Name identifies the policy in the transactions submitted to the base shard.
+	Name string
+	// Order returns the transactions of the tick in the order they are processed. The transactions are given in the
+	// order they were added to the pool.
+	Order func(txs []TxData) []TxData
+}
+
+// ArrivalOrder keeps the transactions in the order they were added to the pool. It is the default policy.
+var ArrivalOrder = OrderingPolicy{
+	Name: "arrival",
+	Order: func(txs []TxData) []TxData {
+		return txs
+	},
+}
+
+// TimestampOrder orders the transactions by their timestamp, and then by their hash.
+var TimestampOrder = OrderingPolicy{
+	Name:  "timestamp",
+	Order: orderByTimestamp,
+}
+
+// RoundRobinOrder takes one transaction from each persona in turn, so that a persona that submits many transactions
+// doesn't delay the transactions of the others. The transactions of each persona are in TimestampOrder, and the
+// personas take turns in the order of their first transaction.
+var RoundRobinOrder = OrderingPolicy{
+	Name:  "round-robin",
+	Order: orderRoundRobin,
+}
+
+// PriorityOrder orders the transactions by decreasing priority. Transactions with the same priority are in
+// TimestampOrder. The name identifies the priority function.
+func PriorityOrder(name string, priority func(tx TxData) uint64) OrderingPolicy {
+	return OrderingPolicy{
+		Name: name,
+		Order: func(txs []TxData) []TxData {
+			slices.SortStableFunc(txs, func(a, b TxData) int {
+				if c := cmp.Compare(priority(b), priority(a)); c != 0 {
+					return c
+				}
+				return compareTimestamps(a, b)
+			})
+			return txs
+		},
+	}
+}
+
+// FeeOrder orders the transactions by decreasing fee. Transactions with the same fee are in TimestampOrder.
+var FeeOrder = PriorityOrder("fee", func(tx TxData) uint64 {
+	return tx.Tx.Fee
+})
+
+func orderByTimestamp(txs []TxData) []TxData {
+	slices.SortStableFunc(txs, compareTimestamps)
+	return txs
+}
+
+func orderRoundRobin(txs []TxData) []TxData {
+	txs = orderByTimestamp(txs)
+	var personas []string
+	txsOfPersona := make(map[string][]TxData)
+	for _, tx := range txs {
+		tag := tx.Tx.PersonaTag
+		if _, ok := txsOfPersona[tag]; !ok {
+			personas = append(personas, tag)
+		}
+		txsOfPersona[tag] = append(txsOfPersona[tag], tx)
+	}
+
+	ordered := make([]TxData, 0, len(txs))
+	for round := 0; len(ordered) < len(txs); round++ {
+		for _, tag := range personas {
+			if round < len(txsOfPersona[tag]) {
+				ordered = append(ordered, txsOfPersona[tag][round])
+			}
+		}
+	}
+	return ordered
+}
+
+func compareTimestamps(a, b TxData) int {
+	if c := cmp.Compare(a.Tx.Timestamp, b.Tx.Timestamp); c != 0 {
+		return c
+	}
+	return cmp.Compare(a.TxHash, b.TxHash)
+}
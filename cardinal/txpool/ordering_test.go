@@ -0,0 +1,109 @@
+package txpool
+
+import (
+	"context"
+	"testing"
+
+	"pkg.world.dev/world-engine/assert"
+	"pkg.world.dev/world-engine/sign"
+)
+
+// addTxs adds the transactions to the pool as transactions of message 1.
+func addTxs(pool *TxPool, txs ...*sign.Transaction) {
+	for _, tx := range txs {
+		pool.AddTransaction(1, nil, tx)
+	}
+}
+
+func personaTags(txs []TxData) []string {
+	tags := make([]string, 0, len(txs))
+	for _, tx := range txs {
+		tags = append(tags, tx.Tx.PersonaTag)
+	}
+	return tags
+}
+
+func timestampedTx(personaTag string, timestamp int64) *sign.Transaction {
+	return &sign.Transaction{PersonaTag: personaTag, Timestamp: timestamp}
+}
+
+func TestOrderingPolicies(t *testing.T) {
+	txs := []*sign.Transaction{
+		timestampedTx("a1", 30),
+		timestampedTx("a2", 10),
+		timestampedTx("b1", 20),
+		timestampedTx("a3", 40),
+		timestampedTx("c1", 50),
+		timestampedTx("b2", 35),
+	}
+	priorities := map[string]uint64{"b1": 5, "c1": 5, "a3": 1}
+	byPriority := PriorityOrder("priority", func(tx TxData) uint64 {
+		return priorities[tx.Tx.PersonaTag]
+	})
+	// Round robin groups the transactions by the first letter of their persona tag.
+	roundRobin := OrderingPolicy{
+		Name: "round-robin-by-letter",
+		Order: func(txs []TxData) []TxData {
+			for i := range txs {
+				tx := *txs[i].Tx
+				tx.PersonaTag = tx.PersonaTag[:1]
+				txs[i].Tx = &tx
+			}
+			return RoundRobinOrder.Order(txs)
+		},
+	}
+
+	testCases := []struct {
+		name   string
+		policy OrderingPolicy
+		want   []string
+	}{
+		{"arrival", ArrivalOrder, []string{"a1", "a2", "b1", "a3", "c1", "b2"}},
+		{"timestamp", TimestampOrder, []string{"a2", "b1", "a1", "b2", "a3", "c1"}},
+		{"round robin", roundRobin, []string{"a", "b", "c", "a", "b", "a"}},
+		{"priority", byPriority, []string{"b1", "c1", "a3", "a2", "a1", "b2"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			pool := New()
+			pool.SetOrderingPolicy(tc.policy)
+			addTxs(pool, txs...)
+			ordered := pool.CopyTransactions(context.Background()).ForID(1)
+			assert.DeepEqual(t, personaTags(ordered), tc.want)
+
+			// Ordering the transactions again doesn't change their order.
+			assert.DeepEqual(t, personaTags(tc.policy.Order(ordered)), tc.want)
+		})
+	}
+}
+
+func TestTimestampOrderBreaksTiesWithTheHash(t *testing.T) {
+	first, second := New(), New()
+	first.SetOrderingPolicy(TimestampOrder)
+	second.SetOrderingPolicy(TimestampOrder)
+	txs := []*sign.Transaction{timestampedTx("a", 10), timestampedTx("b", 10), timestampedTx("c", 10)}
+	addTxs(first, txs...)
+	addTxs(second, txs[2], txs[0], txs[1])
+
+	assert.DeepEqual(t,
+		personaTags(first.CopyTransactions(context.Background()).ForID(1)),
+		personaTags(second.CopyTransactions(context.Background()).ForID(1)))
+}
+
+func TestSequenceIsThePositionInTheTickAcrossMessages(t *testing.T) {
+	pool := New()
+	pool.SetOrderingPolicy(TimestampOrder)
+	pool.AddTransaction(1, nil, timestampedTx("a", 30))
+	pool.AddTransaction(2, nil, timestampedTx("b", 10))
+	pool.AddTransaction(1, nil, timestampedTx("c", 20))
+	cpy := pool.CopyTransactions(context.Background())
+
+	sequences := map[string]uint64{}
+	for _, txs := range cpy.Transactions() {
+		for _, tx := range txs {
+			sequences[tx.Tx.PersonaTag] = tx.Sequence
+		}
+	}
+	assert.DeepEqual(t, sequences, map[string]uint64{"b": 0, "c": 1, "a": 2})
+	assert.DeepEqual(t, personaTags(cpy.ForID(1)), []string{"c", "a"})
+}
@@ -1,9 +1,14 @@
 package txpool
 
 import (
+	"cmp"
 	"context"
+	"errors"
+	"maps"
+	"slices"
 	"sync"
 
+	"github.com/rotisserie/eris"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/trace"
 
@@ -11,8 +16,23 @@ import (
 	"pkg.world.dev/world-engine/sign"
 )
 
+// ErrTxRejected is returned when a transaction would exceed the limits of the pool. The pool is emptied by every tick,
+// so the transaction can be submitted again after the next tick.
+var ErrTxRejected = errors.New("transaction rejected by the tx pool limits")
+
 type TxMap map[types.MessageID][]TxData
 
+// limits bound the transactions that TryAddTransaction accepts into the pool between two ticks. A limit of 0 is not
+// enforced.
+type limits struct {
+	// maxSize is the maximum number of transactions in the pool.
+	maxSize int
+	// maxPerPersona is the maximum number of transactions of a single persona in the pool.
+	maxPerPersona int
+	// maxPerMessage is the maximum number of transactions of each message type in the pool.
+	maxPerMessage map[types.MessageID]int
+}
+
 type TxData struct {
 	MsgID  types.MessageID
 	Msg    any
@@ -20,24 +40,67 @@ type TxData struct {
 	Tx     *sign.Transaction
 	// EVMSourceTxHash is the tx hash of the EVM tx that triggered this tx.
 	EVMSourceTxHash string
+	// Sequence is the position of the transaction in the order of the tick, across all message types. Until the pool
+	// is copied, it is the position of the transaction in the order the transactions were added to the pool.
+	Sequence uint64
 }
 
 type TxPool struct {
 	m         TxMap
+	hashes    map[types.TxHash]struct{}
 	txsInPool int
-	mux       *sync.Mutex
-	tracer    trace.Tracer
+	// txsOfPersona counts the transactions of each persona in the pool.
+	txsOfPersona map[string]int
+	limits       limits
+	ordering     OrderingPolicy
+	mux          *sync.Mutex
+	tracer       trace.Tracer
 }
 
 func New() *TxPool {
 	return &TxPool{
-		m:      TxMap{},
-		mux:    &sync.Mutex{},
-		tracer: otel.Tracer("txpool"),
+		m:            TxMap{},
+		hashes:       map[types.TxHash]struct{}{},
+		txsOfPersona: map[string]int{},
+		limits:       limits{maxPerMessage: map[types.MessageID]int{}},
+		ordering:     ArrivalOrder,
+		mux:          &sync.Mutex{},
+		tracer:       otel.Tracer("txpool"),
 	}
 }
 
+// SetLimits sets the pool size and per persona limits. The per message limits are set with SetMessageLimit.
+func (t *TxPool) SetLimits(maxSize, maxPerPersona int) {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	t.limits.maxSize = maxSize
+	t.limits.maxPerPersona = maxPerPersona
+}
+
+// SetMessageLimit sets the maximum number of transactions of the message type in the pool.
+func (t *TxPool) SetMessageLimit(id types.MessageID, maxTxs int) {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	t.limits.maxPerMessage[id] = maxTxs
+}
+
+// SetOrderingPolicy sets the policy that orders the transactions of the pool when it is copied.
+func (t *TxPool) SetOrderingPolicy(policy OrderingPolicy) {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	t.ordering = policy
+}
+
+// OrderingPolicy returns the policy that orders the transactions of the pool when it is copied.
+func (t *TxPool) OrderingPolicy() OrderingPolicy {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	return t.ordering
+}
+
 func (t *TxPool) GetAmountOfTxs() int {
+	t.mux.Lock()
+	defer t.mux.Unlock()
 	return t.txsInPool
 }
 
@@ -67,9 +130,33 @@ func (t *TxPool) AddEVMTransaction(id types.MessageID, v any, sig *sign.Transact
 	return t.addTransaction(id, v, sig, evmTxHash)
 }
 
+// TryAddTransaction adds the transaction to the pool unless it would exceed the limits of the pool, in which case an
+// error wrapping ErrTxRejected is returned. AddTransaction ignores the limits.
+func (t *TxPool) TryAddTransaction(id types.MessageID, v any, sig *sign.Transaction) (types.TxHash, error) {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	switch {
+	case t.limits.maxSize > 0 && t.txsInPool >= t.limits.maxSize:
+		return "", eris.Wrapf(ErrTxRejected, "the pool is full with %d transactions", t.txsInPool)
+	case t.limits.maxPerPersona > 0 && t.txsOfPersona[sig.PersonaTag] >= t.limits.maxPerPersona:
+		return "", eris.Wrapf(ErrTxRejected, "persona %q already has %d transactions in the pool",
+			sig.PersonaTag, t.limits.maxPerPersona)
+	case t.limits.maxPerMessage[id] > 0 && len(t.m[id]) >= t.limits.maxPerMessage[id]:
+		return "", eris.Wrapf(ErrTxRejected, "the message already has %d transactions in the pool",
+			t.limits.maxPerMessage[id])
+	}
+	return t.addTransactionLocked(id, v, sig, ""), nil
+}
+
 func (t *TxPool) addTransaction(id types.MessageID, v any, sig *sign.Transaction, evmTxHash string) types.TxHash {
 	t.mux.Lock()
 	defer t.mux.Unlock()
+	return t.addTransactionLocked(id, v, sig, evmTxHash)
+}
+
+func (t *TxPool) addTransactionLocked(
+	id types.MessageID, v any, sig *sign.Transaction, evmTxHash string,
+) types.TxHash {
 	txHash := types.TxHash(sig.HashHex())
 	t.m[id] = append(t.m[id], TxData{
 		MsgID:           id,
@@ -77,31 +164,101 @@ func (t *TxPool) addTransaction(id types.MessageID, v any, sig *sign.Transaction
 		Msg:             v,
 		Tx:              sig,
 		EVMSourceTxHash: evmTxHash,
+		Sequence:        uint64(t.txsInPool), //nolint:gosec // the size of the pool is never negative
 	})
+	t.hashes[txHash] = struct{}{}
+	t.txsOfPersona[sig.PersonaTag]++
 	t.txsInPool++
 	return txHash
 }
 
+// Has reports whether a transaction with the given hash is in the pool.
+func (t *TxPool) Has(txHash types.TxHash) bool {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	_, ok := t.hashes[txHash]
+	return ok
+}
+
 func (t *TxPool) Transactions() TxMap {
 	return t.m
 }
 
-// CopyTransactions returns a copy of the TxPool, and resets the state to 0 values.
+// CopyTransactions returns a copy of the TxPool, and resets the state to 0 values. The transactions in the copy are
+// ordered by the ordering policy of the pool, and their Sequence is their position in that order.
 func (t *TxPool) CopyTransactions(ctx context.Context) *TxPool {
 	_, span := t.tracer.Start(ctx, "txpool.copy-transactions")
 	defer span.End()
 
 	t.mux.Lock()
-	defer t.mux.Unlock()
-
 	cpy := *t
 	t.reset()
+	t.mux.Unlock()
 
+	// The policy orders all the transactions of the tick at once, starting from the order they were added in.
+	txs := cpy.ordered()
+	cpy.m = TxMap{}
+	for i, tx := range cpy.ordering.Order(txs) {
+		tx.Sequence = uint64(i) //nolint:gosec // i is never negative
+		cpy.m[tx.MsgID] = append(cpy.m[tx.MsgID], tx)
+	}
 	return &cpy
 }
 
+// Ordered returns the transactions of the pool, across all message types, in the order of their Sequence. In a copied
+// pool, this is the order set by the ordering policy.
+func (t *TxPool) Ordered() []TxData {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	return t.ordered()
+}
+
+func (t *TxPool) ordered() []TxData {
+	txs := make([]TxData, 0, t.txsInPool)
+	for _, id := range slices.Sorted(maps.Keys(t.m)) {
+		txs = append(txs, t.m[id]...)
+	}
+	slices.SortStableFunc(txs, func(a, b TxData) int {
+		return cmp.Compare(a.Sequence, b.Sequence)
+	})
+	return txs
+}
+
+// Filter returns a pool with the transactions for which keep returns true. Like a copied pool, it is not meant to be
+// added to.
+func (t *TxPool) Filter(keep func(tx TxData) bool) *TxPool {
+	return t.Expand(func(tx TxData) []TxData {
+		if keep(tx) {
+			return []TxData{tx}
+		}
+		return nil
+	})
+}
+
+// Expand returns a pool in which each transaction is replaced by the transactions that expand returns for it. The
+// transactions are expanded in the order of their Sequence, so the transactions returned for a message type are in
+// the order of the tick. Like a copied pool, it is not meant to be added to.
+func (t *TxPool) Expand(expand func(tx TxData) []TxData) *TxPool {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	expanded := New()
+	expanded.limits = t.limits
+	expanded.ordering = t.ordering
+	for _, tx := range t.ordered() {
+		for _, expandedTx := range expand(tx) {
+			expanded.m[expandedTx.MsgID] = append(expanded.m[expandedTx.MsgID], expandedTx)
+			expanded.hashes[expandedTx.TxHash] = struct{}{}
+			expanded.txsOfPersona[expandedTx.Tx.PersonaTag]++
+			expanded.txsInPool++
+		}
+	}
+	return expanded
+}
+
 func (t *TxPool) reset() {
 	t.m = TxMap{}
+	t.hashes = map[types.TxHash]struct{}{}
+	t.txsOfPersona = map[string]int{}
 	t.txsInPool = 0
 }
 
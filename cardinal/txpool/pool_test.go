@@ -0,0 +1,84 @@
+package txpool
+
+import (
+	"context"
+	"testing"
+
+	"pkg.world.dev/world-engine/assert"
+	"pkg.world.dev/world-engine/cardinal/types"
+	"pkg.world.dev/world-engine/sign"
+)
+
+func tx(personaTag string, salt uint16) *sign.Transaction {
+	return &sign.Transaction{PersonaTag: personaTag, Salt: salt}
+}
+
+func TestTryAddTransactionEnforcesTheLimits(t *testing.T) {
+	const fooID, barID = types.MessageID(1), types.MessageID(2)
+	pool := New()
+	pool.SetLimits(4, 2)
+	pool.SetMessageLimit(barID, 1)
+
+	_, err := pool.TryAddTransaction(fooID, nil, tx("alpha", 1))
+	assert.NilError(t, err)
+	_, err = pool.TryAddTransaction(fooID, nil, tx("alpha", 2))
+	assert.NilError(t, err)
+	_, err = pool.TryAddTransaction(fooID, nil, tx("alpha", 3))
+	assert.ErrorIs(t, err, ErrTxRejected)
+
+	_, err = pool.TryAddTransaction(barID, nil, tx("beta", 1))
+	assert.NilError(t, err)
+	_, err = pool.TryAddTransaction(barID, nil, tx("beta", 2))
+	assert.ErrorIs(t, err, ErrTxRejected)
+
+	_, err = pool.TryAddTransaction(fooID, nil, tx("gamma", 1))
+	assert.NilError(t, err)
+	_, err = pool.TryAddTransaction(fooID, nil, tx("delta", 1))
+	assert.ErrorIs(t, err, ErrTxRejected)
+
+	// AddTransaction ignores the limits.
+	pool.AddTransaction(fooID, nil, tx("alpha", 4))
+	assert.Equal(t, 5, pool.GetAmountOfTxs())
+}
+
+func TestLimitsApplyUntilTheNextTick(t *testing.T) {
+	const fooID = types.MessageID(1)
+	pool := New()
+	pool.SetLimits(1, 0)
+
+	_, err := pool.TryAddTransaction(fooID, nil, tx("alpha", 1))
+	assert.NilError(t, err)
+	_, err = pool.TryAddTransaction(fooID, nil, tx("alpha", 2))
+	assert.ErrorIs(t, err, ErrTxRejected)
+
+	pool.CopyTransactions(context.Background())
+	_, err = pool.TryAddTransaction(fooID, nil, tx("alpha", 2))
+	assert.NilError(t, err)
+}
+
+func TestExpandReplacesEachTransaction(t *testing.T) {
+	const fooID, barID = types.MessageID(1), types.MessageID(2)
+	pool := New()
+	pool.AddTransaction(barID, "bar", tx("alpha", 1))
+	pool.AddTransaction(fooID, "foo", tx("beta", 1))
+	pool.AddTransaction(fooID, "dropped", tx("gamma", 1))
+
+	expanded := pool.Expand(func(tx TxData) []TxData {
+		switch tx.Msg {
+		case "foo":
+			// foo was added after bar, so its expansion comes after bar in the transactions of bar.
+			return []TxData{{MsgID: barID, Msg: "foo1", TxHash: "foo1", Tx: tx.Tx}, tx}
+		case "dropped":
+			return nil
+		default:
+			return []TxData{tx}
+		}
+	})
+	assert.Equal(t, expanded.GetAmountOfTxs(), 3)
+	assert.Equal(t, len(expanded.ForID(fooID)), 1)
+	assert.Equal(t, expanded.ForID(fooID)[0].Msg, "foo")
+	assert.Equal(t, len(expanded.ForID(barID)), 2)
+	assert.Equal(t, expanded.ForID(barID)[0].Msg, "bar")
+	assert.Equal(t, expanded.ForID(barID)[1].Msg, "foo1")
+	assert.True(t, expanded.Has("foo1"))
+}
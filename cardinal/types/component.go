@@ -31,10 +31,21 @@ type ComponentMetadata interface { //revive:disable-line:exported
 	Decode([]byte) (Component, error)
 	GetSchema() []byte
 	ValidateAgainstSchema(targetSchema []byte) error
+	// Indexes returns the indexes declared on the fields of the component.
+	Indexes() []ComponentIndex
 
 	Component
 }
 
+// ComponentIndex declares an index on a field of a component. Indexed fields can be searched without loading every
+// entity that has the component.
+type ComponentIndex struct {
+	// Field is the dot separated path to the indexed field in the JSON encoding of the component, e.g. "Cell.X".
+	Field string
+	// Ordered indexes support range lookups in addition to lookups by value.
+	Ordered bool
+}
+
 func SerializeComponentSchema(component Component) ([]byte, error) {
 	componentSchema := jsonschema.Reflect(component)
 	schema, err := componentSchema.MarshalJSON()
@@ -1,9 +1,32 @@
 package types
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"errors"
+)
 
+// EntityID is a generational handle to an entity. The lower 32 bits are the index of the entity, and the upper 32 bits
+// are the generation of the index. When an entity is removed its index is reused by a later entity with the next
+// generation, so IDs of removed entities never refer to a different entity.
 type EntityID uint64
 
+const entityIndexBits = 32
+
+// NewEntityID returns the handle of the entity with the given index and generation.
+func NewEntityID(index, generation uint32) EntityID {
+	return EntityID(uint64(generation)<<entityIndexBits | uint64(index))
+}
+
+// Index returns the index of the entity. Entities that are alive at the same time never share an index.
+func (id EntityID) Index() uint32 {
+	return uint32(id) //nolint:gosec // truncation is intended
+}
+
+// Generation returns the number of times the index of the entity was reused.
+func (id EntityID) Generation() uint32 {
+	return uint32(id >> entityIndexBits)
+}
+
 type EntityStateElement struct {
 	ID   EntityID          `json:"id"`
 	Data []json.RawMessage `json:"data" swaggertype:"object"`
@@ -13,3 +36,18 @@ type DebugStateElement struct {
 	ID         EntityID                   `json:"id"`
 	Components map[string]json.RawMessage `json:"components" swaggertype:"object"`
 }
+
+// ErrInvalidEntityPage is returned when the cursor or the components of EntityPageOptions are invalid.
+var ErrInvalidEntityPage = errors.New("invalid entity page")
+
+// EntityPageOptions selects a page of entities, ordered by ID.
+type EntityPageOptions struct {
+	// Cursor is the cursor returned with the previous page. The page starts at the first entity if it is empty.
+	Cursor string
+	// Limit is the maximum number of entities in the page. Every remaining entity is returned if it is 0.
+	Limit int
+	// Descending orders the entities by descending ID instead.
+	Descending bool
+	// Components only selects the entities that have every one of the named components.
+	Components []string
+}
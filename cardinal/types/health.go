@@ -0,0 +1,55 @@
+package types
+
+// WorldHealth reports the failures of the systems of a world.
+type WorldHealth struct {
+	// Healthy is false if the last tick was aborted, or if the circuit of a system is open.
+	Healthy bool `json:"healthy"`
+	// AbortedTicks is the number of ticks that were aborted by a failing system since the world started.
+	AbortedTicks uint64 `json:"abortedTicks"`
+	// LastAbortedTick is the last tick that was aborted, and LastAbortError is the error that aborted it.
+	LastAbortedTick *uint64 `json:"lastAbortedTick,omitempty"`
+	LastAbortError  string  `json:"lastAbortError,omitempty"`
+	// Systems holds the systems that failed at least once since the world started.
+	Systems []SystemHealth `json:"systems"`
+	// QuarantinedEntities holds the entities that are skipped by the searches of systems.
+	QuarantinedEntities []EntityID `json:"quarantinedEntities"`
+	// Ticks reports the durations of the ticks against their budget.
+	Ticks TickHealth `json:"ticks"`
+}
+
+// SystemHealth reports the failures of a system.
+type SystemHealth struct {
+	Name string `json:"name"`
+	// Policy is the failure policy of the system.
+	Policy              string `json:"policy"`
+	Failures            uint64 `json:"failures"`
+	ConsecutiveFailures int    `json:"consecutiveFailures"`
+	LastFailedTick      uint64 `json:"lastFailedTick"`
+	LastError           string `json:"lastError"`
+	// CircuitOpen reports whether the system is not run because it failed too many times in a row.
+	CircuitOpen bool `json:"circuitOpen"`
+}
+
+// TickHealth reports the durations of the ticks run by the game loop against their budget. Durations are in
+// milliseconds.
+type TickHealth struct {
+	BudgetMs float64 `json:"budgetMs"`
+	// OverrunPolicy decides what happens to the ticks that should have started while a tick overran its budget.
+	OverrunPolicy string  `json:"overrunPolicy"`
+	LastTickMs    float64 `json:"lastTickMs"`
+	MaxTickMs     float64 `json:"maxTickMs"`
+	// Overruns is the number of ticks that took longer than the budget. SkippedTicks and CaughtUpTicks are the
+	// number of ticks that should have started during them, and were skipped or run late.
+	Overruns      uint64 `json:"overruns"`
+	SkippedTicks  uint64 `json:"skippedTicks"`
+	CaughtUpTicks uint64 `json:"caughtUpTicks"`
+	// LastOverrunTick is the last tick that overran its budget, and SlowestSystems holds its slowest systems.
+	LastOverrunTick *uint64          `json:"lastOverrunTick,omitempty"`
+	SlowestSystems  []SystemDuration `json:"slowestSystems"`
+}
+
+// SystemDuration is the time a system took in a tick, in milliseconds.
+type SystemDuration struct {
+	Name       string  `json:"name"`
+	DurationMs float64 `json:"durationMs"`
+}
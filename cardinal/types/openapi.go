@@ -0,0 +1,194 @@
+package types
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/invopop/jsonschema"
+	"github.com/rotisserie/eris"
+)
+
+// OpenAPIVersion is the version of the OpenAPI specification of the documents generated for worlds. OpenAPI 3.1
+// schemas are JSON schemas, so the schemas of the types of a world are reflected the same way as component schemas.
+const OpenAPIVersion = "3.1.0"
+
+// OpenAPI is an OpenAPI document that describes the messages, queries and components registered in a world.
+type OpenAPI struct {
+	OpenAPI    string                     `json:"openapi"`
+	Info       OpenAPIInfo                `json:"info"`
+	Paths      map[string]OpenAPIPathItem `json:"paths"`
+	Components OpenAPIComponents          `json:"components"`
+	// Cardinal describes the parts of the world that are not endpoints, such as its components.
+	Cardinal OpenAPICardinal `json:"x-cardinal"`
+}
+
+type OpenAPIInfo struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+type OpenAPIPathItem struct {
+	Post *OpenAPIOperation `json:"post,omitempty"`
+}
+
+type OpenAPIOperation struct {
+	OperationID string                     `json:"operationId"`
+	Summary     string                     `json:"summary"`
+	Description string                     `json:"description,omitempty"`
+	Tags        []string                   `json:"tags"`
+	RequestBody OpenAPIRequestBody         `json:"requestBody"`
+	Responses   map[string]OpenAPIResponse `json:"responses"`
+	// Message is set on the operations that submit a transaction, and Query on the operations that run a query.
+	Message *OpenAPIMessage `json:"x-cardinal-message,omitempty"`
+	Query   *OpenAPIQuery   `json:"x-cardinal-query,omitempty"`
+}
+
+type OpenAPIRequestBody struct {
+	Required bool                        `json:"required"`
+	Content  map[string]OpenAPIMediaType `json:"content"`
+}
+
+type OpenAPIResponse struct {
+	Description string                      `json:"description"`
+	Content     map[string]OpenAPIMediaType `json:"content,omitempty"`
+}
+
+type OpenAPIMediaType struct {
+	Schema json.RawMessage `json:"schema"`
+}
+
+type OpenAPIComponents struct {
+	Schemas map[string]json.RawMessage `json:"schemas"`
+}
+
+// OpenAPIMessage describes the message of a transaction endpoint. Input is the schema of the body of the
+// transaction, and Output is the schema of the result of its receipt.
+type OpenAPIMessage struct {
+	Group         string          `json:"group"`
+	Name          string          `json:"name"`
+	FullName      string          `json:"fullName"`
+	Input         json.RawMessage `json:"input"`
+	Output        json.RawMessage `json:"output"`
+	EVMCompatible bool            `json:"evmCompatible"`
+}
+
+// OpenAPIQuery describes the query of a query endpoint.
+type OpenAPIQuery struct {
+	Group         string `json:"group"`
+	Name          string `json:"name"`
+	EVMCompatible bool   `json:"evmCompatible"`
+}
+
+// OpenAPICardinal describes the world the document was generated for.
+type OpenAPICardinal struct {
+	Namespace string `json:"namespace"`
+	// Components maps the name of each component to its schema.
+	Components map[string]json.RawMessage `json:"components"`
+}
+
+// OpenAPISchemas reflects the JSON schemas of Go types into the schemas of an OpenAPI document. Named struct types are
+// added to the components of the document and referenced by name, so a type used by several messages is described
+// once.
+type OpenAPISchemas struct {
+	reflector *jsonschema.Reflector
+	schemas   map[string]json.RawMessage
+	// names holds the name of each struct type, and types the type of each name, so that types with the same name in
+	// different packages are given different names.
+	names map[reflect.Type]string
+	types map[string]reflect.Type
+}
+
+func NewOpenAPISchemas() *OpenAPISchemas {
+	s := &OpenAPISchemas{
+		schemas: map[string]json.RawMessage{},
+		names:   map[reflect.Type]string{},
+		types:   map[string]reflect.Type{},
+	}
+	s.reflector = &jsonschema.Reflector{
+		Anonymous: true,
+		Namer:     s.name,
+		Mapper:    textSchema,
+	}
+	return s
+}
+
+// Schema returns the schema of the given type, and adds the struct types it refers to to the components. It returns an
+// error if the type can't be encoded as JSON, such as a channel or a function.
+func (s *OpenAPISchemas) Schema(t reflect.Type) (_ json.RawMessage, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = eris.Errorf("failed to reflect the schema of %s: %v", t, r)
+		}
+	}()
+	schema := s.reflector.ReflectFromType(t)
+	for name, def := range schema.Definitions {
+		bz, err := s.marshal(def)
+		if err != nil {
+			return nil, err
+		}
+		s.schemas[name] = bz
+	}
+	schema.Definitions = nil
+	schema.Version = ""
+	return s.marshal(schema)
+}
+
+// Components returns the schemas of the struct types referred to by the schemas returned so far.
+func (s *OpenAPISchemas) Components() map[string]json.RawMessage {
+	return s.schemas
+}
+
+// marshal encodes a schema, and points its references to the components of the document.
+func (s *OpenAPISchemas) marshal(schema *jsonschema.Schema) (json.RawMessage, error) {
+	bz, err := json.Marshal(schema)
+	if err != nil {
+		return nil, eris.Wrap(err, "failed to encode schema")
+	}
+	return json.RawMessage(strings.ReplaceAll(string(bz), `"#/$defs/`, `"#/components/schemas/`)), nil
+}
+
+func (s *OpenAPISchemas) name(t reflect.Type) string {
+	if t.Kind() != reflect.Struct || t.Name() == "" {
+		return t.Name()
+	}
+	if name, ok := s.names[t]; ok {
+		return name
+	}
+	// Generic types are named after their type arguments and packages, e.g. Page[example.com/game.Item], which is
+	// not a valid component name.
+	base := packagePath.ReplaceAllString(t.Name(), "")
+	base = strings.NewReplacer("[", "_", "]", "", ",", "_", "*", "", " ", "").Replace(base)
+	name := base
+	for i := 2; ; i++ {
+		if _, taken := s.types[name]; !taken {
+			break
+		}
+		name = fmt.Sprintf("%s%d", base, i)
+	}
+	s.names[t] = name
+	s.types[name] = t
+	return name
+}
+
+var (
+	packagePath       = regexp.MustCompile(`[\w./-]*\.`)
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// textSchema describes the types that are encoded as text, such as addresses and hashes, as strings rather than by
+// their Go representation.
+func textSchema(t reflect.Type) *jsonschema.Schema {
+	implements := func(i reflect.Type) bool {
+		return t.Implements(i) || reflect.PointerTo(t).Implements(i)
+	}
+	if implements(textMarshalerType) && !implements(jsonMarshalerType) {
+		return &jsonschema.Schema{Type: "string"}
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package types
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"pkg.world.dev/world-engine/assert"
+)
+
+type openAPIItem struct {
+	Name  string `json:"name"`
+	Count int    `json:"count,omitempty"`
+}
+
+type openAPIPage[T any] struct {
+	Items []T `json:"items"`
+}
+
+type openAPIAddress [20]byte
+
+func (openAPIAddress) MarshalText() ([]byte, error) { return []byte("0x"), nil }
+
+func TestOpenAPISchemasReferToComponents(t *testing.T) {
+	schemas := NewOpenAPISchemas()
+	schema, err := schemas.Schema(reflect.TypeOf(openAPIPage[openAPIItem]{}))
+	assert.NilError(t, err)
+	assert.Equal(t, string(schema), `{"$ref":"#/components/schemas/openAPIPage_openAPIItem"}`)
+
+	components := schemas.Components()
+	assert.Equal(t, len(components), 2)
+	var page map[string]any
+	assert.NilError(t, json.Unmarshal(components["openAPIPage_openAPIItem"], &page))
+	assert.DeepEqual(t, page["properties"], map[string]any{
+		"items": map[string]any{
+			"type":  "array",
+			"items": map[string]any{"$ref": "#/components/schemas/openAPIItem"},
+		},
+	})
+	var item map[string]any
+	assert.NilError(t, json.Unmarshal(components["openAPIItem"], &item))
+	assert.DeepEqual(t, item["required"], []any{"name"})
+}
+
+func TestOpenAPISchemasNameTypesOfDifferentPackages(t *testing.T) {
+	schemas := NewOpenAPISchemas()
+	first := func() reflect.Type {
+		type Item struct{ A int }
+		return reflect.TypeOf(Item{})
+	}()
+	second := func() reflect.Type {
+		type Item struct{ B string }
+		return reflect.TypeOf(Item{})
+	}()
+
+	schema, err := schemas.Schema(first)
+	assert.NilError(t, err)
+	assert.Equal(t, string(schema), `{"$ref":"#/components/schemas/Item"}`)
+	schema, err = schemas.Schema(second)
+	assert.NilError(t, err)
+	assert.Equal(t, string(schema), `{"$ref":"#/components/schemas/Item2"}`)
+	// The name of a type does not change when it is reflected again.
+	schema, err = schemas.Schema(first)
+	assert.NilError(t, err)
+	assert.Equal(t, string(schema), `{"$ref":"#/components/schemas/Item"}`)
+}
+
+func TestOpenAPISchemasDescribeTextAsStrings(t *testing.T) {
+	schema, err := NewOpenAPISchemas().Schema(reflect.TypeOf(openAPIAddress{}))
+	assert.NilError(t, err)
+	assert.Equal(t, string(schema), `{"type":"string"}`)
+}
+
+func TestOpenAPISchemasRejectTypesThatAreNotJSON(t *testing.T) {
+	_, err := NewOpenAPISchemas().Schema(reflect.TypeOf(struct{ C chan int }{}))
+	assert.ErrorContains(t, err, "failed to reflect the schema")
+}
@@ -0,0 +1,33 @@
+package types
+
+import "encoding/json"
+
+// SubscriptionRequest selects the entities, and the components of the entities, whose changes are sent to a
+// subscriber. Exactly one of CQL and Components must be set.
+type SubscriptionRequest struct {
+	// CQL selects the entities with a CQL query. ORDER BY, LIMIT and OFFSET are not supported. Only the components
+	// listed by SELECT are sent, if the query has a SELECT.
+	CQL string `json:"cql,omitempty"`
+	// Components selects the entities that have every one of the named components, and only sends those components.
+	Components []string `json:"components,omitempty"`
+}
+
+// SubscriptionUpdate holds the changes of the entities selected by a subscription. The first update of a
+// subscription is a snapshot of every selected entity.
+type SubscriptionUpdate struct {
+	// Tick is the last tick whose changes are included.
+	Tick uint64 `json:"tick"`
+	// Snapshot is true for the first update of a subscription.
+	Snapshot bool `json:"-"`
+	// Entities holds the components that changed of the entities that are still selected, and every component of
+	// the entities that became selected. A component is null if it was removed from the entity.
+	Entities []EntityDelta `json:"entities"`
+	// Removed holds the entities that are not selected anymore, including the entities that were removed.
+	Removed []EntityID `json:"removed"`
+}
+
+// EntityDelta holds the new values of some components of an entity.
+type EntityDelta struct {
+	ID         EntityID                   `json:"id"`
+	Components map[string]json.RawMessage `json:"components" swaggertype:"object"`
+}
@@ -1,3 +1,44 @@
 package types
 
+import "encoding/json"
+
 type TxHash string
+
+// TxStatus is how far along a transaction is.
+type TxStatus string
+
+const (
+	// TxStatusPending transactions are waiting for, or being processed in, a tick.
+	TxStatusPending TxStatus = "pending"
+	// TxStatusProcessed transactions were processed without errors.
+	TxStatusProcessed TxStatus = "processed"
+	// TxStatusFailed transactions were processed, and their receipt has errors.
+	TxStatusFailed TxStatus = "failed"
+	// TxStatusUnknown transactions were never received, or their receipt is no longer kept.
+	TxStatusUnknown TxStatus = "unknown"
+)
+
+// TransactionStatus is the status of a transaction, along with its receipt once it was processed.
+type TransactionStatus struct {
+	TxHash TxHash   `json:"txHash"`
+	Status TxStatus `json:"status"`
+	// Tick is the tick the transaction was processed in. It is only set once the transaction was processed.
+	Tick   *uint64         `json:"tick,omitempty"`
+	Result json.RawMessage `json:"result,omitempty" swaggertype:"object"`
+	Errors []string        `json:"errors,omitempty"`
+	// Fee is the fee that was charged for the transaction.
+	Fee uint64 `json:"fee,omitempty"`
+	// FeeRefunded reports whether the fee was refunded because the message returned an error.
+	FeeRefunded bool `json:"feeRefunded,omitempty"`
+}
+
+// BatchMessageGroup and BatchMessageName name the message of batch transactions, whose body is a sign.Batch.
+const (
+	BatchMessageGroup = "tx"
+	BatchMessageName  = "batch"
+)
+
+// BatchResult is the result of a batch transaction: the results of its messages, in order.
+type BatchResult struct {
+	Results []any `json:"results"`
+}
@@ -1,11 +1,15 @@
 package cardinal
 
 import (
+	"cmp"
 	"context"
 	"encoding/json"
 	"errors"
 	"os"
 	"os/signal"
+	"slices"
+	"strconv"
+	"sync"
 	"sync/atomic"
 	"syscall"
 	"time"
@@ -27,6 +31,8 @@ import (
 	"pkg.world.dev/world-engine/cardinal/server"
 	"pkg.world.dev/world-engine/cardinal/server/handler/cql"
 	servertypes "pkg.world.dev/world-engine/cardinal/server/types"
+	"pkg.world.dev/world-engine/cardinal/storage"
+	"pkg.world.dev/world-engine/cardinal/storage/badger"
 	"pkg.world.dev/world-engine/cardinal/storage/redis"
 	"pkg.world.dev/world-engine/cardinal/telemetry"
 	"pkg.world.dev/world-engine/cardinal/txpool"
@@ -54,8 +60,14 @@ type World struct {
 	cancel        context.CancelFunc
 
 	// Storage
-	redisStorage *redis.Storage
-	entityStore  gamestate.Manager
+	metaStorage storage.Storage
+	entityStore gamestate.Manager
+
+	// Snapshots
+	snapshotDir      string
+	snapshotInterval uint64
+	// snapshotWrites tracks the snapshot that is being written in the background.
+	snapshotWrites sync.WaitGroup
 
 	// Networking
 	server        *server.Server
@@ -65,10 +77,18 @@ type World struct {
 	worldStage *worldstage.Manager
 	router     router.Router
 	txPool     *txpool.TxPool
+	// txsInTick holds the transactions taken from txPool by the tick that is running, until its results are published.
+	txsInTick atomic.Pointer[txpool.TxPool]
+	feePayer  FeePayer
 
 	// Receipt
 	receiptHistory *receipt.History
 	evmTxReceipts  map[string]EVMTxReceipt
+	// receiptLog is nil unless CARDINAL_RECEIPT_LOG is enabled. receiptLogEnd is the tick after the last tick in it.
+	// receiptLogRetention is the number of ticks kept in it, or 0 to keep every tick.
+	receiptLog          storage.ReceiptLog
+	receiptLogEnd       atomic.Uint64
+	receiptLogRetention uint64
 
 	// Telemetry
 	telemetry *telemetry.Manager
@@ -78,13 +98,24 @@ type World struct {
 	tick            *atomic.Uint64
 	timestamp       *atomic.Uint64
 	tickResults     *TickResults
+	subscriptions   *subscriptionManager
 	tickChannel     <-chan time.Time
 	tickDoneChannel chan<- uint64
+	// ownsTickChannel is true when tickChannel is the ticker created from the tick rate, rather than a channel passed
+	// with WithTickChannel. tickMonitor tracks the durations of the ticks against the period of the tick rate.
+	ownsTickChannel bool
+	tickMonitor     *tickMonitor
+	metrics         *worldMetrics
 	// addChannelWaitingForNextTick accepts a channel which will be closed after a tick has been completed.
 	addChannelWaitingForNextTick chan chan struct{}
+	// abortedTicks counts the ticks aborted by a failing system, and lastAbort describes the last of them.
+	abortedTicks atomic.Uint64
+	lastAbort    atomic.Pointer[tickAbort]
+	// refunds holds the transactions of the tick that is running whose fee is refunded after the systems ran.
+	refunds feeRefunds
 }
 
-// NewWorld creates a new World object using Redis as the storage layer.
+// NewWorld creates a new World object using the storage layer set by CARDINAL_STORAGE_BACKEND (Redis by default).
 func NewWorld(opts ...WorldOption) (*World, error) {
 	serverOptions, routerOptions, cardinalOptions := separateOptions(opts)
 
@@ -110,15 +141,12 @@ func NewWorld(opts ...WorldOption) (*World, error) {
 		}
 	}
 
-	redisMetaStore := redis.NewRedisStorage(redis.Options{
-		Addr:        cfg.RedisAddress,
-		Password:    cfg.RedisPassword,
-		DB:          0,                              // use default DB
-		DialTimeout: RedisDialTimeOut * time.Second, // Increase startup dial timeout
-	}, cfg.CardinalNamespace)
+	metaStore, primitiveStore, err := newStorage(cfg)
+	if err != nil {
+		return nil, err
+	}
 
-	redisStore := gamestate.NewRedisPrimitiveStorage(redisMetaStore.Client)
-	entityCommandBuffer, err := gamestate.NewEntityCommandBuffer(&redisStore)
+	entityCommandBuffer, err := gamestate.NewEntityCommandBuffer(primitiveStore)
 	if err != nil {
 		return nil, err
 	}
@@ -130,8 +158,12 @@ func NewWorld(opts ...WorldOption) (*World, error) {
 		cancel:        nil,
 
 		// Storage
-		redisStorage: &redisMetaStore,
-		entityStore:  entityCommandBuffer,
+		metaStorage: metaStore,
+		entityStore: entityCommandBuffer,
+
+		// Snapshots
+		snapshotDir:      cfg.CardinalSnapshotDir,
+		snapshotInterval: cfg.CardinalSnapshotInterval,
 
 		// Networking
 		server:        nil, // Will be initialized in StartGame
@@ -141,7 +173,7 @@ func NewWorld(opts ...WorldOption) (*World, error) {
 		worldStage:       worldstage.NewManager(),
 		MessageManager:   newMessageManager(),
 		SystemManager:    newSystemManager(),
-		ComponentManager: component.NewManager(&redisMetaStore),
+		ComponentManager: component.NewManager(metaStore),
 		QueryManager:     nil,
 		router:           nil, // Will be set if run mode is production or its injected via options
 		txPool:           txpool.New(),
@@ -158,12 +190,27 @@ func NewWorld(opts ...WorldOption) (*World, error) {
 		tick:                         tick,
 		timestamp:                    new(atomic.Uint64),
 		tickResults:                  NewTickResults(tick.Load()),
+		subscriptions:                newSubscriptionManager(),
 		tickChannel:                  time.Tick(time.Second),
 		tickDoneChannel:              nil, // Will be injected via options
+		ownsTickChannel:              true,
+		tickMonitor:                  newTickMonitor(cfg.CardinalTickRate, cfg.CardinalTickOverrunPolicy),
 		addChannelWaitingForNextTick: make(chan chan struct{}),
 	}
 
 	world.QueryManager = newQueryManager(world)
+	world.metrics = newWorldMetrics(world)
+	if redisStore, ok := primitiveStore.(*gamestate.RedisStorage); ok {
+		redisStore.ObservePipelines(func(d time.Duration) {
+			world.metrics.redisPipelineDuration.Observe(d.Seconds())
+		})
+	}
+	world.txPool.SetLimits(int(cfg.CardinalTxPoolSize), int(cfg.CardinalTxPersonaLimit))
+
+	if cfg.CardinalReceiptLog {
+		world.receiptLog = metaStore
+		world.receiptLogRetention = cfg.CardinalReceiptLogRetention
+	}
 
 	// Initialize shard router if running in rollup mode
 	if cfg.CardinalRollupEnabled {
@@ -193,10 +240,40 @@ func NewWorld(opts ...WorldOption) (*World, error) {
 	// Register internal plugins
 	world.RegisterPlugin(newPersonaPlugin())
 	world.RegisterPlugin(newFutureTaskPlugin())
+	world.RegisterPlugin(newBatchPlugin())
+	world.RegisterPlugin(newSystemFailurePlugin())
 
 	return world, nil
 }
 
+// newStorage creates the storage for nonces and component schemas, and the primitive storage the game state is
+// written to, based on the configured storage backend. Both are backed by the same redis server or badger database.
+func newStorage(cfg *WorldConfig) (storage.Storage, gamestate.PrimitiveStorage[string], error) {
+	switch cfg.CardinalStorageBackend {
+	case StorageBackendBadger:
+		badgerMetaStore, err := badger.NewBadgerStorage(cfg.CardinalStoragePath)
+		if err != nil {
+			return nil, nil, err
+		}
+		badgerStore, err := gamestate.NewBadgerPrimitiveStorage(badgerMetaStore.DB)
+		if err != nil {
+			return nil, nil, errors.Join(err, badgerMetaStore.Close())
+		}
+		return &badgerMetaStore, &badgerStore, nil
+	case StorageBackendRedis:
+		redisMetaStore := redis.NewRedisStorage(redis.Options{
+			Addr:        cfg.RedisAddress,
+			Password:    cfg.RedisPassword,
+			DB:          0,                              // use default DB
+			DialTimeout: RedisDialTimeOut * time.Second, // Increase startup dial timeout
+		}, cfg.CardinalNamespace)
+		redisStore := gamestate.NewRedisPrimitiveStorage(redisMetaStore.Client)
+		return &redisMetaStore, &redisStore, nil
+	default:
+		return nil, nil, eris.Errorf("unknown storage backend %q", cfg.CardinalStorageBackend)
+	}
+}
+
 func (w *World) CurrentTick() uint64 {
 	return w.tick.Load()
 }
@@ -228,16 +305,15 @@ func (w *World) doTick(ctx context.Context, timestamp uint64) (err error) {
 
 	// Copy the transactions from the pool so that we can safely modify the pool while the tick is running.
 	txPool := w.txPool.CopyTransactions(ctx)
+	w.txsInTick.Store(txPool)
+	defer w.txsInTick.Store(nil)
 
 	// Store the timestamp for this tick
 	w.timestamp.Store(timestamp)
 
-	// Create the engine context to inject into systems
-	wCtx := newWorldContextForTick(w, txPool)
-
 	// Run all registered systems.
 	// This will run the registered init systems if the current tick is 0
-	if err := w.SystemManager.runSystems(ctx, wCtx); err != nil {
+	if err := w.runTickSystems(ctx, txPool); err != nil {
 		span.SetStatus(codes.Error, eris.ToString(err, true))
 		span.RecordError(err)
 		return err
@@ -256,7 +332,12 @@ func (w *World) doTick(ctx context.Context, timestamp uint64) (err error) {
 	// 1. The shard router is set
 	// 2. The world is not in the recovering stage (we don't want to resubmit past transactions)
 	if w.router != nil && w.worldStage.Current() != worldstage.Recovering {
-		err := w.router.SubmitTxBlob(ctx, txPool.Transactions(), w.tick.Load(), w.timestamp.Load())
+		var stateRoot []byte
+		if diff := w.entityStore.LastTickDiff(); diff != nil {
+			stateRoot = diff.StateRoot
+		}
+		err := w.router.SubmitTxBlob(ctx, txPool.Transactions(), txPool.OrderingPolicy().Name, w.tick.Load(),
+			w.timestamp.Load(), stateRoot)
 		if err != nil {
 			span.SetStatus(codes.Error, eris.ToString(err, true))
 			span.RecordError(err)
@@ -268,9 +349,16 @@ func (w *World) doTick(ctx context.Context, timestamp uint64) (err error) {
 	w.tick.Add(1)
 	w.receiptHistory.NextTick() // todo(scott): use channels
 
+	// A failed snapshot does not affect the game state, so it is logged rather than failing the tick.
+	if w.snapshotInterval > 0 && w.tick.Load()%w.snapshotInterval == 0 {
+		if err := w.takeSnapshot(ctx); err != nil {
+			log.Error().Err(err).Msgf("Failed to take snapshot at tick %d", w.tick.Load())
+		}
+	}
+
 	if w.worldStage.Current() != worldstage.Recovering {
 		// Populate world.TickResults for the current tick and emit it as an Event
-		w.broadcastTickResults(ctx)
+		w.broadcastTickResults(ctx, txPool)
 	}
 
 	log.Info().
@@ -282,6 +370,54 @@ func (w *World) doTick(ctx context.Context, timestamp uint64) (err error) {
 	return nil
 }
 
+// runTickSystems runs the systems on the transactions of the tick. The messages of a batch transaction are processed
+// by the systems like other transactions, in the same tick, or not at all: when a message of a batch returns an error,
+// the changes of the tick are discarded, and the systems run again without the batch, whose fee is then refunded.
+func (w *World) runTickSystems(ctx context.Context, txPool *txpool.TxPool) error {
+	batches, err := w.decodeBatches(txPool)
+	if err != nil {
+		return err
+	}
+	// The refunds of an aborted tick are discarded along with its changes.
+	defer w.refunds.take()
+	eventCount := len(w.tickResults.Events)
+	txs := batches.withoutInvalid(txPool)
+	for {
+		// Charge the fees of the transactions. The systems only see the transactions whose fee was paid.
+		paidTxs := w.chargeFees(newWorldContextForTick(w, txs), txs)
+
+		// Create the engine context to inject into systems
+		wCtx := newWorldContextForTick(w, batches.expand(paidTxs))
+		if err := w.SystemManager.runSystems(ctx, wCtx); err != nil {
+			return w.abortTick(txPool, eventCount, err)
+		}
+
+		// Every run leaves out at least one more batch, so the loop ends.
+		if batches.foldReceipts(w, paidTxs) {
+			if err := w.discardTick(eventCount); err != nil {
+				return err
+			}
+			w.refunds.take()
+			continue
+		}
+		batches.refundFailed(w, paidTxs)
+		w.refundFees(newWorldContextForTick(w, paidTxs), paidTxs)
+		break
+	}
+	batches.recordFailures(w.receiptHistory)
+	return nil
+}
+
+// discardTick discards the state changes, receipts and events of the tick.
+func (w *World) discardTick(eventCount int) error {
+	if err := w.entityStore.DiscardPending(); err != nil {
+		return eris.Wrap(err, "failed to discard the state changes of the tick")
+	}
+	w.receiptHistory.DiscardTick()
+	w.tickResults.truncateEvents(eventCount)
+	return nil
+}
+
 // StartGame starts running the world game loop. Each time a message arrives on the tickChannel, a world tick is
 // attempted. In addition, an HTTP server (listening on the given port) is created so that game messages can be sent
 // to this world. After StartGame is called, RegisterComponent, registerMessagesByName,
@@ -313,6 +449,11 @@ func (w *World) StartGame() error {
 		return eris.Wrap(err, "failed to register components")
 	}
 
+	// Check the order of the systems before anything runs, so that a cycle is reported at startup.
+	if err := w.SystemManager.planSystems(); err != nil {
+		return eris.Wrap(err, "failed to plan the systems")
+	}
+
 	// Log world info
 	ecslog.World(&log.Logger, w, zerolog.InfoLevel)
 
@@ -333,6 +474,18 @@ func (w *World) StartGame() error {
 	}
 	w.tick.Store(tick)
 
+	// Restore the latest snapshot if it is ahead of the game state storage so that only the ticks after the snapshot
+	// need to be recovered from the base shard.
+	if w.snapshotInterval > 0 {
+		if _, err := w.restoreFromSnapshot(ctx, tick); err != nil {
+			return eris.Wrap(err, "failed to restore from snapshot")
+		}
+	}
+
+	if err := w.migrateComponents(ctx); err != nil {
+		return eris.Wrap(err, "failed to migrate components")
+	}
+
 	// If Cardinal is in rollup mode and router is set, recover any old state of Cardinal from base shard.
 	if w.rollupEnabled && w.router != nil {
 		if err := w.recoverFromChain(ctx); err != nil {
@@ -344,6 +497,14 @@ func (w *World) StartGame() error {
 	//  use a reliable source of truth for the tick? It's not clear to me why we need to manually increment the
 	//  receiptHistory tick separately.
 	w.receiptHistory.SetTick(w.CurrentTick())
+	w.receiptLogEnd.Store(w.CurrentTick())
+
+	// The server must exist before the world is running, since every tick run from then on publishes its results to
+	// the clients of the server.
+	w.server, err = server.New(w, w.GetRegisteredComponents(), w.GetRegisteredMessages(), w.serverOptions...)
+	if err != nil {
+		return eris.Wrap(err, "failed to create server")
+	}
 
 	// World stage: Ready -> Running
 	w.worldStage.Store(worldstage.Running)
@@ -353,10 +514,6 @@ func (w *World) StartGame() error {
 		return w.startGameLoop(ctx, w.tickChannel, w.tickDoneChannel)
 	})
 	g.Go(func() error {
-		w.server, err = server.New(w, w.GetRegisteredComponents(), w.GetRegisteredMessages(), w.serverOptions...)
-		if err != nil {
-			return err
-		}
 		return w.server.Serve(ctx)
 	})
 	if err := g.Wait(); err != nil {
@@ -366,6 +523,24 @@ func (w *World) StartGame() error {
 	return nil
 }
 
+// migrateComponents brings the stored instances of every component whose stored schema did not match the registered
+// component up to date, and then stores the new schema.
+func (w *World) migrateComponents(ctx context.Context) error {
+	for _, migration := range w.PendingSchemaMigrations() {
+		name := migration.Component.Name()
+		if migration.Migrate != nil {
+			if err := w.entityStore.MigrateComponent(ctx, migration.Component, migration.Migrate); err != nil {
+				return eris.Wrapf(err, "failed to migrate component %q", name)
+			}
+		}
+		if err := w.CompleteSchemaMigration(name); err != nil {
+			return eris.Wrapf(err, "failed to store schema of component %q", name)
+		}
+		log.Info().Str("component", name).Msg("Migrated component to new schema")
+	}
+	return nil
+}
+
 func (w *World) startGameLoop(ctx context.Context, tickStart <-chan time.Time, tickDone chan<- uint64) error {
 	log.Info().Msg("Game loop started")
 	var waitingChs []chan struct{}
@@ -387,7 +562,7 @@ loop:
 			if !ok {
 				return eris.New("tickStart channel has been closed; tick rate is now unbounded.")
 			}
-			w.tickTheEngine(context.Background(), tickDone)
+			w.runTicks(ctx, tickStart, tickDone)
 			closeAllChannels(waitingChs)
 			waitingChs = waitingChs[:0]
 
@@ -400,8 +575,12 @@ loop:
 	return nil
 }
 
-func (w *World) tickTheEngine(ctx context.Context, tickDone chan<- uint64) {
+// tickTheEngine runs a tick, and returns the number of ticks that should have started while it ran.
+func (w *World) tickTheEngine(ctx context.Context, tickDone chan<- uint64) (missedTicks int) {
 	currTick := w.CurrentTick()
+	// Discard the durations of the systems of the ticks that did not run in the game loop, such as recovery ticks.
+	w.SystemManager.takeSystemDurations()
+	startTime := time.Now()
 	// this is the final point where errors bubble up and hit a panic. There are other places where this occurs
 	// but this is the highest terminal point.
 	// the panic may point you to here, (or the tick function) but the real stack trace is in the error message.
@@ -413,9 +592,13 @@ func (w *World) tickTheEngine(ctx context.Context, tickDone chan<- uint64) {
 		}
 		panic(string(bytes))
 	}
+	duration, systemDurations := time.Since(startTime), w.SystemManager.takeSystemDurations()
+	w.metrics.observeTick(duration, systemDurations)
+	missedTicks = w.tickMonitor.record(currTick, duration, systemDurations)
 	if tickDone != nil {
 		tickDone <- currTick
 	}
+	return missedTicks
 }
 
 func (w *World) IsGameRunning() bool {
@@ -441,7 +624,8 @@ func (w *World) Shutdown() {
 
 // cleanup is called after StartGame terminates. It does the housekeeping required to cleanly shutdown World.
 func (w *World) cleanup() {
-	if err := w.redisStorage.Close(); err != nil {
+	w.snapshotWrites.Wait()
+	if err := w.metaStorage.Close(); err != nil {
 		log.Error().Err(err).Msg("Failed to close storage connection")
 	}
 	if w.telemetry != nil {
@@ -499,6 +683,16 @@ func (w *World) AddTransaction(id types.MessageID, v any, sig *sign.Transaction)
 	return tick, txHash
 }
 
+// SubmitTransaction adds the transaction to the tx pool like AddTransaction, unless it would exceed the limits of the
+// pool, in which case an error wrapping txpool.ErrTxRejected is returned.
+func (w *World) SubmitTransaction(id types.MessageID, v any, sig *sign.Transaction) (
+	tick uint64, txHash types.TxHash, err error,
+) {
+	tick = w.CurrentTick()
+	txHash, err = w.txPool.TryAddTransaction(id, v, sig)
+	return tick, txHash, err
+}
+
 func (w *World) AddEVMTransaction(
 	id types.MessageID,
 	v any,
@@ -513,44 +707,179 @@ func (w *World) AddEVMTransaction(
 }
 
 func (w *World) UseNonce(signerAddress string, nonce uint64) error {
-	return w.redisStorage.UseNonce(signerAddress, nonce)
+	return w.metaStorage.UseNonce(signerAddress, nonce)
 }
 
+// GetDebugState returns every entity and its components.
 func (w *World) GetDebugState() ([]types.DebugStateElement, error) {
-	result := make([]types.DebugStateElement, 0)
-	s := w.Search(filter.All())
-	var eachClosureErr error
-	wCtx := NewReadOnlyWorldContext(w)
-	searchEachErr := s.Each(wCtx,
-		func(id types.EntityID) bool {
-			var components []types.ComponentMetadata
-			components, eachClosureErr = w.StoreReader().GetComponentTypesForEntity(id)
-			if eachClosureErr != nil {
-				return false
+	ids, _, err := w.GetDebugStatePage(types.EntityPageOptions{})
+	if err != nil {
+		return nil, err
+	}
+	result := make([]types.DebugStateElement, 0, len(ids))
+	for _, id := range ids {
+		element, ok, err := w.GetDebugStateElement(id)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			result = append(result, element)
+		}
+	}
+	return result, nil
+}
+
+// GetDebugStatePage returns the IDs of the entities in the page selected by opts, and the cursor of the next page,
+// which is empty if there are no more entities. The components of the entities are read separately with
+// GetDebugStateElement, so a page doesn't need to be held in memory.
+func (w *World) GetDebugStatePage(opts types.EntityPageOptions) ([]types.EntityID, string, error) {
+	return entityPage(NewReadOnlyWorldContext(w), filter.All(), opts)
+}
+
+// GetDebugStateElement returns the components of the entity. ok is false if the entity does not exist anymore, e.g.
+// because it was removed by a tick after its page was returned.
+func (w *World) GetDebugStateElement(id types.EntityID) (element types.DebugStateElement, ok bool, err error) {
+	element, err = debugStateElement(w.StoreReader(), id)
+	if errors.Is(err, gamestate.ErrKeyNotFound) {
+		return types.DebugStateElement{}, false, nil
+	}
+	return element, err == nil, err
+}
+
+// entityPage returns the IDs of the entities that match the filter and are in the page selected by opts, and the
+// cursor of the next page. The cursor is the ID of the last entity of the page.
+func entityPage(wCtx WorldContext, entityFilter filter.ComponentFilter, opts types.EntityPageOptions) (
+	ids []types.EntityID, nextCursor string, err error,
+) {
+	if opts.Limit < 0 {
+		return nil, "", eris.Wrap(types.ErrInvalidEntityPage, "limit must not be negative")
+	}
+	if len(opts.Components) > 0 {
+		components := make([]filter.ComponentWrapper, 0, len(opts.Components))
+		for _, name := range opts.Components {
+			c, err := wCtx.getComponentByName(name)
+			if err != nil {
+				return nil, "", eris.Wrapf(types.ErrInvalidEntityPage, "component %q is not registered", name)
 			}
-			resultElement := types.DebugStateElement{
-				ID:         id,
-				Components: make(map[string]json.RawMessage),
+			components = append(components, filter.ComponentWrapper{Component: c})
+		}
+		entityFilter = filter.And(entityFilter, filter.Contains(components...))
+	}
+
+	var cursor *types.EntityID
+	if opts.Cursor != "" {
+		parsed, err := strconv.ParseUint(opts.Cursor, 10, 64)
+		if err != nil {
+			return nil, "", eris.Wrapf(types.ErrInvalidEntityPage, "invalid cursor %q", opts.Cursor)
+		}
+		cursor = (*types.EntityID)(&parsed)
+	}
+
+	// The entities of each archetype are in ascending order, so every archetype is seeked to the cursor, and the
+	// archetypes are merged until there is one entity more than the limit.
+	search := NewSearch().Entity(entityFilter).(*Search) //nolint:errcheck // It's safe
+	var pages []entityPageArchetype
+	for _, archID := range search.evaluateSearch(wCtx) {
+		archIDs, err := wCtx.storeReader().GetEntitiesForArchID(archID)
+		if err != nil {
+			return nil, "", err
+		}
+		page := newEntityPageArchetype(archIDs, cursor, opts.Descending)
+		if !page.done() {
+			pages = append(pages, page)
+		}
+	}
+	for len(pages) > 0 && (opts.Limit == 0 || len(ids) <= opts.Limit) {
+		next := 0
+		for i := range pages {
+			if pages[i].before(pages[next]) {
+				next = i
 			}
-			for _, c := range components {
-				var data json.RawMessage
-				data, eachClosureErr = w.StoreReader().GetComponentForEntityInRawJSON(c, id)
-				if eachClosureErr != nil {
-					return false
-				}
-				resultElement.Components[c.Name()] = data
+		}
+		id := pages[next].pop()
+		if pages[next].done() {
+			pages = slices.Delete(pages, next, next+1)
+		}
+		if !wCtx.isQuarantined(id) {
+			ids = append(ids, id)
+		}
+	}
+	if opts.Limit > 0 && len(ids) > opts.Limit {
+		ids = ids[:opts.Limit]
+		nextCursor = strconv.FormatUint(uint64(ids[len(ids)-1]), 10)
+	}
+	return ids, nextCursor, nil
+}
+
+// entityPageArchetype holds the entities of an archetype that are left to be merged into a page.
+type entityPageArchetype struct {
+	ids        []types.EntityID
+	descending bool
+}
+
+// newEntityPageArchetype returns the entities of an archetype that come after the cursor. ids must be in ascending
+// order.
+func newEntityPageArchetype(ids []types.EntityID, cursor *types.EntityID, descending bool) entityPageArchetype {
+	if cursor != nil {
+		i, found := slices.BinarySearch(ids, *cursor)
+		if descending {
+			ids = ids[:i]
+		} else {
+			if found {
+				i++
 			}
-			result = append(result, resultElement)
-			return true
-		},
-	)
-	if eachClosureErr != nil {
-		return nil, eachClosureErr
+			ids = ids[i:]
+		}
+	}
+	return entityPageArchetype{ids: ids, descending: descending}
+}
+
+func (a *entityPageArchetype) done() bool {
+	return len(a.ids) == 0
+}
+
+func (a *entityPageArchetype) peek() types.EntityID {
+	if a.descending {
+		return a.ids[len(a.ids)-1]
 	}
-	if searchEachErr != nil {
-		return nil, searchEachErr
+	return a.ids[0]
+}
+
+func (a *entityPageArchetype) pop() types.EntityID {
+	id := a.peek()
+	if a.descending {
+		a.ids = a.ids[:len(a.ids)-1]
+	} else {
+		a.ids = a.ids[1:]
 	}
-	return result, nil
+	return id
+}
+
+// before reports whether the next entity of a comes before the next entity of other in the page.
+func (a *entityPageArchetype) before(other entityPageArchetype) bool {
+	if a.descending {
+		return a.peek() > other.peek()
+	}
+	return a.peek() < other.peek()
+}
+
+func debugStateElement(reader gamestate.Reader, id types.EntityID) (types.DebugStateElement, error) {
+	components, err := reader.GetComponentTypesForEntity(id)
+	if err != nil {
+		return types.DebugStateElement{}, err
+	}
+	element := types.DebugStateElement{
+		ID:         id,
+		Components: make(map[string]json.RawMessage, len(components)),
+	}
+	for _, c := range components {
+		data, err := reader.GetComponentForEntityInRawJSON(c, id)
+		if err != nil {
+			return types.DebugStateElement{}, err
+		}
+		element.Components[c.Name()] = data
+	}
+	return element, nil
 }
 
 func (w *World) Namespace() string {
@@ -592,7 +921,7 @@ func (w *World) GetMessageByID(id types.MessageID) (types.Message, bool) {
 	return msg, msg != nil
 }
 
-func (w *World) broadcastTickResults(ctx context.Context) {
+func (w *World) broadcastTickResults(ctx context.Context, txPool *txpool.TxPool) {
 	_, span := w.tracer.Start(ctx, "world.tick.broadcast_tick_results")
 	defer span.End()
 
@@ -603,14 +932,20 @@ func (w *World) broadcastTickResults(ctx context.Context) {
 		log.Error().Err(err).Msgf("failed to get receipts for tick %d", w.CurrentTick()-1)
 	}
 	w.tickResults.SetReceipts(receipts)
+	w.metrics.countTransactions(w, txPool, receipts)
+	w.tickResults.SetReceiptOwners(txPool.Transactions())
 	w.tickResults.SetTick(w.CurrentTick() - 1)
+	w.tickResults.SetDiff(w.entityStore.LastTickDiff())
 
-	// Broadcast the tick results to all clients
-	if err := w.server.BroadcastEvent(w.tickResults); err != nil {
-		span.SetStatus(codes.Error, eris.ToString(err, true))
-		span.RecordError(err)
-		log.Err(err).Msgf("failed to broadcast tick results")
-	}
+	// Keep the results before sending them, so a client can look them up as soon as it receives them
+	w.appendToReceiptLog(w.tickResults)
+
+	// Send each client the tick results it may see
+	w.server.PublishEvent(func(client servertypes.EventClient) any {
+		return w.tickResults.ForClient(client)
+	})
+
+	w.publishSubscriptions(w.tickResults.Diff)
 
 	// Clear the TickResults for this tick in preparation for the next tick
 	w.tickResults.Clear()
@@ -631,42 +966,117 @@ func (w *World) EvaluateCQL(cqlString string) ([]types.EntityStateElement, error
 		return comp, nil
 	}
 
-	// Parse the CQL string into a filter
-	cqlFilter, err := cql.Parse(cqlString, getComponentByName)
+	// Parse the CQL string into a query
+	query, err := cql.ParseQuery(cqlString, getComponentByName)
 	if err != nil {
 		return nil, eris.Errorf("failed to parse cql string: %s", cqlString)
 	}
-	result := make([]types.EntityStateElement, 0)
-	var eachError error
-	wCtx := NewReadOnlyWorldContext(w)
-	searchErr := w.Search(cqlFilter).Each(wCtx,
-		func(id types.EntityID) bool {
-			components, err := w.StoreReader().GetComponentTypesForEntity(id)
+
+	var evalError error
+	search := w.Search(query.Filter)
+	if query.Where != nil {
+		search = search.Where(func(_ WorldContext, id types.EntityID) (bool, error) {
+			ok, err := query.Where.Evaluate(w.cqlComponentGetter(id))
 			if err != nil {
-				eachError = err
-				return false
-			}
-			resultElement := types.EntityStateElement{
-				ID:   id,
-				Data: make([]json.RawMessage, 0),
+				evalError = err
 			}
+			return ok, err
+		})
+	}
+	ids, err := search.Collect(NewReadOnlyWorldContext(w))
+	if err != nil {
+		return nil, err
+	} else if evalError != nil {
+		return nil, evalError
+	}
 
+	if len(query.OrderBy) > 0 {
+		if ids, err = w.sortCQLResults(ids, query.OrderBy); err != nil {
+			return nil, err
+		}
+	}
+	ids = ids[min(query.Offset, len(ids)):]
+	if query.Limit != nil {
+		ids = ids[:min(*query.Limit, len(ids))]
+	}
+
+	result := make([]types.EntityStateElement, 0, len(ids))
+	for _, id := range ids {
+		resultElement := types.EntityStateElement{
+			ID:   id,
+			Data: make([]json.RawMessage, 0),
+		}
+		if len(query.Select) > 0 {
+			// Projected components are returned in the selected order, with null for the ones the entity does not have.
+			getComponent := w.cqlComponentGetter(id)
+			for _, c := range query.Select {
+				data, ok, err := getComponent(c.Name())
+				if err != nil {
+					return nil, err
+				} else if !ok {
+					data = json.RawMessage("null")
+				}
+				resultElement.Data = append(resultElement.Data, data)
+			}
+		} else {
+			components, err := w.StoreReader().GetComponentTypesForEntity(id)
+			if err != nil {
+				return nil, err
+			}
 			for _, c := range components {
 				data, err := w.StoreReader().GetComponentForEntityInRawJSON(c, id)
 				if err != nil {
-					eachError = err
-					return false
+					return nil, err
 				}
 				resultElement.Data = append(resultElement.Data, data)
 			}
-			result = append(result, resultElement)
-			return true
-		},
-	)
-	if eachError != nil {
-		return nil, eachError
-	} else if searchErr != nil {
-		return nil, searchErr
+		}
+		result = append(result, resultElement)
 	}
 	return result, nil
 }
+
+// cqlComponentGetter returns the raw JSON values of the components of an entity to evaluate CQL predicates.
+func (w *World) cqlComponentGetter(id types.EntityID) cql.ComponentGetter {
+	return func(name string) (json.RawMessage, bool, error) {
+		components, err := w.StoreReader().GetComponentTypesForEntity(id)
+		if err != nil {
+			return nil, false, err
+		}
+		for _, c := range components {
+			if c.Name() == name {
+				data, err := w.StoreReader().GetComponentForEntityInRawJSON(c, id)
+				return data, err == nil, err
+			}
+		}
+		return nil, false, nil
+	}
+}
+
+// sortCQLResults sorts the entities by the given fields. Ties are broken by entity ID, so the order is stable
+// across queries.
+func (w *World) sortCQLResults(ids []types.EntityID, orderBy []cql.Order) ([]types.EntityID, error) {
+	keys := make(map[types.EntityID][]any, len(ids))
+	for _, id := range ids {
+		getComponent := w.cqlComponentGetter(id)
+		values := make([]any, len(orderBy))
+		for i, order := range orderBy {
+			value, ok, err := order.Field.Value(getComponent)
+			if err != nil {
+				return nil, err
+			} else if ok {
+				values[i] = value
+			}
+		}
+		keys[id] = values
+	}
+	slices.SortFunc(ids, func(a, b types.EntityID) int {
+		for i, order := range orderBy {
+			if c := order.Compare(keys[a][i], keys[b][i]); c != 0 {
+				return c
+			}
+		}
+		return cmp.Compare(a, b)
+	})
+	return ids, nil
+}
@@ -1,6 +1,7 @@
 package cardinal
 
 import (
+	"hash/fnv"
 	"math/rand"
 	"reflect"
 	"time"
@@ -41,6 +42,13 @@ type WorldContext interface {
 	// This method is provided for backwards compatibility. EmitEvent should be used for most cases.
 	EmitStringEvent(string) error
 
+	// EmitEventToPersona emits an event that is only sent to the websocket subscribers that authenticated as the
+	// persona.
+	EmitEventToPersona(personaTag string, event map[string]any) error
+
+	// EmitEventToTopic emits an event that is only sent to the websocket subscribers that subscribed to the topic.
+	EmitEventToTopic(topic string, event map[string]any) error
+
 	// Namespace returns the namespace of the world.
 	Namespace() string
 
@@ -59,10 +67,15 @@ type WorldContext interface {
 	// The map is keyed by entity ID, and the value is a map of component name to component data.
 	GetAllEntities() (map[types.EntityID]map[string]any, error)
 
+	// GetEntities returns the entities in the page selected by opts and their components, and the cursor of the next
+	// page, which is empty if there are no more entities. Like GetAllEntities, internal entities are excluded.
+	GetEntities(opts types.EntityPageOptions) (entities []types.DebugStateElement, nextCursor string, err error)
+
 	// Private methods for internal use.
 	setLogger(logger zerolog.Logger)
 	addMessageError(id types.TxHash, err error)
 	setMessageResult(id types.TxHash, a any)
+	refundFee(id types.TxHash)
 	getComponentByName(name string) (types.ComponentMetadata, error)
 	getMessageByType(mType reflect.Type) (types.Message, bool)
 	getTransactionReceipt(id types.TxHash) (any, []error, bool)
@@ -75,6 +88,12 @@ type WorldContext interface {
 	storeManager() gamestate.Manager
 	getTxPool() *txpool.TxPool
 	isReadOnly() bool
+	forSystem(name string, access *systemAccess, store gamestate.Manager) WorldContext
+	eventBuffer() *TickResults
+	systemAccess() *systemAccess
+	startReceiptJournal() *receiptJournal
+	stopReceiptJournal()
+	isQuarantined(id types.EntityID) bool
 }
 
 type worldContext struct {
@@ -83,6 +102,17 @@ type worldContext struct {
 	logger   *zerolog.Logger
 	readOnly bool
 	rand     *rand.Rand
+
+	// The following fields are only set on contexts created by forSystem for systems that declared their component
+	// access. access restricts the components the system can touch, store overrides the world's game state manager
+	// and events buffers emitted events until the system's batch is complete.
+	access *systemAccess
+	store  gamestate.Manager
+	events *TickResults
+
+	// receipts records the receipts changed by the system that is running, so they can be restored if the changes of
+	// the system are rolled back.
+	receipts *receiptJournal
 }
 
 func newWorldContextForTick(world *World, txPool *txpool.TxPool) WorldContext {
@@ -92,7 +122,11 @@ func newWorldContextForTick(world *World, txPool *txpool.TxPool) WorldContext {
 		logger:   &log.Logger,
 		readOnly: false,
 		//nolint:gosec // we require manual in the rng which crypto/rand doesn't have, but math/rand does.
-		rand: rand.New(rand.NewSource(int64(world.timestamp.Load()))),
+		rand:     rand.New(rand.NewSource(int64(world.timestamp.Load()))),
+		access:   nil,
+		store:    nil,
+		events:   nil,
+		receipts: nil,
 	}
 }
 
@@ -103,6 +137,10 @@ func NewWorldContext(world *World) WorldContext {
 		logger:   &log.Logger,
 		readOnly: false,
 		rand:     nil,
+		access:   nil,
+		store:    nil,
+		events:   nil,
+		receipts: nil,
 	}
 }
 
@@ -113,6 +151,10 @@ func NewReadOnlyWorldContext(world *World) WorldContext {
 		logger:   &log.Logger,
 		readOnly: true,
 		rand:     nil,
+		access:   nil,
+		store:    nil,
+		events:   nil,
+		receipts: nil,
 	}
 }
 
@@ -135,11 +177,19 @@ func (ctx *worldContext) ScheduleTimeTask(duration time.Duration, task Task) err
 }
 
 func (ctx *worldContext) EmitEvent(event map[string]any) error {
-	return ctx.world.tickResults.AddEvent(event)
+	return ctx.eventBuffer().AddEvent(event)
 }
 
 func (ctx *worldContext) EmitStringEvent(e string) error {
-	return ctx.world.tickResults.AddStringEvent(e)
+	return ctx.eventBuffer().AddStringEvent(e)
+}
+
+func (ctx *worldContext) EmitEventToPersona(personaTag string, event map[string]any) error {
+	return ctx.eventBuffer().AddPersonaEvent(personaTag, event)
+}
+
+func (ctx *worldContext) EmitEventToTopic(topic string, event map[string]any) error {
+	return ctx.eventBuffer().AddTopicEvent(topic, event)
 }
 
 func (ctx *worldContext) Timestamp() uint64 {
@@ -170,38 +220,41 @@ func (ctx *worldContext) Namespace() string {
 // GetAllEntities returns all entities and their components as a map.
 // The map is keyed by entity ID, and the value is a map of component name to component data.
 func (ctx *worldContext) GetAllEntities() (map[types.EntityID]map[string]any, error) {
-	entities := make(map[types.EntityID]map[string]any)
+	page, _, err := ctx.GetEntities(types.EntityPageOptions{})
+	if err != nil {
+		return nil, err
+	}
+	entities := make(map[types.EntityID]map[string]any, len(page))
+	for _, entity := range page {
+		entities[entity.ID] = make(map[string]any, len(entity.Components))
+		for name, compJSON := range entity.Components {
+			entities[entity.ID][name] = compJSON
+		}
+	}
+	return entities, nil
+}
 
+// GetEntities returns the entities in the page selected by opts and their components.
+func (ctx *worldContext) GetEntities(opts types.EntityPageOptions) ([]types.DebugStateElement, string, error) {
 	// Get all entities excluding internal Persona components
-	err := NewSearch().Entity(
-		filter.Not(
-			filter.Or(
-				filter.Contains(filter.Component[component.SignerComponent]()),
-				filter.Contains(filter.Component[taskMetadata]()),
-			),
+	ids, nextCursor, err := entityPage(ctx, filter.Not(
+		filter.Or(
+			filter.Contains(filter.Component[component.SignerComponent]()),
+			filter.Contains(filter.Component[taskMetadata]()),
 		),
-	).Each(ctx, func(id types.EntityID) bool {
-		entities[id] = make(map[string]any)
-
-		components, err := ctx.world.StoreReader().GetComponentTypesForEntity(id)
+	), opts)
+	if err != nil {
+		return nil, "", err
+	}
+	entities := make([]types.DebugStateElement, 0, len(ids))
+	for _, id := range ids {
+		entity, err := debugStateElement(ctx.storeReader(), id)
 		if err != nil {
-			return false
+			return nil, "", err
 		}
-
-		for _, c := range components {
-			compJSON, err := ctx.world.StoreReader().GetComponentForEntityInRawJSON(c, id)
-			if err != nil {
-				return false
-			}
-			entities[id][c.Name()] = compJSON
-		}
-		return true
-	})
-	if err != nil {
-		return nil, err
+		entities = append(entities, entity)
 	}
-
-	return entities, nil
+	return entities, nextCursor, nil
 }
 
 // -----------------------------------------------------------------------------
@@ -222,14 +275,21 @@ func (ctx *worldContext) getComponentByName(name string) (types.ComponentMetadat
 
 func (ctx *worldContext) addMessageError(id types.TxHash, err error) {
 	// TODO(scott): i dont trust exposing this to the users. this should be fully abstracted away.
+	ctx.receipts.record(id)
 	ctx.world.receiptHistory.AddError(id, err)
 }
 
 func (ctx *worldContext) setMessageResult(id types.TxHash, a any) {
 	// TODO(scott): i dont trust exposing this to the users. this should be fully abstracted away.
+	ctx.receipts.record(id)
 	ctx.world.receiptHistory.SetResult(id, a)
 }
 
+func (ctx *worldContext) refundFee(id types.TxHash) {
+	ctx.receipts.record(id)
+	ctx.world.refundFee(id)
+}
+
 func (ctx *worldContext) getTransactionReceipt(id types.TxHash) (any, []error, bool) {
 	rec, ok := ctx.world.receiptHistory.GetReceipt(id)
 	if !ok {
@@ -263,6 +323,9 @@ func (ctx *worldContext) isReadOnly() bool {
 }
 
 func (ctx *worldContext) storeManager() gamestate.Manager {
+	if ctx.store != nil {
+		return ctx.store
+	}
 	return ctx.world.entityStore
 }
 
@@ -274,6 +337,57 @@ func (ctx *worldContext) storeReader() gamestate.Reader {
 	return sm
 }
 
+// forSystem returns a copy of this context for a system that declared its component access. The copy has its own
+// logger, its own random number generator seeded from the tick timestamp and the system name, and buffers emitted
+// events so they can be flushed in a deterministic order.
+func (ctx *worldContext) forSystem(name string, access *systemAccess, store gamestate.Manager) WorldContext {
+	logger := ctx.logger.With().Str("system", name).Logger()
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(name))
+	seed := int64(ctx.world.timestamp.Load() ^ h.Sum64()) //nolint:gosec // overflow is fine for a seed
+	return &worldContext{
+		world:    ctx.world,
+		txPool:   ctx.txPool,
+		logger:   &logger,
+		readOnly: ctx.readOnly,
+		//nolint:gosec // we require manual in the rng which crypto/rand doesn't have, but math/rand does.
+		rand:     rand.New(rand.NewSource(seed)),
+		access:   access,
+		store:    store,
+		events:   NewTickResults(ctx.CurrentTick()),
+		receipts: nil,
+	}
+}
+
+// eventBuffer returns the results that emitted events are added to. A system's events are buffered until its batch is
+// complete, and every other event is added to the results of the tick.
+func (ctx *worldContext) eventBuffer() *TickResults {
+	if ctx.events != nil {
+		return ctx.events
+	}
+	return ctx.world.tickResults
+}
+
+func (ctx *worldContext) systemAccess() *systemAccess {
+	return ctx.access
+}
+
+// startReceiptJournal starts recording the receipts changed through this context, until stopReceiptJournal is called.
+func (ctx *worldContext) startReceiptJournal() *receiptJournal {
+	ctx.receipts = newReceiptJournal(ctx.world.receiptHistory)
+	return ctx.receipts
+}
+
+func (ctx *worldContext) stopReceiptJournal() {
+	ctx.receipts = nil
+}
+
+// isQuarantined reports whether the searches of systems skip the entity. Read only contexts, which are used by
+// queries, don't skip any entity.
+func (ctx *worldContext) isQuarantined(id types.EntityID) bool {
+	return !ctx.readOnly && ctx.world.SystemManager.isQuarantined(id)
+}
+
 func (ctx *worldContext) isWorldReady() bool {
 	stage := ctx.world.worldStage.Current()
 	return stage == worldstage.Ready ||
@@ -1,9 +1,9 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: cardinal/world_context.go
+// Source: world_context.go
 //
 // Generated by this command:
 //
-//	mockgen -source=cardinal/world_context.go -destination=cardinal/world_context_mock.go -package=cardinal
+//	mockgen -source=world_context.go -destination=world_context_mock.go -package=cardinal
 //
 
 // Package cardinal is a generated GoMock package.
@@ -75,6 +75,34 @@ func (mr *MockWorldContextMockRecorder) EmitEvent(arg0 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EmitEvent", reflect.TypeOf((*MockWorldContext)(nil).EmitEvent), arg0)
 }
 
+// EmitEventToPersona mocks base method.
+func (m *MockWorldContext) EmitEventToPersona(personaTag string, event map[string]any) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EmitEventToPersona", personaTag, event)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EmitEventToPersona indicates an expected call of EmitEventToPersona.
+func (mr *MockWorldContextMockRecorder) EmitEventToPersona(personaTag, event any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EmitEventToPersona", reflect.TypeOf((*MockWorldContext)(nil).EmitEventToPersona), personaTag, event)
+}
+
+// EmitEventToTopic mocks base method.
+func (m *MockWorldContext) EmitEventToTopic(topic string, event map[string]any) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EmitEventToTopic", topic, event)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EmitEventToTopic indicates an expected call of EmitEventToTopic.
+func (mr *MockWorldContextMockRecorder) EmitEventToTopic(topic, event any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EmitEventToTopic", reflect.TypeOf((*MockWorldContext)(nil).EmitEventToTopic), topic, event)
+}
+
 // EmitStringEvent mocks base method.
 func (m *MockWorldContext) EmitStringEvent(arg0 string) error {
 	m.ctrl.T.Helper()
@@ -104,6 +132,22 @@ func (mr *MockWorldContextMockRecorder) GetAllEntities() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllEntities", reflect.TypeOf((*MockWorldContext)(nil).GetAllEntities))
 }
 
+// GetEntities mocks base method.
+func (m *MockWorldContext) GetEntities(opts types.EntityPageOptions) ([]types.DebugStateElement, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEntities", opts)
+	ret0, _ := ret[0].([]types.DebugStateElement)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetEntities indicates an expected call of GetEntities.
+func (mr *MockWorldContextMockRecorder) GetEntities(opts any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEntities", reflect.TypeOf((*MockWorldContext)(nil).GetEntities), opts)
+}
+
 // Logger mocks base method.
 func (m *MockWorldContext) Logger() *zerolog.Logger {
 	m.ctrl.T.Helper()
@@ -215,6 +259,34 @@ func (mr *MockWorldContextMockRecorder) addTransaction(id, v, sig any) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "addTransaction", reflect.TypeOf((*MockWorldContext)(nil).addTransaction), id, v, sig)
 }
 
+// eventBuffer mocks base method.
+func (m *MockWorldContext) eventBuffer() *TickResults {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "eventBuffer")
+	ret0, _ := ret[0].(*TickResults)
+	return ret0
+}
+
+// eventBuffer indicates an expected call of eventBuffer.
+func (mr *MockWorldContextMockRecorder) eventBuffer() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "eventBuffer", reflect.TypeOf((*MockWorldContext)(nil).eventBuffer))
+}
+
+// forSystem mocks base method.
+func (m *MockWorldContext) forSystem(name string, access *systemAccess, store gamestate.Manager) WorldContext {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "forSystem", name, access, store)
+	ret0, _ := ret[0].(WorldContext)
+	return ret0
+}
+
+// forSystem indicates an expected call of forSystem.
+func (mr *MockWorldContextMockRecorder) forSystem(name, access, store any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "forSystem", reflect.TypeOf((*MockWorldContext)(nil).forSystem), name, access, store)
+}
+
 // getComponentByName mocks base method.
 func (m *MockWorldContext) getComponentByName(name string) (types.ComponentMetadata, error) {
 	m.ctrl.T.Helper()
@@ -305,6 +377,20 @@ func (mr *MockWorldContextMockRecorder) getTxPool() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "getTxPool", reflect.TypeOf((*MockWorldContext)(nil).getTxPool))
 }
 
+// isQuarantined mocks base method.
+func (m *MockWorldContext) isQuarantined(id types.EntityID) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "isQuarantined", id)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// isQuarantined indicates an expected call of isQuarantined.
+func (mr *MockWorldContextMockRecorder) isQuarantined(id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "isQuarantined", reflect.TypeOf((*MockWorldContext)(nil).isQuarantined), id)
+}
+
 // isReadOnly mocks base method.
 func (m *MockWorldContext) isReadOnly() bool {
 	m.ctrl.T.Helper()
@@ -347,6 +433,18 @@ func (mr *MockWorldContextMockRecorder) receiptHistorySize() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "receiptHistorySize", reflect.TypeOf((*MockWorldContext)(nil).receiptHistorySize))
 }
 
+// refundFee mocks base method.
+func (m *MockWorldContext) refundFee(id types.TxHash) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "refundFee", id)
+}
+
+// refundFee indicates an expected call of refundFee.
+func (mr *MockWorldContextMockRecorder) refundFee(id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "refundFee", reflect.TypeOf((*MockWorldContext)(nil).refundFee), id)
+}
+
 // setLogger mocks base method.
 func (m *MockWorldContext) setLogger(logger zerolog.Logger) {
 	m.ctrl.T.Helper()
@@ -371,6 +469,32 @@ func (mr *MockWorldContextMockRecorder) setMessageResult(id, a any) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "setMessageResult", reflect.TypeOf((*MockWorldContext)(nil).setMessageResult), id, a)
 }
 
+// startReceiptJournal mocks base method.
+func (m *MockWorldContext) startReceiptJournal() *receiptJournal {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "startReceiptJournal")
+	ret0, _ := ret[0].(*receiptJournal)
+	return ret0
+}
+
+// startReceiptJournal indicates an expected call of startReceiptJournal.
+func (mr *MockWorldContextMockRecorder) startReceiptJournal() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "startReceiptJournal", reflect.TypeOf((*MockWorldContext)(nil).startReceiptJournal))
+}
+
+// stopReceiptJournal mocks base method.
+func (m *MockWorldContext) stopReceiptJournal() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "stopReceiptJournal")
+}
+
+// stopReceiptJournal indicates an expected call of stopReceiptJournal.
+func (mr *MockWorldContextMockRecorder) stopReceiptJournal() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "stopReceiptJournal", reflect.TypeOf((*MockWorldContext)(nil).stopReceiptJournal))
+}
+
 // storeManager mocks base method.
 func (m *MockWorldContext) storeManager() gamestate.Manager {
 	m.ctrl.T.Helper()
@@ -398,3 +522,17 @@ func (mr *MockWorldContextMockRecorder) storeReader() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "storeReader", reflect.TypeOf((*MockWorldContext)(nil).storeReader))
 }
+
+// systemAccess mocks base method.
+func (m *MockWorldContext) systemAccess() *systemAccess {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "systemAccess")
+	ret0, _ := ret[0].(*systemAccess)
+	return ret0
+}
+
+// systemAccess indicates an expected call of systemAccess.
+func (mr *MockWorldContextMockRecorder) systemAccess() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "systemAccess", reflect.TypeOf((*MockWorldContext)(nil).systemAccess))
+}
@@ -2,6 +2,7 @@ package cardinal
 
 import (
 	"encoding/json"
+	"slices"
 	"testing"
 
 	"pkg.world.dev/world-engine/assert"
@@ -148,3 +149,95 @@ func TestGetAllEntities(t *testing.T) {
 	assert.Equal(t, true, foundEntities[0], "Entity 1 should be found via Search")
 	assert.Equal(t, true, foundEntities[1], "Entity 2 should be found via Search")
 }
+
+func TestGetEntitiesPages(t *testing.T) {
+	tf := NewTestFixture(t, nil)
+	world := tf.World
+	assert.NilError(t, RegisterComponent[TestComponentA](world))
+	assert.NilError(t, RegisterComponent[TestComponentB](world))
+	tf.StartWorld()
+
+	wCtx := NewWorldContext(world)
+	withA, err := CreateMany(wCtx, 3, TestComponentA{Value: "a"})
+	assert.NilError(t, err)
+	withB, err := CreateMany(wCtx, 2, TestComponentA{}, TestComponentB{Counter: 1})
+	assert.NilError(t, err)
+	tf.CreatePersona("testpersona", "testaddress")
+
+	// The persona's signer entity is excluded.
+	entities, cursor, err := wCtx.GetEntities(types.EntityPageOptions{Limit: 4})
+	assert.NilError(t, err)
+	assert.Equal(t, len(entities), 4)
+	assert.Equal(t, entities[0].ID, withA[0])
+	entities, cursor, err = wCtx.GetEntities(types.EntityPageOptions{Limit: 4, Cursor: cursor})
+	assert.NilError(t, err)
+	assert.Equal(t, cursor, "")
+	assert.Equal(t, len(entities), 1)
+	assert.Equal(t, entities[0].ID, withB[1])
+
+	entities, _, err = wCtx.GetEntities(types.EntityPageOptions{
+		Components: []string{TestComponentB{}.Name()}, Descending: true,
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, len(entities), 2)
+	assert.Equal(t, entities[0].ID, withB[1])
+	assert.Equal(t, len(entities[0].Components), 2)
+
+	_, _, err = wCtx.GetEntities(types.EntityPageOptions{Cursor: "abc"})
+	assert.ErrorIs(t, err, types.ErrInvalidEntityPage)
+}
+
+func TestEntityPagesMergeTheArchetypesInOrder(t *testing.T) {
+	tf := NewTestFixture(t, nil)
+	world := tf.World
+	assert.NilError(t, RegisterComponent[TestComponentA](world))
+	assert.NilError(t, RegisterComponent[TestComponentB](world))
+	tf.StartWorld()
+
+	wCtx := NewWorldContext(world)
+	var want []types.EntityID
+	for i := 0; i < 20; i++ {
+		id, err := Create(wCtx, TestComponentA{})
+		assert.NilError(t, err)
+		want = append(want, id)
+	}
+	// Move some entities to another archetype and remove others, so that the entities of each archetype are spread
+	// over the whole range of IDs.
+	for i := len(want) - 1; i >= 0; i-- {
+		switch i % 3 {
+		case 0:
+			assert.NilError(t, AddComponentTo[TestComponentB](wCtx, want[i]))
+		case 1:
+			assert.NilError(t, Remove(wCtx, want[i]))
+			want = append(want[:i], want[i+1:]...)
+		}
+	}
+	tf.DoTick()
+
+	for _, descending := range []bool{false, true} {
+		var got []types.EntityID
+		cursor := ""
+		for {
+			ids, next, err := entityPage(wCtx, filter.All(), types.EntityPageOptions{
+				Cursor: cursor, Limit: 4, Descending: descending,
+			})
+			assert.NilError(t, err)
+			assert.True(t, len(ids) <= 4)
+			got = append(got, ids...)
+			if next == "" {
+				break
+			}
+			cursor = next
+		}
+		expected := slices.Clone(want)
+		if descending {
+			slices.Reverse(expected)
+		}
+		assert.DeepEqual(t, got, expected)
+	}
+
+	// A cursor of an entity that was removed still selects the entities after it.
+	ids, _, err := entityPage(wCtx, filter.All(), types.EntityPageOptions{Cursor: "1", Limit: 2})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, ids, want[1:3])
+}
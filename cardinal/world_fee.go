@@ -0,0 +1,108 @@
+package cardinal
+
+import (
+	"sync"
+
+	"github.com/rotisserie/eris"
+
+	"pkg.world.dev/world-engine/cardinal/txpool"
+	"pkg.world.dev/world-engine/cardinal/types"
+)
+
+// FeePayer charges the fees of transactions, typically by debiting a balance component of the persona that submitted
+// the transaction. A fee payer is registered with RegisterFeePayer.
+type FeePayer interface {
+	// Charge is called before the systems of a tick run, with the fee of every transaction that isn't a system
+	// transaction, including the transactions without a fee, so that a minimum fee can be required. The transaction is
+	// not processed if Charge returns an error, and Charge should not change the state of the world when it does.
+	Charge(wCtx WorldContext, personaTag string, fee uint64) error
+	// Refund is called after the systems of a tick ran, in the order of the transactions, with the fee of every
+	// transaction whose message returned an error. Refund can return nil without changing anything to keep the fee.
+	Refund(wCtx WorldContext, personaTag string, fee uint64) error
+}
+
+// feeRefunds holds the transactions whose fee is refunded once the systems of the tick ran. Systems can run in
+// parallel, so it is guarded by a mutex.
+type feeRefunds struct {
+	mux    sync.Mutex
+	hashes map[types.TxHash]struct{}
+}
+
+func (r *feeRefunds) add(hash types.TxHash) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	if r.hashes == nil {
+		r.hashes = make(map[types.TxHash]struct{})
+	}
+	r.hashes[hash] = struct{}{}
+}
+
+// take returns the transactions whose fee is refunded, and forgets them.
+func (r *feeRefunds) take() map[types.TxHash]struct{} {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	hashes := r.hashes
+	r.hashes = nil
+	return hashes
+}
+
+// chargeFees charges the fee of every transaction in the pool, and returns a pool with the transactions whose fee was
+// paid. The error of a transaction that could not pay is added to its receipt. The fees are charged in the order set
+// by the ordering policy, across all message types, so that when a balance can't pay for every transaction, the
+// policy decides which ones are processed.
+func (w *World) chargeFees(wCtx WorldContext, txPool *txpool.TxPool) *txpool.TxPool {
+	if w.feePayer == nil {
+		return txPool
+	}
+	unpaid := make(map[types.TxHash]struct{})
+	for _, tx := range txPool.Ordered() {
+		if tx.Tx == nil || tx.Tx.IsSystemTransaction() {
+			continue
+		}
+		if err := w.feePayer.Charge(wCtx, tx.Tx.PersonaTag, tx.Tx.Fee); err != nil {
+			w.receiptHistory.AddError(tx.TxHash, eris.Wrap(err, "failed to pay the fee"))
+			unpaid[tx.TxHash] = struct{}{}
+			continue
+		}
+		if tx.Tx.Fee > 0 {
+			w.receiptHistory.SetFee(tx.TxHash, tx.Tx.Fee)
+		}
+	}
+	if len(unpaid) == 0 {
+		return txPool
+	}
+	return txPool.Filter(func(tx txpool.TxData) bool {
+		_, ok := unpaid[tx.TxHash]
+		return !ok
+	})
+}
+
+// refundFee marks the fee that was charged for the transaction to be refunded once the systems of the tick ran.
+func (w *World) refundFee(hash types.TxHash) {
+	if w.feePayer == nil {
+		return
+	}
+	w.refunds.add(hash)
+}
+
+// refundFees refunds the fees marked by refundFee, in the order of the transactions in the pool. A fee is refunded at
+// most once, even if the message of the transaction is processed by several systems. Like the fees, the refunds are
+// applied outside of the systems: Refund can touch any component, so it would otherwise conflict with the systems that
+// run in parallel.
+func (w *World) refundFees(wCtx WorldContext, txPool *txpool.TxPool) {
+	refunds := w.refunds.take()
+	if len(refunds) == 0 {
+		return
+	}
+	for _, tx := range txPool.Ordered() {
+		if _, ok := refunds[tx.TxHash]; !ok {
+			continue
+		}
+		err := w.receiptHistory.RefundFee(tx.TxHash, func(fee uint64) error {
+			return w.feePayer.Refund(wCtx, tx.Tx.PersonaTag, fee)
+		})
+		if err != nil {
+			w.receiptHistory.AddError(tx.TxHash, eris.Wrap(err, "failed to refund the fee"))
+		}
+	}
+}
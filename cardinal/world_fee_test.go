@@ -0,0 +1,211 @@
+package cardinal_test
+
+import (
+	"errors"
+	"testing"
+
+	"pkg.world.dev/world-engine/assert"
+	"pkg.world.dev/world-engine/cardinal"
+	"pkg.world.dev/world-engine/cardinal/filter"
+	"pkg.world.dev/world-engine/cardinal/txpool"
+	"pkg.world.dev/world-engine/cardinal/types"
+	"pkg.world.dev/world-engine/sign"
+)
+
+type Balance struct {
+	Amount uint64
+}
+
+func (Balance) Name() string {
+	return "balance"
+}
+
+type SpendMsg struct {
+	Fail bool
+}
+
+type SpendResult struct{}
+
+// balanceFeePayer charges the fees from the Balance of each persona.
+type balanceFeePayer struct {
+	balances map[string]types.EntityID
+}
+
+func (p *balanceFeePayer) Charge(wCtx cardinal.WorldContext, personaTag string, fee uint64) error {
+	balance, err := cardinal.GetComponent[Balance](wCtx, p.balances[personaTag])
+	if err != nil {
+		return err
+	}
+	if balance.Amount < fee {
+		return errors.New("insufficient balance")
+	}
+	return cardinal.SetComponent(wCtx, p.balances[personaTag], &Balance{Amount: balance.Amount - fee})
+}
+
+func (p *balanceFeePayer) Refund(wCtx cardinal.WorldContext, personaTag string, fee uint64) error {
+	return cardinal.UpdateComponent[Balance](wCtx, p.balances[personaTag], func(b *Balance) *Balance {
+		b.Amount += fee
+		return b
+	})
+}
+
+func TestFeesAreChargedBeforeTheMessagesRun(t *testing.T) {
+	tf := cardinal.NewTestFixture(t, nil)
+	world := tf.World
+	payer := &balanceFeePayer{balances: map[string]types.EntityID{}}
+	assert.NilError(t, cardinal.RegisterComponent[Balance](world))
+	assert.NilError(t, cardinal.RegisterMessage[SpendMsg, SpendResult](world, "spend"))
+	assert.NilError(t, cardinal.RegisterFeePayer(world, payer))
+	assert.NilError(t, cardinal.RegisterInitSystems(world, func(wCtx cardinal.WorldContext) error {
+		for _, personaTag := range []string{"alice", "bob", "carol"} {
+			id, err := cardinal.Create(wCtx, Balance{Amount: 100})
+			if err != nil {
+				return err
+			}
+			payer.balances[personaTag] = id
+		}
+		return nil
+	}))
+	var processed []string
+	assert.NilError(t, cardinal.RegisterSystems(world, func(wCtx cardinal.WorldContext) error {
+		return cardinal.EachMessage[SpendMsg, SpendResult](wCtx,
+			func(tx cardinal.TxData[SpendMsg]) (SpendResult, error) {
+				processed = append(processed, tx.Tx.PersonaTag)
+				if tx.Msg.Fail {
+					return SpendResult{}, errors.New("failed to spend")
+				}
+				return SpendResult{}, nil
+			})
+	}))
+	spend, ok := world.GetMessageByFullName("game.spend")
+	assert.True(t, ok)
+	tf.DoTick()
+
+	paid := tf.AddTransaction(spend.ID(), SpendMsg{}, &sign.Transaction{PersonaTag: "alice", Fee: 10})
+	tooExpensive := tf.AddTransaction(spend.ID(), SpendMsg{}, &sign.Transaction{PersonaTag: "bob", Fee: 500})
+	refunded := tf.AddTransaction(spend.ID(), SpendMsg{Fail: true}, &sign.Transaction{PersonaTag: "carol", Fee: 5})
+	tf.DoTick()
+
+	// The transaction whose fee could not be paid is not processed.
+	assert.DeepEqual(t, processed, []string{"alice", "carol"})
+
+	wCtx := cardinal.NewReadOnlyWorldContext(world)
+	for personaTag, want := range map[string]uint64{"alice": 90, "bob": 100, "carol": 100} {
+		balance, err := cardinal.GetComponent[Balance](wCtx, payer.balances[personaTag])
+		assert.NilError(t, err)
+		assert.Equal(t, balance.Amount, want, personaTag)
+	}
+
+	status, err := world.GetTransactionStatus(paid)
+	assert.NilError(t, err)
+	assert.Equal(t, status.Status, types.TxStatusProcessed)
+	assert.Equal(t, status.Fee, uint64(10))
+	assert.False(t, status.FeeRefunded)
+
+	status, err = world.GetTransactionStatus(tooExpensive)
+	assert.NilError(t, err)
+	assert.Equal(t, status.Status, types.TxStatusFailed)
+	assert.Equal(t, status.Fee, uint64(0))
+	assert.Equal(t, len(status.Errors), 1)
+	assert.Contains(t, status.Errors[0], "insufficient balance")
+
+	status, err = world.GetTransactionStatus(refunded)
+	assert.NilError(t, err)
+	assert.Equal(t, status.Status, types.TxStatusFailed)
+	assert.Equal(t, status.Fee, uint64(5))
+	assert.True(t, status.FeeRefunded)
+}
+
+type SpendMoreMsg struct{}
+
+func TestFeesAreChargedInTheOrderOfThePolicy(t *testing.T) {
+	tf := cardinal.NewTestFixture(t, nil, cardinal.WithTxOrderingPolicy(txpool.TimestampOrder))
+	world := tf.World
+	payer := &balanceFeePayer{balances: map[string]types.EntityID{}}
+	assert.NilError(t, cardinal.RegisterComponent[Balance](world))
+	assert.NilError(t, cardinal.RegisterMessage[SpendMsg, SpendResult](world, "spend"))
+	assert.NilError(t, cardinal.RegisterMessage[SpendMoreMsg, SpendResult](world, "spend-more"))
+	assert.NilError(t, cardinal.RegisterFeePayer(world, payer))
+	assert.NilError(t, cardinal.RegisterInitSystems(world, func(wCtx cardinal.WorldContext) error {
+		id, err := cardinal.Create(wCtx, Balance{Amount: 10})
+		payer.balances["alice"] = id
+		return err
+	}))
+	spend, ok := world.GetMessageByFullName("game.spend")
+	assert.True(t, ok)
+	spendMore, ok := world.GetMessageByFullName("game.spend-more")
+	assert.True(t, ok)
+	tf.DoTick()
+
+	// The balance can only pay for one of the transactions. The policy puts the transaction of the message with the
+	// larger ID first, so its fee is the one that is charged.
+	late := tf.AddTransaction(spend.ID(), SpendMsg{},
+		&sign.Transaction{PersonaTag: "alice", Fee: 10, Timestamp: 2})
+	early := tf.AddTransaction(spendMore.ID(), SpendMoreMsg{},
+		&sign.Transaction{PersonaTag: "alice", Fee: 10, Timestamp: 1})
+	tf.DoTick()
+
+	status, err := world.GetTransactionStatus(early)
+	assert.NilError(t, err)
+	assert.Equal(t, status.Fee, uint64(10))
+	assert.Equal(t, len(status.Errors), 0)
+
+	status, err = world.GetTransactionStatus(late)
+	assert.NilError(t, err)
+	assert.Equal(t, status.Fee, uint64(0))
+	assert.Equal(t, len(status.Errors), 1)
+	assert.Contains(t, status.Errors[0], "insufficient balance")
+}
+
+func TestFeesAreRefundedAfterTheSystemsRan(t *testing.T) {
+	tf := cardinal.NewTestFixture(t, nil)
+	world := tf.World
+	payer := &balanceFeePayer{balances: map[string]types.EntityID{}}
+	assert.NilError(t, cardinal.RegisterComponent[Balance](world))
+	assert.NilError(t, cardinal.RegisterMessage[SpendMsg, SpendResult](world, "spend"))
+	assert.NilError(t, cardinal.RegisterFeePayer(world, payer))
+	assert.NilError(t, cardinal.RegisterInitSystems(world, func(wCtx cardinal.WorldContext) error {
+		id, err := cardinal.Create(wCtx, Balance{Amount: 100})
+		payer.balances["alice"] = id
+		return err
+	}))
+	// The systems only read the balances, but the fee payer writes them when the fee is refunded.
+	assert.NilError(t, cardinal.RegisterSystem(world, func(wCtx cardinal.WorldContext) error {
+		return cardinal.EachMessage[SpendMsg, SpendResult](wCtx,
+			func(cardinal.TxData[SpendMsg]) (SpendResult, error) {
+				return SpendResult{}, errors.New("failed to spend")
+			})
+	}, cardinal.WithSystemReads(filter.Component[Balance]())))
+	var balanceInTick uint64
+	assert.NilError(t, cardinal.RegisterSystem(world, func(wCtx cardinal.WorldContext) error {
+		balance, err := cardinal.GetComponent[Balance](wCtx, payer.balances["alice"])
+		if err != nil {
+			return err
+		}
+		balanceInTick = balance.Amount
+		return nil
+	}, cardinal.WithSystemReads(filter.Component[Balance]())))
+	spend, ok := world.GetMessageByFullName("game.spend")
+	assert.True(t, ok)
+	tf.DoTick()
+
+	hash := tf.AddTransaction(spend.ID(), SpendMsg{}, &sign.Transaction{PersonaTag: "alice", Fee: 10})
+	tf.DoTick()
+
+	// The fee is refunded once every system of the tick ran.
+	assert.Equal(t, balanceInTick, uint64(90))
+	balance, err := cardinal.GetComponent[Balance](cardinal.NewReadOnlyWorldContext(world), payer.balances["alice"])
+	assert.NilError(t, err)
+	assert.Equal(t, balance.Amount, uint64(100))
+	status, err := world.GetTransactionStatus(hash)
+	assert.NilError(t, err)
+	assert.True(t, status.FeeRefunded)
+	assert.Equal(t, len(status.Errors), 1)
+}
+
+func TestCannotRegisterAFeePayerInRollupMode(t *testing.T) {
+	setEnvToCardinalRollupMode(t)
+	tf := cardinal.NewTestFixture(t, nil)
+	err := cardinal.RegisterFeePayer(tf.World, &balanceFeePayer{})
+	assert.ErrorContains(t, err, "not supported in rollup mode")
+}
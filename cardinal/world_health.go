@@ -0,0 +1,65 @@
+package cardinal
+
+import (
+	"github.com/rotisserie/eris"
+	"github.com/rs/zerolog/log"
+
+	"pkg.world.dev/world-engine/cardinal/txpool"
+	"pkg.world.dev/world-engine/cardinal/types"
+)
+
+// tickAbort describes a tick that was aborted by a failing system.
+type tickAbort struct {
+	tick uint64
+	err  string
+}
+
+// abortTick discards the changes of the tick after a system with the AbortTick failure policy failed, and adds the
+// error to the receipt of every transaction of the tick. The tick then completes without any change. Any other error
+// of the systems is returned, and fails the tick. The init systems can't be aborted, since the world would be left
+// without its initial state.
+func (w *World) abortTick(txPool *txpool.TxPool, eventCount int, err error) error {
+	if w.CurrentTick() == 0 || !abortsTick(err) {
+		return err
+	}
+	if err := w.discardTick(eventCount); err != nil {
+		return err
+	}
+	// The failure of the system is the only change of the tick that is kept, so that its circuit can open.
+	if err := w.SystemManager.saveFailures(newWorldContextForTick(w, txPool)); err != nil {
+		return err
+	}
+	txErr := eris.Wrap(err, "the tick was aborted")
+	for _, txs := range txPool.Transactions() {
+		for _, tx := range txs {
+			w.receiptHistory.AddError(tx.TxHash, txErr)
+		}
+	}
+	w.abortedTicks.Add(1)
+	w.lastAbort.Store(&tickAbort{tick: w.CurrentTick(), err: err.Error()})
+	log.Error().Err(err).Msgf("Tick %d was aborted and its changes were discarded", w.CurrentTick())
+	return nil
+}
+
+// Health reports the failures of the systems of the world, and the durations of its ticks. The world is not healthy
+// if the last tick was aborted, or if the circuit of a system is open. Ticks that overrun their budget do not make the
+// world unhealthy.
+func (w *World) Health() types.WorldHealth {
+	systems, circuitOpen := w.SystemManager.systemsHealth()
+	health := types.WorldHealth{
+		Healthy:             !circuitOpen,
+		AbortedTicks:        w.abortedTicks.Load(),
+		LastAbortedTick:     nil,
+		LastAbortError:      "",
+		Systems:             systems,
+		QuarantinedEntities: w.SystemManager.quarantinedEntities(),
+		Ticks:               w.tickMonitor.report(),
+	}
+	if abort := w.lastAbort.Load(); abort != nil {
+		tick := abort.tick
+		health.LastAbortedTick = &tick
+		health.LastAbortError = abort.err
+		health.Healthy = health.Healthy && tick+1 != w.CurrentTick()
+	}
+	return health
+}
@@ -0,0 +1,170 @@
+package cardinal
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+
+	"pkg.world.dev/world-engine/cardinal/gamestate"
+	"pkg.world.dev/world-engine/cardinal/receipt"
+	"pkg.world.dev/world-engine/cardinal/txpool"
+	"pkg.world.dev/world-engine/cardinal/types"
+)
+
+// worldMetrics holds the Prometheus metrics of a world, which are served by the /metrics endpoint. Every world has its
+// own registry, so that several worlds can run in the same process.
+type worldMetrics struct {
+	registry *prometheus.Registry
+
+	tickDuration          prometheus.Histogram
+	systemDuration        *prometheus.HistogramVec
+	transactions          *prometheus.CounterVec
+	receiptErrors         *prometheus.CounterVec
+	redisPipelineDuration prometheus.Histogram
+	snapshotPauseDuration prometheus.Histogram
+}
+
+// newWorldMetrics creates the metrics of the world. The metrics that are read from the world when they are scraped,
+// such as the size of the transaction pool, may only be scraped once the world is created.
+func newWorldMetrics(w *World) *worldMetrics {
+	//nolint:mnd // the buckets go from 1ms to 8s, and from 0.5ms to 4s for redis pipelines.
+	m := &worldMetrics{
+		registry: prometheus.NewRegistry(),
+		tickDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "cardinal_tick_duration_seconds",
+			Help:    "Time taken by the ticks of the game loop.",
+			Buckets: prometheus.ExponentialBuckets(0.001, 2, 14),
+		}),
+		systemDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "cardinal_system_duration_seconds",
+			Help:    "Time taken by each system in the ticks of the game loop.",
+			Buckets: prometheus.ExponentialBuckets(0.001, 2, 14),
+		}, []string{"system"}),
+		transactions: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "cardinal_transactions_total",
+			Help: "Number of transactions processed, by message.",
+		}, []string{"message"}),
+		receiptErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "cardinal_receipt_errors_total",
+			Help: "Number of processed transactions whose receipt has errors, by message.",
+		}, []string{"message"}),
+		redisPipelineDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "cardinal_redis_pipeline_duration_seconds",
+			Help:    "Time taken to execute the redis pipeline that commits the state changes of a tick.",
+			Buckets: prometheus.ExponentialBuckets(0.0005, 2, 14),
+		}),
+		snapshotPauseDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "cardinal_snapshot_pause_duration_seconds",
+			Help:    "Time the game loop is paused to copy the state of a snapshot.",
+			Buckets: prometheus.ExponentialBuckets(0.001, 2, 14),
+		}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.tickDuration,
+		m.systemDuration,
+		m.transactions,
+		m.receiptErrors,
+		m.redisPipelineDuration,
+		m.snapshotPauseDuration,
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "cardinal_tick",
+			Help: "Current tick of the world.",
+		}, func() float64 {
+			return float64(w.CurrentTick())
+		}),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "cardinal_tx_pool_size",
+			Help: "Number of transactions waiting for the next tick.",
+		}, func() float64 {
+			return float64(w.txPool.GetAmountOfTxs())
+		}),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name: "cardinal_tick_overruns_total",
+			Help: "Number of ticks that took longer than the tick budget.",
+		}, func() float64 {
+			return float64(w.tickMonitor.report().Overruns)
+		}),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name: "cardinal_ticks_skipped_total",
+			Help: "Number of ticks skipped because a tick took longer than the tick budget.",
+		}, func() float64 {
+			return float64(w.tickMonitor.report().SkippedTicks)
+		}),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name: "cardinal_ticks_caught_up_total",
+			Help: "Number of ticks run late because a tick took longer than the tick budget.",
+		}, func() float64 {
+			return float64(w.tickMonitor.report().CaughtUpTicks)
+		}),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name: "cardinal_ticks_aborted_total",
+			Help: "Number of ticks aborted by a failing system.",
+		}, func() float64 {
+			return float64(w.abortedTicks.Load())
+		}),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "cardinal_archetypes",
+			Help: "Number of archetypes in the committed game state.",
+		}, func() float64 {
+			return float64(w.entityStore.ToReadOnly().ArchetypeCount())
+		}),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "cardinal_entities",
+			Help: "Number of entities in the committed game state.",
+		}, func() float64 {
+			return float64(countEntities(w.entityStore.ToReadOnly()))
+		}),
+	)
+	return m
+}
+
+// countEntities returns the number of entities of every archetype.
+func countEntities(reader gamestate.Reader) int {
+	count := 0
+	for archID := range reader.ArchetypeCount() {
+		ids, err := reader.GetEntitiesForArchID(types.ArchetypeID(archID))
+		if err != nil {
+			continue
+		}
+		count += len(ids)
+	}
+	return count
+}
+
+// observeTick records the duration of a tick of the game loop, and the time each of its systems ran for.
+func (m *worldMetrics) observeTick(d time.Duration, systems map[string]time.Duration) {
+	m.tickDuration.Observe(d.Seconds())
+	for name, d := range systems {
+		m.systemDuration.WithLabelValues(name).Observe(d.Seconds())
+	}
+}
+
+// countTransactions counts the transactions processed by a tick, and those whose receipt has errors.
+func (m *worldMetrics) countTransactions(w *World, txPool *txpool.TxPool, receipts []receipt.Receipt) {
+	failed := make(map[types.TxHash]bool, len(receipts))
+	for _, rec := range receipts {
+		failed[rec.TxHash] = len(rec.Errs) > 0
+	}
+	for id, txs := range txPool.Transactions() {
+		msg, ok := w.GetMessageByID(id)
+		if !ok {
+			continue
+		}
+		m.transactions.WithLabelValues(msg.FullName()).Add(float64(len(txs)))
+		for _, tx := range txs {
+			if failed[tx.TxHash] {
+				m.receiptErrors.WithLabelValues(msg.FullName()).Inc()
+			}
+		}
+	}
+}
+
+// MetricsRegistry returns the registry of the Prometheus metrics of the world. Games can register their own metrics
+// with it, and they are then served by the /metrics endpoint along with the metrics of Cardinal.
+func (w *World) MetricsRegistry() *prometheus.Registry {
+	return w.metrics.registry
+}
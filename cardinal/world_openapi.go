@@ -0,0 +1,163 @@
+package cardinal
+
+import (
+	"cmp"
+	"encoding/json"
+	"reflect"
+	"slices"
+
+	"github.com/rotisserie/eris"
+
+	"pkg.world.dev/world-engine/cardinal/server/handler"
+	"pkg.world.dev/world-engine/cardinal/server/utils"
+	"pkg.world.dev/world-engine/cardinal/types"
+	"pkg.world.dev/world-engine/sign"
+)
+
+const openAPIContentType = "application/json"
+
+// typedMessage is a message that knows the Go types of its input and output, which every MessageType does.
+type typedMessage interface {
+	types.Message
+	types() (in, out reflect.Type)
+}
+
+// OpenAPI generates an OpenAPI document that describes the transaction and query endpoints of the registered messages
+// and queries, with the schemas of their bodies and replies, and the schemas of the registered components.
+func (w *World) OpenAPI() (types.OpenAPI, error) {
+	schemas := types.NewOpenAPISchemas()
+	doc := types.OpenAPI{
+		OpenAPI: types.OpenAPIVersion,
+		Info: types.OpenAPIInfo{
+			Title:       "Cardinal " + w.Namespace(),
+			Description: "The messages, queries and components of the " + w.Namespace() + " world.",
+			Version:     "1.0.0",
+		},
+		Paths: map[string]types.OpenAPIPathItem{},
+		Cardinal: types.OpenAPICardinal{
+			Namespace:  w.Namespace(),
+			Components: map[string]json.RawMessage{},
+		},
+	}
+
+	txSchema, err := schemas.Schema(reflect.TypeOf(sign.Transaction{}))
+	if err != nil {
+		return types.OpenAPI{}, err
+	}
+	txResponseSchema, err := schemas.Schema(reflect.TypeOf(handler.PostTransactionResponse{}))
+	if err != nil {
+		return types.OpenAPI{}, err
+	}
+
+	for _, comp := range w.GetRegisteredComponents() {
+		// Decoding the schema of a component yields a value of its type, as in the /world endpoint.
+		value, _ := comp.Decode(comp.GetSchema())
+		schema, err := schemas.Schema(reflect.TypeOf(value))
+		if err != nil {
+			return types.OpenAPI{}, eris.Wrapf(err, "component %q", comp.Name())
+		}
+		doc.Cardinal.Components[comp.Name()] = schema
+	}
+
+	for _, msg := range w.GetRegisteredMessages() {
+		typed, ok := msg.(typedMessage)
+		if !ok {
+			continue
+		}
+		in, out := typed.types()
+		inSchema, err := schemas.Schema(in)
+		if err != nil {
+			return types.OpenAPI{}, eris.Wrapf(err, "message %q", msg.FullName())
+		}
+		outSchema, err := schemas.Schema(out)
+		if err != nil {
+			return types.OpenAPI{}, eris.Wrapf(err, "message %q", msg.FullName())
+		}
+		// The body of the transaction is the input of the message.
+		bodySchema, err := json.Marshal(map[string]any{
+			"allOf": []any{
+				txSchema,
+				map[string]any{"properties": map[string]any{"body": inSchema}},
+			},
+		})
+		if err != nil {
+			return types.OpenAPI{}, eris.Wrap(err, "failed to encode schema")
+		}
+		path := utils.GetTxURL(msg.Group(), msg.Name())
+		if msg.Group() == types.BatchMessageGroup && msg.Name() == types.BatchMessageName {
+			path = "/tx/batch"
+		}
+		doc.Paths[path] = types.OpenAPIPathItem{Post: &types.OpenAPIOperation{
+			OperationID: "tx-" + msg.Group() + "-" + msg.Name(),
+			Summary:     "Submits a " + msg.FullName() + " transaction",
+			Description: "The body of the transaction is the input of the message, and the result of its receipt is " +
+				"the output of the message.",
+			Tags:        []string{msg.Group()},
+			RequestBody: openAPIRequestBody(bodySchema),
+			Responses: map[string]types.OpenAPIResponse{
+				"200": openAPIResponse("Transaction hash and tick", txResponseSchema),
+				"400": {Description: "Invalid request parameter"},
+				"401": {Description: "Unauthorized - signature was invalid"},
+				"408": {Description: "Request Timeout - message expired"},
+				"429": {Description: "Too Many Requests - rejected by the tx pool limits"},
+			},
+			Message: &types.OpenAPIMessage{
+				Group:         msg.Group(),
+				Name:          msg.Name(),
+				FullName:      msg.FullName(),
+				Input:         inSchema,
+				Output:        outSchema,
+				EVMCompatible: msg.IsEVMCompatible(),
+			},
+		}}
+	}
+
+	// Queries are kept in maps, so they are sorted for the names of their schemas to be the same on every run.
+	queries := w.GetRegisteredQueries()
+	slices.SortFunc(queries, func(a, b query) int {
+		return cmp.Or(cmp.Compare(a.Group(), b.Group()), cmp.Compare(a.Name(), b.Name()))
+	})
+	for _, q := range queries {
+		request, reply := q.types()
+		requestSchema, err := schemas.Schema(request)
+		if err != nil {
+			return types.OpenAPI{}, eris.Wrapf(err, "query %s/%s", q.Group(), q.Name())
+		}
+		replySchema, err := schemas.Schema(reply)
+		if err != nil {
+			return types.OpenAPI{}, eris.Wrapf(err, "query %s/%s", q.Group(), q.Name())
+		}
+		doc.Paths[utils.GetQueryURL(q.Group(), q.Name())] = types.OpenAPIPathItem{Post: &types.OpenAPIOperation{
+			OperationID: "query-" + q.Group() + "-" + q.Name(),
+			Summary:     "Runs the " + q.Group() + "/" + q.Name() + " query",
+			Tags:        []string{q.Group()},
+			RequestBody: openAPIRequestBody(requestSchema),
+			Responses: map[string]types.OpenAPIResponse{
+				"200": openAPIResponse("Reply of the query", replySchema),
+				"400": {Description: "Invalid request parameters"},
+			},
+			Query: &types.OpenAPIQuery{
+				Group:         q.Group(),
+				Name:          q.Name(),
+				EVMCompatible: q.IsEVMCompatible(),
+			},
+		}}
+	}
+
+	doc.Components.Schemas = schemas.Components()
+	return doc, nil
+}
+
+func openAPIRequestBody(schema json.RawMessage) types.OpenAPIRequestBody {
+	return types.OpenAPIRequestBody{
+		Required: true,
+		Content:  map[string]types.OpenAPIMediaType{openAPIContentType: {Schema: schema}},
+	}
+}
+
+func openAPIResponse(description string, schema json.RawMessage) types.OpenAPIResponse {
+	return types.OpenAPIResponse{
+		Description: description,
+		Content:     map[string]types.OpenAPIMediaType{openAPIContentType: {Schema: schema}},
+	}
+}
@@ -1,10 +1,16 @@
 package cardinal
 
 import (
+	"encoding/json"
+	"errors"
+
 	"github.com/rotisserie/eris"
+	"github.com/rs/zerolog/log"
 
 	"pkg.world.dev/world-engine/cardinal/receipt"
+	"pkg.world.dev/world-engine/cardinal/storage"
 	"pkg.world.dev/world-engine/cardinal/txpool"
+	"pkg.world.dev/world-engine/cardinal/types"
 )
 
 type EVMTxReceipt struct {
@@ -17,6 +23,86 @@ func (w *World) GetTransactionReceiptsForTick(tick uint64) ([]receipt.Receipt, e
 	return w.receiptHistory.GetReceiptsForTick(tick)
 }
 
+// GetTransactionStatus looks the transaction up in the tx pool, then in the receipt history, and then in the receipt
+// log if it is enabled. A processed transaction that did not get a receipt, because no system handled its message, is
+// unknown.
+func (w *World) GetTransactionStatus(hash types.TxHash) (types.TransactionStatus, error) {
+	status := types.TransactionStatus{TxHash: hash, Status: types.TxStatusUnknown}
+	if w.txPool.Has(hash) {
+		status.Status = types.TxStatusPending
+		return status, nil
+	}
+	if processing := w.txsInTick.Load(); processing != nil && processing.Has(hash) {
+		status.Status = types.TxStatusPending
+		return status, nil
+	}
+
+	var rec storage.ReceiptRecord
+	if found, tick, ok := w.receiptHistory.FindReceipt(hash); ok {
+		result, err := json.Marshal(found.Result)
+		if err != nil {
+			return status, eris.Wrapf(err, "failed to encode the result of transaction %q", hash)
+		}
+		rec = storage.ReceiptRecord{Tick: tick, Result: result, Fee: found.Fee, FeeRefunded: found.FeeRefunded}
+		for _, err := range found.Errs {
+			rec.Errors = append(rec.Errors, err.Error())
+		}
+	} else if w.receiptLog != nil {
+		var err error
+		rec, err = w.receiptLog.GetReceipt(string(hash))
+		if errors.Is(err, storage.ErrReceiptNotFound) {
+			return status, nil
+		} else if err != nil {
+			return status, err
+		}
+	} else {
+		return status, nil
+	}
+
+	status.Status = types.TxStatusProcessed
+	if len(rec.Errors) > 0 {
+		status.Status = types.TxStatusFailed
+	}
+	status.Tick = &rec.Tick
+	status.Result = rec.Result
+	status.Errors = rec.Errors
+	status.Fee = rec.Fee
+	status.FeeRefunded = rec.FeeRefunded
+	return status, nil
+}
+
+// ReceiptLog returns the durable log of receipts and events, and the tick after the last tick it holds. The log is nil
+// if CARDINAL_RECEIPT_LOG is not enabled.
+func (w *World) ReceiptLog() (receiptLog storage.ReceiptLog, endTick uint64) {
+	if w.receiptLog == nil {
+		return nil, 0
+	}
+	return w.receiptLog, w.receiptLogEnd.Load()
+}
+
+// appendToReceiptLog adds the results of the tick to the receipt log. Ticks without receipts or events are skipped.
+// If the log has a retention, the ticks that fall out of it are then pruned. Like the rest of the tick results, a tick
+// that fails to be added or pruned is logged rather than failing the tick.
+func (w *World) appendToReceiptLog(tr *TickResults) {
+	if w.receiptLog == nil {
+		return
+	}
+	record, err := tr.tickRecord()
+	if err == nil && (len(record.Receipts) > 0 || len(record.Events) > 0) {
+		err = w.receiptLog.AppendTick(record)
+	}
+	if err != nil {
+		log.Error().Err(err).Msgf("failed to add tick %d to the receipt log", tr.Tick)
+	}
+	w.receiptLogEnd.Store(tr.Tick + 1)
+
+	if w.receiptLogRetention > 0 && tr.Tick+1 > w.receiptLogRetention {
+		if err := w.receiptLog.PruneTicks(tr.Tick + 1 - w.receiptLogRetention); err != nil {
+			log.Error().Err(err).Msgf("failed to prune the receipt log at tick %d", tr.Tick)
+		}
+	}
+}
+
 // ConsumeEVMMsgResult consumes a tx result from an EVM originated Cardinal message.
 // It will fetch the receipt from the map, and then delete ('consume') it from the map.
 func (w *World) ConsumeEVMMsgResult(evmTxHash string) ([]byte, []error, string, bool) {
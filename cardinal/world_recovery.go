@@ -1,12 +1,15 @@
 package cardinal
 
 import (
+	"cmp"
 	"context"
+	"slices"
 
 	"github.com/rotisserie/eris"
 	"github.com/rs/zerolog/log"
 
 	"pkg.world.dev/world-engine/cardinal/router/iterator"
+	"pkg.world.dev/world-engine/cardinal/txpool"
 )
 
 // recoverFromChain will attempt to recover the state of the engine based on historical transaction data.
@@ -22,6 +25,13 @@ func (w *World) recoverFromChain(ctx context.Context) error {
 
 	log.Info().Msgf("Synchronizing state from base shard starting from tick %d", w.CurrentTick())
 
+	// The recovered transactions are added to the pool in the order they were processed, which must not change even
+	// if the ordering policy did. The base shard returns them grouped by message, so they are sorted by their sequence
+	// first. Transactions submitted before sequences were recorded all have the same sequence, and keep their order.
+	policy := w.txPool.OrderingPolicy()
+	w.txPool.SetOrderingPolicy(txpool.ArrivalOrder)
+	defer w.txPool.SetOrderingPolicy(policy)
+
 	start := w.CurrentTick()
 	err := w.router.TransactionIterator().Each(func(batches []*iterator.TxBatch, tick, timestamp uint64) error {
 		select {
@@ -41,6 +51,9 @@ func (w *World) recoverFromChain(ctx context.Context) error {
 			}
 			log.Info().Msgf("Successfully fast forwarded to tick %d", tick)
 
+			slices.SortStableFunc(batches, func(a, b *iterator.TxBatch) int {
+				return cmp.Compare(a.Sequence, b.Sequence)
+			})
 			for _, batch := range batches {
 				w.AddTransaction(batch.MsgID, batch.MsgValue, batch.Tx)
 			}
@@ -1,6 +1,7 @@
 package cardinal_test
 
 import (
+	"context"
 	"testing"
 
 	"github.com/golang/mock/gomock"
@@ -10,6 +11,7 @@ import (
 	"pkg.world.dev/world-engine/cardinal/router/iterator"
 	iteratormocks "pkg.world.dev/world-engine/cardinal/router/iterator/mocks"
 	"pkg.world.dev/world-engine/cardinal/router/mocks"
+	"pkg.world.dev/world-engine/cardinal/txpool"
 	"pkg.world.dev/world-engine/cardinal/types"
 	"pkg.world.dev/world-engine/sign"
 )
@@ -81,7 +83,7 @@ func TestWorldRecovery(t *testing.T) {
 	router.EXPECT().Start().Times(1)
 	router.EXPECT().RegisterGameShard(gomock.Any()).Times(1)
 	router.EXPECT().
-		SubmitTxBlob(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		SubmitTxBlob(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
 		Return(nil).AnyTimes()
 
 	tf.StartWorld()
@@ -91,3 +93,186 @@ func TestWorldRecovery(t *testing.T) {
 
 	controller.Finish()
 }
+
+// registerFooOrderRecorder registers the foo message, and a system that appends the persona tag of every foo
+// transaction to the returned slice.
+func registerFooOrderRecorder(t *testing.T, world *cardinal.World) (types.Message, *[]string) {
+	assert.NilError(t, cardinal.RegisterMessage[fooMessage, fooResponse](world, "foo"))
+	fooTx, ok := world.GetMessageByFullName("game.foo")
+	assert.Check(t, ok)
+	var processed []string
+	assert.NilError(t, cardinal.RegisterSystems(world, func(wCtx cardinal.WorldContext) error {
+		return cardinal.EachMessage[fooMessage, fooResponse](wCtx,
+			func(tx cardinal.TxData[fooMessage]) (fooResponse, error) {
+				processed = append(processed, tx.Tx.PersonaTag)
+				return fooResponse{}, nil
+			})
+	}))
+	return fooTx, &processed
+}
+
+func TestTxOrderingPolicyIsSubmittedToTheBaseShard(t *testing.T) {
+	controller := gomock.NewController(t)
+	router := mocks.NewMockRouter(controller)
+	tf := cardinal.NewTestFixture(t, nil,
+		cardinal.WithCustomRouter(router), cardinal.WithTxOrderingPolicy(txpool.TimestampOrder))
+	fooTx, processed := registerFooOrderRecorder(t, tf.World)
+
+	var submitted []string
+	router.EXPECT().Start().Times(1)
+	router.EXPECT().RegisterGameShard(gomock.Any()).Times(1)
+	router.EXPECT().
+		SubmitTxBlob(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, txs txpool.TxMap, orderingPolicy string, _, _ uint64, _ []byte) error {
+			assert.Check(t, orderingPolicy == txpool.TimestampOrder.Name)
+			for _, tx := range txs[fooTx.ID()] {
+				submitted = append(submitted, tx.Tx.PersonaTag)
+			}
+			return nil
+		}).AnyTimes()
+
+	tf.DoTick()
+	tf.AddTransaction(fooTx.ID(), fooMessage{}, &sign.Transaction{PersonaTag: "late", Timestamp: 20})
+	tf.AddTransaction(fooTx.ID(), fooMessage{}, &sign.Transaction{PersonaTag: "early", Timestamp: 10})
+	tf.DoTick()
+
+	assert.DeepEqual(t, *processed, []string{"early", "late"})
+	assert.DeepEqual(t, submitted, []string{"early", "late"})
+}
+
+func TestRecoveryProcessesTxsInTheRecordedOrder(t *testing.T) {
+	setEnvToCardinalRollupMode(t)
+	controller := gomock.NewController(t)
+	router := mocks.NewMockRouter(controller)
+	tf := cardinal.NewTestFixture(t, nil,
+		cardinal.WithCustomRouter(router), cardinal.WithTxOrderingPolicy(txpool.TimestampOrder))
+	fooTx, processed := registerFooOrderRecorder(t, tf.World)
+
+	iter := iteratormocks.NewMockIterator(controller)
+	iter.EXPECT().Each(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(fn func(batch []*iterator.TxBatch, tick, timestamp uint64) error, _ ...uint64) error {
+			// The transactions were processed before the timestamp ordering policy was used.
+			return fn([]*iterator.TxBatch{
+				{Tx: &sign.Transaction{PersonaTag: "late", Timestamp: 20}, MsgID: fooTx.ID(), MsgValue: fooMessage{}},
+				{Tx: &sign.Transaction{PersonaTag: "early", Timestamp: 10}, MsgID: fooTx.ID(), MsgValue: fooMessage{}},
+			}, 0, 1577883100)
+		})
+	router.EXPECT().TransactionIterator().Return(iter).Times(1)
+	router.EXPECT().Start().Times(1)
+	router.EXPECT().RegisterGameShard(gomock.Any()).Times(1)
+	router.EXPECT().
+		SubmitTxBlob(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(nil).AnyTimes()
+
+	tf.StartWorld()
+	assert.DeepEqual(t, *processed, []string{"late", "early"})
+
+	// Transactions received after the recovery are ordered by the policy again.
+	*processed = nil
+	tf.AddTransaction(fooTx.ID(), fooMessage{}, &sign.Transaction{PersonaTag: "late", Timestamp: 40})
+	tf.AddTransaction(fooTx.ID(), fooMessage{}, &sign.Transaction{PersonaTag: "early", Timestamp: 30})
+	tf.DoTick()
+	assert.DeepEqual(t, *processed, []string{"early", "late"})
+}
+
+// barMessage is registered next to fooMessage to have transactions of two messages in a tick.
+type barMessage struct {
+	Bar string
+}
+
+// processedTx is a transaction processed by a system of registerSequenceRecorder.
+type processedTx struct {
+	Bar      string
+	Sequence uint64
+}
+
+// registerSequenceRecorder registers the foo and bar messages, and systems that append every processed foo and bar
+// transaction to the returned slice.
+func registerSequenceRecorder(t *testing.T, world *cardinal.World) *[]processedTx {
+	assert.NilError(t, cardinal.RegisterMessage[fooMessage, fooResponse](world, "foo"))
+	assert.NilError(t, cardinal.RegisterMessage[barMessage, fooResponse](world, "bar"))
+	var processed []processedTx
+	assert.NilError(t, cardinal.RegisterSystems(world,
+		func(wCtx cardinal.WorldContext) error {
+			return cardinal.EachMessage[fooMessage, fooResponse](wCtx,
+				func(tx cardinal.TxData[fooMessage]) (fooResponse, error) {
+					processed = append(processed, processedTx{Bar: tx.Msg.Bar, Sequence: tx.Sequence})
+					return fooResponse{}, nil
+				})
+		},
+		func(wCtx cardinal.WorldContext) error {
+			return cardinal.EachMessage[barMessage, fooResponse](wCtx,
+				func(tx cardinal.TxData[barMessage]) (fooResponse, error) {
+					processed = append(processed, processedTx{Bar: tx.Msg.Bar, Sequence: tx.Sequence})
+					return fooResponse{}, nil
+				})
+		}))
+	return &processed
+}
+
+func TestRecoveryReplaysTheSubmittedOrder(t *testing.T) {
+	controller := gomock.NewController(t)
+	router := mocks.NewMockRouter(controller)
+	tf := cardinal.NewTestFixture(t, nil,
+		cardinal.WithCustomRouter(router), cardinal.WithTxOrderingPolicy(txpool.RoundRobinOrder))
+	processed := registerSequenceRecorder(t, tf.World)
+	fooTx, ok := tf.World.GetMessageByFullName("game.foo")
+	assert.Check(t, ok)
+	barTx, ok := tf.World.GetMessageByFullName("game.bar")
+	assert.Check(t, ok)
+
+	var submitted txpool.TxMap
+	router.EXPECT().Start().Times(1)
+	router.EXPECT().RegisterGameShard(gomock.Any()).Times(1)
+	router.EXPECT().
+		SubmitTxBlob(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, txs txpool.TxMap, orderingPolicy string, _, _ uint64, _ []byte) error {
+			assert.Check(t, orderingPolicy == txpool.RoundRobinOrder.Name)
+			submitted = txs
+			return nil
+		}).AnyTimes()
+
+	tf.StartWorld()
+	tf.AddTransaction(fooTx.ID(), fooMessage{Bar: "a1"}, &sign.Transaction{PersonaTag: "a", Timestamp: 10})
+	tf.AddTransaction(barTx.ID(), barMessage{Bar: "a2"}, &sign.Transaction{PersonaTag: "a", Timestamp: 20})
+	tf.AddTransaction(fooTx.ID(), fooMessage{Bar: "b1"}, &sign.Transaction{PersonaTag: "b", Timestamp: 30})
+	tf.AddTransaction(fooTx.ID(), fooMessage{Bar: "a3"}, &sign.Transaction{PersonaTag: "a", Timestamp: 40})
+	tf.AddTransaction(barTx.ID(), barMessage{Bar: "b2"}, &sign.Transaction{PersonaTag: "b", Timestamp: 50})
+	tf.DoTick()
+	// The personas take turns across both messages.
+	assert.DeepEqual(t, *processed, []processedTx{
+		{Bar: "a1", Sequence: 0}, {Bar: "b1", Sequence: 1}, {Bar: "a3", Sequence: 4},
+		{Bar: "a2", Sequence: 2}, {Bar: "b2", Sequence: 3},
+	})
+
+	// The base shard returns the transactions of the tick grouped by message.
+	var batches []*iterator.TxBatch
+	for _, msgID := range []types.MessageID{fooTx.ID(), barTx.ID()} {
+		for _, tx := range submitted[msgID] {
+			batches = append(batches, &iterator.TxBatch{Tx: tx.Tx, MsgID: msgID, MsgValue: tx.Msg, Sequence: tx.Sequence})
+		}
+	}
+
+	// The transactions are recovered by a world with another ordering policy.
+	setEnvToCardinalRollupMode(t)
+	recoveryController := gomock.NewController(t)
+	recoveryRouter := mocks.NewMockRouter(recoveryController)
+	recoveryTF := cardinal.NewTestFixture(t, nil,
+		cardinal.WithCustomRouter(recoveryRouter), cardinal.WithTxOrderingPolicy(txpool.TimestampOrder))
+	recovered := registerSequenceRecorder(t, recoveryTF.World)
+
+	iter := iteratormocks.NewMockIterator(recoveryController)
+	iter.EXPECT().Each(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(fn func(batch []*iterator.TxBatch, tick, timestamp uint64) error, _ ...uint64) error {
+			return fn(batches, 0, 1577883100)
+		})
+	recoveryRouter.EXPECT().TransactionIterator().Return(iter).Times(1)
+	recoveryRouter.EXPECT().Start().Times(1)
+	recoveryRouter.EXPECT().RegisterGameShard(gomock.Any()).Times(1)
+	recoveryRouter.EXPECT().
+		SubmitTxBlob(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(nil).AnyTimes()
+
+	recoveryTF.StartWorld()
+	assert.DeepEqual(t, *recovered, *processed)
+}
@@ -0,0 +1,254 @@
+package cardinal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/rotisserie/eris"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/codes"
+)
+
+const (
+	// snapshotVersion is the version of the snapshot file format. It must be bumped whenever worldSnapshot changes in
+	// a way that is not backwards compatible.
+	snapshotVersion = 1
+
+	// snapshotsToKeep is the number of snapshot files kept on disk. Older snapshots are deleted.
+	snapshotsToKeep = 3
+
+	snapshotFilePrefix = "snapshot-"
+	snapshotFileSuffix = ".json"
+)
+
+// worldSnapshot is a point-in-time copy of all the finalized state of a World. Personas are stored as entities, so
+// they are part of State.
+type worldSnapshot struct {
+	Version   int    `json:"version"`
+	Namespace string `json:"namespace"`
+	// Tick is the number of ticks that were finalized when the snapshot was taken, i.e. the next tick to run.
+	Tick      uint64 `json:"tick"`
+	Timestamp uint64 `json:"timestamp"`
+	// Schemas maps component names to their JSON schema.
+	Schemas map[string][]byte `json:"schemas"`
+	// State maps game state storage keys to their values.
+	State map[string][]byte `json:"state"`
+}
+
+// takeSnapshot copies the finalized state of the world, and writes it to the snapshot directory in the background. It
+// must be called between ticks so that there are no pending state changes. Only one snapshot is taken at a time, so it
+// first waits for the previous snapshot to be written.
+//
+// The game loop is paused while the state is copied. With the Badger storage backend, the state is only pinned in a
+// read transaction, and it is read in the background. Redis has no such point-in-time read, so the whole state is read
+// before the next tick runs, and the pause grows with the size of the state. The pause is reported by the
+// cardinal_snapshot_pause_duration_seconds metric.
+func (w *World) takeSnapshot(ctx context.Context) error {
+	ctx, span := w.tracer.Start(ctx, "world.snapshot.take")
+	defer span.End()
+
+	start := time.Now()
+	w.snapshotWrites.Wait()
+	snapshot, readState, err := w.copySnapshot(ctx)
+	if err != nil {
+		span.SetStatus(codes.Error, eris.ToString(err, true))
+		span.RecordError(err)
+		return err
+	}
+	w.metrics.snapshotPauseDuration.Observe(time.Since(start).Seconds())
+
+	w.snapshotWrites.Add(1)
+	go func() {
+		defer w.snapshotWrites.Done()
+		state, err := readState(context.WithoutCancel(ctx))
+		if err != nil {
+			log.Error().Err(err).Msgf("Failed to read the state of the snapshot at tick %d", snapshot.Tick)
+			return
+		}
+		snapshot.State = state
+		if err := w.writeSnapshot(snapshot); err != nil {
+			log.Error().Err(err).Msgf("Failed to write snapshot at tick %d", snapshot.Tick)
+		}
+	}()
+	return nil
+}
+
+// copySnapshot copies the finalized state of the world, except for the game state, which is pinned. The returned
+// function reads the game state as it was when copySnapshot was called, and must be called exactly once.
+func (w *World) copySnapshot(ctx context.Context) (
+	*worldSnapshot, func(context.Context) (map[string][]byte, error), error,
+) {
+	readState, err := w.entityStore.PinSnapshot(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	schemas := make(map[string][]byte)
+	for _, comp := range w.GetComponents() {
+		schema, err := w.metaStorage.GetSchema(comp.Name())
+		if err != nil {
+			// Reading the state releases it.
+			_, _ = readState(ctx)
+			return nil, nil, eris.Wrapf(err, "failed to get schema for component %q", comp.Name())
+		}
+		schemas[comp.Name()] = schema
+	}
+
+	return &worldSnapshot{
+		Version:   snapshotVersion,
+		Namespace: w.namespace.String(),
+		Tick:      w.CurrentTick(),
+		Timestamp: w.timestamp.Load(),
+		Schemas:   schemas,
+	}, readState, nil
+}
+
+// writeSnapshot encodes the snapshot, and writes it to the snapshot directory.
+func (w *World) writeSnapshot(snapshot *worldSnapshot) error {
+	bz, err := json.Marshal(snapshot)
+	if err != nil {
+		return eris.Wrap(err, "failed to marshal snapshot")
+	}
+
+	if err := os.MkdirAll(w.snapshotDir, 0o755); err != nil { //nolint:mnd // standard directory permissions
+		return eris.Wrapf(err, "failed to create snapshot directory %q", w.snapshotDir)
+	}
+
+	// Write to a temporary file first so a crash never leaves a partially written snapshot behind.
+	path := filepath.Join(w.snapshotDir, snapshotFileName(snapshot.Tick))
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, bz, 0o600); err != nil { //nolint:mnd // owner read/write
+		return eris.Wrapf(err, "failed to write snapshot to %q", tmpPath)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return eris.Wrapf(err, "failed to move snapshot to %q", path)
+	}
+	log.Info().Uint64("tick", snapshot.Tick).Str("path", path).Msg("Snapshot saved")
+
+	return w.pruneSnapshots()
+}
+
+// restoreFromSnapshot loads the latest snapshot into the game state storage if the snapshot is ahead of the storage.
+// It returns true if a snapshot was restored.
+func (w *World) restoreFromSnapshot(ctx context.Context, finalizedTick uint64) (bool, error) {
+	ctx, span := w.tracer.Start(ctx, "world.snapshot.restore")
+	defer span.End()
+
+	restored, err := w.loadLatestSnapshot(ctx, finalizedTick)
+	if err != nil {
+		span.SetStatus(codes.Error, eris.ToString(err, true))
+		span.RecordError(err)
+		return false, err
+	}
+	return restored, nil
+}
+
+func (w *World) loadLatestSnapshot(ctx context.Context, finalizedTick uint64) (bool, error) {
+	paths, err := w.snapshotPaths()
+	if err != nil {
+		return false, err
+	}
+	if len(paths) == 0 {
+		return false, nil
+	}
+	path := paths[len(paths)-1]
+
+	bz, err := os.ReadFile(path)
+	if err != nil {
+		return false, eris.Wrapf(err, "failed to read snapshot %q", path)
+	}
+	var snapshot worldSnapshot
+	if err := json.Unmarshal(bz, &snapshot); err != nil {
+		return false, eris.Wrapf(err, "failed to unmarshal snapshot %q", path)
+	}
+
+	if snapshot.Version != snapshotVersion {
+		return false, eris.Errorf("snapshot %q has version %d, expected %d", path, snapshot.Version, snapshotVersion)
+	}
+	if snapshot.Namespace != w.namespace.String() {
+		return false, eris.Errorf("snapshot %q belongs to namespace %q, expected %q",
+			path, snapshot.Namespace, w.namespace.String())
+	}
+	if snapshot.Tick <= finalizedTick {
+		log.Info().Msgf("Skipping snapshot at tick %d, storage is already at tick %d", snapshot.Tick, finalizedTick)
+		return false, nil
+	}
+
+	// The components in the snapshot are stored with the schema they had when it was taken, which may differ from the
+	// schema in storage. Every component must be able to migrate from its schema in the snapshot.
+	for _, comp := range w.GetComponents() {
+		schema, ok := snapshot.Schemas[comp.Name()]
+		if !ok {
+			continue
+		}
+		if err := w.CheckRestoredSchema(comp.Name(), schema); err != nil {
+			return false, eris.Wrapf(err, "component %q does not match the schema in snapshot %q", comp.Name(), path)
+		}
+	}
+
+	if err := w.entityStore.Restore(ctx, snapshot.State); err != nil {
+		return false, eris.Wrapf(err, "failed to restore snapshot %q", path)
+	}
+
+	// Store the schemas of the snapshot, so that the pending migrations start from them.
+	for _, comp := range w.GetComponents() {
+		schema, ok := snapshot.Schemas[comp.Name()]
+		if !ok {
+			continue
+		}
+		if err := w.RestoreSchema(comp.Name(), schema); err != nil {
+			return false, eris.Wrapf(err, "failed to store the schema of component %q in snapshot %q", comp.Name(), path)
+		}
+	}
+
+	w.tick.Store(snapshot.Tick)
+	w.timestamp.Store(snapshot.Timestamp)
+	log.Info().Uint64("tick", snapshot.Tick).Str("path", path).Msg("Restored state from snapshot")
+	return true, nil
+}
+
+// snapshotPaths returns the paths of all snapshots in the snapshot directory, sorted from oldest to newest.
+func (w *World) snapshotPaths() ([]string, error) {
+	entries, err := os.ReadDir(w.snapshotDir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, eris.Wrapf(err, "failed to read snapshot directory %q", w.snapshotDir)
+	}
+	var paths []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, snapshotFilePrefix) || !strings.HasSuffix(name, snapshotFileSuffix) {
+			continue
+		}
+		paths = append(paths, filepath.Join(w.snapshotDir, name))
+	}
+	// The tick in the file name is zero padded, so lexical order is the same as tick order.
+	slices.Sort(paths)
+	return paths, nil
+}
+
+// pruneSnapshots deletes all but the latest snapshotsToKeep snapshots.
+func (w *World) pruneSnapshots() error {
+	paths, err := w.snapshotPaths()
+	if err != nil {
+		return err
+	}
+	for len(paths) > snapshotsToKeep {
+		if err := os.Remove(paths[0]); err != nil {
+			return eris.Wrapf(err, "failed to remove old snapshot %q", paths[0])
+		}
+		paths = paths[1:]
+	}
+	return nil
+}
+
+func snapshotFileName(tick uint64) string {
+	return fmt.Sprintf("%s%020d%s", snapshotFilePrefix, tick, snapshotFileSuffix)
+}
@@ -0,0 +1,173 @@
+package cardinal
+
+import (
+	"os"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+
+	"pkg.world.dev/world-engine/assert"
+	"pkg.world.dev/world-engine/cardinal/filter"
+	"pkg.world.dev/world-engine/cardinal/types"
+	"pkg.world.dev/world-engine/cardinal/worldstage"
+)
+
+func newSnapshotTestWorld(t *testing.T) *World {
+	world, err := NewWorld(WithPort(getOpenPort(t)))
+	assert.NilError(t, err)
+	assert.NilError(t, RegisterComponent[ScalarComponentStatic](world))
+	assert.NilError(t, RegisterSystems(world, func(wCtx WorldContext) error {
+		return NewSearch().Entity(filter.Contains(filter.Component[ScalarComponentStatic]())).
+			Each(wCtx, func(id types.EntityID) bool {
+				assert.NilError(t, UpdateComponent[ScalarComponentStatic](wCtx, id,
+					func(s *ScalarComponentStatic) *ScalarComponentStatic {
+						s.Val++
+						return s
+					}))
+				return true
+			})
+	}))
+	go func() {
+		assert.NilError(t, world.StartGame())
+	}()
+	<-world.worldStage.NotifyOnStage(worldstage.Running)
+	return world
+}
+
+func TestCanRestoreStateFromSnapshot(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("CARDINAL_SNAPSHOT_INTERVAL", "2")
+	t.Setenv("CARDINAL_SNAPSHOT_DIR", dir)
+	ctx := t.Context()
+
+	// Run the first world for 5 ticks. Snapshots are taken after ticks 2 and 4.
+	t.Setenv("REDIS_ADDRESS", miniredis.RunT(t).Addr())
+	world := newSnapshotTestWorld(t)
+	_, err := Create(NewWorldContext(world), ScalarComponentStatic{})
+	assert.NilError(t, err)
+	for range 5 {
+		world.tickTheEngine(ctx, nil)
+	}
+	world.Shutdown()
+	CleanupViper(t)
+
+	entries, err := os.ReadDir(dir)
+	assert.NilError(t, err)
+	assert.Equal(t, 2, len(entries))
+
+	// Start a second world with an empty redis. It should pick up the state from the latest snapshot.
+	t.Setenv("REDIS_ADDRESS", miniredis.RunT(t).Addr())
+	world = newSnapshotTestWorld(t)
+	assert.Equal(t, uint64(4), world.CurrentTick())
+
+	wCtx := NewWorldContext(world)
+	id, err := NewSearch().Entity(filter.Contains(filter.Component[ScalarComponentStatic]())).First(wCtx)
+	assert.NilError(t, err)
+	s, err := GetComponent[ScalarComponentStatic](wCtx, id)
+	assert.NilError(t, err)
+	assert.Equal(t, 4, s.Val)
+
+	// Ticking continues from the restored state, and old snapshots are pruned.
+	for range 4 {
+		world.tickTheEngine(ctx, nil)
+	}
+	s, err = GetComponent[ScalarComponentStatic](wCtx, id)
+	assert.NilError(t, err)
+	assert.Equal(t, 8, s.Val)
+	world.Shutdown()
+	CleanupViper(t)
+
+	entries, err = os.ReadDir(dir)
+	assert.NilError(t, err)
+	assert.Equal(t, snapshotsToKeep, len(entries))
+	assert.Equal(t, snapshotFileName(8), entries[len(entries)-1].Name())
+}
+
+func TestSnapshotIsSkippedWhenStorageIsAhead(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("CARDINAL_SNAPSHOT_INTERVAL", "2")
+	t.Setenv("CARDINAL_SNAPSHOT_DIR", dir)
+	t.Setenv("REDIS_ADDRESS", miniredis.RunT(t).Addr())
+	ctx := t.Context()
+
+	world := newSnapshotTestWorld(t)
+	for range 3 {
+		world.tickTheEngine(ctx, nil)
+	}
+	world.Shutdown()
+	CleanupViper(t)
+
+	// Redis is at tick 3 and the latest snapshot is at tick 2, so redis is used as is.
+	world = newSnapshotTestWorld(t)
+	assert.Equal(t, uint64(3), world.CurrentTick())
+	world.Shutdown()
+	CleanupViper(t)
+}
+
+func TestSnapshotFileNamesSortByTick(t *testing.T) {
+	assert.Check(t, snapshotFileName(9) < snapshotFileName(10))
+	assert.Check(t, snapshotFileName(99) < snapshotFileName(100))
+}
+
+type ScoreV1 struct {
+	Points int
+}
+
+func (ScoreV1) Name() string { return "score" }
+
+type ScoreV2 struct {
+	Score int
+}
+
+func (ScoreV2) Name() string { return "score" }
+
+func TestSnapshotIsMigratedFromItsSchema(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("CARDINAL_SNAPSHOT_INTERVAL", "2")
+	t.Setenv("CARDINAL_SNAPSHOT_DIR", dir)
+	ctx := t.Context()
+	startWorld := func(world *World) {
+		started := make(chan error, 1)
+		go func() {
+			started <- world.StartGame()
+		}()
+		select {
+		case err := <-started:
+			t.Fatalf("the world failed to start: %v", err)
+		case <-world.worldStage.NotifyOnStage(worldstage.Running):
+		}
+	}
+
+	// The snapshot taken after tick 2 holds the first version of the component.
+	t.Setenv("REDIS_ADDRESS", miniredis.RunT(t).Addr())
+	world, err := NewWorld(WithPort(getOpenPort(t)))
+	assert.NilError(t, err)
+	assert.NilError(t, RegisterComponent[ScoreV1](world))
+	startWorld(world)
+	id, err := Create(NewWorldContext(world), ScoreV1{Points: 7})
+	assert.NilError(t, err)
+	for range 2 {
+		world.tickTheEngine(ctx, nil)
+	}
+	world.Shutdown()
+	CleanupViper(t)
+
+	// The storage of the second world only knows the second version, so the snapshot must be migrated from the schema
+	// it was taken with.
+	t.Setenv("REDIS_ADDRESS", miniredis.RunT(t).Addr())
+	world, err = NewWorld(WithPort(getOpenPort(t)))
+	assert.NilError(t, err)
+	assert.NilError(t, RegisterComponentMigration(world, 1, func(old ScoreV1) (ScoreV2, error) {
+		return ScoreV2{Score: old.Points * 10}, nil
+	}))
+	assert.NilError(t, RegisterComponent[ScoreV2](world))
+	startWorld(world)
+	assert.Equal(t, uint64(2), world.CurrentTick())
+
+	score, err := GetComponent[ScoreV2](NewWorldContext(world), id)
+	assert.NilError(t, err)
+	assert.Equal(t, *score, ScoreV2{Score: 70})
+	assert.Equal(t, len(world.PendingSchemaMigrations()), 0)
+	world.Shutdown()
+	CleanupViper(t)
+}
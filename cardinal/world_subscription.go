@@ -0,0 +1,387 @@
+package cardinal
+
+import (
+	"encoding/json"
+	"errors"
+	"maps"
+	"slices"
+	"sync"
+	"sync/atomic"
+
+	"github.com/rotisserie/eris"
+	"github.com/rs/zerolog/log"
+
+	"pkg.world.dev/world-engine/cardinal/filter"
+	"pkg.world.dev/world-engine/cardinal/gamestate"
+	"pkg.world.dev/world-engine/cardinal/server/handler/cql"
+	"pkg.world.dev/world-engine/cardinal/types"
+)
+
+// subscription tracks the entities selected by a subscriber, so that only the changes of those entities are sent
+// after each tick.
+type subscription struct {
+	filter filter.ComponentFilter
+	where  cql.Predicate
+	// selected holds the names of the components that are sent, or nil if every component is sent.
+	selected []string
+	// matched is only accessed by the worker of the subscription manager.
+	matched map[types.EntityID]struct{}
+	send    func(types.SubscriptionUpdate)
+	closed  atomic.Bool
+}
+
+// subscriptionManager holds the subscriptions of a world. The snapshots of new subscriptions and the changes of the
+// ticks are computed and sent by a single worker, in the order they were requested, so that a subscriber never misses
+// the changes of a tick between its snapshot and its first update, and the tick loop never waits for subscribers. The
+// lock only guards the queue of the worker and the set of subscriptions.
+type subscriptionManager struct {
+	mux           sync.Mutex
+	nextID        uint64
+	subscriptions map[uint64]*subscription
+	// jobs are run in order by the worker, which runs while there are jobs.
+	jobs    []func()
+	running bool
+}
+
+func newSubscriptionManager() *subscriptionManager {
+	return &subscriptionManager{subscriptions: make(map[uint64]*subscription)}
+}
+
+// enqueue adds a job to the queue of the worker, and starts the worker if it is not running.
+func (m *subscriptionManager) enqueue(job func()) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	m.jobs = append(m.jobs, job)
+	if !m.running {
+		m.running = true
+		go m.work()
+	}
+}
+
+// work runs the jobs of the queue until it is empty.
+func (m *subscriptionManager) work() {
+	for {
+		m.mux.Lock()
+		if len(m.jobs) == 0 {
+			m.running = false
+			m.mux.Unlock()
+			return
+		}
+		job := m.jobs[0]
+		m.jobs[0] = nil
+		m.jobs = m.jobs[1:]
+		m.mux.Unlock()
+		job()
+	}
+}
+
+// active returns the subscriptions that have not been unsubscribed, in the order they were made.
+func (m *subscriptionManager) active() []*subscription {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	ids := slices.Sorted(maps.Keys(m.subscriptions))
+	subs := make([]*subscription, 0, len(ids))
+	for _, id := range ids {
+		subs = append(subs, m.subscriptions[id])
+	}
+	return subs
+}
+
+// Subscribe sends a snapshot of the entities selected by the request to send, and then, after every tick, the
+// changes of the selected entities, until unsubscribe is called. send is called by the worker that publishes the
+// changes of the ticks to every subscriber, so it must not block.
+func (w *World) Subscribe(req types.SubscriptionRequest, send func(types.SubscriptionUpdate)) (
+	unsubscribe func(), err error,
+) {
+	sub, err := w.newSubscription(req, send)
+	if err != nil {
+		return nil, err
+	}
+
+	// The snapshot is taken by the worker, after the changes of the ticks that were already published, so that the
+	// subscription gets the changes of every later tick.
+	m := w.subscriptions
+	done := make(chan error, 1)
+	var id uint64
+	m.enqueue(func() {
+		update, err := w.subscriptionSnapshot(sub)
+		if err != nil {
+			done <- err
+			return
+		}
+		send(update)
+		m.mux.Lock()
+		id = m.nextID
+		m.nextID++
+		m.subscriptions[id] = sub
+		m.mux.Unlock()
+		done <- nil
+	})
+	if err := <-done; err != nil {
+		return nil, err
+	}
+	return func() {
+		sub.closed.Store(true)
+		m.mux.Lock()
+		defer m.mux.Unlock()
+		delete(m.subscriptions, id)
+	}, nil
+}
+
+// subscriptionSnapshot returns every entity selected by the subscription, and marks them as matched.
+func (w *World) subscriptionSnapshot(sub *subscription) (types.SubscriptionUpdate, error) {
+	ids, err := NewSearch().Entity(sub.filter).Collect(NewReadOnlyWorldContext(w))
+	if err != nil {
+		return types.SubscriptionUpdate{}, err
+	}
+	update := types.SubscriptionUpdate{
+		Tick:     w.lastFinalizedTick(),
+		Snapshot: true,
+		Entities: make([]types.EntityDelta, 0),
+		Removed:  make([]types.EntityID, 0),
+	}
+	for _, id := range ids {
+		if sub.where != nil {
+			ok, err := sub.where.Evaluate(w.cqlComponentGetter(id))
+			if err != nil {
+				return update, err
+			}
+			if !ok {
+				continue
+			}
+		}
+		delta, err := w.selectedComponents(sub, id)
+		if err != nil {
+			return update, err
+		}
+		sub.matched[id] = struct{}{}
+		update.Entities = append(update.Entities, delta)
+	}
+	return update, nil
+}
+
+func (w *World) newSubscription(req types.SubscriptionRequest, send func(types.SubscriptionUpdate)) (
+	*subscription, error,
+) {
+	sub := &subscription{matched: make(map[types.EntityID]struct{}), send: send}
+	switch {
+	case req.CQL != "" && len(req.Components) > 0:
+		return nil, eris.New("a subscription must select entities with either cql or components, not both")
+	case req.CQL != "":
+		query, err := cql.ParseQuery(req.CQL, func(name string) (types.Component, error) {
+			return w.GetComponentByName(name)
+		})
+		if err != nil {
+			return nil, eris.Wrap(err, "failed to parse cql")
+		}
+		if len(query.OrderBy) > 0 || query.Limit != nil || query.Offset > 0 {
+			return nil, eris.New("subscriptions do not support ORDER BY, LIMIT and OFFSET")
+		}
+		sub.filter = query.Filter
+		sub.where = query.Where
+		for _, c := range query.Select {
+			sub.selected = append(sub.selected, c.Name())
+		}
+	case len(req.Components) > 0:
+		components := make([]filter.ComponentWrapper, 0, len(req.Components))
+		for _, name := range req.Components {
+			c, err := w.GetComponentByName(name)
+			if err != nil {
+				return nil, err
+			}
+			components = append(components, filter.ComponentWrapper{Component: c})
+		}
+		sub.filter = filter.Contains(components...)
+		sub.selected = req.Components
+	default:
+		return nil, eris.New("a subscription must select entities with cql or components")
+	}
+	return sub, nil
+}
+
+// publishSubscriptions queues the changes of the last tick, to be sent to every subscriber whose entities changed.
+// It returns without waiting for the changes to be sent.
+func (w *World) publishSubscriptions(diff *gamestate.TickDiff) {
+	m := w.subscriptions
+	m.mux.Lock()
+	idle := len(m.subscriptions) == 0 && !m.running
+	m.mux.Unlock()
+	if diff == nil || idle {
+		return
+	}
+	tick := w.lastFinalizedTick()
+	m.enqueue(func() {
+		w.sendSubscriptionUpdates(tick, diff)
+	})
+}
+
+// tickChanges are the changes of a tick, by entity, shared by the subscriptions the tick is published to.
+type tickChanges struct {
+	ids []types.EntityID
+	// values are the new values of the components that changed. A removed component is null.
+	values map[types.EntityID]map[string]json.RawMessage
+	// moved are the entities that were created or removed, or whose set of components changed.
+	moved map[types.EntityID]struct{}
+	// components caches the components of the entities, which are only looked up once per tick. An entity that does
+	// not exist has no components.
+	components map[types.EntityID][]types.ComponentMetadata
+}
+
+func newTickChanges(diff *gamestate.TickDiff) *tickChanges {
+	c := &tickChanges{
+		values:     make(map[types.EntityID]map[string]json.RawMessage),
+		moved:      make(map[types.EntityID]struct{}),
+		components: make(map[types.EntityID][]types.ComponentMetadata),
+	}
+	changed := func(id types.EntityID) map[string]json.RawMessage {
+		if c.values[id] == nil {
+			c.values[id] = make(map[string]json.RawMessage)
+		}
+		return c.values[id]
+	}
+	for _, id := range slices.Concat(diff.CreatedEntities, diff.RemovedEntities, diff.MovedEntities) {
+		changed(id)
+		c.moved[id] = struct{}{}
+	}
+	for _, change := range diff.Components {
+		value := change.NewValue
+		if len(value) == 0 {
+			value = json.RawMessage("null")
+		}
+		changed(change.EntityID)[change.Component] = value
+	}
+	c.ids = slices.Sorted(maps.Keys(c.values))
+	return c
+}
+
+// sendSubscriptionUpdates sends the changes of a tick to every subscriber whose entities changed.
+func (w *World) sendSubscriptionUpdates(tick uint64, diff *gamestate.TickDiff) {
+	subs := w.subscriptions.active()
+	if len(subs) == 0 {
+		return
+	}
+	changes := newTickChanges(diff)
+	for _, sub := range subs {
+		update, err := w.subscriptionUpdate(sub, tick, changes)
+		if err != nil {
+			log.Error().Err(err).Msg("failed to compute subscription update")
+			continue
+		}
+		if (len(update.Entities) > 0 || len(update.Removed) > 0) && !sub.closed.Load() {
+			sub.send(update)
+		}
+	}
+}
+
+// subscriptionUpdate returns the changes of the entities selected by the subscription. An entity that starts to
+// match, or whose set of components changed, is sent with every selected component, because a component added with
+// its default value is not part of the changes.
+func (w *World) subscriptionUpdate(sub *subscription, tick uint64, changes *tickChanges) (
+	types.SubscriptionUpdate, error,
+) {
+	update := types.SubscriptionUpdate{
+		Tick:     tick,
+		Entities: make([]types.EntityDelta, 0),
+		Removed:  make([]types.EntityID, 0),
+	}
+	for _, id := range changes.ids {
+		_, wasMatched := sub.matched[id]
+		_, moved := changes.moved[id]
+		// The components of an entity that was not moved are the same, so it can only start to match a filter
+		// without a where clause if it was moved.
+		if !wasMatched && !moved && sub.where == nil {
+			continue
+		}
+		ok, err := w.subscriptionMatches(sub, id, changes)
+		if err != nil {
+			return update, err
+		}
+		switch {
+		case ok && (!wasMatched || moved):
+			delta, err := w.selectedComponents(sub, id)
+			if err != nil {
+				return update, err
+			}
+			// Removed components are not part of the entity anymore, so they are only known from the changes.
+			for name, value := range changes.values[id] {
+				if _, ok := delta.Components[name]; !ok && sub.isSelected(name) {
+					delta.Components[name] = value
+				}
+			}
+			sub.matched[id] = struct{}{}
+			update.Entities = append(update.Entities, delta)
+		case ok:
+			delta := types.EntityDelta{ID: id, Components: make(map[string]json.RawMessage)}
+			for name, value := range changes.values[id] {
+				if sub.isSelected(name) {
+					delta.Components[name] = value
+				}
+			}
+			if len(delta.Components) > 0 {
+				update.Entities = append(update.Entities, delta)
+			}
+		case wasMatched:
+			delete(sub.matched, id)
+			update.Removed = append(update.Removed, id)
+		}
+	}
+	return update, nil
+}
+
+func (sub *subscription) isSelected(component string) bool {
+	return sub.selected == nil || slices.Contains(sub.selected, component)
+}
+
+// subscriptionMatches reports whether the entity exists and is selected by the subscription.
+func (w *World) subscriptionMatches(sub *subscription, id types.EntityID, changes *tickChanges) (bool, error) {
+	components, ok := changes.components[id]
+	if !ok {
+		var err error
+		components, err = w.StoreReader().GetComponentTypesForEntity(id)
+		if errors.Is(err, gamestate.ErrKeyNotFound) {
+			components = nil
+		} else if err != nil {
+			return false, err
+		}
+		changes.components[id] = components
+	}
+	if components == nil {
+		return false, nil
+	}
+	if !sub.filter.MatchesComponents(types.ConvertComponentMetadatasToComponents(components)) {
+		return false, nil
+	}
+	if sub.where == nil {
+		return true, nil
+	}
+	return sub.where.Evaluate(w.cqlComponentGetter(id))
+}
+
+// selectedComponents returns the components of the entity that are sent to the subscriber. Selected components that
+// the entity does not have are null.
+func (w *World) selectedComponents(sub *subscription, id types.EntityID) (types.EntityDelta, error) {
+	if sub.selected == nil {
+		element, err := debugStateElement(w.StoreReader(), id)
+		return types.EntityDelta{ID: id, Components: element.Components}, err
+	}
+	delta := types.EntityDelta{ID: id, Components: make(map[string]json.RawMessage, len(sub.selected))}
+	getComponent := w.cqlComponentGetter(id)
+	for _, name := range sub.selected {
+		data, ok, err := getComponent(name)
+		if err != nil {
+			return delta, err
+		} else if !ok {
+			data = json.RawMessage("null")
+		}
+		delta.Components[name] = data
+	}
+	return delta, nil
+}
+
+// lastFinalizedTick returns the number of the last tick whose changes are stored, or 0 if no tick was finalized.
+func (w *World) lastFinalizedTick() uint64 {
+	if tick := w.CurrentTick(); tick > 0 {
+		return tick - 1
+	}
+	return 0
+}
@@ -0,0 +1,141 @@
+package cardinal_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"pkg.world.dev/world-engine/assert"
+	"pkg.world.dev/world-engine/cardinal"
+	"pkg.world.dev/world-engine/cardinal/types"
+)
+
+func TestSubscribeSendsSnapshotAndChanges(t *testing.T) {
+	tf := cardinal.NewTestFixture(t, nil)
+	world := tf.World
+	assert.NilError(t, cardinal.RegisterComponent[Health](world))
+	assert.NilError(t, cardinal.RegisterComponent[Foo](world))
+	tf.DoTick()
+
+	wCtx := cardinal.NewWorldContext(world)
+	first, err := cardinal.Create(wCtx, Health{Value: 1}, Foo{})
+	assert.NilError(t, err)
+	second, err := cardinal.Create(wCtx, Foo{})
+	assert.NilError(t, err)
+	tf.DoTick()
+
+	updates := make(chan types.SubscriptionUpdate, 10)
+	unsubscribe, err := world.Subscribe(
+		types.SubscriptionRequest{Components: []string{"health"}},
+		func(update types.SubscriptionUpdate) { updates <- update },
+	)
+	assert.NilError(t, err)
+
+	snapshot := <-updates
+	assert.Check(t, snapshot.Snapshot)
+	assert.Equal(t, len(snapshot.Entities), 1)
+	assert.Equal(t, snapshot.Entities[0].ID, first)
+	assert.DeepEqual(t, snapshot.Entities[0].Components, map[string]json.RawMessage{
+		"health": json.RawMessage(`{"Value":1}`),
+	})
+
+	// The first entity changes, and the second entity gets the subscribed component.
+	wCtx = cardinal.NewWorldContext(world)
+	assert.NilError(t, cardinal.SetComponent[Health](wCtx, first, &Health{Value: 2}))
+	assert.NilError(t, cardinal.AddComponentTo[Health](wCtx, second))
+	tf.DoTick()
+
+	update := <-updates
+	assert.Check(t, !update.Snapshot)
+	assert.Equal(t, len(update.Entities), 2)
+	assert.Equal(t, update.Entities[0].ID, first)
+	assert.Equal(t, string(update.Entities[0].Components["health"]), `{"Value":2}`)
+	assert.Equal(t, update.Entities[1].ID, second)
+	assert.Equal(t, string(update.Entities[1].Components["health"]), `{"Value":0}`)
+	assert.Equal(t, len(update.Removed), 0)
+
+	// Changes of components that are not subscribed to are not sent.
+	wCtx = cardinal.NewWorldContext(world)
+	assert.NilError(t, cardinal.SetComponent[Foo](wCtx, first, &Foo{}))
+	tf.DoTick()
+	assert.Equal(t, len(updates), 0)
+
+	// An entity whose set of components changed is sent with every subscribed component.
+	wCtx = cardinal.NewWorldContext(world)
+	assert.NilError(t, cardinal.RemoveComponentFrom[Foo](wCtx, second))
+	tf.DoTick()
+
+	update = <-updates
+	assert.Equal(t, len(update.Entities), 1)
+	assert.Equal(t, update.Entities[0].ID, second)
+	assert.Equal(t, string(update.Entities[0].Components["health"]), `{"Value":0}`)
+
+	// The first entity no longer matches the subscription.
+	wCtx = cardinal.NewWorldContext(world)
+	assert.NilError(t, cardinal.RemoveComponentFrom[Health](wCtx, first))
+	tf.DoTick()
+
+	update = <-updates
+	assert.Equal(t, len(update.Entities), 0)
+	assert.DeepEqual(t, update.Removed, []types.EntityID{first})
+
+	unsubscribe()
+	wCtx = cardinal.NewWorldContext(world)
+	assert.NilError(t, cardinal.SetComponent[Health](wCtx, second, &Health{Value: 3}))
+	tf.DoTick()
+	assert.Equal(t, len(updates), 0)
+}
+
+func TestSubscribeWithCQL(t *testing.T) {
+	tf := cardinal.NewTestFixture(t, nil)
+	world := tf.World
+	assert.NilError(t, cardinal.RegisterComponent[Health](world))
+	tf.DoTick()
+
+	wCtx := cardinal.NewWorldContext(world)
+	ids, err := cardinal.CreateMany(wCtx, 2, Health{Value: 1})
+	assert.NilError(t, err)
+	tf.DoTick()
+
+	updates := make(chan types.SubscriptionUpdate, 10)
+	_, err = world.Subscribe(
+		types.SubscriptionRequest{CQL: "CONTAINS(health) WHERE health.Value > 5"},
+		func(update types.SubscriptionUpdate) { updates <- update },
+	)
+	assert.NilError(t, err)
+	assert.Equal(t, len((<-updates).Entities), 0)
+
+	wCtx = cardinal.NewWorldContext(world)
+	assert.NilError(t, cardinal.SetComponent[Health](wCtx, ids[1], &Health{Value: 10}))
+	tf.DoTick()
+
+	update := <-updates
+	assert.Equal(t, len(update.Entities), 1)
+	assert.Equal(t, update.Entities[0].ID, ids[1])
+	assert.Equal(t, string(update.Entities[0].Components["health"]), `{"Value":10}`)
+}
+
+func TestSubscribeRejectsInvalidRequests(t *testing.T) {
+	tf := cardinal.NewTestFixture(t, nil)
+	world := tf.World
+	assert.NilError(t, cardinal.RegisterComponent[Health](world))
+	tf.DoTick()
+
+	testCases := []struct {
+		name string
+		req  types.SubscriptionRequest
+	}{
+		{name: "empty", req: types.SubscriptionRequest{}},
+		{name: "cql and components", req: types.SubscriptionRequest{CQL: "CONTAINS(health)", Components: []string{"health"}}},
+		{name: "limit", req: types.SubscriptionRequest{CQL: "CONTAINS(health) LIMIT 1"}},
+		{name: "unknown component", req: types.SubscriptionRequest{Components: []string{"missing"}}},
+		{name: "invalid cql", req: types.SubscriptionRequest{CQL: "CONTAINS("}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := world.Subscribe(tc.req, func(types.SubscriptionUpdate) {
+				t.Fatal("an invalid subscription must not send a snapshot")
+			})
+			assert.Check(t, err != nil)
+		})
+	}
+}
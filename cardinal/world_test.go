@@ -384,3 +384,55 @@ func getOpenPort(t testing.TB) string {
 	assert.NilError(t, err)
 	return strconv.Itoa(tcpAddr.Port)
 }
+
+func TestCanRecoverStateFromBadgerStorage(t *testing.T) {
+	t.Setenv("CARDINAL_STORAGE_BACKEND", StorageBackendBadger)
+	t.Setenv("CARDINAL_STORAGE_PATH", t.TempDir())
+
+	ctx := t.Context()
+
+	for _, isFirstIteration := range []bool{true, false} {
+		world, err := NewWorld(WithPort(getOpenPort(t)))
+		assert.NilError(t, err)
+		assert.NilError(t, RegisterComponent[ScalarComponentStatic](world))
+		assert.NilError(t, RegisterSystems(world, func(wCtx WorldContext) error {
+			return NewSearch().Entity(filter.Contains(filter.Component[ScalarComponentStatic]())).
+				Each(wCtx, func(id types.EntityID) bool {
+					assert.NilError(t, UpdateComponent[ScalarComponentStatic](wCtx, id,
+						func(s *ScalarComponentStatic) *ScalarComponentStatic {
+							s.Val++
+							return s
+						}))
+					return true
+				})
+		}))
+
+		go func() {
+			assert.NilError(t, world.StartGame())
+		}()
+		<-world.worldStage.NotifyOnStage(worldstage.Running)
+
+		wCtx := NewWorldContext(world)
+		if isFirstIteration {
+			_, err = Create(wCtx, ScalarComponentStatic{})
+			assert.NilError(t, err)
+		}
+
+		world.tickTheEngine(ctx, nil)
+		world.tickTheEngine(ctx, nil)
+
+		id, err := NewSearch().Entity(filter.Contains(filter.Component[ScalarComponentStatic]())).First(wCtx)
+		assert.NilError(t, err)
+		s, err := GetComponent[ScalarComponentStatic](wCtx, id)
+		assert.NilError(t, err)
+		if isFirstIteration {
+			assert.Equal(t, 2, s.Val)
+		} else {
+			// The state from the first run was persisted to disk and picked up on restart.
+			assert.Equal(t, 4, s.Val)
+		}
+
+		world.Shutdown()
+		CleanupViper(t)
+	}
+}
@@ -0,0 +1,154 @@
+package cardinal
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"pkg.world.dev/world-engine/cardinal/types"
+)
+
+const (
+	// maxCatchUpTicks is the maximum number of late ticks run after a tick overran its budget. The ticks beyond it are
+	// skipped, so that a world that can't keep up with its tick rate does not fall further and further behind.
+	maxCatchUpTicks = 10
+	// slowestSystemsReported is the number of systems reported for a tick that overran its budget.
+	slowestSystemsReported = 5
+)
+
+// tickMonitor tracks the durations of the ticks run by the game loop against their budget, which is the period of
+// the tick rate.
+type tickMonitor struct {
+	budget time.Duration
+	policy string
+
+	mux           sync.Mutex
+	lastTick      time.Duration
+	maxTick       time.Duration
+	overruns      uint64
+	skippedTicks  uint64
+	caughtUpTicks uint64
+	// lastOverrunTick is the last tick that overran its budget, and slowestSystems holds its slowest systems.
+	lastOverrunTick *uint64
+	slowestSystems  []types.SystemDuration
+}
+
+func newTickMonitor(tickRate uint64, policy string) *tickMonitor {
+	budget := time.Second
+	if tickRate > 0 {
+		budget = time.Second / time.Duration(tickRate) //nolint:gosec // the tick rate is a small number
+	}
+	return &tickMonitor{
+		budget:          budget,
+		policy:          policy,
+		lastOverrunTick: nil,
+		slowestSystems:  []types.SystemDuration{},
+	}
+}
+
+// record records the duration of a tick, and the time each of its systems ran for. It returns the number of ticks
+// that should have started while the tick ran, which is 0 unless the tick overran its budget.
+func (m *tickMonitor) record(tick uint64, d time.Duration, systems map[string]time.Duration) int {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	m.lastTick = d
+	m.maxTick = max(m.maxTick, d)
+	if d <= m.budget {
+		return 0
+	}
+
+	missed := int((d - 1) / m.budget)
+	m.overruns++
+	m.lastOverrunTick = &tick
+	m.slowestSystems = slowestSystems(systems)
+	names := make([]string, len(m.slowestSystems))
+	for i, sys := range m.slowestSystems {
+		names[i] = fmt.Sprintf("%s (%.2fms)", sys.Name, sys.DurationMs)
+	}
+	log.Warn().
+		Uint64("tick", tick).
+		Str("duration", d.String()).
+		Str("budget", m.budget.String()).
+		Int("missed_ticks", missed).
+		Str("slowest_systems", strings.Join(names, ", ")).
+		Msg("Tick took longer than its budget")
+	return missed
+}
+
+// recordMissed records what happened to the ticks that should have started while a tick overran its budget.
+func (m *tickMonitor) recordMissed(skipped, caughtUp int) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	m.skippedTicks += uint64(skipped)   //nolint:gosec // never negative
+	m.caughtUpTicks += uint64(caughtUp) //nolint:gosec // never negative
+}
+
+func (m *tickMonitor) report() types.TickHealth {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	return types.TickHealth{
+		BudgetMs:        toMilliseconds(m.budget),
+		OverrunPolicy:   m.policy,
+		LastTickMs:      toMilliseconds(m.lastTick),
+		MaxTickMs:       toMilliseconds(m.maxTick),
+		Overruns:        m.overruns,
+		SkippedTicks:    m.skippedTicks,
+		CaughtUpTicks:   m.caughtUpTicks,
+		LastOverrunTick: m.lastOverrunTick,
+		SlowestSystems:  m.slowestSystems,
+	}
+}
+
+// slowestSystems returns the slowest of the given systems, slowest first.
+func slowestSystems(systems map[string]time.Duration) []types.SystemDuration {
+	names := slices.SortedFunc(maps.Keys(systems), func(a, b string) int {
+		return cmp.Or(cmp.Compare(systems[b], systems[a]), strings.Compare(a, b))
+	})
+	names = names[:min(len(names), slowestSystemsReported)]
+	slowest := make([]types.SystemDuration, len(names))
+	for i, name := range names {
+		slowest[i] = types.SystemDuration{Name: name, DurationMs: toMilliseconds(systems[name])}
+	}
+	return slowest
+}
+
+func toMilliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// runTicks runs the tick started by the tick channel, and applies the tick overrun policy if it overran its budget.
+// With TickOverrunCatchUp, the ticks that should have started while the tick ran are run right after it, and may
+// overrun in turn. With TickOverrunSkip, they are skipped. In both cases, the tick that the ticker queued during the
+// overrun is dropped, unless the tick channel was passed with WithTickChannel, since its ticks are then decided by
+// the caller.
+func (w *World) runTicks(ctx context.Context, tickStart <-chan time.Time, tickDone chan<- uint64) {
+	// pending is the number of late ticks that are still to be run.
+	pending := 0
+	for {
+		if missed := w.tickTheEngine(context.Background(), tickDone); missed > 0 {
+			if w.ownsTickChannel {
+				select {
+				case <-tickStart:
+				default:
+				}
+			}
+			caughtUp := 0
+			if w.tickMonitor.policy == TickOverrunCatchUp {
+				caughtUp = min(missed, maxCatchUpTicks-pending)
+				pending += caughtUp
+			}
+			w.tickMonitor.recordMissed(missed-caughtUp, caughtUp)
+		}
+		if pending == 0 || ctx.Err() != nil {
+			return
+		}
+		pending--
+	}
+}
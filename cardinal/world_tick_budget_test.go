@@ -0,0 +1,43 @@
+package cardinal_test
+
+import (
+	"testing"
+	"time"
+
+	"pkg.world.dev/world-engine/assert"
+	"pkg.world.dev/world-engine/cardinal"
+)
+
+func TestLateTicksAreCaughtUp(t *testing.T) {
+	// A tick rate of 10 ticks per second gives each tick a budget of 100ms.
+	t.Setenv("CARDINAL_TICK_RATE", "10")
+	t.Setenv("CARDINAL_TICK_OVERRUN_POLICY", cardinal.TickOverrunCatchUp)
+	tf := cardinal.NewTestFixture(t, nil)
+	world := tf.World
+	assert.NilError(t, cardinal.RegisterSystems(world, func(wCtx cardinal.WorldContext) error {
+		if wCtx.CurrentTick() == 1 {
+			time.Sleep(250 * time.Millisecond)
+		}
+		return nil
+	}))
+	tf.DoTick()
+	tf.DoTick()
+
+	// Two ticks should have started while tick 1 ran, so they run right after it without being started.
+	for _, want := range []uint64{2, 3} {
+		select {
+		case tick := <-tf.DoneTickCh:
+			assert.Equal(t, tick, want)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("tick %d was not caught up", want)
+		}
+	}
+
+	ticks := world.Health().Ticks
+	assert.Equal(t, ticks.OverrunPolicy, cardinal.TickOverrunCatchUp)
+	assert.Equal(t, ticks.Overruns, uint64(1))
+	assert.Equal(t, *ticks.LastOverrunTick, uint64(1))
+	assert.Check(t, ticks.CaughtUpTicks >= 2)
+	assert.Equal(t, ticks.SkippedTicks, uint64(0))
+	assert.Check(t, ticks.MaxTickMs >= 250)
+}
@@ -29,6 +29,7 @@ var (
 	ErrCannotSignEmptyBody       = errors.New("cannot sign empty body")
 	ErrInvalidPersonaTag         = errors.New("invalid persona tag")
 	ErrInvalidNamespace          = errors.New("invalid namespace")
+	ErrEmptyBatch                = errors.New("a batch must contain at least one message")
 
 	ErrNoPersonaTagField = errors.New("transaction must contain personaTag field")
 	ErrNoNamespaceField  = errors.New("transaction must contain namespace field")
@@ -37,11 +38,25 @@ var (
 	ErrNoTimestampField  = errors.New("transaction must contain timestamp field")
 )
 
+// BatchMessage is one of the messages of a batch transaction. Message is the full name of a registered message, e.g.
+// "game.craft", and Body is the JSON encoded message.
+type BatchMessage struct {
+	Message string          `json:"message"`
+	Body    json.RawMessage `json:"body" swaggertype:"object"`
+}
+
+// Batch is the body of a batch transaction. The messages of a batch are signed together, and they are processed in
+// the same tick, in order, or not at all.
+type Batch struct {
+	Messages []BatchMessage `json:"messages"`
+}
+
 type Transaction struct {
 	PersonaTag string          `json:"personaTag"`
 	Namespace  string          `json:"namespace"`
 	Timestamp  int64           `json:"timestamp"`                 // unix millisecond timestamp
 	Salt       uint16          `json:"salt,omitempty"`            // an optional field for additional hash uniqueness
+	Fee        uint64          `json:"fee,omitempty"`             // an optional fee paid to process the transaction
 	Signature  string          `json:"signature"`                 // hex encoded string
 	Hash       common.Hash     `json:"-"`                         // don't marshal or unmarshal for json
 	Body       json.RawMessage `json:"body" swaggertype:"object"` // json string
@@ -111,6 +126,7 @@ func MappedTransaction(tx map[string]interface{}) (*Transaction, error) {
 		"signature":  true,
 		"timestamp":  true,
 		"salt":       true,
+		"fee":        true,
 		"body":       true,
 		"hash":       true,
 	}
@@ -174,9 +190,9 @@ func normalizeJSON(data any) ([]byte, error) {
 	return normalizedBz, nil
 }
 
-// sign uses the given private key to sign the personaTag, namespace, timestamp, and data. The timestamp is set
+// sign uses the given private key to sign the personaTag, namespace, timestamp, fee, and data. The timestamp is set
 // automatically to the wall time by the sign function just before signing.
-func sign(pk *ecdsa.PrivateKey, personaTag, namespace string, data any) (*Transaction, error) {
+func sign(pk *ecdsa.PrivateKey, personaTag, namespace string, fee uint64, data any) (*Transaction, error) {
 	if data == nil || reflect.ValueOf(data).IsZero() {
 		return nil, ErrCannotSignEmptyBody
 	}
@@ -195,6 +211,7 @@ func sign(pk *ecdsa.PrivateKey, personaTag, namespace string, data any) (*Transa
 		Namespace:  namespace,
 		Timestamp:  TimestampNow(),
 		Salt:       uint16(rand.Intn(math.MaxUint16)), //nolint: gosec // additional uniqueness for each hash and sign
+		Fee:        fee,
 		Body:       bz,
 	}
 	sp.populateHash()
@@ -208,7 +225,7 @@ func sign(pk *ecdsa.PrivateKey, personaTag, namespace string, data any) (*Transa
 
 // NewSystemTransaction signs a given body with the given private key using the SystemPersonaTag.
 func NewSystemTransaction(pk *ecdsa.PrivateKey, namespace string, data any) (*Transaction, error) {
-	return sign(pk, SystemPersonaTag, namespace, data)
+	return sign(pk, SystemPersonaTag, namespace, 0, data)
 }
 
 // NewTransaction signs a given body, tag, and nonce with the given private key.
@@ -217,11 +234,46 @@ func NewTransaction(
 	personaTag,
 	namespace string,
 	data any,
+) (*Transaction, error) {
+	return NewTransactionWithFee(pk, personaTag, namespace, 0, data)
+}
+
+// NewTransactionWithFee signs a given body, tag, and fee with the given private key. The fee is covered by the
+// signature, so it can't be changed without invalidating the transaction.
+func NewTransactionWithFee(
+	pk *ecdsa.PrivateKey,
+	personaTag,
+	namespace string,
+	fee uint64,
+	data any,
 ) (*Transaction, error) {
 	if len(personaTag) == 0 || personaTag == SystemPersonaTag {
 		return nil, ErrInvalidPersonaTag
 	}
-	return sign(pk, personaTag, namespace, data)
+	return sign(pk, personaTag, namespace, fee, data)
+}
+
+// NewBatchMessage encodes the body of a message of a batch transaction. message is the full name of the message.
+func NewBatchMessage(message string, body any) (BatchMessage, error) {
+	bz, err := json.Marshal(body)
+	if err != nil {
+		return BatchMessage{}, eris.Wrapf(err, "failed to encode the body of %q", message)
+	}
+	return BatchMessage{Message: message, Body: bz}, nil
+}
+
+// NewBatchTransaction signs the given messages and tag with the given private key. The body of the transaction
+// is a Batch.
+func NewBatchTransaction(
+	pk *ecdsa.PrivateKey,
+	personaTag,
+	namespace string,
+	messages ...BatchMessage,
+) (*Transaction, error) {
+	if len(messages) == 0 {
+		return nil, ErrEmptyBatch
+	}
+	return NewTransaction(pk, personaTag, namespace, Batch{Messages: messages})
 }
 
 func (s *Transaction) IsSystemTransaction() bool {
@@ -276,7 +328,18 @@ func (s *Transaction) Verify(hexAddress string) error {
 }
 
 func (s *Transaction) populateHash() {
-	if s.Salt != 0 {
+	if s.Fee != 0 {
+		// The fee is only hashed when it is set, so the hashes of transactions without a fee don't change. It is
+		// prefixed so that it can't be mistaken for the salt.
+		s.Hash = crypto.Keccak256Hash(
+			[]byte(s.PersonaTag),
+			[]byte(s.Namespace),
+			[]byte(strconv.FormatInt(s.Timestamp, 10)),
+			[]byte(strconv.FormatInt(int64(s.Salt), 10)),
+			[]byte("fee"+strconv.FormatUint(s.Fee, 10)),
+			s.Body,
+		)
+	} else if s.Salt != 0 {
 		s.Hash = crypto.Keccak256Hash(
 			[]byte(s.PersonaTag),
 			[]byte(s.Namespace),
@@ -2,6 +2,7 @@ package sign
 
 import (
 	"encoding/json"
+	"strconv"
 	"testing"
 	"time"
 
@@ -353,3 +354,65 @@ func TestUnsortedJSONBlobsCanBeSignedAndVerified(t *testing.T) {
 
 	assert.NilError(t, gotTx.Verify(addr))
 }
+
+func TestFeeIsCoveredByTheSignature(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	assert.NilError(t, err)
+	addr := crypto.PubkeyToAddress(key.PublicKey).Hex()
+
+	tx, err := NewTransactionWithFee(key, "persona-tag", "namespace", 100, `{"msg": "pay me"}`)
+	assert.NilError(t, err)
+	bz, err := tx.Marshal()
+	assert.NilError(t, err)
+	gotTx, err := UnmarshalTransaction(bz)
+	assert.NilError(t, err)
+	assert.Equal(t, gotTx.Fee, uint64(100))
+	assert.NilError(t, gotTx.Verify(addr))
+
+	// The fee can't be lowered without the signature.
+	gotTx.Fee = 1
+	gotTx.Hash = common.Hash{}
+	assert.ErrorIs(t, eris.Unwrap(gotTx.Verify(addr)), ErrSignatureValidationFailed)
+
+	// A transaction without a fee has the same hash it had before fees were added.
+	noFee := *tx
+	noFee.Fee = 0
+	noFee.populateHash()
+	assert.Equal(t, noFee.Hash, crypto.Keccak256Hash(
+		[]byte(tx.PersonaTag),
+		[]byte(tx.Namespace),
+		[]byte(strconv.FormatInt(tx.Timestamp, 10)),
+		[]byte(strconv.FormatInt(int64(tx.Salt), 10)),
+		tx.Body,
+	))
+}
+
+func TestCanSignABatch(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	assert.NilError(t, err)
+	addr := crypto.PubkeyToAddress(key.PublicKey).Hex()
+
+	craft, err := NewBatchMessage("game.craft", map[string]any{"item": "sword"})
+	assert.NilError(t, err)
+	equip, err := NewBatchMessage("game.equip", map[string]any{"slot": 1})
+	assert.NilError(t, err)
+	tx, err := NewBatchTransaction(key, "persona-tag", "namespace", craft, equip)
+	assert.NilError(t, err)
+
+	bz, err := tx.Marshal()
+	assert.NilError(t, err)
+	gotTx, err := UnmarshalTransaction(bz)
+	assert.NilError(t, err)
+	assert.NilError(t, gotTx.Verify(addr))
+
+	var batch Batch
+	assert.NilError(t, json.Unmarshal(gotTx.Body, &batch))
+	assert.Equal(t, len(batch.Messages), 2)
+	assert.Equal(t, batch.Messages[0].Message, "game.craft")
+	assert.Equal(t, string(batch.Messages[0].Body), `{"item":"sword"}`)
+	assert.Equal(t, batch.Messages[1].Message, "game.equip")
+	assert.Equal(t, string(batch.Messages[1].Body), `{"slot":1}`)
+
+	_, err = NewBatchTransaction(key, "persona-tag", "namespace")
+	assert.ErrorIs(t, err, ErrEmptyBatch)
+}